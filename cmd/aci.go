@@ -15,15 +15,23 @@
 package cmd
 
 import (
+	"fmt"
 	"strings"
 	"time"
 
 	"github.com/cisco-cx/of/info"
 	of "github.com/cisco-cx/of/pkg/v1"
+	of_v2 "github.com/cisco-cx/of/pkg/v2"
 	aci "github.com/cisco-cx/of/wrap/aci/v1"
 	acigo "github.com/cisco-cx/of/wrap/acigo/v1"
 	alertmanager "github.com/cisco-cx/of/wrap/alertmanager/v1"
+	am_v2 "github.com/cisco-cx/of/wrap/alertmanager/v2"
+	http "github.com/cisco-cx/of/wrap/http/v2"
 	profile "github.com/cisco-cx/of/wrap/profile/v1"
+	prometheus "github.com/cisco-cx/of/wrap/prometheus/client_golang/v2"
+	ratelimit "github.com/cisco-cx/of/wrap/ratelimit/v2"
+	sink "github.com/cisco-cx/of/wrap/sink/v2"
+	clock "github.com/cisco-cx/of/wrap/time/v2"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -60,8 +68,18 @@ func runACIHandler(cmd *cobra.Command, args []string) {
 
 	cmd.Flags().String("aci-listen-address", "localhost:9011", "host:port on which to listen, for metrics scraping")
 	cmd.Flags().Int("aci-cycle-interval", 60, "Number of seconds to sleep between APIC -> AM notification cycles (default: 60)")
-	cmd.Flags().String("aci-am-url", "", "[Required] AlertManager's URL")
-	cmd.Flags().SetAnnotation("aci-am-url", "required", []string{"true"})
+	cmd.Flags().String("aci-am-url", "", "AlertManager URLs separated by ',', alerts are posted to each. Required without --aci-am-srv.")
+	cmd.Flags().String("aci-am-srv", "", "DNS SRV name of AlertManager peers, alerts are posted to each. Resolved only at startup, restart to pick up changed peers. (default: none)")
+	cmd.Flags().String("aci-am-policy", "all", "Posting alerts succeeds if any or all AlertManager peers accept them. (default: all)")
+	cmd.Flags().String("aci-am-api-version", "auto", "Alertmanager API version to post alerts to, auto, v1 or v2. auto detects it through /api/v2/status. (default: auto)")
+	cmd.Flags().Duration("aci-am-timeout", 10*time.Second, "Alertmanager timeout  (default: 10s)")
+	cmd.Flags().String("aci-am-basic-auth-user", "", "Username for basic auth to Alertmanager. (default: none)")
+	cmd.Flags().String("aci-am-basic-auth-password-file", "", "Path to file with password for basic auth to Alertmanager, reloaded on change. (default: none)")
+	cmd.Flags().String("aci-am-bearer-token-file", "", "Path to file with bearer token for Alertmanager, reloaded on change. (default: none)")
+	cmd.Flags().String("aci-am-cert-file", "", "Path to client certificate for TLS to Alertmanager, reloaded on change. (default: none)")
+	cmd.Flags().String("aci-am-key-file", "", "Path to client key for TLS to Alertmanager, reloaded on change. (default: none)")
+	cmd.Flags().String("aci-am-ca-file", "", "Path to CA bundle to verify Alertmanager with, reloaded on change. (default: system roots)")
+	cmd.Flags().String("aci-am-proxy-url", "", "HTTP proxy to reach Alertmanager through. (default: from HTTP_PROXY and HTTPS_PROXY)")
 	cmd.Flags().String("aci-hosts", "localhost", "[Required] ACI hosts separated by ',' (Value is ignored when --aci-enable-consul is set")
 	cmd.Flags().SetAnnotation("aci-hosts", "required", []string{"true"})
 	cmd.Flags().String("aci-user", "", "[Required] ACI username")
@@ -73,12 +91,61 @@ func runACIHandler(cmd *cobra.Command, args []string) {
 	cmd.Flags().Duration("aci-timeout", 10*time.Second, "ACI Read/Write timeout  (default: 10s)")
 	cmd.Flags().String("aci-static-labels", "None", staticLabelUsage)
 	cmd.Flags().Bool("aci-throttle", true, "Trottle posts to Alertmanager (default: true)")
+	cmd.Flags().Float64("aci-am-rate", defaultAMRate, "Max. posts per second to Alertmanager, with throttle, 0 for no limit. (default: 5)")
+	cmd.Flags().Int("aci-am-max-batch", defaultAMMaxBatch, "Max. alerts per post to Alertmanager, with throttle, 0 for no limit. (default: 500)")
+	cmd.Flags().Int("aci-am-max-concurrency", defaultAMMaxConcurrency, "Max. posts to Alertmanager in flight at once, with throttle. (default: 1)")
+	cmd.Flags().Duration("aci-am-min-backoff", defaultAMMinBackoff, "Wait between posts once Alertmanager responds with 429 or 5xx, doubled while it continues. (default: 1s)")
+	cmd.Flags().Duration("aci-am-max-backoff", defaultAMMaxBackoff, "Max. wait between posts while Alertmanager is overloaded. (default: 30s)")
 	cmd.Flags().Int("aci-post-time", 300, "Approx time in ms, that it takes to HTTP POST to AM. (default: 300)")
 	cmd.Flags().Int("aci-sleep-time", 100, "Time in ms, to sleep between HTTP POST to AM. (default: 100)")
 	cmd.Flags().Int("aci-send-time", 60000, "Time in ms, to complete HTTP POST to AM. (default: 60000)")
+	cmd.Flags().MarkDeprecated("aci-post-time", fmt.Sprintf(throttleDeprecated, "aci-am-rate"))
+	cmd.Flags().MarkDeprecated("aci-sleep-time", fmt.Sprintf(throttleDeprecated, "aci-am-rate"))
+	cmd.Flags().MarkDeprecated("aci-send-time", fmt.Sprintf(throttleDeprecated, "aci-am-rate"))
 	cmd.Flags().Bool("aci-enable-consul", false, "Whether to use consul for host discovery (default: false)")
 	cmd.Flags().String("consul-aci-group-host", "", "Consul group host used on filtering the host list (empty by default: matches everything)")
 	cmd.Flags().Bool("aci-debug", false, "Enable debug level logs for acigo. (default: false)")
+	cmd.Flags().String("aci-maintenance-file", "", "Path to maintenance schedule file, reloaded on change. Supports drop and tag actions. (default: none)")
+	cmd.Flags().String("aci-queue-dir", "", "Path to directory to queue alerts in, retrying failed posts to AlertManager. (default: none)")
+	cmd.Flags().Duration("aci-queue-max-age", defaultQueueMaxAge, "Age after which queued alerts are moved to dead letters, 0 to retry forever. (default: 24h)")
+	cmd.Flags().Duration("aci-queue-min-backoff", defaultQueueMinBackoff, "Wait before retrying a failed post, doubled on every failure. (default: 1s)")
+	cmd.Flags().Duration("aci-queue-max-backoff", defaultQueueMaxBackoff, "Max. wait before retrying a failed post. (default: 5m)")
+	cmd.Flags().String("aci-sinks", "alertmanager", sinksUsage)
+	cmd.Flags().String("aci-sink-policy", "any", sinkPolicyUsage)
+	cmd.Flags().String("aci-webhook-url", "", "URL the webhook sink posts alerts to. (default: none)")
+	cmd.Flags().String("aci-webhook-template", "", "Path to Go template of webhook sink bodies. (default: alerts as JSON)")
+	cmd.Flags().String("aci-webhook-secret-file", "", "Path to file with key signing webhook sink bodies with HMAC-SHA256. (default: unsigned)")
+	cmd.Flags().Duration("aci-webhook-timeout", 10*time.Second, "Webhook sink timeout. (default: 10s)")
+	cmd.Flags().String("aci-sink-file", "", "Path to file the file sink appends alerts to as NDJSON. (default: none)")
+	cmd.Flags().String("aci-trap-targets", "", "SNMP managers the trap sink notifies, as host[:port] separated by ','. (default: none)")
+	cmd.Flags().String("aci-trap-version", "v2c", "SNMP version of the trap sink, v2c or v3. (default: v2c)")
+	cmd.Flags().String("aci-trap-community", "public", "SNMPv2c community of the trap sink. (default: public)")
+	cmd.Flags().Bool("aci-trap-inform", false, "Send informs acknowledged by managers, instead of traps. (default: false)")
+	cmd.Flags().Int("aci-trap-retries", 2, "Times an unacknowledged inform is resent. (default: 2)")
+	cmd.Flags().Duration("aci-trap-timeout", 5*time.Second, "Wait for the acknowledgement of an inform. (default: 5s)")
+	cmd.Flags().Float64("aci-trap-rate", 0, "Max. SNMP notifications per second to each manager, 0 for no limit. (default: 0)")
+	cmd.Flags().String("aci-trap-mib", "1.3.6.1.4.1.32473.1", "OID OF-ALERT-MIB is registered at by managers, under your enterprise. (default: 1.3.6.1.4.1.32473.1, reserved for documentation)")
+	cmd.Flags().String("aci-trap-user", "", "SNMPv3 user of the trap sink. (default: none)")
+	cmd.Flags().String("aci-trap-auth-protocol", "", "SNMPv3 auth protocol of the trap sink, MD5 or SHA. (default: noAuth)")
+	cmd.Flags().String("aci-trap-auth-passphrase-file", "", "Path to file with the SNMPv3 auth passphrase. (default: none)")
+	cmd.Flags().String("aci-trap-priv-protocol", "", "SNMPv3 privacy protocol of the trap sink, DES or AES. (default: noPriv)")
+	cmd.Flags().String("aci-trap-priv-passphrase-file", "", "Path to file with the SNMPv3 privacy passphrase. (default: none)")
+	cmd.Flags().String("aci-trap-engine-id", "", "SNMPv3 engine ID in hex, traps are sent from. Requires --aci-trap-engine-boots-file. (default: generated)")
+	cmd.Flags().String("aci-trap-engine-boots-file", "", "Path to file keeping SNMPv3 engine boots of --aci-trap-engine-id, incremented on every start. (default: none)")
+	cmd.Flags().String("aci-syslog-address", "", "Syslog collector the syslog sink sends alerts to, as host[:port]. (default: none)")
+	cmd.Flags().String("aci-syslog-transport", "udp", "Transport of the syslog sink, udp, tcp or tls. (default: udp)")
+	cmd.Flags().String("aci-syslog-format", "rfc5424", "Format of syslog sink messages, rfc5424 or rfc3164. (default: rfc5424)")
+	cmd.Flags().String("aci-syslog-facility", "local0", "Facility of syslog sink messages. (default: local0)")
+	cmd.Flags().String("aci-syslog-app-name", "of", "App name of syslog sink messages. (default: of)")
+	cmd.Flags().String("aci-syslog-enterprise", "32473", "Private enterprise number of structured data IDs of syslog sink messages, like labels@32473. (default: 32473, reserved for documentation)")
+	cmd.Flags().Duration("aci-syslog-timeout", 10*time.Second, "Syslog sink connect and write timeout. (default: 10s)")
+	cmd.Flags().String("aci-syslog-cert-file", "", "Path to client certificate of the syslog sink over TLS. (default: none)")
+	cmd.Flags().String("aci-syslog-key-file", "", "Path to client key of the syslog sink over TLS. (default: none)")
+	cmd.Flags().String("aci-syslog-ca-file", "", "Path to CA bundle verifying syslog collectors over TLS. (default: system roots)")
+	cmd.Flags().String("aci-routes-file", "", "Path to file routing alerts to receivers by label, reloaded on change. (default: none, alerts go to sinks)")
+	cmd.Flags().String("aci-audit-file", "", "Path to file to write a JSON record of every alert decision to. (default: none)")
+	cmd.Flags().Int64("aci-audit-file-size", 100, "Size in MB of the audit file, after which it is rotated. (default: 100)")
+	cmd.Flags().Int("aci-audit-files", 10, "Rotated audit files kept. (default: 10)")
 
 	checkRequiredFlags(cmd, args, []string{})
 
@@ -89,7 +156,56 @@ func runACIHandler(cmd *cobra.Command, args []string) {
 		log.WithError(err).Fatalf("Failed to get ACI client.")
 	}
 	handler := &aci.Handler{Config: config, Log: log, Aci: client}
-	handler.Ams = &alertmanager.AlertService{AmURL: config.AmURL, Version: config.Version}
+	// Faults are scraped every cycle, so ACI alerts can't be delayed.
+	handler.Maintenance = maintenanceSchedule(config.MaintenanceFile, of_v2.MaintenanceDrop, of_v2.MaintenanceTag)
+	handler.Audit = auditor(config.AuditFile, config.AuditFileSize, config.AuditFiles)
+	apiVersion, err := am_v2.ParseAPIVersion(config.AmAPIVersion)
+	if err != nil {
+		log.WithError(err).Fatalf("Invalid aci-am-api-version.")
+	}
+	policy, err := am_v2.ParsePolicy(config.AmPolicy)
+	if err != nil {
+		log.WithError(err).Fatalf("Invalid aci-am-policy.")
+	}
+	transport, err := http.NewTransport(&config.AmClient, logv2)
+	if err != nil {
+		log.WithError(err).Fatalf("Failed to init Alertmanager client.")
+	}
+	watchAMTransport(transport)
+	handler.Ams = &alertmanager.AlertService{
+		AmURL:      config.AmURL,
+		Peers:      config.AmPeers,
+		Policy:     policy,
+		Version:    config.Version,
+		APIVersion: apiVersion,
+		Client:     transport.Client(),
+	}
+	if config.Throttle == true {
+		limiter, err := ratelimit.NewLimiter(&config.AmLimit, config.Application, &clock.Clock{}, logv2)
+		if err != nil {
+			log.WithError(err).Fatalf("Failed to init Alertmanager limiter.")
+		}
+		handler.Limiter = limiter
+	}
+	sinks, err := sink.NewComposite(&config.Sinks, config.Version, of_v2.NotifierFunc(handler.Ams.Deliver), logv2)
+	if err != nil {
+		log.WithError(err).Fatalf("Failed to init alert sinks.")
+	}
+	if sinks.Sink(string(of_v2.SinkAlertmanager)) != nil {
+		if q := alertQueue(&config.AmQueue, config.Application, handler.Ams.Post); q != nil {
+			handler.Ams.Queue = q
+		}
+	}
+	// Posts are spaced by handler.Limiter before alerts are routed, and counted once the handler is run.
+	ams := &amReceivers{
+		Client:     handler.Ams.Client,
+		APIVersion: apiVersion,
+		Version:    config.Version,
+		Posts:      func() *prometheus.CounterVec { return handler.Ams.Posts },
+		Queue:      config.AmQueue,
+		Namespace:  config.Application,
+	}
+	handler.Ams.Sinks = alertRouter(config.RoutesFile, sinks, ams, config.Version)
 	handler.Run()
 }
 
@@ -113,6 +229,8 @@ func ACIConfig(cmd *cobra.Command) *of.ACIConfig {
 	cfg.ListenAddress = viper.GetString("aci-listen-address")
 	cfg.CycleInterval = viper.GetInt("aci-cycle-interval")
 	cfg.AmURL = viper.GetString("aci-am-url")
+	cfg.AmAPIVersion = viper.GetString("aci-am-api-version")
+	cfg.AmClient = amClientConfig("aci-am-", viper.GetDuration("aci-am-timeout"))
 	cfg.ACIHosts = parseHosts(viper.GetString("aci-hosts"))
 
 	cfg.AlertsCFGFile = viper.GetString("aci-alerts-config")
@@ -125,17 +243,32 @@ func ACIConfig(cmd *cobra.Command) *of.ACIConfig {
 	cfg.ACITimeout = viper.GetDuration("aci-timeout")
 
 	cfg.Throttle = viper.GetBool("aci-throttle")
-	cfg.PostTime = viper.GetInt("aci-post-time")
-	cfg.SleepTime = viper.GetInt("aci-sleep-time")
-	cfg.SendTime = viper.GetInt("aci-send-time")
+	cfg.AmLimit = limitConfig("aci-am-")
 
 	cfg.ConsulEnabled = viper.GetBool("aci-enable-consul")
 	cfg.ConsulACIGroupHost = viper.GetString("consul-aci-group-host")
 	cfg.Debug = viper.GetBool("aci-debug")
+	cfg.MaintenanceFile = viper.GetString("aci-maintenance-file")
+	cfg.AuditFile = viper.GetString("aci-audit-file")
+	cfg.AuditFileSize = viper.GetInt64("aci-audit-file-size") * 1024 * 1024
+	cfg.AuditFiles = viper.GetInt("aci-audit-files")
+	cfg.AmQueue = queueConfig("aci-queue-")
+	cfg.Sinks = sinkConfig("aci-")
+	cfg.RoutesFile = viper.GetString("aci-routes-file")
 
-	if strings.HasPrefix(cfg.AmURL, "http") == false {
-		log.Fatalf("aci-am-url must begin with http/https")
+	resolver := am_v2.PeerResolver{}
+	peers, err := resolver.Resolve(cfg.AmURL, viper.GetString("aci-am-srv"))
+	if err != nil {
+		log.WithError(err).Fatalf("Failed to resolve AlertManager peers.")
+	}
+	for _, peer := range peers {
+		if strings.HasPrefix(peer, "http") == false {
+			log.Fatalf("aci-am-url must begin with http/https")
+		}
 	}
+	cfg.AmPeers = peers
+	cfg.AmURL = peers[0]
+	cfg.AmPolicy = viper.GetString("aci-am-policy")
 
 	staticLabels := viper.GetString("aci-static-labels")
 	if staticLabels != "" && staticLabels != "None" {
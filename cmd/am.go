@@ -0,0 +1,369 @@
+// Copyright © 2019 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	of_v2 "github.com/cisco-cx/of/pkg/v2"
+	am "github.com/cisco-cx/of/wrap/alertmanager/v2"
+	http "github.com/cisco-cx/of/wrap/http/v2"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// Labels of silence shortcuts, set on alerts by the handlers.
+// Only the ACI handler sets cluster_name, the SNMP handler names clusters in source_address.
+var silenceShortcuts = []struct{ flag, label string }{
+	{"source-address", "source_address"},
+	{"cluster", "cluster_name"},
+	{"fingerprint", "alert_fingerprint"},
+}
+
+// cmdAM returns the `am` command.
+func cmdAM() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "am",
+		Short: "Commands for Alertmanager, with the client settings of the handlers",
+	}
+	return cmd
+}
+
+// cmdAMSilence returns the `am silence` command.
+func cmdAMSilence() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "silence",
+		Short: "Add, list and expire silences",
+	}
+	return cmd
+}
+
+// cmdAMSilenceAdd returns the `am silence add` command.
+func cmdAMSilenceAdd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                "add [flags] [matchers...]",
+		Short:              "Add a silence, by matchers like name=value, name!=value, name=~regex or name!~regex",
+		Run:                RunAMSilenceAdd,
+		DisableFlagParsing: true,
+	}
+	return cmd
+}
+
+// cmdAMSilenceList returns the `am silence list` command.
+func cmdAMSilenceList() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                "list [flags] [matchers...]",
+		Short:              "List silences matching all matchers",
+		Run:                RunAMSilenceList,
+		DisableFlagParsing: true,
+	}
+	return cmd
+}
+
+// cmdAMSilenceExpire returns the `am silence expire` command.
+func cmdAMSilenceExpire() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                "expire [flags] silence-ids...",
+		Short:              "Expire silences",
+		Run:                RunAMSilenceExpire,
+		DisableFlagParsing: true,
+	}
+	return cmd
+}
+
+// cmdAMAlerts returns the `am alerts` command.
+func cmdAMAlerts() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                "alerts [flags] [matchers...]",
+		Short:              "List alerts generated by of, grouped by subsystem and source",
+		Run:                RunAMAlerts,
+		DisableFlagParsing: true,
+	}
+	return cmd
+}
+
+// cmdAMPush returns the `am push` command.
+func cmdAMPush() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                "push [flags] alerts-file",
+		Short:              "Post alerts from a JSON or YAML file to Alertmanager",
+		Run:                RunAMPush,
+		DisableFlagParsing: true,
+	}
+	return cmd
+}
+
+// Define flags of the Alertmanager client, named like those of the SNMP handler.
+func amClientFlags(cmd *cobra.Command) {
+	cmd.Flags().String("am-address", "http://localhost:9093", "AlertManager URLs separated by ','. Requests go to the first that answers, alerts are pushed to each.")
+	cmd.Flags().String("am-srv", "", "DNS SRV name of AlertManager peers. (default: none)")
+	cmd.Flags().Duration("am-timeout", 10*time.Second, "Alertmanager timeout  (default: 10s)")
+	cmd.Flags().String("am-basic-auth-user", "", "Username for basic auth to Alertmanager. (default: none)")
+	cmd.Flags().String("am-basic-auth-password-file", "", "Path to file with password for basic auth to Alertmanager. (default: none)")
+	cmd.Flags().String("am-bearer-token-file", "", "Path to file with bearer token for Alertmanager. (default: none)")
+	cmd.Flags().String("am-cert-file", "", "Path to client certificate for TLS to Alertmanager. (default: none)")
+	cmd.Flags().String("am-key-file", "", "Path to client key for TLS to Alertmanager. (default: none)")
+	cmd.Flags().String("am-ca-file", "", "Path to CA bundle to verify Alertmanager with. (default: system roots)")
+	cmd.Flags().String("am-proxy-url", "", "HTTP proxy to reach Alertmanager through. (default: from HTTP_PROXY and HTTPS_PROXY)")
+}
+
+// Alertmanager peers and client from the flags of amClientFlags.
+func amAPIClient() *am.APIClient {
+	resolver := am.PeerResolver{}
+	peers, err := resolver.Resolve(viper.GetString("am-address"), viper.GetString("am-srv"))
+	if err != nil {
+		logv2.WithError(err).Fatalf("Failed to resolve AlertManager peers.")
+	}
+	cfg := amClientConfig("am-", viper.GetDuration("am-timeout"))
+	transport, err := http.NewTransport(&cfg, logv2)
+	if err != nil {
+		logv2.WithError(err).Fatalf("Failed to set up Alertmanager client.")
+	}
+	return &am.APIClient{Peers: peers, Client: transport.Client(), Version: infoSvc.String()}
+}
+
+// Matchers from args, failing on invalid ones.
+func amMatchers(args []string) []of_v2.SilenceMatcher {
+	matchers, err := am.ParseMatchers(args)
+	if err != nil {
+		logv2.WithError(err).Fatalf("Invalid matchers.")
+	}
+	return matchers
+}
+
+// Write v to stdout as JSON if output is json, otherwise with table.
+func writeAMOutput(output string, v interface{}, table func() error) {
+	var err error
+	switch output {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(v)
+	case "table":
+		err = table()
+	default:
+		logv2.Fatalf("Unknown output %q, expected table or json.", output)
+	}
+	if err != nil {
+		logv2.WithError(err).Fatalf("Failed to write output.")
+	}
+}
+
+// Entry point for ./of am silence add.
+func RunAMSilenceAdd(cmd *cobra.Command, args []string) {
+	logv2.WithField("info", infoSvc).Infof("am silence add called")
+
+	// Define flags and configuration settings.
+	amClientFlags(cmd)
+	cmd.Flags().String("source-address", "", "Silence alerts of a source_address. (default: none)")
+	cmd.Flags().String("cluster", "", "Silence ACI alerts of a cluster, by cluster_name. SNMP alerts of a cluster have its name as source_address, silence them with --source-address. (default: none)")
+	cmd.Flags().String("fingerprint", "", "Silence an alert, by alert_fingerprint. (default: none)")
+	cmd.Flags().Duration("duration", time.Hour, "Duration of the silence. (default: 1h)")
+	cmd.Flags().String("start", "", "Start of the silence, in RFC 3339. (default: now)")
+	cmd.Flags().String("end", "", "End of the silence in RFC 3339, instead of duration. (default: none)")
+	cmd.Flags().String("comment", "", "Reason for the silence.")
+	cmd.Flags().String("author", os.Getenv("USER"), "Creator of the silence. (default: $USER)")
+
+	checkRequiredFlags(cmd, args, []string{})
+
+	matchers := amMatchers(cmd.Flags().Args())
+	for _, s := range silenceShortcuts {
+		if v := viper.GetString(s.flag); v != "" {
+			matchers = append(matchers, of_v2.SilenceMatcher{Name: s.label, Value: v})
+		}
+	}
+	if len(matchers) == 0 {
+		logv2.Fatalf("Please specify matchers, source-address, cluster or fingerprint.")
+	}
+	silence := &of_v2.Silence{
+		Matchers:  matchers,
+		StartsAt:  time.Now(),
+		CreatedBy: viper.GetString("author"),
+		Comment:   viper.GetString("comment"),
+	}
+	if silence.Comment == "" || silence.CreatedBy == "" {
+		logv2.Fatalf("Please specify a comment and author.")
+	}
+	var err error
+	if start := viper.GetString("start"); start != "" {
+		if silence.StartsAt, err = time.Parse(time.RFC3339, start); err != nil {
+			logv2.WithError(err).Fatalf("Invalid start.")
+		}
+	}
+	silence.EndsAt = silence.StartsAt.Add(viper.GetDuration("duration"))
+	if end := viper.GetString("end"); end != "" {
+		if silence.EndsAt, err = time.Parse(time.RFC3339, end); err != nil {
+			logv2.WithError(err).Fatalf("Invalid end.")
+		}
+	}
+	if silence.EndsAt.After(silence.StartsAt) == false {
+		logv2.Fatalf("Silence must end after it starts.")
+	}
+
+	id, err := amAPIClient().AddSilence(silence)
+	if err != nil {
+		logv2.WithError(err).Fatalf("Failed to add silence.")
+	}
+	fmt.Println(id)
+}
+
+// Entry point for ./of am silence list.
+func RunAMSilenceList(cmd *cobra.Command, args []string) {
+	logv2.WithField("info", infoSvc).Infof("am silence list called")
+
+	// Define flags and configuration settings.
+	amClientFlags(cmd)
+	cmd.Flags().Bool("expired", false, "List expired silences too. (default: false)")
+	cmd.Flags().String("output", "table", "Output format, table or json. (default: table)")
+
+	checkRequiredFlags(cmd, args, []string{"expired"})
+
+	silences, err := amAPIClient().Silences(amMatchers(cmd.Flags().Args()))
+	if err != nil {
+		logv2.WithError(err).Fatalf("Failed to list silences.")
+	}
+	if viper.GetBool("expired") == false {
+		current := silences[:0]
+		for _, s := range silences {
+			if s.Status == nil || s.Status.State != "expired" {
+				current = append(current, s)
+			}
+		}
+		silences = current
+	}
+	writeAMOutput(viper.GetString("output"), silences, func() error {
+		return am.WriteSilences(os.Stdout, silences)
+	})
+}
+
+// Entry point for ./of am silence expire.
+func RunAMSilenceExpire(cmd *cobra.Command, args []string) {
+	logv2.WithField("info", infoSvc).Infof("am silence expire called")
+
+	// Define flags and configuration settings.
+	amClientFlags(cmd)
+
+	checkRequiredFlags(cmd, args, []string{})
+
+	ids := cmd.Flags().Args()
+	if len(ids) == 0 {
+		logv2.Fatalf("Please specify silence IDs.")
+	}
+	c := amAPIClient()
+	for _, id := range ids {
+		if err := c.ExpireSilence(id); err != nil {
+			logv2.WithError(err).Fatalf("Failed to expire silence %s.", id)
+		}
+		fmt.Printf("Expired %s\n", id)
+	}
+}
+
+// Entry point for ./of am alerts.
+func RunAMAlerts(cmd *cobra.Command, args []string) {
+	logv2.WithField("info", infoSvc).Infof("am alerts called")
+
+	// Define flags and configuration settings.
+	amClientFlags(cmd)
+	cmd.Flags().Bool("all", false, "List alerts not generated by of too, those without alert_fingerprint. (default: false)")
+	cmd.Flags().String("output", "table", "Output format, table or json. (default: table)")
+
+	checkRequiredFlags(cmd, args, []string{"all"})
+
+	filter := amMatchers(cmd.Flags().Args())
+	if viper.GetBool("all") == false {
+		// Alerts generated by of are fingerprinted.
+		filter = append([]of_v2.SilenceMatcher{{Name: "alert_fingerprint", Value: ".+", IsRegex: true}}, filter...)
+	}
+	alerts, err := amAPIClient().Alerts(filter)
+	if err != nil {
+		logv2.WithError(err).Fatalf("Failed to list alerts.")
+	}
+	groups := am.GroupAlerts(alerts)
+	writeAMOutput(viper.GetString("output"), groups, func() error {
+		return am.WriteAlertGroups(os.Stdout, groups)
+	})
+}
+
+// Entry point for ./of am push.
+func RunAMPush(cmd *cobra.Command, args []string) {
+	logv2.WithField("info", infoSvc).Infof("am push called")
+
+	// Define flags and configuration settings.
+	amClientFlags(cmd)
+	cmd.Flags().String("am-policy", "all", "Pushing alerts succeeds if any or all AlertManager peers accept them. (default: all)")
+	cmd.Flags().String("am-api-version", "auto", "Alertmanager API version to post alerts to, auto, v1 or v2. auto detects it through /api/v2/status. (default: auto)")
+	cmd.Flags().Bool("resolve", false, "Resolve the alerts, ending them now. (default: false)")
+
+	checkRequiredFlags(cmd, args, []string{"resolve"})
+
+	files := cmd.Flags().Args()
+	if len(files) != 1 {
+		logv2.Fatalf("Please specify an alerts file.")
+	}
+	alerts, err := am.LoadAlerts(files[0])
+	if err != nil {
+		logv2.WithError(err).Fatalf("Failed to load alerts.")
+	}
+	if viper.GetBool("resolve") == true {
+		now := time.Now()
+		for i := range alerts {
+			alerts[i].EndsAt = now
+		}
+	}
+	policy, err := am.ParsePolicy(viper.GetString("am-policy"))
+	if err != nil {
+		logv2.WithError(err).Fatalf("Invalid am-policy.")
+	}
+	apiVersion, err := am.ParseAPIVersion(viper.GetString("am-api-version"))
+	if err != nil {
+		logv2.WithError(err).Fatalf("Invalid am-api-version.")
+	}
+
+	c := amAPIClient()
+	as := &am.AlertService{
+		Version:    c.Version,
+		AmURL:      c.Peers[0],
+		Peers:      c.Peers,
+		Policy:     policy,
+		APIVersion: apiVersion,
+		Client:     c.Client,
+		Log:        logv2,
+	}
+	if err = as.Notify(&alerts); err != nil {
+		logv2.WithError(err).Fatalf("Failed to push alerts.")
+	}
+	fmt.Printf("Pushed %d alerts\n", len(alerts))
+}
+
+// init creates and adds the `am` command with its subcommands.
+func init() {
+	// Create the `am` command.
+	cmd := cmdAM()
+
+	// Create and add the subcommands of the `am` command.
+	silence := cmdAMSilence()
+	silence.AddCommand(cmdAMSilenceAdd())
+	silence.AddCommand(cmdAMSilenceList())
+	silence.AddCommand(cmdAMSilenceExpire())
+	cmd.AddCommand(silence)
+	cmd.AddCommand(cmdAMAlerts())
+	cmd.AddCommand(cmdAMPush())
+
+	// Add the `am` command to the root command.
+	rootCmd.AddCommand(cmd)
+}
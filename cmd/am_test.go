@@ -0,0 +1,45 @@
+package cmd_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	snmp_cmd "github.com/cisco-cx/of/cmd"
+	of_v2 "github.com/cisco-cx/of/pkg/v2"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+// Test alerts are pushed from a file, and silences added with shortcuts.
+func TestAMCommands(t *testing.T) {
+	var pushed []of_v2.PostableAlert
+	var silence of_v2.Silence
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v2/status", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("/api/v2/alerts", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&pushed))
+	})
+	mux.HandleFunc("/api/v2/silences", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&silence))
+		w.Write([]byte(`{"silenceID": "s0"}`))
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	snmp_cmd.RunAMPush(&cobra.Command{}, []string{"--am-address=" + ts.URL, "--resolve", "../wrap/alertmanager/v2/test/alerts.yaml"})
+	require.Len(t, pushed, 2)
+	require.Equal(t, "fanDown", pushed[0].Labels["alertname"])
+	require.NotNil(t, pushed[1].EndsAt)
+
+	snmp_cmd.RunAMSilenceAdd(&cobra.Command{}, []string{
+		"--am-address=" + ts.URL, "--cluster=fabric1", "--comment=Upgrade", "--author=noc", "--duration=2h", "alertname=~link.*",
+	})
+	require.Equal(t, []of_v2.SilenceMatcher{
+		{Name: "alertname", Value: "link.*", IsRegex: true},
+		{Name: "cluster_name", Value: "fabric1"},
+	}, silence.Matchers)
+	require.Equal(t, "noc", silence.CreatedBy)
+	require.Equal(t, "2h0m0s", silence.EndsAt.Sub(silence.StartsAt).String())
+}
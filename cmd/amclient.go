@@ -0,0 +1,44 @@
+// Copyright © 2019 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"time"
+
+	of_v2 "github.com/cisco-cx/of/pkg/v2"
+	http "github.com/cisco-cx/of/wrap/http/v2"
+	"github.com/spf13/viper"
+)
+
+// Alertmanager client settings from flags starting with prefix, like am- or aci-am-.
+func amClientConfig(prefix string, timeout time.Duration) of_v2.HTTPClientConfig {
+	return of_v2.HTTPClientConfig{
+		Timeout:               timeout,
+		BasicAuthUser:         viper.GetString(prefix + "basic-auth-user"),
+		BasicAuthPasswordFile: viper.GetString(prefix + "basic-auth-password-file"),
+		BearerTokenFile:       viper.GetString(prefix + "bearer-token-file"),
+		CertFile:              viper.GetString(prefix + "cert-file"),
+		KeyFile:               viper.GetString(prefix + "key-file"),
+		CAFile:                viper.GetString(prefix + "ca-file"),
+		ProxyURL:              viper.GetString(prefix + "proxy-url"),
+	}
+}
+
+// Reload credentials of Alertmanager client transport t when their files change.
+func watchAMTransport(t *http.Transport) {
+	if err := t.Watch(); err != nil {
+		logv2.WithError(err).Fatalf("Failed to watch Alertmanager client credentials.")
+	}
+}
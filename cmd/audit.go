@@ -0,0 +1,34 @@
+// Copyright © 2019 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	of_v2 "github.com/cisco-cx/of/pkg/v2"
+	audit "github.com/cisco-cx/of/wrap/audit/v2"
+	clock "github.com/cisco-cx/of/wrap/time/v2"
+)
+
+// Open audit file at path, rotated at size bytes keeping files rotated files. Returns nil if path is empty.
+func auditor(path string, size int64, files int) of_v2.Auditor {
+	if path == "" {
+		return nil
+	}
+
+	a, err := audit.NewFileAuditor(path, size, files, &clock.Clock{})
+	if err != nil {
+		logv2.WithError(err).Fatalf("Failed to open audit file.")
+	}
+	return a
+}
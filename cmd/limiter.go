@@ -0,0 +1,52 @@
+// Copyright © 2019 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"time"
+
+	of_v2 "github.com/cisco-cx/of/pkg/v2"
+	"github.com/spf13/viper"
+)
+
+// Default limits of posts to Alertmanager.
+const (
+	defaultAMRate           = 5
+	defaultAMMaxBatch       = 500
+	defaultAMMaxConcurrency = 1
+	defaultAMMinBackoff     = time.Second
+	defaultAMMaxBackoff     = 30 * time.Second
+
+	// Replaced by the limits above.
+	throttleDeprecated = "posts are limited with --%s now."
+)
+
+// Limits of posts to Alertmanager from flags starting with prefix, like am- or aci-am-.
+func limitConfig(prefix string) of_v2.BatchLimitConfig {
+	cfg := of_v2.BatchLimitConfig{
+		Rate:           viper.GetFloat64(prefix + "rate"),
+		MaxBatch:       viper.GetInt(prefix + "max-batch"),
+		MaxConcurrency: viper.GetInt(prefix + "max-concurrency"),
+		MinBackoff:     viper.GetDuration(prefix + "min-backoff"),
+		MaxBackoff:     viper.GetDuration(prefix + "max-backoff"),
+	}
+	if cfg.Rate < 0 || cfg.MaxBatch < 0 {
+		logv2.Fatalf("%srate and %smax-batch cannot be negative.", prefix, prefix)
+	}
+	if cfg.MaxBackoff > 0 && cfg.MinBackoff > cfg.MaxBackoff {
+		logv2.Fatalf("%smin-backoff %s is above %smax-backoff %s.", prefix, cfg.MinBackoff, prefix, cfg.MaxBackoff)
+	}
+	return cfg
+}
@@ -0,0 +1,39 @@
+// Copyright © 2019 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	of_v2 "github.com/cisco-cx/of/pkg/v2"
+	maintenance "github.com/cisco-cx/of/wrap/maintenance/v2"
+)
+
+// Load maintenance schedule from path and watch it for changes. Returns nil if path is empty.
+// Windows are restricted to actions, if any are given.
+func maintenanceSchedule(path string, actions ...of_v2.MaintenanceAction) *maintenance.Schedule {
+	if path == "" {
+		return nil
+	}
+
+	schedule := &maintenance.Schedule{Path: path, Log: logv2, Actions: actions}
+	err := schedule.Load()
+	if err != nil {
+		logv2.WithError(err).Fatalf("Failed to load maintenance schedule.")
+	}
+	err = schedule.Watch()
+	if err != nil {
+		logv2.WithError(err).Fatalf("Failed to watch maintenance schedule.")
+	}
+	return schedule
+}
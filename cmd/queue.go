@@ -0,0 +1,59 @@
+// Copyright © 2019 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"time"
+
+	of_v2 "github.com/cisco-cx/of/pkg/v2"
+	queue "github.com/cisco-cx/of/wrap/queue/v2"
+	clock "github.com/cisco-cx/of/wrap/time/v2"
+	"github.com/spf13/viper"
+)
+
+// Default retry queue settings.
+const (
+	defaultQueueMaxAge     = 24 * time.Hour
+	defaultQueueMinBackoff = time.Second
+	defaultQueueMaxBackoff = 5 * time.Minute
+)
+
+// Retry queue settings from flags starting with prefix, like queue- or aci-queue-.
+func queueConfig(prefix string) of_v2.QueueConfig {
+	return of_v2.QueueConfig{
+		Dir:        viper.GetString(prefix + "dir"),
+		MaxAge:     viper.GetDuration(prefix + "max-age"),
+		MinBackoff: viper.GetDuration(prefix + "min-backoff"),
+		MaxBackoff: viper.GetDuration(prefix + "max-backoff"),
+	}
+}
+
+// Open retry queue of cfg, delivering pending and new alerts with post in background.
+// Returns nil if cfg.Dir is empty.
+func alertQueue(cfg *of_v2.QueueConfig, namespace string, post func([]of_v2.Alert) error) *queue.Queue {
+	if cfg.Dir == "" {
+		return nil
+	}
+	if cfg.MaxBackoff > 0 && cfg.MinBackoff > cfg.MaxBackoff {
+		logv2.Fatalf("Queue min backoff %s is above max backoff %s.", cfg.MinBackoff, cfg.MaxBackoff)
+	}
+
+	q, err := queue.NewQueue(cfg, namespace, post, &clock.Clock{}, logv2)
+	if err != nil {
+		logv2.WithError(err).Fatalf("Failed to open alert queue.")
+	}
+	q.Start()
+	return q
+}
@@ -0,0 +1,148 @@
+// Copyright © 2019 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"sync"
+
+	of_v2 "github.com/cisco-cx/of/pkg/v2"
+	am "github.com/cisco-cx/of/wrap/alertmanager/v2"
+	prometheus "github.com/cisco-cx/of/wrap/prometheus/client_golang/v2"
+	queue "github.com/cisco-cx/of/wrap/queue/v2"
+	route "github.com/cisco-cx/of/wrap/route/v2"
+	clock "github.com/cisco-cx/of/wrap/time/v2"
+)
+
+// Characters not allowed in queue directories and metric names of receivers.
+var receiverNameRE = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// Route alerts with the routes file at path, reloaded on change. Alerts of the default receiver are sent to def.
+// Returns def if path is empty.
+func alertRouter(path string, def of_v2.Notifier, ams *amReceivers, version string) of_v2.Notifier {
+	if path == "" {
+		return def
+	}
+
+	router, err := route.NewRouter(path, def, ams.New, version, logv2)
+	if err != nil {
+		logv2.WithError(err).Fatalf("Failed to load routes.")
+	}
+	router.Closer = ams
+	if err = router.Watch(); err != nil {
+		logv2.WithError(err).Fatalf("Failed to watch routes.")
+	}
+	return router
+}
+
+// Creates Alertmanager receivers of routes, posting like the alertmanager sink of the handler.
+// Each receiver gets a retry queue in a directory of Queue.Dir if set, kept across route reloads.
+type amReceivers struct {
+	Client     *http.Client
+	APIVersion of_v2.AMAPIVersion
+	Version    string
+	Posts      func() *prometheus.CounterVec // Counts posts by peer and result, if set. Read as receivers are created.
+	Limiter    of_v2.BatchLimiter            // Spaces posts, if set.
+	Queue      of_v2.QueueConfig
+	Namespace  string // Of queue metrics, followed by _route_<receiver>.
+
+	mu     sync.Mutex
+	queues map[string]*routeQueue // By directory.
+}
+
+// Retry queue of a receiver, posting to its Alertmanager peers of the last route reload.
+type routeQueue struct {
+	*queue.Queue
+	name string
+
+	mu sync.RWMutex
+	as *am.AlertService
+}
+
+// Post alerts to current peers of the receiver.
+func (q *routeQueue) post(alerts []of_v2.Alert) error {
+	q.mu.RLock()
+	as := q.as
+	q.mu.RUnlock()
+	return as.Notify(&alerts)
+}
+
+// Notifier posting alerts of receiver name to peers, through its retry queue if enabled.
+// Implements route.AMFactory.
+func (r *amReceivers) New(name string, peers []string, policy of_v2.AMSuccessPolicy) (of_v2.Notifier, error) {
+	as := &am.AlertService{
+		Version:    r.Version,
+		AmURL:      peers[0],
+		Peers:      peers,
+		Policy:     policy,
+		APIVersion: r.APIVersion,
+		Client:     r.Client,
+		Limiter:    r.Limiter,
+		Log:        logv2,
+	}
+	if r.Posts != nil {
+		as.Posts = r.Posts()
+	}
+	if r.Queue.Dir == "" {
+		return as, nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	dir := receiverNameRE.ReplaceAllString(name, "_")
+	q, ok := r.queues[dir]
+	if ok == false {
+		cfg := r.Queue
+		cfg.Dir = filepath.Join(r.Queue.Dir, "routes", dir)
+		q = &routeQueue{name: name, as: as}
+		var err error
+		q.Queue, err = queue.NewQueue(&cfg, r.Namespace+"_route_"+dir, q.post, &clock.Clock{}, logv2)
+		if err != nil {
+			return nil, err
+		}
+		q.Start()
+		if r.queues == nil {
+			r.queues = make(map[string]*routeQueue)
+		}
+		r.queues[dir] = q
+	}
+	if q.name != name {
+		return nil, fmt.Errorf("%s Receiver %q would share its retry queue with receiver %q.", of_v2.ErrInvalidRoutes, name, q.name)
+	}
+
+	q.mu.Lock()
+	q.as = as
+	q.mu.Unlock()
+	// Queue outlives receivers, so it isn't closed when routes are reloaded.
+	return of_v2.NotifierFunc(q.Notify), nil
+}
+
+// Close retry queues of receivers.
+func (r *amReceivers) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var err error
+	for _, q := range r.queues {
+		if qerr := q.Close(); qerr != nil {
+			err = qerr
+		}
+	}
+	r.queues = nil
+	return err
+}
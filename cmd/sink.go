@@ -0,0 +1,107 @@
+// Copyright © 2019 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"strings"
+
+	of_v2 "github.com/cisco-cx/of/pkg/v2"
+	queue "github.com/cisco-cx/of/wrap/queue/v2"
+	sink "github.com/cisco-cx/of/wrap/sink/v2"
+	"github.com/spf13/viper"
+)
+
+// Usage of sinks flags.
+const sinksUsage = "Sinks alerts are sent to, separated by ','. One of alertmanager, webhook, file, stdout, log, trap or syslog. (default: alertmanager)"
+
+// Usage of sink policy flags.
+const sinkPolicyUsage = "Sending alerts succeeds if any or all sinks accept them. Failures of other sinks are logged and counted, so alerts aren't resent to sinks that accepted them. (default: any)"
+
+// Sink settings from flags starting with prefix, like empty or aci-.
+func sinkConfig(prefix string) of_v2.SinkConfig {
+	sinks, err := sink.ParseSinks(viper.GetString(prefix + "sinks"))
+	if err != nil {
+		logv2.WithError(err).Fatalf("Invalid %ssinks.", prefix)
+	}
+	policy, err := sink.ParsePolicy(viper.GetString(prefix + "sink-policy"))
+	if err != nil {
+		logv2.WithError(err).Fatalf("Invalid %ssink-policy.", prefix)
+	}
+	return of_v2.SinkConfig{
+		Sinks:             sinks,
+		Policy:            policy,
+		WebhookURL:        viper.GetString(prefix + "webhook-url"),
+		WebhookTemplate:   viper.GetString(prefix + "webhook-template"),
+		WebhookSecretFile: viper.GetString(prefix + "webhook-secret-file"),
+		WebhookTimeout:    viper.GetDuration(prefix + "webhook-timeout"),
+		File:              viper.GetString(prefix + "sink-file"),
+		Trap:              trapConfig(prefix),
+		Syslog: of_v2.SyslogConfig{
+			Address:    viper.GetString(prefix + "syslog-address"),
+			Transport:  of_v2.SyslogTransport(strings.ToLower(viper.GetString(prefix + "syslog-transport"))),
+			Format:     of_v2.SyslogFormat(strings.ToLower(viper.GetString(prefix + "syslog-format"))),
+			Facility:   viper.GetString(prefix + "syslog-facility"),
+			AppName:    viper.GetString(prefix + "syslog-app-name"),
+			Enterprise: viper.GetString(prefix + "syslog-enterprise"),
+			Timeout:    viper.GetDuration(prefix + "syslog-timeout"),
+			CertFile:   viper.GetString(prefix + "syslog-cert-file"),
+			KeyFile:    viper.GetString(prefix + "syslog-key-file"),
+			CAFile:     viper.GetString(prefix + "syslog-ca-file"),
+		},
+	}
+}
+
+// Trap sink settings from flags starting with prefix.
+func trapConfig(prefix string) of_v2.TrapConfig {
+	var targets []string
+	for _, t := range strings.Split(viper.GetString(prefix+"trap-targets"), ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			targets = append(targets, t)
+		}
+	}
+	return of_v2.TrapConfig{
+		Targets:            targets,
+		Version:            of_v2.SNMPVersion(viper.GetString(prefix + "trap-version")),
+		Community:          viper.GetString(prefix + "trap-community"),
+		Inform:             viper.GetBool(prefix + "trap-inform"),
+		Retries:            viper.GetInt(prefix + "trap-retries"),
+		Timeout:            viper.GetDuration(prefix + "trap-timeout"),
+		Rate:               viper.GetFloat64(prefix + "trap-rate"),
+		MIB:                viper.GetString(prefix + "trap-mib"),
+		User:               viper.GetString(prefix + "trap-user"),
+		AuthProtocol:       of_v2.SNMPAuthProtocol(strings.ToUpper(viper.GetString(prefix + "trap-auth-protocol"))),
+		AuthPassphraseFile: viper.GetString(prefix + "trap-auth-passphrase-file"),
+		PrivProtocol:       of_v2.SNMPPrivProtocol(strings.ToUpper(viper.GetString(prefix + "trap-priv-protocol"))),
+		PrivPassphraseFile: viper.GetString(prefix + "trap-priv-passphrase-file"),
+		EngineID:           viper.GetString(prefix + "trap-engine-id"),
+		EngineBootsFile:    viper.GetString(prefix + "trap-engine-boots-file"),
+	}
+}
+
+// Queue alerts of the alertmanager sink of sinks, if cfg.Dir is set.
+// Returns the queue, to be closed on shutdown, or nil.
+func queueAMSink(sinks *sink.Composite, cfg *of_v2.QueueConfig, namespace string) *queue.Queue {
+	am := sinks.Sink(string(of_v2.SinkAlertmanager))
+	if am == nil {
+		return nil
+	}
+	n := am.Notifier
+	post := func(alerts []of_v2.Alert) error { return n.Notify(&alerts) }
+	q := alertQueue(cfg, namespace, post)
+	if q != nil {
+		am.Notifier = q
+	}
+	return q
+}
@@ -15,20 +15,40 @@
 package cmd
 
 import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
 	"os"
 	"os/signal"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	of_v2 "github.com/cisco-cx/of/pkg/v2"
+	of_snmp "github.com/cisco-cx/of/pkg/v2/snmp"
+	am "github.com/cisco-cx/of/wrap/alertmanager/v2"
+	maintenance "github.com/cisco-cx/of/wrap/maintenance/v2"
 	mib_registry "github.com/cisco-cx/of/wrap/mib/v2"
 	profile "github.com/cisco-cx/of/wrap/profile/v1"
 	prometheus "github.com/cisco-cx/of/wrap/prometheus/client_golang/v2"
+	sink "github.com/cisco-cx/of/wrap/sink/v2"
 	snmp "github.com/cisco-cx/of/wrap/snmp/v2"
+	clock "github.com/cisco-cx/of/wrap/time/v2"
+	uuid "github.com/cisco-cx/of/wrap/uuid/v2"
 	watcher "github.com/cisco-cx/of/wrap/watcher/v2"
+	yaml "github.com/cisco-cx/of/wrap/yaml/v2"
+)
+
+// Counters updated by commands processing events offline.
+var (
+	offlineCountersOnce sync.Once
+	offlineCntr         map[string]*prometheus.Counter
+	offlineCntrVec      map[string]*prometheus.CounterVec
 )
 
 // cmdSNMP returns the `snmp` command.
@@ -63,6 +83,61 @@ func cmdSNMPMIBsProcessor() *cobra.Command {
 	return cmd
 }
 
+// cmdSNMPValidate returns the `snmp validate` command.
+func cmdSNMPValidate() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                "validate",
+		Short:              "Validate SNMP configs against schema and MIBs",
+		Run:                RunSNMPValidate,
+		DisableFlagParsing: true,
+	}
+	return cmd
+}
+
+// cmdSNMPEvaluate returns the `snmp evaluate` command.
+func cmdSNMPEvaluate() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                "evaluate",
+		Short:              "Explain alerts generated for SNMP trap events, without sending them",
+		Run:                RunSNMPEvaluate,
+		DisableFlagParsing: true,
+	}
+	return cmd
+}
+
+// cmdSNMPTest returns the `snmp test` command.
+func cmdSNMPTest() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                "test [flags] test-files...",
+		Short:              "Run unit tests of SNMP configs",
+		Run:                RunSNMPTest,
+		DisableFlagParsing: true,
+	}
+	return cmd
+}
+
+// cmdSNMPDiff returns the `snmp diff` command.
+func cmdSNMPDiff() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                "diff",
+		Short:              "Compare alerts generated for recorded SNMP trap events, with two config dirs",
+		Run:                RunSNMPDiff,
+		DisableFlagParsing: true,
+	}
+	return cmd
+}
+
+// cmdSNMPReplay returns the `snmp replay` command.
+func cmdSNMPReplay() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                "replay [flags] recording-files-or-dirs...",
+		Short:              "Replay recorded SNMP trap events, to a running handler or in process with dry-run output",
+		Run:                RunSNMPReplay,
+		DisableFlagParsing: true,
+	}
+	return cmd
+}
+
 // Entry point for ./of snmp mib-preprocess.
 func RunMibsPreProcess(cmd *cobra.Command, args []string) {
 	// Start the profiler and defer stopping it until the program exits.
@@ -98,6 +173,411 @@ func RunMibsPreProcess(cmd *cobra.Command, args []string) {
 	}
 }
 
+// Entry point for ./of snmp validate.
+func RunSNMPValidate(cmd *cobra.Command, args []string) {
+	logv2.WithField("info", infoSvc).Infof("snmp validate called")
+
+	// Define flags and configuration settings.
+	cmd.Flags().String("config-dir", "", "Path to directory containing configs.")
+	cmd.Flags().String("mibs-dir", "none", "Path to MIBs directory, to check OIDs. (default: none)")
+	cmd.Flags().String("cache-file", "none", "Path to MIBs cache file, to check OIDs. (default: none)")
+
+	checkRequiredFlags(cmd, args, []string{})
+
+	cfg := &of_v2.SNMPConfig{
+		ConfigDir:   viper.GetString("config-dir"),
+		SNMPMibsDir: viper.GetString("mibs-dir"),
+		CacheFile:   viper.GetString("cache-file"),
+	}
+	if cfg.ConfigDir == "" {
+		logv2.Fatalf("Please specify a config-dir.")
+	}
+
+	count, err := ValidateSNMPConfigs(cfg, os.Stdout)
+	if err != nil {
+		logv2.WithError(err).Fatalf("Failed to validate configs.")
+	}
+	if count != 0 {
+		os.Exit(1)
+	}
+}
+
+// Print diagnostics of configs in cfg.ConfigDir to w, checking OIDs if MIBs are configured.
+// Returns the number of errors found.
+func ValidateSNMPConfigs(cfg *of_v2.SNMPConfig, w io.Writer) (int, error) {
+	var mr of_v2.MIBRegistry
+	if cfg.SNMPMibsDir != "none" || cfg.CacheFile != "none" {
+		var err error
+		mr, err = snmp.LoadMIBs(logv2, cfg)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	v, err := snmp.NewValidator(mr)
+	if err != nil {
+		return 0, err
+	}
+	diags, err := v.ValidateDir(cfg.ConfigDir)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, d := range diags {
+		fmt.Fprintln(w, d.String())
+	}
+	count := snmp.CountErrors(diags)
+	fmt.Fprintf(w, "%d errors, %d warnings.\n", count, len(diags)-count)
+	return count, nil
+}
+
+// Entry point for ./of snmp evaluate.
+func RunSNMPEvaluate(cmd *cobra.Command, args []string) {
+	logv2.WithField("info", infoSvc).Infof("snmp evaluate called")
+
+	// Define flags and configuration settings.
+	cmd.Flags().String("config-dir", "", "Path to directory containing configs.")
+	cmd.Flags().String("mibs-dir", "none", "Path to MIBs directory.")
+	cmd.Flags().String("cache-file", "none", "Path to MIBs cache file.")
+	cmd.Flags().String("input", "-", "Path to file with events, - for stdin. (default: -)")
+	cmd.Flags().Bool("forward-unknown", false, "Generate alerts for unknown traps. (default: false)")
+
+	checkRequiredFlags(cmd, args, []string{"forward-unknown"})
+
+	cfg := &of_v2.SNMPConfig{
+		ConfigDir:      viper.GetString("config-dir"),
+		SNMPMibsDir:    viper.GetString("mibs-dir"),
+		CacheFile:      viper.GetString("cache-file"),
+		ForwardUnknown: viper.GetBool("forward-unknown"),
+	}
+	if cfg.ConfigDir == "" {
+		logv2.Fatalf("Please specify a config-dir.")
+	}
+	if cfg.SNMPMibsDir == "none" && cfg.CacheFile == "none" {
+		logv2.Fatalf("Please specify a mibs-dir or cache-file.")
+	}
+
+	var r io.Reader = os.Stdin
+	if input := viper.GetString("input"); input != "-" {
+		f, err := os.Open(input)
+		if err != nil {
+			logv2.WithError(err).Fatalf("Failed to open %s.", input)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	err := EvaluateSNMPEvents(cfg, r, os.Stdout)
+	if err != nil {
+		logv2.WithError(err).Fatalf("Failed to evaluate events.")
+	}
+}
+
+// Load configs and MIBs, and print decisions taken for events read from r,
+// followed by the generated alerts as Alertmanager JSON.
+func EvaluateSNMPEvents(cfg *of_v2.SNMPConfig, r io.Reader, w io.Writer) error {
+	events, err := snmp.ParseEvents(r)
+	if err != nil {
+		return err
+	}
+
+	rt, err := snmp.LoadRuntime(logv2, cfg)
+	if err != nil {
+		return err
+	}
+
+	cntr, cntrVec := offlineCounters()
+	e := snmp.Evaluator{
+		Runtime:        rt,
+		Log:            logv2,
+		U:              &uuid.UUID{},
+		Clock:          &clock.Clock{},
+		Cntr:           cntr,
+		CntrVec:        cntrVec,
+		ForwardUnknown: cfg.ForwardUnknown,
+		Tracer:         &snmp.TextTracer{W: w},
+	}
+	alerts := e.Evaluate(events)
+
+	b, err := json.MarshalIndent(alerts, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s\n", b)
+	return err
+}
+
+// Entry point for ./of snmp test.
+func RunSNMPTest(cmd *cobra.Command, args []string) {
+	logv2.WithField("info", infoSvc).Infof("snmp test called")
+
+	// Define flags and configuration settings.
+	cmd.Flags().String("config-dir", "", "Path to directory containing configs.")
+	cmd.Flags().String("mibs-dir", "none", "Path to MIBs directory.")
+	cmd.Flags().String("cache-file", "none", "Path to MIBs cache file.")
+	cmd.Flags().String("junit-file", "", "Path to write JUnit XML report to. (default: none)")
+
+	checkRequiredFlags(cmd, args, []string{"junit-file"})
+
+	cfg := &of_v2.SNMPConfig{
+		ConfigDir:   viper.GetString("config-dir"),
+		SNMPMibsDir: viper.GetString("mibs-dir"),
+		CacheFile:   viper.GetString("cache-file"),
+	}
+	if cfg.ConfigDir == "" {
+		logv2.Fatalf("Please specify a config-dir.")
+	}
+	if cfg.SNMPMibsDir == "none" && cfg.CacheFile == "none" {
+		logv2.Fatalf("Please specify a mibs-dir or cache-file.")
+	}
+	files := cmd.Flags().Args()
+	if len(files) == 0 {
+		logv2.Fatalf("Please specify test files.")
+	}
+
+	var junit io.Writer
+	if junitFile := viper.GetString("junit-file"); junitFile != "" {
+		f, err := os.Create(junitFile)
+		if err != nil {
+			logv2.WithError(err).Fatalf("Failed to create %s.", junitFile)
+		}
+		defer f.Close()
+		junit = f
+	}
+
+	failed, err := RunSNMPConfigTests(cfg, files, os.Stdout, junit)
+	if err != nil {
+		logv2.WithError(err).Fatalf("Failed to run tests.")
+	}
+	if failed != 0 {
+		os.Exit(1)
+	}
+}
+
+// Run unit tests in files against configs, printing results to w, and a JUnit XML report to junit, if set.
+// Returns the number of failed tests.
+func RunSNMPConfigTests(cfg *of_v2.SNMPConfig, files []string, w io.Writer, junit io.Writer) (int, error) {
+	rt, err := snmp.LoadRuntime(logv2, cfg)
+	if err != nil {
+		return 0, err
+	}
+
+	cntr, cntrVec := offlineCounters()
+	tester := snmp.Tester{
+		Runtime: rt,
+		Log:     logv2,
+		Cntr:    cntr,
+		CntrVec: cntrVec,
+	}
+
+	var results []of_snmp.TestResult
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			return 0, err
+		}
+		tf := yaml.TestFile{}
+		err = tf.Decode(f)
+		f.Close()
+		if err != nil {
+			return 0, fmt.Errorf("%s: %s", file, err.Error())
+		}
+		results = append(results, tester.Run(file, of_snmp.TestFile(tf))...)
+	}
+
+	failed := 0
+	for _, r := range results {
+		if len(r.Failures) == 0 {
+			fmt.Fprintf(w, "ok    %s: %s\n", r.File, r.Name)
+			continue
+		}
+		failed++
+		fmt.Fprintf(w, "FAIL  %s: %s\n", r.File, r.Name)
+		for _, failure := range r.Failures {
+			fmt.Fprintf(w, "      %s\n", strings.Replace(failure, "\n", "\n      ", -1))
+		}
+	}
+	fmt.Fprintf(w, "%d tests, %d failed.\n", len(results), failed)
+
+	if junit != nil {
+		if err := snmp.WriteJUnit(junit, results); err != nil {
+			return failed, err
+		}
+	}
+	return failed, nil
+}
+
+// Entry point for ./of snmp diff.
+func RunSNMPDiff(cmd *cobra.Command, args []string) {
+	logv2.WithField("info", infoSvc).Infof("snmp diff called")
+
+	// Define flags and configuration settings.
+	cmd.Flags().String("old", "", "Path to directory containing current configs.")
+	cmd.Flags().String("new", "", "Path to directory containing changed configs.")
+	cmd.Flags().String("events", "", "Path to file with recorded events, gzipped if ending in .gz.")
+	cmd.Flags().String("mibs-dir", "none", "Path to MIBs directory.")
+	cmd.Flags().String("cache-file", "none", "Path to MIBs cache file.")
+
+	checkRequiredFlags(cmd, args, []string{})
+
+	oldCfg := &of_v2.SNMPConfig{
+		ConfigDir:   viper.GetString("old"),
+		SNMPMibsDir: viper.GetString("mibs-dir"),
+		CacheFile:   viper.GetString("cache-file"),
+	}
+	newCfg := &of_v2.SNMPConfig{
+		ConfigDir:   viper.GetString("new"),
+		SNMPMibsDir: viper.GetString("mibs-dir"),
+		CacheFile:   viper.GetString("cache-file"),
+	}
+	if oldCfg.ConfigDir == "" || newCfg.ConfigDir == "" {
+		logv2.Fatalf("Please specify old and new config dirs.")
+	}
+	if oldCfg.SNMPMibsDir == "none" && oldCfg.CacheFile == "none" {
+		logv2.Fatalf("Please specify a mibs-dir or cache-file.")
+	}
+	eventsFile := viper.GetString("events")
+	if eventsFile == "" {
+		logv2.Fatalf("Please specify an events file.")
+	}
+
+	f, err := os.Open(eventsFile)
+	if err != nil {
+		logv2.WithError(err).Fatalf("Failed to open %s.", eventsFile)
+	}
+	defer f.Close()
+	var r io.Reader = f
+	if strings.HasSuffix(eventsFile, ".gz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			logv2.WithError(err).Fatalf("Failed to read %s.", eventsFile)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	err = DiffSNMPConfigs(oldCfg, newCfg, r, os.Stdout)
+	if err != nil {
+		logv2.WithError(err).Fatalf("Failed to compare configs.")
+	}
+}
+
+// Load old and new configs, and print differences in alerts generated for events read from r.
+func DiffSNMPConfigs(oldCfg *of_v2.SNMPConfig, newCfg *of_v2.SNMPConfig, r io.Reader, w io.Writer) error {
+	events, err := snmp.ParseEvents(r)
+	if err != nil {
+		return err
+	}
+
+	oldRt, err := snmp.LoadRuntime(logv2, oldCfg)
+	if err != nil {
+		return fmt.Errorf("old configs: %s", err.Error())
+	}
+	newRt, err := snmp.LoadRuntime(logv2, newCfg)
+	if err != nil {
+		return fmt.Errorf("new configs: %s", err.Error())
+	}
+
+	cntr, cntrVec := offlineCounters()
+	d := snmp.Differ{
+		Old:     oldRt,
+		New:     newRt,
+		Log:     logv2,
+		Cntr:    cntr,
+		CntrVec: cntrVec,
+	}
+	return snmp.WriteDiff(w, d.Diff(events))
+}
+
+// Entry point for ./of snmp replay.
+func RunSNMPReplay(cmd *cobra.Command, args []string) {
+	logv2.WithField("info", infoSvc).Infof("snmp replay called")
+
+	// Define flags and configuration settings.
+	cmd.Flags().String("target", "", "URL of running handler's events endpoint, like http://localhost:80/api/v2/events. (default: in process)")
+	cmd.Flags().Duration("timeout", 10*time.Second, "Timeout posting events to target. (default: 10s)")
+	cmd.Flags().Float64("speed", 1, "Factor of original speed, 0 for maximum speed. (default: 1)")
+	cmd.Flags().String("config-dir", "", "Path to directory containing configs, for in process replay.")
+	cmd.Flags().String("mibs-dir", "none", "Path to MIBs directory, for in process replay.")
+	cmd.Flags().String("cache-file", "none", "Path to MIBs cache file, for in process replay.")
+	cmd.Flags().Bool("forward-unknown", false, "Generate alerts for unknown traps, for in process replay. (default: false)")
+
+	checkRequiredFlags(cmd, args, []string{"forward-unknown"})
+
+	cfg := &of_v2.SNMPConfig{
+		ConfigDir:      viper.GetString("config-dir"),
+		SNMPMibsDir:    viper.GetString("mibs-dir"),
+		CacheFile:      viper.GetString("cache-file"),
+		ForwardUnknown: viper.GetBool("forward-unknown"),
+		AMTimeout:      viper.GetDuration("timeout"),
+	}
+	target := viper.GetString("target")
+	if target == "" && cfg.ConfigDir == "" {
+		logv2.Fatalf("Please specify a target or config-dir.")
+	}
+	if target == "" && cfg.SNMPMibsDir == "none" && cfg.CacheFile == "none" {
+		logv2.Fatalf("Please specify a mibs-dir or cache-file.")
+	}
+	speed := viper.GetFloat64("speed")
+	if speed < 0 {
+		logv2.Fatalf("speed cannot be negative.")
+	}
+	paths := cmd.Flags().Args()
+	if len(paths) == 0 {
+		logv2.Fatalf("Please specify recording files or dirs.")
+	}
+
+	sent, err := ReplaySNMPEvents(cfg, target, speed, paths)
+	logv2.Infof("Replayed %d events.", sent)
+	if err != nil {
+		logv2.WithError(err).Fatalf("Failed to replay events.")
+	}
+}
+
+// Replay events in paths at speed, posting them to target, or if it is empty,
+// through a service with cfg that logs alerts instead of sending them.
+// Returns the number of events replayed.
+func ReplaySNMPEvents(cfg *of_v2.SNMPConfig, target string, speed float64, paths []string) (int, error) {
+	events, err := snmp.LoadEvents(paths)
+	if err != nil {
+		return 0, err
+	}
+
+	r := snmp.Replayer{
+		Speed: speed,
+		Clock: &clock.Clock{},
+		Log:   logv2,
+	}
+	if target != "" {
+		sender := snmp.HTTPSender{URL: target, Client: &http.Client{Timeout: cfg.AMTimeout}}
+		r.Send = sender.Send
+		return r.Replay(events)
+	}
+
+	// Time windows, like of aggregation, use the wall clock and are only kept at original speed.
+	cfg.DryRun = true
+	cfg.Application = "of_snmp_offline"
+	cntr, cntrVec := offlineCounters()
+	service, err := snmp.NewService(logv2, cfg, cntr, cntrVec)
+	if err != nil {
+		return 0, err
+	}
+	r.Send = func(events []*of_v2.PostableEvent) error {
+		err := service.Handle(events)
+		service.Sweep()
+		return err
+	}
+	return r.Replay(events)
+}
+
+// Counters for commands processing events offline, registered once.
+func offlineCounters() (map[string]*prometheus.Counter, map[string]*prometheus.CounterVec) {
+	offlineCountersOnce.Do(func() {
+		offlineCntr, offlineCntrVec = snmp.InitCounters("of_snmp_offline", logv2)
+	})
+	return offlineCntr, offlineCntrVec
+}
+
 // Entry point for ./of snmp handler.
 func runSNMPHandler(cmd *cobra.Command, args []string) {
 	// Start the profiler and defer stopping it until the program exits.
@@ -113,29 +593,17 @@ func runSNMPHandler(cmd *cobra.Command, args []string) {
 		logv2.WithError(err).Fatalf("Failed to init watcher for config dir.")
 	}
 
+	schedule := maintenanceSchedule(config.MaintenanceFile)
 	cntr, cntrVec := snmp.InitCounters(config.Application, logv2)
-	handler := initSNMPHandler(config, cntr, cntrVec)
+	handler := initSNMPHandler(config, cntr, cntrVec, schedule)
 	cntrVec[snmp.HandlerRestarted].Incr(map[string]string{
 		"op_type": "start",
 	})
 	go handler.Run()
 
-	var restartTimer *time.Timer
-
-	restartFunc := func() {
-		logv2.Infof("Shutting down SNMP handler")
-		cntrVec[snmp.HandlerRestarted].Incr(map[string]string{
-			"op_type": "shutdown",
-		})
-		handler.Shutdown()
-		handler = initSNMPHandler(config, cntr, cntrVec)
-		logv2.Infof("Starting SNMP handler")
-		cntrVec[snmp.HandlerRestarted].Incr(map[string]string{
-			"op_type": "start",
-		})
-		handler.Run()
-		restartTimer = nil
-	}
+	// Reload timer is only touched from the loop below, reloadC is nil while no reload is pending.
+	var reloadTimer *time.Timer
+	var reloadC <-chan time.Time
 
 	err = fs.Watch()
 	if err != nil {
@@ -149,18 +617,25 @@ func runSNMPHandler(cmd *cobra.Command, args []string) {
 		select {
 		case _ = <-fs.Changed:
 			// Might get multiple events, since we are watching a directory.
-			// Delaying and restarting the service only once.
+			// Delaying and reloading the configs only once.
 			logv2.Infof("Config change detected.")
-			if restartTimer == nil {
-				logv2.Debugf("Started timer to restart handler.")
-				restartTimer = time.AfterFunc(2*time.Second, restartFunc)
+			if reloadTimer == nil {
+				logv2.Debugf("Started timer to reload configs.")
+				reloadTimer = time.NewTimer(2 * time.Second)
+				reloadC = reloadTimer.C
 			} else {
-				logv2.Debugf("Delaying handler restart.")
-				if !restartTimer.Stop() {
-					<-restartTimer.C
+				logv2.Debugf("Delaying config reload.")
+				// Drain timer that fired, but wasn't received yet.
+				if reloadTimer.Stop() == false {
+					<-reloadTimer.C
 				}
-				restartTimer.Reset(time.Second)
+				reloadTimer.Reset(time.Second)
 			}
+		case _ = <-reloadC:
+			// Configs are reloaded behind the running handler, last good configs are kept on failure.
+			logv2.Infof("Reloading SNMP configs")
+			handler.SNMP.Reload()
+			reloadTimer, reloadC = nil, nil
 		case _ = <-signalChan:
 			logv2.Infof("Process killed, shutting down.")
 			handler.Shutdown()
@@ -170,16 +645,53 @@ func runSNMPHandler(cmd *cobra.Command, args []string) {
 
 }
 
-func initSNMPHandler(config *of_v2.SNMPConfig, cntr map[string]*prometheus.Counter, cntrVec map[string]*prometheus.CounterVec) *snmp.Handler {
+func initSNMPHandler(config *of_v2.SNMPConfig, cntr map[string]*prometheus.Counter, cntrVec map[string]*prometheus.CounterVec, schedule *maintenance.Schedule) *snmp.Handler {
 	service, err := snmp.NewService(logv2, config, cntr, cntrVec)
 	if err != nil {
 		logv2.WithError(err).Fatalf("Failed to init SNMP service.")
 	}
+	watchAMTransport(service.AMTransport)
+
+	if schedule != nil {
+		service.Maintenance = snmp.NewMaintenanceGuard(schedule, &clock.Clock{}, logv2, cntrVec)
+	}
+
+	if config.RecordDir != "" {
+		recorder, err := snmp.NewRecorder(config, &clock.Clock{}, logv2)
+		if err != nil {
+			logv2.WithError(err).Fatalf("Failed to init event recording.")
+		}
+		service.Recorder = recorder
+	}
+	service.Audit = auditor(config.AuditFile, config.AuditFileSize, config.AuditFiles)
+
+	// Alerts for Alertmanager are written to the queue, and posted from it. Dry runs only log alerts.
+	if sinks, ok := service.As.(*sink.Composite); ok == true {
+		// Set only if present, an interface holding a nil pointer is not nil.
+		if q := queueAMSink(sinks, &config.Queue, config.Application); q != nil {
+			service.Queue = q
+		}
+	}
+	if config.DryRun == true && config.RoutesFile != "" {
+		logv2.Warningf("Routes are not applied on dry runs, alerts are only logged.")
+	} else {
+		ams := &amReceivers{
+			Client:     service.AM.Client,
+			APIVersion: service.AM.APIVersion,
+			Version:    config.Version,
+			Posts:      func() *prometheus.CounterVec { return service.AM.Posts },
+			Limiter:    service.AM.Limiter,
+			Queue:      config.Queue,
+			Namespace:  config.Application,
+		}
+		service.As = alertRouter(config.RoutesFile, service.As, ams, config.Version)
+	}
 
 	handler := &snmp.Handler{
-		Config: config,
-		SNMP:   service,
-		Log:    logv2,
+		Config:      config,
+		SNMP:        service,
+		Log:         logv2,
+		Maintenance: schedule,
 	}
 	return handler
 }
@@ -188,18 +700,86 @@ func ParseSNMPHandlerFlags(cmd *cobra.Command, args []string) {
 
 	// Define flags and configuration settings.
 	cmd.Flags().String("listen-address", "localhost:80", "host:port on which to listen, for SNMP trap events.")
-	cmd.Flags().String("am-address", "http://localhost:9093", "AlertManager's URL")
-	cmd.Flags().Duration("am-timeout", 1*time.Second, "Alertmanager timeout  (default: 10s)")
+	cmd.Flags().String("am-address", "http://localhost:9093", "AlertManager URLs separated by ',', alerts are posted to each.")
+	cmd.Flags().String("am-srv", "", "DNS SRV name of AlertManager peers, alerts are posted to each. Resolved only at startup, restart to pick up changed peers. (default: none)")
+	cmd.Flags().String("am-policy", "all", "Posting alerts succeeds if any or all AlertManager peers accept them. (default: all)")
+	cmd.Flags().Duration("am-timeout", 10*time.Second, "Alertmanager timeout  (default: 10s)")
+	cmd.Flags().String("am-api-version", "auto", "Alertmanager API version to post alerts to, auto, v1 or v2. auto detects it through /api/v2/status. (default: auto)")
+	cmd.Flags().String("am-basic-auth-user", "", "Username for basic auth to Alertmanager. (default: none)")
+	cmd.Flags().String("am-basic-auth-password-file", "", "Path to file with password for basic auth to Alertmanager, reloaded on change. (default: none)")
+	cmd.Flags().String("am-bearer-token-file", "", "Path to file with bearer token for Alertmanager, reloaded on change. (default: none)")
+	cmd.Flags().String("am-cert-file", "", "Path to client certificate for TLS to Alertmanager, reloaded on change. (default: none)")
+	cmd.Flags().String("am-key-file", "", "Path to client key for TLS to Alertmanager, reloaded on change. (default: none)")
+	cmd.Flags().String("am-ca-file", "", "Path to CA bundle to verify Alertmanager with, reloaded on change. (default: system roots)")
+	cmd.Flags().String("am-proxy-url", "", "HTTP proxy to reach Alertmanager through. (default: from HTTP_PROXY and HTTPS_PROXY)")
 	cmd.Flags().String("mibs-dir", "none", "Path to MIBs directory.")
 	cmd.Flags().String("cache-file", "none", "Path to MIBs cache file.")
 	cmd.Flags().String("config-dir", "", "Path to directory containing configs.")
 	cmd.Flags().Bool("throttle", true, "Trottle posts to Alertmanager (default: true)")
+	cmd.Flags().Float64("am-rate", defaultAMRate, "Max. posts per second to Alertmanager, with throttle, 0 for no limit. (default: 5)")
+	cmd.Flags().Int("am-max-batch", defaultAMMaxBatch, "Max. alerts per post to Alertmanager, with throttle, 0 for no limit. (default: 500)")
+	cmd.Flags().Int("am-max-concurrency", defaultAMMaxConcurrency, "Max. posts to Alertmanager in flight at once, with throttle. (default: 1)")
+	cmd.Flags().Duration("am-min-backoff", defaultAMMinBackoff, "Wait between posts once Alertmanager responds with 429 or 5xx, doubled while it continues. (default: 1s)")
+	cmd.Flags().Duration("am-max-backoff", defaultAMMaxBackoff, "Max. wait between posts while Alertmanager is overloaded. (default: 30s)")
 	cmd.Flags().Int("post-time", 300, "Approx time in ms, that it takes to HTTP POST to AM. (default: 300)")
 	cmd.Flags().Int("sleep-time", 100, "Time in ms, to sleep between HTTP POST to AM. (default: 100)")
 	cmd.Flags().Int("send-time", 10000, "Time in ms, to complete HTTP POST to AM. (default: 10000)")
+	cmd.Flags().MarkDeprecated("post-time", fmt.Sprintf(throttleDeprecated, "am-rate"))
+	cmd.Flags().MarkDeprecated("sleep-time", fmt.Sprintf(throttleDeprecated, "am-rate"))
+	cmd.Flags().MarkDeprecated("send-time", fmt.Sprintf(throttleDeprecated, "am-rate"))
 	cmd.Flags().Bool("dry-run", false, "Log generated alerts, instead of sending to Alertmanager. (default: false)")
 	cmd.Flags().Bool("log-unknown", false, "Log unknown alerts at info level. (default: false)")
 	cmd.Flags().Bool("forward-unknown", false, "send unknown alerts to Alertmanager. (default: false)")
+	cmd.Flags().Float64("storm-global-rate", 0, "Max. SNMP trap events per second accepted across all sources, 0 to disable. (default: 0)")
+	cmd.Flags().Int("storm-global-burst", 1000, "Max. SNMP trap events accepted in a burst across all sources. (default: 1000)")
+	cmd.Flags().String("storm-global-action", "drop", "Action for events above global limit, drop or sample. (default: drop)")
+	cmd.Flags().Int("storm-global-sample-rate", 100, "With sample action, 1 in n events above global limit is processed. (default: 100)")
+	cmd.Flags().String("maintenance-file", "", "Path to maintenance schedule file, reloaded on change. (default: none)")
+	cmd.Flags().String("record-dir", "", "Path to directory to record received events to. (default: none)")
+	cmd.Flags().Int64("record-file-size", 100, "Size in MB of a recording file, after which a new one is started. (default: 100)")
+	cmd.Flags().Int64("record-retention-size", 1024, "Size in MB of recording files kept, oldest are removed first. (default: 1024)")
+	cmd.Flags().Duration("record-retention-time", 7*24*time.Hour, "Age of recording files kept. (default: 168h)")
+	cmd.Flags().String("audit-file", "", "Path to file to write a JSON record of every alert decision to. (default: none)")
+	cmd.Flags().Int64("audit-file-size", 100, "Size in MB of the audit file, after which it is rotated. (default: 100)")
+	cmd.Flags().Int("audit-files", 10, "Rotated audit files kept. (default: 10)")
+	cmd.Flags().String("queue-dir", "", "Path to directory to queue alerts in, retrying failed posts to AlertManager. (default: none)")
+	cmd.Flags().Duration("queue-max-age", defaultQueueMaxAge, "Age after which queued alerts are moved to dead letters, 0 to retry forever. (default: 24h)")
+	cmd.Flags().Duration("queue-min-backoff", defaultQueueMinBackoff, "Wait before retrying a failed post, doubled on every failure. (default: 1s)")
+	cmd.Flags().Duration("queue-max-backoff", defaultQueueMaxBackoff, "Max. wait before retrying a failed post. (default: 5m)")
+	cmd.Flags().String("sinks", "alertmanager", sinksUsage)
+	cmd.Flags().String("sink-policy", "any", sinkPolicyUsage)
+	cmd.Flags().String("webhook-url", "", "URL the webhook sink posts alerts to. (default: none)")
+	cmd.Flags().String("webhook-template", "", "Path to Go template of webhook sink bodies. (default: alerts as JSON)")
+	cmd.Flags().String("webhook-secret-file", "", "Path to file with key signing webhook sink bodies with HMAC-SHA256. (default: unsigned)")
+	cmd.Flags().Duration("webhook-timeout", 10*time.Second, "Webhook sink timeout. (default: 10s)")
+	cmd.Flags().String("sink-file", "", "Path to file the file sink appends alerts to as NDJSON. (default: none)")
+	cmd.Flags().String("trap-targets", "", "SNMP managers the trap sink notifies, as host[:port] separated by ','. (default: none)")
+	cmd.Flags().String("trap-version", "v2c", "SNMP version of the trap sink, v2c or v3. (default: v2c)")
+	cmd.Flags().String("trap-community", "public", "SNMPv2c community of the trap sink. (default: public)")
+	cmd.Flags().Bool("trap-inform", false, "Send informs acknowledged by managers, instead of traps. (default: false)")
+	cmd.Flags().Int("trap-retries", 2, "Times an unacknowledged inform is resent. (default: 2)")
+	cmd.Flags().Duration("trap-timeout", 5*time.Second, "Wait for the acknowledgement of an inform. (default: 5s)")
+	cmd.Flags().Float64("trap-rate", 0, "Max. SNMP notifications per second to each manager, 0 for no limit. (default: 0)")
+	cmd.Flags().String("trap-mib", "1.3.6.1.4.1.32473.1", "OID OF-ALERT-MIB is registered at by managers, under your enterprise. (default: 1.3.6.1.4.1.32473.1, reserved for documentation)")
+	cmd.Flags().String("trap-user", "", "SNMPv3 user of the trap sink. (default: none)")
+	cmd.Flags().String("trap-auth-protocol", "", "SNMPv3 auth protocol of the trap sink, MD5 or SHA. (default: noAuth)")
+	cmd.Flags().String("trap-auth-passphrase-file", "", "Path to file with the SNMPv3 auth passphrase. (default: none)")
+	cmd.Flags().String("trap-priv-protocol", "", "SNMPv3 privacy protocol of the trap sink, DES or AES. (default: noPriv)")
+	cmd.Flags().String("trap-priv-passphrase-file", "", "Path to file with the SNMPv3 privacy passphrase. (default: none)")
+	cmd.Flags().String("trap-engine-id", "", "SNMPv3 engine ID in hex, traps are sent from. Requires --trap-engine-boots-file. (default: generated)")
+	cmd.Flags().String("trap-engine-boots-file", "", "Path to file keeping SNMPv3 engine boots of --trap-engine-id, incremented on every start. (default: none)")
+	cmd.Flags().String("syslog-address", "", "Syslog collector the syslog sink sends alerts to, as host[:port]. (default: none)")
+	cmd.Flags().String("syslog-transport", "udp", "Transport of the syslog sink, udp, tcp or tls. (default: udp)")
+	cmd.Flags().String("syslog-format", "rfc5424", "Format of syslog sink messages, rfc5424 or rfc3164. (default: rfc5424)")
+	cmd.Flags().String("syslog-facility", "local0", "Facility of syslog sink messages. (default: local0)")
+	cmd.Flags().String("syslog-app-name", "of", "App name of syslog sink messages. (default: of)")
+	cmd.Flags().String("syslog-enterprise", "32473", "Private enterprise number of structured data IDs of syslog sink messages, like labels@32473. (default: 32473, reserved for documentation)")
+	cmd.Flags().Duration("syslog-timeout", 10*time.Second, "Syslog sink connect and write timeout. (default: 10s)")
+	cmd.Flags().String("syslog-cert-file", "", "Path to client certificate of the syslog sink over TLS. (default: none)")
+	cmd.Flags().String("syslog-key-file", "", "Path to client key of the syslog sink over TLS. (default: none)")
+	cmd.Flags().String("syslog-ca-file", "", "Path to CA bundle verifying syslog collectors over TLS. (default: system roots)")
+	cmd.Flags().String("routes-file", "", "Path to file routing alerts to receivers by label, reloaded on change. (default: none, alerts go to sinks)")
+	cmd.Flags().Int("debug-events", 100, "Recent events kept with decision traces at /api/v2/debug/events, 0 to disable. (default: 100)")
 	checkRequiredFlags(cmd, args, []string{"dry-run", "log-unknown", "forward-unknown"})
 }
 
@@ -209,21 +789,55 @@ func SNMPConfig(cmd *cobra.Command) *of_v2.SNMPConfig {
 	cfg.ListenAddress = viper.GetString("listen-address")
 	cfg.AMAddress = viper.GetString("am-address")
 	cfg.AMTimeout = viper.GetDuration("am-timeout")
+	apiVersion, err := am.ParseAPIVersion(viper.GetString("am-api-version"))
+	if err != nil {
+		logv2.WithError(err).Fatalf("Invalid am-api-version.")
+	}
+	cfg.AMAPIVersion = apiVersion
+	cfg.AMClient = amClientConfig("am-", cfg.AMTimeout)
 	cfg.SNMPMibsDir = viper.GetString("mibs-dir")
 	cfg.CacheFile = viper.GetString("cache-file")
 	cfg.ConfigDir = viper.GetString("config-dir")
 	cfg.Version = infoSvc.String()
 
 	cfg.Throttle = viper.GetBool("throttle")
-	cfg.PostTime = viper.GetInt("post-time")
-	cfg.SleepTime = viper.GetInt("sleep-time")
-	cfg.SendTime = viper.GetInt("send-time")
+	cfg.AMLimit = limitConfig("am-")
 	cfg.DryRun = viper.GetBool("dry-run")
 	cfg.LogUnknown = viper.GetBool("log-unknown")
 	cfg.ForwardUnknown = viper.GetBool("forward-unknown")
+	cfg.StormGlobalRate = viper.GetFloat64("storm-global-rate")
+	cfg.StormGlobalBurst = viper.GetInt("storm-global-burst")
+	cfg.StormGlobalAction = viper.GetString("storm-global-action")
+	cfg.StormGlobalSampleRate = viper.GetInt("storm-global-sample-rate")
+	cfg.MaintenanceFile = viper.GetString("maintenance-file")
+	cfg.RecordDir = viper.GetString("record-dir")
+	cfg.RecordFileSize = viper.GetInt64("record-file-size") * 1024 * 1024
+	cfg.RecordRetentionSize = viper.GetInt64("record-retention-size") * 1024 * 1024
+	cfg.RecordRetentionTime = viper.GetDuration("record-retention-time")
+	cfg.DebugEvents = viper.GetInt("debug-events")
+	cfg.Queue = queueConfig("queue-")
+	cfg.Sinks = sinkConfig("")
+	cfg.RoutesFile = viper.GetString("routes-file")
+	cfg.AuditFile = viper.GetString("audit-file")
+	cfg.AuditFileSize = viper.GetInt64("audit-file-size") * 1024 * 1024
+	cfg.AuditFiles = viper.GetInt("audit-files")
+
+	resolver := am.PeerResolver{}
+	if cfg.AMPeers, err = resolver.Resolve(cfg.AMAddress, viper.GetString("am-srv")); err != nil {
+		logv2.WithError(err).Fatalf("Failed to resolve AlertManager peers.")
+	}
+	for _, peer := range cfg.AMPeers {
+		if strings.HasPrefix(peer, "http") == false {
+			logv2.Fatalf("AM URL must begin with http/https")
+		}
+	}
+	cfg.AMAddress = cfg.AMPeers[0]
+	if cfg.AMPolicy, err = am.ParsePolicy(viper.GetString("am-policy")); err != nil {
+		logv2.WithError(err).Fatalf("Invalid am-policy.")
+	}
 
-	if strings.HasPrefix(cfg.AMAddress, "http") == false {
-		logv2.Fatalf("AM URL must begin with http/https")
+	if cfg.StormGlobalAction != "drop" && cfg.StormGlobalAction != "sample" {
+		logv2.Fatalf("storm-global-action must be drop or sample.")
 	}
 
 	if cfg.SNMPMibsDir == "none" && cfg.CacheFile == "none" {
@@ -243,6 +857,11 @@ func init() {
 	// Create and add the subcommands of the `snmp` command.
 	cmd.AddCommand(cmdSNMPHandler())
 	cmd.AddCommand(cmdSNMPMIBsProcessor())
+	cmd.AddCommand(cmdSNMPValidate())
+	cmd.AddCommand(cmdSNMPEvaluate())
+	cmd.AddCommand(cmdSNMPTest())
+	cmd.AddCommand(cmdSNMPDiff())
+	cmd.AddCommand(cmdSNMPReplay())
 
 	// Add the `snmp` command to the root command.
 	rootCmd.AddCommand(cmd)
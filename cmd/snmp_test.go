@@ -16,6 +16,7 @@ import (
 	http "github.com/cisco-cx/of/wrap/http/v2"
 	logger "github.com/cisco-cx/of/wrap/logrus/v2"
 	snmp "github.com/cisco-cx/of/wrap/snmp/v2"
+	clock "github.com/cisco-cx/of/wrap/time/v2"
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/require"
 )
@@ -179,3 +180,115 @@ func getResponse(t *testing.T, expectedStatusCode int, u string) string {
 	require.NoError(t, err)
 	return string(all)
 }
+
+// Test SNMP configs validation.
+func TestValidateSNMPConfigs(t *testing.T) {
+	cfg := &of_v2.SNMPConfig{
+		ConfigDir:   "test/snmp/configs/",
+		SNMPMibsDir: "none",
+		CacheFile:   "none",
+	}
+	var b bytes.Buffer
+	count, err := snmp_cmd.ValidateSNMPConfigs(cfg, &b)
+	require.NoError(t, err)
+	require.Equal(t, 0, count)
+	require.Equal(t, "0 errors, 0 warnings.\n", b.String())
+
+	// OIDs are checked against MIBs.
+	cfg.SNMPMibsDir = "test/snmp/mibs/"
+	b.Reset()
+	count, err = snmp_cmd.ValidateSNMPConfigs(cfg, &b)
+	require.NoError(t, err)
+	require.Equal(t, 0, count)
+	require.Contains(t, b.String(), "is not defined in MIBs")
+}
+
+// Test SNMP events evaluation.
+func TestEvaluateSNMPEvents(t *testing.T) {
+	cfg := &of_v2.SNMPConfig{
+		ConfigDir:   "test/snmp/configs/",
+		SNMPMibsDir: "test/snmp/mibs/",
+		CacheFile:   "none",
+	}
+	r := bytes.NewBufferString("source=10.0.0.1 .1.3.6.1.6.3.1.1.4.1=.1.3.6.1.4.1.8164.2.44 .1.3.6.1.4.1.8164.1.2.1.1.1=7\n")
+	var b bytes.Buffer
+	err := snmp_cmd.EvaluateSNMPEvents(cfg, r, &b)
+	require.NoError(t, err)
+	require.Contains(t, b.String(), "  lookup: epc\n")
+	require.Contains(t, b.String(), `"alert_name": "starCard"`)
+}
+
+// Test SNMP config unit tests.
+func TestRunSNMPConfigTests(t *testing.T) {
+	cfg := &of_v2.SNMPConfig{
+		ConfigDir:   "test/snmp/configs/",
+		SNMPMibsDir: "test/snmp/mibs/",
+		CacheFile:   "none",
+	}
+	var b, junit bytes.Buffer
+	failed, err := snmp_cmd.RunSNMPConfigTests(cfg, []string{"test/snmp/tests/epc.yaml"}, &b, &junit)
+	require.NoError(t, err)
+	require.Equal(t, 0, failed)
+	require.Contains(t, b.String(), "ok    test/snmp/tests/epc.yaml: starCard fires\n")
+	require.Contains(t, b.String(), "3 tests, 0 failed.\n")
+	require.Contains(t, junit.String(), `<testsuite name="test/snmp/tests/epc.yaml" tests="3" failures="0"`)
+}
+
+// Test SNMP configs diff, with the same configs on both sides.
+func TestDiffSNMPConfigs(t *testing.T) {
+	cfg := &of_v2.SNMPConfig{
+		ConfigDir:   "test/snmp/configs/",
+		SNMPMibsDir: "test/snmp/mibs/",
+		CacheFile:   "none",
+	}
+	r := bytes.NewBufferString(`{"document": {"receipts": {"snmptrapd": {"source": {"address": "10.0.0.1"}, "vars": [{"oid": ".1.3.6.1.6.3.1.1.4.1", "value": ".1.3.6.1.4.1.8164.2.44"}, {"oid": ".1.3.6.1.4.1.8164.1.2.1.1.1", "value": "7"}]}}}}
+`)
+	var b bytes.Buffer
+	err := snmp_cmd.DiffSNMPConfigs(cfg, cfg, r, &b)
+	require.NoError(t, err)
+	require.Contains(t, b.String(), "1 events, 0 differences.\n")
+	require.Contains(t, b.String(), "  epc     1          0      0        0\n")
+}
+
+// Test replay of recorded SNMP events, in process and to a running handler.
+func TestReplaySNMPEvents(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snmp-recording")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	recorder, err := snmp.NewRecorder(&of_v2.SNMPConfig{RecordDir: dir}, &clock.Clock{}, logger.New())
+	require.NoError(t, err)
+	events, err := snmp.ParseEvents(bytes.NewBufferString(`
+source=10.0.0.1 .1.3.6.1.6.3.1.1.4.1=.1.3.6.1.4.1.8164.2.44 .1.3.6.1.4.1.8164.1.2.1.1.1=7
+source=10.0.0.1 .1.3.6.1.6.3.1.1.4.1=.1.3.6.1.4.1.8164.2.5 .1.3.6.1.4.1.8164.1.2.1.1.1=7
+`))
+	require.NoError(t, err)
+	require.NoError(t, recorder.Record(events))
+	require.NoError(t, recorder.Close())
+
+	cfg := &of_v2.SNMPConfig{
+		ConfigDir:   "test/snmp/configs/",
+		SNMPMibsDir: "test/snmp/mibs/",
+		CacheFile:   "none",
+	}
+	sent, err := snmp_cmd.ReplaySNMPEvents(cfg, "", 0, []string{dir})
+	require.NoError(t, err)
+	require.Equal(t, 2, sent)
+
+	received := 0
+	addr := "localhost:24934"
+	srv := http.NewServer(&of_v2.HTTPConfig{ListenAddress: addr}, t.Name(), nil)
+	srv.HandleFunc("/api/v2/events", func(w of_v2.ResponseWriter, r of_v2.Request) {
+		var events []*of_v2.PostableEvent
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&events))
+		received += len(events)
+	})
+	require.NoError(t, srv.ListenAndServe())
+	defer srv.Shutdown()
+
+	target := "http://" + addr + "/api/v2/events"
+	sent, err = snmp_cmd.ReplaySNMPEvents(&of_v2.SNMPConfig{AMTimeout: time.Second}, target, 0, []string{dir})
+	require.NoError(t, err)
+	require.Equal(t, 2, sent)
+	require.Equal(t, 2, received)
+}
@@ -42,6 +42,7 @@ import (
 	"time"
 
 	aci_config "github.com/cisco-cx/of/pkg/v1/aci"
+	of_v2 "github.com/cisco-cx/of/pkg/v2"
 )
 
 // Represents ACI settings.
@@ -51,6 +52,10 @@ type ACIConfig struct {
 	CycleInterval      int
 	ACITimeout         time.Duration
 	AmURL              string
+	AmAPIVersion       string                 // auto, v1 or v2.
+	AmPeers            []string               // Alertmanager peers alerts are posted to, AmURL if empty.
+	AmPolicy           string                 // any or all peers must accept alerts.
+	AmClient           of_v2.HTTPClientConfig // Auth and TLS settings of the Alertmanager client.
 	ACIHosts           []string
 	AlertsCFGFile      string
 	SecretsCFGFile     string
@@ -61,10 +66,15 @@ type ACIConfig struct {
 	sc                 aci_config.Secrets
 	StaticLabels       LabelMap
 	Throttle           bool
-	PostTime           int
-	SleepTime          int
-	SendTime           int
+	AmLimit            of_v2.BatchLimitConfig // Limits posts to Alertmanager, if Throttle is set.
 	ConsulEnabled      bool
 	ConsulACIGroupHost string
 	Debug              bool
+	MaintenanceFile    string
+	AuditFile          string // Audit log of alert decisions, disabled if empty.
+	AuditFileSize      int64  // Bytes, after which the audit file is rotated.
+	AuditFiles         int    // Rotated audit files kept.
+	AmQueue            of_v2.QueueConfig
+	Sinks              of_v2.SinkConfig // Sinks alerts are sent to, Alertmanager if none are set.
+	RoutesFile         string           // Routes alerts to receivers by label, the default receiver sends to Sinks.
 }
@@ -40,13 +40,26 @@ package v2
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"sort"
+	"strings"
 	"time"
 )
 
 // RFC3339 with milliseconds. Time format expected by Alertmanager.
 const AMTimeFormat = "2006-01-02T15:04:05.000Z07:00"
 
+// Represents the Alertmanager API version alerts are posted to.
+type AMAPIVersion string
+
+const (
+	// AMAPIVersion constants
+	AMAPIAuto AMAPIVersion = "auto" // Detected through /api/v2/status.
+	AMAPIV1   AMAPIVersion = "v1"   // Deprecated, removed in Alertmanager 0.27.
+	AMAPIV2   AMAPIVersion = "v2"
+)
+
 // Represents Alertmanager alert.
 type Alert struct {
 	Labels       map[string]string `json:"labels"`
@@ -56,6 +69,116 @@ type Alert struct {
 	GeneratorURL string            `json:"generatorURL"`
 }
 
+// Represents when posting alerts to Alertmanager peers succeeds.
+type AMSuccessPolicy string
+
+const (
+	// AMSuccessPolicy constants
+	AMPolicyAny AMSuccessPolicy = "any" // At least one peer accepted the alerts.
+	AMPolicyAll AMSuccessPolicy = "all" // Every peer accepted the alerts.
+)
+
+// Represents alert in the postableAlerts schema of Alertmanager API v2.
+// Zero times are left out, for Alertmanager to set them.
+type PostableAlert struct {
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations,omitempty"`
+	StartsAt     *time.Time        `json:"startsAt,omitempty"`
+	EndsAt       *time.Time        `json:"endsAt,omitempty"`
+	GeneratorURL string            `json:"generatorURL,omitempty"`
+}
+
+// Represents a label matcher of a silence, or of an alert filter.
+type SilenceMatcher struct {
+	Name    string `json:"name"`
+	Value   string `json:"value"`
+	IsRegex bool   `json:"isRegex"`
+	IsEqual *bool  `json:"isEqual,omitempty"` // Not set by Alertmanager before 0.22, equal if nil.
+}
+
+// Represents a silence of Alertmanager API v2.
+type Silence struct {
+	ID        string           `json:"id,omitempty"`
+	Matchers  []SilenceMatcher `json:"matchers"`
+	StartsAt  time.Time        `json:"startsAt"`
+	EndsAt    time.Time        `json:"endsAt"`
+	CreatedBy string           `json:"createdBy"`
+	Comment   string           `json:"comment"`
+	Status    *SilenceStatus   `json:"status,omitempty"` // Set by Alertmanager.
+}
+
+// Represents the state of a silence, active, pending or expired.
+type SilenceStatus struct {
+	State string `json:"state"`
+}
+
+// Represents alert in the gettableAlerts schema of Alertmanager API v2.
+type GettableAlert struct {
+	Alert
+	Fingerprint string      `json:"fingerprint"`
+	Status      AlertStatus `json:"status"`
+}
+
+// Represents the state of an alert in Alertmanager, active, suppressed or unprocessed.
+type AlertStatus struct {
+	State       string   `json:"state"`
+	SilencedBy  []string `json:"silencedBy"`
+	InhibitedBy []string `json:"inhibitedBy"`
+}
+
+// Represents an error returned by the Alertmanager API.
+type AMError struct {
+	Method     string `json:"-"` // POST if empty.
+	URL        string `json:"-"`
+	StatusCode int    `json:"-"`
+	Code       int    `json:"code,omitempty"`      // Set by API v2.
+	ErrorType  string `json:"errorType,omitempty"` // Set by API v1.
+	Message    string `json:"message"`
+}
+
+func (e *AMError) Error() string {
+	method := e.Method
+	if method == "" {
+		method = "POST"
+	}
+	return fmt.Sprintf("%s to AlertManager on %q returned HTTP %d: %s", method, e.URL, e.StatusCode, e.Message)
+}
+
+// Check if Alertmanager is overloaded or failing, so posts should slow down.
+func (e *AMError) Overloaded() bool {
+	return e.StatusCode == 429 || e.StatusCode >= 500
+}
+
+// Represents failures posting alerts to Alertmanager peers, failing the policy.
+type AMPeersError struct {
+	Policy AMSuccessPolicy
+	Peers  int              // Number of peers posted to.
+	Errors map[string]error // By peer URL.
+}
+
+func (e *AMPeersError) Error() string {
+	peers := make([]string, 0, len(e.Errors))
+	for peer := range e.Errors {
+		peers = append(peers, peer)
+	}
+	sort.Strings(peers)
+	msgs := make([]string, len(peers))
+	for i, peer := range peers {
+		msgs[i] = peer + ": " + e.Errors[peer].Error()
+	}
+	return fmt.Sprintf("Posting to %d of %d Alertmanager peers failed, policy %s: %s", len(e.Errors), e.Peers, e.Policy, strings.Join(msgs, "; "))
+}
+
+// Check if any failed peer is overloaded.
+func (e *AMPeersError) Overloaded() bool {
+	for _, err := range e.Errors {
+		if oe, ok := err.(OverloadError); ok == true && oe.Overloaded() == true {
+			return true
+		}
+	}
+	return false
+}
+
 // Represents Alertmanager postAlerts params.
 type PostAlertsParams struct {
 	Alerts []Alert
@@ -0,0 +1,40 @@
+package v2
+
+import "time"
+
+type AuditAction string
+
+const (
+	// AuditAction constants
+	AuditGenerated  AuditAction = "generated"  // Alert was generated, to be posted.
+	AuditDropped    AuditAction = "dropped"    // Event or alert was dropped by config or limits.
+	AuditSuppressed AuditAction = "suppressed" // Alert was held back or tagged, by maintenance, aggregation or a parent.
+	AuditFailed     AuditAction = "failed"     // Alert could not be generated.
+
+	// Post results of generated alerts.
+	AuditPostSent   string = "sent"
+	AuditPostFailed string = "failed"
+	AuditPostDryRun string = "dry_run"
+	AuditPostQueued string = "queued" // Written to the retry queue, to be delivered.
+)
+
+// Represents a decision taken for an alert.
+type AuditRecord struct {
+	Time        time.Time   `json:"time"`
+	EventID     string      `json:"event_id"`
+	Source      string      `json:"source"`
+	TrapOID     string      `json:"trap_oid,omitempty"`
+	Config      string      `json:"config,omitempty"`
+	Alert       string      `json:"alert,omitempty"`
+	Fingerprint string      `json:"fingerprint,omitempty"`
+	Action      AuditAction `json:"action"`
+	Reason      string      `json:"reason,omitempty"`
+	PostResult  string      `json:"post_result,omitempty"` // Set for generated alerts, once posted.
+	PostError   string      `json:"post_error,omitempty"`
+}
+
+// Represents a durable record of alert decisions.
+type Auditor interface {
+	Audit([]AuditRecord) error
+	Close() error
+}
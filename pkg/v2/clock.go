@@ -0,0 +1,8 @@
+package v2
+
+import "time"
+
+// Represents a source of the current time.
+type Clock interface {
+	Now() time.Time
+}
@@ -38,3 +38,10 @@ type HistogramVector interface {
 	Create([]string) error            // Labels.
 	Observed([]string, float64) error // []LabelValues, seconds
 }
+
+// GaugeVector is a Domain Type that represents the options for creating
+// a GaugeVec. It is based on `prometheus/GaugeVec`
+type GaugeVector interface {
+	Create([]string) error                // Labels.
+	Set(map[string]string, float64) error // map[Label]Value, value
+}
@@ -15,9 +15,64 @@ const (
 	ErrKeyMissing       = Error("Key missing in mod.")
 
 	// Alert Generator errors.
-	ErrConfigNotFound   = Error("Unknown config.")
-	ErrNoMatch          = Error("No alert matched in alert config.")
-	ErrUnknownEventType = Error("Unknown event type specified.")
+	ErrConfigNotFound    = Error("Unknown config.")
+	ErrNoMatch           = Error("No alert matched in alert config.")
+	ErrUnknownEventType  = Error("Unknown event type specified.")
+	ErrUnknownActionType = Error("Unknown action type specified.")
+
+	// Schema errors.
+	ErrSchemaMismatch = Error("Data does not match schema.")
+
+	// SNMP service errors.
+	ErrMIBsNotConfigured  = Error("No MIBs cache file or MIBs dir configured.")
+	ErrInvalidConfig      = Error("Config files have errors.")
+	ErrReloadNotSupported = Error("Service was not created with NewService, reload not supported.")
+
+	// Alertmanager errors.
+	ErrUnknownAMAPIVersion = Error("Unknown Alertmanager API version.")
+	ErrUnknownAMPolicy     = Error("Unknown Alertmanager success policy.")
+	ErrNoAMPeers           = Error("No Alertmanager peers found.")
+	ErrInvalidMatcher      = Error("Invalid label matcher.")
+	ErrNoSilenceMatchers   = Error("Silence requires matchers.")
+	ErrNoAlertLabels       = Error("Alert requires labels.")
+
+	// HTTP client errors.
+	ErrInvalidCA      = Error("No certificates found in CA file.")
+	ErrKeyWithoutCert = Error("Client certificate and key must be set together.")
+
+	// Sink errors.
+	ErrUnknownSink       = Error("Unknown alert sink.")
+	ErrNoWebhookURL      = Error("Webhook sink requires a URL.")
+	ErrNoSinkFile        = Error("File sink requires a file.")
+	ErrDuplicateSink     = Error("Alert sink listed more than once.")
+	ErrUnknownSinkPolicy = Error("Unknown sink success policy.")
+	ErrQueueClosed       = Error("Alert queue is closed.")
+
+	// Syslog errors.
+	ErrNoSyslogAddress         = Error("Syslog sink requires an address.")
+	ErrUnknownSyslogFormat     = Error("Unknown syslog format.")
+	ErrUnknownSyslogFacility   = Error("Unknown syslog facility.")
+	ErrUnknownSyslogTransport  = Error("Unknown syslog transport.")
+	ErrInvalidSyslogEnterprise = Error("Invalid syslog enterprise number.")
+
+	// SNMP notification errors.
+	ErrUnknownSNMPVersion  = Error("Unknown SNMP version.")
+	ErrUnknownAuthProtocol = Error("Unknown SNMPv3 auth protocol.")
+	ErrUnknownPrivProtocol = Error("Unknown SNMPv3 privacy protocol.")
+	ErrPrivWithoutAuth     = Error("SNMPv3 privacy requires auth.")
+	ErrShortPassphrase     = Error("SNMPv3 passphrases must have at least 8 characters.")
+	ErrNoTrapTargets       = Error("Trap sink requires targets.")
+	ErrNoSNMPUser          = Error("SNMPv3 requires a user.")
+	ErrNoEngineBootsFile   = Error("SNMPv3 engine ID requires an engine boots file.")
+	ErrMalformedSNMP       = Error("Malformed SNMP message.")
+	ErrSNMPAuthFailed      = Error("SNMPv3 message authentication failed.")
+
+	// Routing errors.
+	ErrInvalidRoutes = Error("Invalid routes.")
+
+	// Maintenance errors.
+	ErrInvalidCron              = Error("Invalid cron expression.")
+	ErrInvalidMaintenanceWindow = Error("Invalid maintenance window.")
 
 	// Counter errors.
 	ErrCounterCreateFailed  = Error("Failed to create counter.")
@@ -26,6 +81,7 @@ const (
 	// CounterVec errors.
 	ErrCounterVecCreateFailed   = Error("Failed to create counter vector.")
 	ErrHistogramVecCreateFailed = Error("Failed to create histogram vector.")
+	ErrGaugeVecCreateFailed     = Error("Failed to create gauge vector.")
 )
 
 // Error represents an OF error.
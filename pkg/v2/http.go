@@ -27,6 +27,19 @@ type HTTPConfig struct {
 	WriteTimeout  time.Duration
 }
 
+// Represents HTTP client settings. Credentials and certificates are read from
+// files, so they can be rotated without a restart.
+type HTTPClientConfig struct {
+	Timeout               time.Duration // Of a request, including reading the response.
+	BasicAuthUser         string
+	BasicAuthPasswordFile string
+	BearerTokenFile       string // Sent as Authorization: Bearer, instead of basic auth.
+	CertFile              string // Client certificate, with KeyFile.
+	KeyFile               string
+	CAFile                string // CA bundle to verify servers with, system roots if empty.
+	ProxyURL              string // Proxy for all requests, else from HTTP_PROXY and HTTPS_PROXY.
+}
+
 type Server struct {
 	Srv *http.Server
 	Mux *http.ServeMux
@@ -0,0 +1,61 @@
+package v2
+
+import "time"
+
+type MaintenanceAction string
+
+const (
+	// MaintenanceAction constants
+	MaintenanceDrop  MaintenanceAction = "drop"
+	MaintenanceTag   MaintenanceAction = "tag"
+	MaintenanceDelay MaintenanceAction = "delay"
+
+	// Annotation added to alerts tagged during maintenance.
+	MaintenanceText string = "maintenance"
+)
+
+// Represents the maintenance schedule file.
+type MaintenanceSchedule struct {
+	Windows []MaintenanceWindow `yaml:"windows,omitempty" json:"windows"`
+}
+
+// Represents a one-off window between start and end, or a recurring window
+// starting at schedule (5 field cron expression) and lasting duration minutes.
+type MaintenanceWindow struct {
+	Name     string            `yaml:"name,omitempty" json:"name"`
+	Start    time.Time         `yaml:"start,omitempty" json:"start,omitempty"`
+	End      time.Time         `yaml:"end,omitempty" json:"end,omitempty"`
+	Schedule string            `yaml:"schedule,omitempty" json:"schedule,omitempty"`
+	Duration int               `yaml:"duration,omitempty" json:"duration,omitempty"`
+	Timezone string            `yaml:"timezone,omitempty" json:"timezone,omitempty"` // Timezone of schedule. Defaults to UTC.
+	Action   MaintenanceAction `yaml:"action,omitempty" json:"action"`               // Defaults to drop.
+	Scope    MaintenanceScope  `yaml:"scope,omitempty" json:"scope"`
+}
+
+// Alerts in scope of a window. Each defined criteria must match, an empty scope matches all alerts.
+type MaintenanceScope struct {
+	SourceAddresses []string          `yaml:"source_addresses,omitempty" json:"source_addresses,omitempty"` // IP addresses or CIDRs.
+	Clusters        []string          `yaml:"clusters,omitempty" json:"clusters,omitempty"`                 // SNMP cluster names.
+	ACIClusters     []string          `yaml:"aci_clusters,omitempty" json:"aci_clusters,omitempty"`         // ACI cluster names.
+	Labels          map[string]string `yaml:"labels,omitempty" json:"labels,omitempty"`                     // Labels that must be equal.
+}
+
+// Represents the alert being checked against maintenance windows.
+type MaintenanceTarget struct {
+	Address    string
+	Cluster    string
+	ACICluster string
+	Labels     map[string]string
+}
+
+// Represents a window and if it is active.
+type MaintenanceStatus struct {
+	MaintenanceWindow
+	Active bool `json:"active"`
+}
+
+// Maintenance decides if alerts are in maintenance.
+type Maintenance interface {
+	Window(target MaintenanceTarget, t time.Time) (MaintenanceWindow, bool) // Active window for target at t.
+	Windows(t time.Time) []MaintenanceStatus                                // All windows, with their status at t.
+}
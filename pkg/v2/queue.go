@@ -0,0 +1,34 @@
+package v2
+
+import "time"
+
+// Represents retry queue settings, the queue is disabled if Dir is empty.
+type QueueConfig struct {
+	Dir        string        // Write-ahead log and dead letters are kept here.
+	MaxAge     time.Duration // Batches older than this are dead-lettered, 0 to retry forever.
+	MinBackoff time.Duration // Wait after the first failed attempt, doubled on every failure.
+	MaxBackoff time.Duration
+}
+
+// Represents alerts posted together, waiting for delivery.
+type QueuedBatch struct {
+	ID         uint64    `json:"id"`
+	EnqueuedAt time.Time `json:"enqueued_at"`
+	Alerts     []Alert   `json:"alerts"`
+	Attempts   int       `json:"attempts,omitempty"`
+	LastError  string    `json:"last_error,omitempty"`
+}
+
+// Represents a batch given up on.
+type DeadLetter struct {
+	QueuedBatch
+	Time   time.Time `json:"time"`
+	Reason string    `json:"reason"`
+}
+
+// Represents alerts waiting for delivery to Alertmanager, kept across restarts.
+// Notify enqueues alerts, failing only if they could not be written.
+type AlertQueue interface {
+	Notifier
+	Close() error
+}
@@ -0,0 +1,31 @@
+package v2
+
+import "time"
+
+// Represents a rate limiter, deciding if n events may happen at given time.
+type RateLimiter interface {
+	Allow(time.Time) bool
+	AllowN(time.Time, int) bool
+}
+
+// Represents limits on posting items in batches.
+type BatchLimitConfig struct {
+	Rate           float64       // Posts per second, 0 for no limit.
+	MaxBatch       int           // Items per post, 0 for no limit.
+	MaxConcurrency int           // Posts in flight at once, 1 if less.
+	MinBackoff     time.Duration // Wait added after an overloaded response, doubled while they continue.
+	MaxBackoff     time.Duration
+}
+
+// Represents a limiter posting items in batches.
+type BatchLimiter interface {
+	// Post n items with post, a batch [start:end) at a time. Returns the last error.
+	Do(n int, post func(start int, end int) error) error
+}
+
+// Represents an error of a receiver, telling if it is overloaded
+// and senders should slow down, like on HTTP 429 and 5xx.
+type OverloadError interface {
+	error
+	Overloaded() bool
+}
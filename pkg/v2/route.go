@@ -0,0 +1,49 @@
+package v2
+
+import "time"
+
+// Receiver of alerts no route in the routes file sends elsewhere, the sinks of the handler.
+const DefaultReceiver = "default"
+
+// Represents the routes file, a routing tree modelled on the one of Alertmanager.
+type RoutesConfig struct {
+	Route     Route           `yaml:"route"`
+	Receivers []RouteReceiver `yaml:"receivers,omitempty"`
+}
+
+// Represents a node of the routing tree. Alerts matching Match and MatchRE are sent
+// to the receivers of matching child routes, or to Receiver if no child route matches.
+// Child routes after a matching one are only tried, if it has Continue set.
+type Route struct {
+	Receiver string            `yaml:"receiver,omitempty"` // Inherited from the parent route if empty.
+	Match    map[string]string `yaml:"match,omitempty"`    // Labels that must be equal.
+	MatchRE  map[string]string `yaml:"match_re,omitempty"` // Labels that must match anchored regular expressions.
+	Continue bool              `yaml:"continue,omitempty"`
+	Routes   []Route           `yaml:"routes,omitempty"`
+}
+
+// Represents a named receiver, sending alerts to each of the sinks set.
+type RouteReceiver struct {
+	Name         string             `yaml:"name"`
+	Alertmanager *RouteAlertmanager `yaml:"alertmanager,omitempty"`
+	Webhook      *RouteWebhook      `yaml:"webhook,omitempty"`
+	File         string             `yaml:"file,omitempty"` // Path alerts are appended to as NDJSON.
+	Stdout       bool               `yaml:"stdout,omitempty"`
+	Log          bool               `yaml:"log,omitempty"`
+	Trap         *TrapConfig        `yaml:"trap,omitempty"`
+	Syslog       *SyslogConfig      `yaml:"syslog,omitempty"`
+}
+
+// Represents Alertmanager peers of a receiver, posted to with the client settings of the handler.
+type RouteAlertmanager struct {
+	URLs   []string        `yaml:"urls"`
+	Policy AMSuccessPolicy `yaml:"policy,omitempty"` // Defaults to all.
+}
+
+// Represents the webhook of a receiver.
+type RouteWebhook struct {
+	URL        string        `yaml:"url"`
+	Template   string        `yaml:"template,omitempty"`    // Path to Go template of bodies.
+	SecretFile string        `yaml:"secret_file,omitempty"` // Path to key signing bodies.
+	Timeout    time.Duration `yaml:"timeout,omitempty"`
+}
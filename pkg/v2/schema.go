@@ -10,3 +10,9 @@ type SchemaValidator interface {
 	ValidateYAML([]byte) error
 	ValidateJSON([]byte) error
 }
+
+// Represents a value in a document, that does not match the schema.
+type SchemaError struct {
+	Path    string // JSON pointer to the value.
+	Message string
+}
@@ -0,0 +1,77 @@
+package v2
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Represents where alerts are sent to.
+type SinkType string
+
+const (
+	// SinkType constants
+	SinkAlertmanager SinkType = "alertmanager"
+	SinkWebhook      SinkType = "webhook" // JSON, or a templated body, posted to a URL.
+	SinkFile         SinkType = "file"    // NDJSON appended to a file.
+	SinkStdout       SinkType = "stdout"  // NDJSON written to stdout.
+	SinkLog          SinkType = "log"     // Logged, like dry runs.
+	SinkTrap         SinkType = "trap"    // SNMP notifications of OF-ALERT-MIB.
+	SinkSyslog       SinkType = "syslog"  // Syslog messages.
+)
+
+// Represents when sending alerts to sinks succeeds.
+type SinkPolicy string
+
+const (
+	// SinkPolicy constants
+	SinkPolicyAny SinkPolicy = "any" // At least one sink accepted the alerts.
+	SinkPolicyAll SinkPolicy = "all" // Every sink accepted the alerts.
+)
+
+// Represents settings of sinks alerts are sent to.
+type SinkConfig struct {
+	Sinks             []SinkType    // Alertmanager if empty.
+	Policy            SinkPolicy    // When sending to Sinks succeeds, all if empty.
+	WebhookURL        string        // Required by the webhook sink.
+	WebhookTemplate   string        // Path to Go template of webhook bodies, WebhookMessage as JSON if empty.
+	WebhookSecretFile string        // Path to key signing webhook bodies with HMAC-SHA256, unsigned if empty.
+	WebhookTimeout    time.Duration // 0 for no timeout.
+	File              string        // Required by the file sink.
+	Trap              TrapConfig    // Settings of the trap sink.
+	Syslog            SyslogConfig  // Settings of the syslog sink.
+	DryRun            bool          // Only log alerts as a dry run, instead of sending them to Sinks.
+}
+
+// Represents the body posted to webhooks, and the data of webhook templates.
+type WebhookMessage struct {
+	Version string  `json:"version"`
+	Alerts  []Alert `json:"alerts"`
+}
+
+// Represents failures of sinks, alerts were sent to the other sinks.
+type SinksError struct {
+	Sinks  int              // Number of sinks alerts were sent to.
+	Errors map[string]error // By sink name.
+}
+
+func (e *SinksError) Error() string {
+	names := make([]string, 0, len(e.Errors))
+	for name := range e.Errors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	msgs := make([]string, len(names))
+	for i, name := range names {
+		msgs[i] = name + ": " + e.Errors[name].Error()
+	}
+	return fmt.Sprintf("Sending alerts to %d of %d sinks failed: %s", len(e.Errors), e.Sinks, strings.Join(msgs, "; "))
+}
+
+// Adapts a func to Notifier.
+type NotifierFunc func(*[]Alert) error
+
+func (f NotifierFunc) Notify(alerts *[]Alert) error {
+	return f(alerts)
+}
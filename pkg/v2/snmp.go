@@ -46,17 +46,44 @@ import (
 type SNMPConfig struct {
 	Application    string
 	AMAddress      string
+	AMPeers        []string        // Alertmanager peers alerts are posted to, AMAddress if empty.
+	AMPolicy       AMSuccessPolicy // When posting to AMPeers succeeds.
 	AMTimeout      time.Duration
+	AMAPIVersion   AMAPIVersion
+	AMClient       HTTPClientConfig // Auth and TLS settings of the Alertmanager client.
 	SNMPMibsDir    string
 	CacheFile      string
 	ListenAddress  string
 	ConfigDir      string
 	Version        string
 	Throttle       bool
-	PostTime       int
-	SleepTime      int
-	SendTime       int
+	AMLimit        BatchLimitConfig // Limits posts to Alertmanager, if Throttle is set.
 	DryRun         bool
 	LogUnknown     bool
 	ForwardUnknown bool
+
+	// Global limit for traps received, across all sources.
+	StormGlobalRate       float64
+	StormGlobalBurst      int
+	StormGlobalAction     string
+	StormGlobalSampleRate int
+	MaintenanceFile       string
+
+	// Recording of received events, disabled if RecordDir is empty.
+	RecordDir           string
+	RecordFileSize      int64         // Bytes, after which a new file is started.
+	RecordRetentionSize int64         // Bytes of files kept, oldest are removed first.
+	RecordRetentionTime time.Duration // Files older than this are removed.
+
+	DebugEvents int // Recent events kept with decision traces, 0 to disable.
+
+	// Audit log of alert decisions, disabled if AuditFile is empty.
+	AuditFile     string
+	AuditFileSize int64 // Bytes, after which the file is rotated.
+	AuditFiles    int   // Rotated files kept.
+
+	Queue QueueConfig // Retry queue of alerts posted to Alertmanager.
+
+	Sinks      SinkConfig // Sinks alerts are sent to, only the log sink on dry runs.
+	RoutesFile string     // Routes alerts to receivers by label, the default receiver sends to Sinks.
 }
@@ -6,6 +6,9 @@ type SelectType string
 type As string
 type OnError string
 type EventType string
+type StormAction string
+type SuppressAction string
+type ActionType string
 
 type Enabled *bool // Need *bool to differentiate between Enabled being set to false and not being defined in config.
 type URLPrefix string
@@ -35,6 +38,18 @@ const (
 	Send OnError = "send"
 	Drop OnError = "drop"
 
+	// StormAction constants
+	StormDrop   StormAction = "drop"
+	StormSample StormAction = "sample"
+
+	// SuppressAction constants
+	SuppressHold SuppressAction = "hold"
+	SuppressTag  SuppressAction = "tag"
+
+	// ActionType constants
+	ClearAction ActionType = "clear"
+	RaiseAction ActionType = "raise"
+
 	// Alert related constants
 	Firing          EventType = "error"
 	Clearing        EventType = "clear"
@@ -42,6 +57,9 @@ const (
 	FingerprintText string    = "alert_fingerprint"
 	SNMPTrapOID     string    = ".1.3.6.1.6.3.1.1.4.1.0"
 	SysUpTime       string    = ".1.3.6.1.2.1.1.3.0"
+	StormAlertName  string    = "snmpTrapStorm"
+	HeartbeatName   string    = "missingHeartbeat"
+	SuppressedText  string    = "suppressed_by"
 )
 
 // Represents map of configs from different files in conf.d
@@ -64,6 +82,16 @@ type Default struct {
 	LabelMods          []Mod              `yaml:"label_mods,omitempty"`
 	AnnotationMods     []Mod              `yaml:"annotation_mods,omitempty"`
 	EndsAt             int                `yaml:"ends_at,omitempty"`
+	StormControl       *StormControl      `yaml:"storm_control,omitempty"`
+}
+
+// Represents per source rate limits for traps matching a config.
+type StormControl struct {
+	Rate       float64     `yaml:"rate,omitempty"`        // Traps per second allowed from a source.
+	Burst      int         `yaml:"burst,omitempty"`       // Traps allowed in a burst from a source.
+	Action     StormAction `yaml:"action,omitempty"`      // What to do with traps above the limit. Defaults to drop.
+	SampleRate int         `yaml:"sample_rate,omitempty"` // With action sample, 1 in sample_rate traps above the limit is processed.
+	ClearAfter int         `yaml:"clear_after,omitempty"` // Seconds a source must stay within limit, to clear snmpTrapStorm alert. Defaults to 60.
 }
 
 // Maps IPaddresses to there cluster name.
@@ -97,6 +125,40 @@ type Alert struct {
 	Firing             map[string][]Select `yaml:"firing,omitempty"`
 	Clearing           map[string][]Select `yaml:"clearing,omitempty"`
 	EndsAt             int                 `yaml:"ends_at,omitempty"`
+	Aggregate          *Aggregate          `yaml:"aggregate,omitempty"`
+	Heartbeat          *Heartbeat          `yaml:"heartbeat,omitempty"`
+	SuppressedBy       []Suppression       `yaml:"suppressed_by,omitempty"`
+	OnFire             []Action            `yaml:"on_fire,omitempty"`
+}
+
+// Represents aggregation of repeated firing traps into a single alert.
+type Aggregate struct {
+	Window   int      `yaml:"window,omitempty"`    // Seconds within which occurrences are counted.
+	GroupBy  []string `yaml:"group_by,omitempty"`  // Label keys to count occurrences by. Defaults to all labels, except alert_oid.
+	MinCount int      `yaml:"min_count,omitempty"` // Occurrences within window, needed to fire the alert.
+}
+
+// Represents a periodic trap, expected from each source or cluster, based on defaults.source_type.
+// missingHeartbeat alert fires when the trap is not received within interval + grace.
+type Heartbeat struct {
+	Oid      string `yaml:"oid,omitempty"`      // Expected value of snmpTrapOID.
+	Interval int    `yaml:"interval,omitempty"` // Seconds between heartbeats.
+	Grace    int    `yaml:"grace,omitempty"`    // Seconds to wait past interval, before missingHeartbeat fires.
+}
+
+// Represents a parent alert, that suppresses this alert while firing.
+type Suppression struct {
+	Alert  string         `yaml:"alert,omitempty"`  // alertname of the parent alert.
+	Equal  []string       `yaml:"equal,omitempty"`  // Label keys that must be equal in parent and child. Defaults to source_address.
+	Action SuppressAction `yaml:"action,omitempty"` // hold or tag the child alert. Defaults to hold.
+}
+
+// Represents an action on other alerts, when this alert fires.
+// clear resolves firing alerts, raise re-raises alerts that were resolved.
+type Action struct {
+	Type   ActionType `yaml:"type,omitempty"`
+	Alerts []string   `yaml:"alerts,omitempty"` // alertname of alerts to act on.
+	Equal  []string   `yaml:"equal,omitempty"`  // Label keys that must be equal in both alerts. Defaults to source_address.
 }
 
 // Represents the alert selection criteria.
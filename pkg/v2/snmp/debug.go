@@ -0,0 +1,47 @@
+package snmp
+
+import (
+	"time"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+)
+
+// Represents what came of an event received.
+type EventOutcome string
+
+const (
+	// EventOutcome constants
+	EventAlerted EventOutcome = "alerted"  // Alerts were sent for the event.
+	EventNoAlert EventOutcome = "no_alert" // Configs were found, but no alert was sent.
+	EventUnknown EventOutcome = "unknown"  // No config was found for the event.
+	EventDropped EventOutcome = "dropped"  // Event was above the global storm limit.
+	EventError   EventOutcome = "error"    // Lookup for the event failed.
+)
+
+// Represents an event received, with the decisions taken for it.
+type DebugEvent struct {
+	ID         uint64            `json:"id"`
+	ReceivedAt time.Time         `json:"received_at"`
+	Source     string            `json:"source"`
+	TrapOID    string            `json:"trap_oid"`
+	Outcome    EventOutcome      `json:"outcome"`
+	Event      *of.PostableEvent `json:"event"`
+	Trace      []string          `json:"trace"`  // Lines written by a Tracer for the event.
+	Alerts     []of.Alert        `json:"alerts"` // Alerts sent for the event.
+}
+
+// Selects events from an EventLog, empty fields match all.
+type DebugFilter struct {
+	Source  string
+	TrapOID string
+	Outcome EventOutcome
+	Limit   int // Max. events returned, 0 for all.
+}
+
+// Keeps recent events with their decision traces.
+type EventLog interface {
+	Add(*DebugEvent)                 // Add event, replacing the oldest if full.
+	Events(DebugFilter) []DebugEvent // Events matching filter, newest first.
+	SetTrace(string, bool)           // Source address, and if its traces are logged.
+	Traced(string) bool              // Check if traces of source address are logged.
+}
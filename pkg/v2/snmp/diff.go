@@ -0,0 +1,53 @@
+package snmp
+
+import (
+	of "github.com/cisco-cx/of/pkg/v2"
+)
+
+// Represents how alerts changed between two config sets.
+type ChangeType string
+
+const (
+	AlertAdded   ChangeType = "added"
+	AlertRemoved ChangeType = "removed"
+	AlertChanged ChangeType = "changed"
+)
+
+// Represents alerts of an alert config, that changed the same way.
+type AlertDiff struct {
+	Change  ChangeType
+	Config  string // Config name.
+	Alert   string // Alert name in config.
+	Type    string // firing or clearing.
+	Count   int
+	Example DiffExample // First event, for which alerts changed.
+}
+
+// Represents an event, and the labels of the alert generated for it with each config set.
+type DiffExample struct {
+	Event  int // Index of event.
+	Source string
+	Old    map[string]string // Nil, if alert was added.
+	New    map[string]string // Nil, if alert was removed.
+}
+
+// Represents number of alerts of a config, by change.
+type ConfigDiff struct {
+	Config    string
+	Unchanged int
+	Added     int
+	Removed   int
+	Changed   int
+}
+
+// Represents differences in alerts, generated for the same events with two config sets.
+type DiffReport struct {
+	Events  int
+	Alerts  []AlertDiff  // Ordered by change, config, alert and type.
+	Configs []ConfigDiff // Ordered by config.
+}
+
+// Compares alerts generated for events, with two config sets.
+type Differ interface {
+	Diff([]*of.PostableEvent) DiffReport
+}
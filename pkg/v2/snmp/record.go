@@ -0,0 +1,16 @@
+package snmp
+
+import (
+	of "github.com/cisco-cx/of/pkg/v2"
+)
+
+// Records events as received, so they can be replayed.
+type Recorder interface {
+	Record([]*of.PostableEvent) error // Append events to the recording.
+	Close() error
+}
+
+// Sends events being replayed, like to a running handler.
+type Replayer interface {
+	Replay([]*of.PostableEvent) (int, error) // Replay events, returning the number sent.
+}
@@ -0,0 +1,32 @@
+package snmp
+
+import (
+	"time"
+)
+
+// Represents the outcome of checking an alert config against an event.
+type RuleResult string
+
+const (
+	// RuleResult constants
+	RuleMatched  RuleResult = "matched"  // Firing or clearing selects matched.
+	RuleFired    RuleResult = "fired"    // Firing alert was sent.
+	RuleCleared  RuleResult = "cleared"  // Clearing alerts were sent.
+	RuleDisabled RuleResult = "disabled" // Selects matched, but the alert is disabled.
+	RuleError    RuleResult = "error"    // Selects or mods failed.
+)
+
+// Represents an alert config, and how often it was hit since start.
+type Rule struct {
+	Config    string                `json:"config"`
+	Alert     string                `json:"alert"` // Alert name, or alerts[n] for alerts without name.
+	Enabled   bool                  `json:"enabled"`
+	Hits      map[RuleResult]uint64 `json:"hits"`
+	LastMatch *time.Time            `json:"last_match,omitempty"` // Nil, if never matched.
+}
+
+// Keeps track of hits of alert configs.
+type RuleTracker interface {
+	Hit(string, string, RuleResult) // Config name, alert name and result.
+	Rules(*V2Config) []Rule         // Every alert config in configs, with its hits.
+}
@@ -0,0 +1,64 @@
+package snmp
+
+import (
+	"time"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+)
+
+// Represents a file of unit tests for alert configs.
+//
+//	tests:
+//	- name: starCard fires
+//	  time: 2019-04-26T03:46:57Z
+//	  traps:
+//	  - source: 192.168.1.28
+//	    vars:
+//	    - oid: .1.3.6.1.6.3.1.1.4.1.0
+//	      value: .1.3.6.1.4.1.8164.2.44
+//	  expected_alerts:
+//	  - type: firing
+//	    labels:
+//	      alert_name: starCard
+type TestFile struct {
+	Tests []TestCase `yaml:"tests"`
+}
+
+// Represents traps processed in order, and the alerts expected from them.
+// No alerts are expected, if expected_alerts is empty.
+type TestCase struct {
+	Name           string          `yaml:"name"`
+	Time           string          `yaml:"time,omitempty"` // RFC3339 time of the fixed clock, for traps without timestamp.
+	Traps          []TestTrap      `yaml:"traps"`
+	ExpectedAlerts []ExpectedAlert `yaml:"expected_alerts,omitempty"`
+}
+
+// Represents a trap received from source.
+type TestTrap struct {
+	Source      string       `yaml:"source,omitempty"`
+	Hostname    string       `yaml:"hostname,omitempty"`
+	Timestamp   string       `yaml:"timestamp,omitempty"`
+	PduSecurity string       `yaml:"pdu_security,omitempty"`
+	Vars        []of.TrapVar `yaml:"vars"`
+}
+
+// Represents an alert expected to be generated.
+// Alerts may have labels and annotations not listed here.
+type ExpectedAlert struct {
+	Type        string            `yaml:"type,omitempty"` // firing or clearing, either if empty.
+	Labels      map[string]string `yaml:"labels,omitempty"`
+	Annotations map[string]string `yaml:"annotations,omitempty"`
+}
+
+// Represents the outcome of a test case.
+type TestResult struct {
+	File     string
+	Name     string
+	Failures []string // Readable differences between expected and generated alerts.
+	Duration time.Duration
+}
+
+// Runs unit tests of alert configs.
+type Tester interface {
+	Run(string, TestFile) []TestResult // Run tests of named file.
+}
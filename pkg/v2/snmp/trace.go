@@ -0,0 +1,16 @@
+package snmp
+
+import (
+	of "github.com/cisco-cx/of/pkg/v2"
+)
+
+// Receives decisions taken while generating alerts for an event, to explain them.
+type Tracer interface {
+	Event(*of.PostableEvent)                               // Event being evaluated.
+	Lookup([]string, error)                                // Configs found by lookup for the event.
+	Config(string, bool)                                   // Config name, and if it applies to the device.
+	Select(string, EventType, Select, string, bool, error) // Alert name, event type, select, resolved value and if it matched.
+	Mod(string, Mod, string, bool, error)                  // Labels or annotations, mod, resulting value and if it was applied.
+	Alert(of.Alert)                                        // Alert generated.
+	Held(of.Alert, string)                                 // Alert generated but not sent, and the reason.
+}
@@ -0,0 +1,31 @@
+package snmp
+
+import "fmt"
+
+type Severity string
+
+const (
+	// Severity constants
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Represents a problem found in a config file.
+type Diagnostic struct {
+	File     string
+	Line     int
+	Path     string // JSON pointer to the value, like /epc/alerts/0/name.
+	Severity Severity
+	Message  string
+}
+
+// Formats diagnostic as file:line: severity: message.
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s:%d: %s: %s", d.File, d.Line, d.Severity, d.Message)
+}
+
+// Validates config files, before they are loaded.
+type Validator interface {
+	ValidateDir(string) ([]Diagnostic, error) // Validate config files in dir.
+	ValidateFile(string, []byte) []Diagnostic // Validate content of named config file.
+}
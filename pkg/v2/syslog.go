@@ -0,0 +1,39 @@
+package v2
+
+import "time"
+
+// Represents the layout of syslog messages.
+type SyslogFormat string
+
+const (
+	// SyslogFormat constants
+	SyslogRFC5424 SyslogFormat = "rfc5424" // Labels and annotations as structured data.
+	SyslogRFC3164 SyslogFormat = "rfc3164" // Legacy BSD syslog, labels in the message.
+)
+
+// Represents how syslog messages are sent.
+type SyslogTransport string
+
+const (
+	// SyslogTransport constants
+	SyslogUDP SyslogTransport = "udp" // A message per datagram.
+	SyslogTCP SyslogTransport = "tcp" // Octet-counting framing (RFC 6587).
+	SyslogTLS SyslogTransport = "tls" // Octet-counting framing (RFC 5425).
+)
+
+// Represents settings of the syslog sink, sending a message for every alert.
+type SyslogConfig struct {
+	Address    string          `yaml:"address"`              // host:port of the collector, port 514, or 6514 for TLS, if not set.
+	Transport  SyslogTransport `yaml:"transport,omitempty"`  // Defaults to udp.
+	Format     SyslogFormat    `yaml:"format,omitempty"`     // Defaults to rfc5424.
+	Facility   string          `yaml:"facility,omitempty"`   // Name like daemon or local0, defaults to local0.
+	Hostname   string          `yaml:"hostname,omitempty"`   // Defaults to the host name.
+	AppName    string          `yaml:"app_name,omitempty"`   // Defaults to of.
+	Enterprise string          `yaml:"enterprise,omitempty"` // Private enterprise number of structured data IDs, defaults to 32473 reserved for documentation.
+	Timeout    time.Duration   `yaml:"timeout,omitempty"`    // Of connecting and writing, 0 for no timeout.
+
+	// TLS.
+	CertFile string `yaml:"cert_file,omitempty"` // Client certificate, with KeyFile.
+	KeyFile  string `yaml:"key_file,omitempty"`
+	CAFile   string `yaml:"ca_file,omitempty"` // CA bundle to verify collectors with, system roots if empty.
+}
@@ -0,0 +1,54 @@
+package v2
+
+import "time"
+
+// Represents the SNMP version notifications are sent with.
+type SNMPVersion string
+
+const (
+	// SNMPVersion constants
+	SNMPv2c SNMPVersion = "v2c"
+	SNMPv3  SNMPVersion = "v3"
+)
+
+// Represents the USM authentication protocol of SNMPv3 notifications.
+type SNMPAuthProtocol string
+
+const (
+	// SNMPAuthProtocol constants
+	SNMPNoAuth SNMPAuthProtocol = ""
+	SNMPMD5    SNMPAuthProtocol = "MD5" // HMAC-MD5-96
+	SNMPSHA    SNMPAuthProtocol = "SHA" // HMAC-SHA-96
+)
+
+// Represents the USM privacy protocol of SNMPv3 notifications.
+type SNMPPrivProtocol string
+
+const (
+	// SNMPPrivProtocol constants
+	SNMPNoPriv SNMPPrivProtocol = ""
+	SNMPDES    SNMPPrivProtocol = "DES" // CBC-DES
+	SNMPAES    SNMPPrivProtocol = "AES" // CFB128-AES-128
+)
+
+// Represents settings of the trap sink, sending alerts as SNMP notifications
+// in the layout of OF-ALERT-MIB to every target.
+type TrapConfig struct {
+	Targets   []string      `yaml:"targets"`             // host:port of managers, port 162 if not set.
+	Version   SNMPVersion   `yaml:"version,omitempty"`   // Defaults to v2c.
+	Community string        `yaml:"community,omitempty"` // Defaults to public, for v2c.
+	Inform    bool          `yaml:"inform,omitempty"`    // Send informs, acknowledged by managers, instead of traps.
+	Retries   int           `yaml:"retries,omitempty"`   // Sends after the first one, if it fails or an inform is not acknowledged.
+	Timeout   time.Duration `yaml:"timeout,omitempty"`   // Wait for inform acknowledgements.
+	Rate      float64       `yaml:"rate,omitempty"`      // Max. notifications per second to each target, 0 for no limit.
+	MIB       string        `yaml:"mib,omitempty"`       // OID OF-ALERT-MIB is registered at, the one of OF-ALERT-MIB.txt if empty.
+
+	// SNMPv3 user based security.
+	User               string           `yaml:"user,omitempty"`
+	AuthProtocol       SNMPAuthProtocol `yaml:"auth_protocol,omitempty"`
+	AuthPassphraseFile string           `yaml:"auth_passphrase_file,omitempty"`
+	PrivProtocol       SNMPPrivProtocol `yaml:"priv_protocol,omitempty"`
+	PrivPassphraseFile string           `yaml:"priv_passphrase_file,omitempty"`
+	EngineID           string           `yaml:"engine_id,omitempty"`         // Hex engine ID traps are sent from, generated if empty.
+	EngineBootsFile    string           `yaml:"engine_boots_file,omitempty"` // Keeps snmpEngineBoots of EngineID, incremented on every start. Required with EngineID.
+}
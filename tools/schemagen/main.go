@@ -0,0 +1,54 @@
+// Generates a Go file, holding a schema file as a string constant.
+// Usage: schemagen <schema file> <output file> <package> <const name>
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+func main() {
+	if len(os.Args) != 5 {
+		usage()
+	}
+
+	inputFile := os.Args[1]
+	outputFile := os.Args[2]
+	pkg := os.Args[3]
+	name := os.Args[4]
+
+	schema, err := ioutil.ReadFile(inputFile)
+	if err != nil {
+		fmt.Printf("Failed to read schema file, %s\n", err.Error())
+		os.Exit(1)
+	}
+	if bytes.Contains(schema, []byte("`")) {
+		fmt.Printf("Schema file %s can't hold a backquote.\n", inputFile)
+		os.Exit(1)
+	}
+
+	b := &bytes.Buffer{}
+	fmt.Fprintf(b, "// Code generated by schemagen from %s. DO NOT EDIT.\n\n", inputFile)
+	fmt.Fprintf(b, "package %s\n\n", pkg)
+	fmt.Fprintf(b, "// JSON schema from %s.\n", inputFile)
+	fmt.Fprintf(b, "const %s = `%s`\n", name, schema)
+
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		fmt.Printf("Failed to format generated code, %s\n", err.Error())
+		os.Exit(1)
+	}
+	if err = ioutil.WriteFile(outputFile, src, 0644); err != nil {
+		fmt.Printf("Failed to write output file, %s\n", err.Error())
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Printf("Usage: %s <schema file> <output file> <package> <const name>\n", filepath.Base(os.Args[0]))
+	os.Exit(1)
+}
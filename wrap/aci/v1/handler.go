@@ -17,6 +17,7 @@ package v1
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 	"time"
@@ -28,8 +29,11 @@ import (
 	alertmanager "github.com/cisco-cx/of/wrap/alertmanager/v1"
 	http "github.com/cisco-cx/of/wrap/http/v2"
 	logger "github.com/cisco-cx/of/wrap/logrus/v1"
+	maintenance "github.com/cisco-cx/of/wrap/maintenance/v2"
 	net "github.com/cisco-cx/of/wrap/net/v1"
 	prometheus "github.com/cisco-cx/of/wrap/prometheus/client_golang/v2"
+	route "github.com/cisco-cx/of/wrap/route/v2"
+	sink "github.com/cisco-cx/of/wrap/sink/v2"
 	yaml "github.com/cisco-cx/of/wrap/yaml/v1"
 	consul "github.com/hashicorp/consul/api"
 	"github.com/mitchellh/mapstructure"
@@ -57,6 +61,9 @@ const (
 	faultsUnknownIgnored    = "faults_unknown_ignored_count"
 	notificationCycleCount  = "notification_cycle_count"
 	nodeEnriched            = "nodes_enriched_count"
+	alertsMutedCount        = "alerts_muted_count"
+	amPostsCount            = "am_posts_count"
+	sinkNotificationsCount  = "sink_notifications_count"
 )
 
 type Handler struct {
@@ -69,36 +76,19 @@ type Handler struct {
 	ac          *yaml.Alerts
 	sc          *yaml.Secrets
 	Log         *logger.Logger
+	Maintenance *maintenance.Schedule
+	Audit       of_v2.Auditor      // Records alert decisions, if set.
+	Limiter     of_v2.BatchLimiter // Spaces posts to Alertmanager, all alerts are posted at once if nil.
+	done        chan struct{}
+	pushed      chan struct{} // Closed when push returns.
 }
 
 func (h *Handler) Run() {
 
 	h.InitHandler()
 
-	go func() {
-		nodes := h.Config.ACIHosts
-		for {
-			if h.Config.ConsulEnabled {
-				nodes = h.GetConsulNodes()
-				h.Log.Infof("ACI nodes received from consul: %s\n", nodes)
-			}
-			if len(nodes) <= 0 {
-				h.Log.Warningf("Empty host list.\n")
-			}
-			startTime := time.Now()
-			for i := 0; i < len(nodes); i++ {
-				h.Log.Debugf("Fetching errors from %s\n", nodes[i])
-				h.Aci.SetHost(nodes[i])
-				h.PushAlerts()
-			}
-			elapsedTime := time.Duration(time.Since(startTime).Seconds())
-			sleepTime := time.Duration(h.Config.CycleInterval) - elapsedTime
-			if sleepTime > 0 {
-				h.Log.Debugf("Sleeping for %d seconds.\n", sleepTime)
-				time.Sleep(sleepTime * time.Second)
-			}
-		}
-	}()
+	h.done, h.pushed = make(chan struct{}), make(chan struct{})
+	go h.push(h.done, h.pushed)
 
 	httpConfig := of_v2.HTTPConfig{
 		ListenAddress: h.Config.ListenAddress,
@@ -111,6 +101,11 @@ func (h *Handler) Run() {
 		fmt.Fprint(w, h.Config.Version)
 	})
 
+	// Listing maintenance windows.
+	if h.Maintenance != nil {
+		h.server.HandleFunc("/api/v2/maintenance", h.Maintenance.Handler)
+	}
+
 	err := h.server.ListenAndServe()
 	if err != nil {
 		h.Log.WithError(err).Fatalf("Failed to listen at %s", h.Config.ListenAddress)
@@ -119,6 +114,44 @@ func (h *Handler) Run() {
 	<-make(chan bool)
 }
 
+// Push alerts of faults of ACI nodes every cycle, until done is closed.
+// Closes pushed on return.
+func (h *Handler) push(done chan struct{}, pushed chan struct{}) {
+	defer close(pushed)
+	nodes := h.Config.ACIHosts
+	for {
+		if h.Config.ConsulEnabled {
+			nodes = h.GetConsulNodes()
+			h.Log.Infof("ACI nodes received from consul: %s\n", nodes)
+		}
+		if len(nodes) <= 0 {
+			h.Log.Warningf("Empty host list.\n")
+		}
+		startTime := time.Now()
+		for i := 0; i < len(nodes); i++ {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			h.Log.Debugf("Fetching errors from %s\n", nodes[i])
+			h.Aci.SetHost(nodes[i])
+			h.PushAlerts()
+		}
+		elapsedTime := time.Duration(time.Since(startTime).Seconds())
+		sleepTime := time.Duration(h.Config.CycleInterval) - elapsedTime
+		if sleepTime < 0 {
+			sleepTime = 0
+		}
+		h.Log.Debugf("Sleeping for %d seconds.\n", sleepTime)
+		select {
+		case <-time.After(sleepTime * time.Second):
+		case <-done:
+			return
+		}
+	}
+}
+
 func (h *Handler) InitHandler() {
 
 	h.ac = &yaml.Alerts{}
@@ -153,6 +186,8 @@ func (h *Handler) InitHandler() {
 			Help: "Number of times we could not find an alertConfig that mentioned the encountered fault code and the fault was ignored."},
 		notificationCycleCount: &prometheus.Counter{Namespace: h.Config.Application, Name: notificationCycleCount,
 			Help: "Number of times we tried ran the notification cycle loop."},
+		alertsMutedCount: &prometheus.Counter{Namespace: h.Config.Application, Name: alertsMutedCount,
+			Help: "Number of times we dropped or tagged an alert during maintenance."},
 	}
 
 	for name, c := range h.counters {
@@ -178,6 +213,22 @@ func (h *Handler) InitHandler() {
 			},
 			labels: []string{"fault_dn", "node_dn", "enriched", "node", "role"},
 		},
+		vectorInfo{
+			vector: &prometheus.CounterVec{
+				Namespace: h.Config.Application,
+				Name:      amPostsCount,
+				Help:      "Number of times alerts were posted to an Alertmanager peer, by result.",
+			},
+			labels: []string{"peer", "result"},
+		},
+		vectorInfo{
+			vector: &prometheus.CounterVec{
+				Namespace: h.Config.Application,
+				Name:      sinkNotificationsCount,
+				Help:      "Number of times alerts were sent to a sink, by result.",
+			},
+			labels: []string{"sink", "result"},
+		},
 	}
 
 	h.counterVecs = make(map[string]*prometheus.CounterVec)
@@ -188,6 +239,20 @@ func (h *Handler) InitHandler() {
 		}
 		h.counterVecs[vi.vector.Name] = vi.vector
 	}
+	if h.Ams != nil {
+		h.Ams.Posts = h.counterVecs[amPostsCount]
+		sinks := h.Ams.Sinks
+		if router, ok := sinks.(*route.Router); ok == true {
+			sinks = router.Default
+			// Reload receivers of routes, to count their posts.
+			if err := router.Load(); err != nil {
+				h.Log.WithError(err).Errorf("Failed to reload routes.")
+			}
+		}
+		if composite, ok := sinks.(*sink.Composite); ok == true {
+			composite.Posts = h.counterVecs[sinkNotificationsCount]
+		}
+	}
 }
 
 // GetConsulNodes Lists the nodes from consul, matching given service and node metadata
@@ -261,15 +326,16 @@ func (h *Handler) PushAlerts() {
 	// Notify AlertManager. if we have any alerts
 	if len(alerts) > 0 {
 		// Send alerts[start:end] to Alertmanager.
-		sendFunc := func(start int, end int) {
+		sendFunc := func(start int, end int) error {
 			h.Log.Debugf("Sending alerts from %d to %d\n", start, end)
 			h.counters[amConnectAttemptCount].Incr()
-			err = h.Ams.Notify(alerts[start:end])
+			err := h.Ams.Notify(alerts[start:end])
+			h.auditPosted(alerts[start:end], err)
 			if err != nil {
 				h.counters[amConnectErrorCount].Incr()
 				h.Log.Errorf("Notification cycle failed. Will retry in %d, %s\n", h.Config.CycleInterval, err.Error())
-				return
 			}
+			return err
 		}
 
 		h.Throttle(len(alerts), sendFunc)
@@ -278,39 +344,14 @@ func (h *Handler) PushAlerts() {
 	}
 }
 
-// Divide alerts into smaller chunks and spread posting to Alertmanager over h.Config.SendTime milliseconds.
-func (h *Handler) Throttle(totalCount int, f func(int, int)) {
-
-	// Send all alerts in a single post to Alertmanager, if Throttle is disabled
-	// or h.Config.SendTime is less than time needed for a single post.
-	if h.Config.Throttle == false || h.Config.SendTime <= h.Config.PostTime+h.Config.SleepTime {
-		f(0, totalCount)
+// Send alerts [0:totalCount) with f, in batches spaced by h.Limiter.
+func (h *Handler) Throttle(totalCount int, f func(int, int) error) {
+	if h.Limiter == nil {
 		h.Log.Infof("Throttle disabled, sending all alerts")
+		f(0, totalCount)
 		return
 	}
-
-	// Max num. of requests that can be send in h.Config.SendTime.
-	maxRequests := h.Config.SendTime / (h.Config.PostTime + h.Config.SleepTime)
-	start := 0
-	if totalCount > maxRequests {
-		chunkSize := totalCount / maxRequests
-
-		end := chunkSize
-		for end <= totalCount {
-			f(start, end)
-			start = end
-			end = start + chunkSize
-			h.Log.Infof("Throttling alerts, sleeping for %d seconds", h.Config.SleepTime)
-			time.Sleep(time.Duration(h.Config.SleepTime) * time.Millisecond)
-		}
-	}
-
-	// Handle condition where totalCount is not divisible by maxRequests.
-	if start < totalCount {
-		f(start, totalCount)
-		h.Log.Infof("Throttling alerts, sleeping for %d seconds", h.Config.SleepTime)
-		time.Sleep(time.Duration(h.Config.SleepTime) * time.Millisecond)
-	}
+	h.Limiter.Do(totalCount, f)
 }
 
 // Convert acigo faults to Alertmanager alerts.
@@ -335,6 +376,7 @@ func (h *Handler) FaultsToAlerts(faults []of.Map, nodes map[string]map[string]in
 		if _, drop := h.ac.DroppedFaults[strings.ToUpper(fp.Fault.Code)]; drop {
 			h.Log.Debugf("Dropping fault: %s\n", f)
 			h.counters[faultsDroppedCount].Incr()
+			h.audit(of_v2.AuditRecord{EventID: f.DN, Source: h.Aci.GetHost(), Alert: f.Rule, Action: of_v2.AuditDropped, Reason: "fault code in dropped_faults"})
 			continue
 		}
 
@@ -387,6 +429,7 @@ func (h *Handler) FaultsToAlerts(faults []of.Map, nodes map[string]map[string]in
 			// the alert wasn't found and we are ignoring unknown alerts
 			h.Log.Debugf("Ignoring unknown fault code=%s, rule=%s\n", f.Code, f.Rule)
 			h.counters[faultsUnknownIgnored].Incr()
+			h.audit(of_v2.AuditRecord{EventID: f.DN, Source: h.Aci.GetHost(), Alert: f.Rule, Action: of_v2.AuditDropped, Reason: "unknown fault code"})
 			continue
 		} else {
 			h.counters[faultsUnmatchedCount].Incr()
@@ -422,6 +465,12 @@ func (h *Handler) FaultsToAlerts(faults []of.Map, nodes map[string]map[string]in
 			h.EnrichTopology(alert, nodes)
 		}
 
+		// Drop or tag firing alerts during maintenance.
+		if alert.EndsAt.IsZero() && h.inMaintenance(alert) {
+			h.audit(h.auditRecord(alert, of_v2.AuditSuppressed, "during maintenance"))
+			continue
+		}
+
 		alert.Labels[amAlertFingerprintLabel] = of.LabelValue(alert.Fingerprint())
 
 		// Debug sample code.
@@ -443,6 +492,81 @@ func (h *Handler) FaultsToAlerts(faults []of.Map, nodes map[string]map[string]in
 
 }
 
+// Write records of alert decisions, if auditing.
+func (h *Handler) audit(records ...of_v2.AuditRecord) {
+	if h.Audit == nil {
+		return
+	}
+	if err := h.Audit.Audit(records); err != nil {
+		h.Log.WithError(err).Errorf("Failed to write %d audit records.", len(records))
+	}
+}
+
+// Record decision taken for alert.
+func (h *Handler) auditRecord(alert *alertmanager.Alert, action of_v2.AuditAction, reason string) of_v2.AuditRecord {
+	return of_v2.AuditRecord{
+		EventID:     alert.Annotations["fault_dn"],
+		Source:      alert.Annotations["source_address"],
+		Alert:       string(alert.Labels["alertname"]),
+		Fingerprint: string(alert.Labels[amAlertFingerprintLabel]),
+		Action:      action,
+		Reason:      reason,
+	}
+}
+
+// Audit alerts generated, with the result of posting them.
+func (h *Handler) auditPosted(alerts []*alertmanager.Alert, postErr error) {
+	if h.Audit == nil {
+		return
+	}
+	records := make([]of_v2.AuditRecord, len(alerts))
+	for i, alert := range alerts {
+		records[i] = h.auditRecord(alert, of_v2.AuditGenerated, "fault "+alert.Annotations["fault_code"])
+		records[i].PostResult = of_v2.AuditPostSent
+		if h.Ams != nil && h.Ams.Queue != nil {
+			records[i].PostResult = of_v2.AuditPostQueued
+		}
+		if postErr != nil {
+			records[i].PostResult = of_v2.AuditPostFailed
+			records[i].PostError = postErr.Error()
+		}
+	}
+	h.audit(records...)
+}
+
+// Check if alert is in an active maintenance window. Tags the alert, if window action is tag.
+func (h *Handler) inMaintenance(alert *alertmanager.Alert) bool {
+	if h.Maintenance == nil {
+		return false
+	}
+
+	labels := make(map[string]string, len(alert.Labels))
+	for k, v := range alert.Labels {
+		labels[string(k)] = string(v)
+	}
+	target := of_v2.MaintenanceTarget{
+		Address:    alert.Annotations["source_address"],
+		ACICluster: h.sc.APIC.Cluster.Name,
+		Labels:     labels,
+	}
+	w, ok := h.Maintenance.Window(target, time.Now().UTC())
+	if ok == false {
+		return false
+	}
+
+	h.counters[alertsMutedCount].Incr()
+	h.Log.WithField("Alert name", alert.Labels["alertname"]).
+		WithField("Window", w.Name).
+		WithField("Action", w.Action).
+		Debugf("Alert in maintenance.")
+	if w.Action == of_v2.MaintenanceTag {
+		// Tag is an annotation, so resolved alert keeps matching labels.
+		alert.Annotations[of_v2.MaintenanceText] = w.Name
+		return false
+	}
+	return true
+}
+
 // Do a forward and reverse lookup to verify the ACI Host.
 // If DNS entry is found, Hostname and IP from DNS Query is returned
 // else aciHost is returned
@@ -561,6 +685,31 @@ func (h *Handler) GetAlertConfig(fault of.ACIFaultRaw) (string, *aci_config.Aler
 	return "", &aci_config.AlertConfig{}, err
 }
 
+// Stop the server and pushing alerts, waiting for requests in flight and the running push,
+// before closing the audit log, queue and sinks they write to.
 func (h *Handler) Shutdown() error {
-	return h.server.Shutdown()
+	err := h.server.Shutdown()
+	if h.done != nil {
+		close(h.done)
+		<-h.pushed
+		h.done = nil
+	}
+	if h.Audit != nil {
+		if err := h.Audit.Close(); err != nil {
+			h.Log.WithError(err).Errorf("Failed to close audit log.")
+		}
+	}
+	if h.Ams != nil && h.Ams.Queue != nil {
+		if err := h.Ams.Queue.Close(); err != nil {
+			h.Log.WithError(err).Errorf("Failed to close alert queue.")
+		}
+	}
+	if h.Ams != nil {
+		if c, ok := h.Ams.Sinks.(io.Closer); ok == true {
+			if err := c.Close(); err != nil {
+				h.Log.WithError(err).Errorf("Failed to close alert sinks.")
+			}
+		}
+	}
+	return err
 }
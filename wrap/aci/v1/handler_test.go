@@ -5,13 +5,19 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"os"
 	"strings"
 	"testing"
 
 	of "github.com/cisco-cx/of/pkg/v1"
+	of_v2 "github.com/cisco-cx/of/pkg/v2"
 	aci "github.com/cisco-cx/of/wrap/aci/v1"
 	logger "github.com/cisco-cx/of/wrap/logrus/v1"
+	logger_v2 "github.com/cisco-cx/of/wrap/logrus/v2"
+	maintenance "github.com/cisco-cx/of/wrap/maintenance/v2"
 	mapstructure "github.com/cisco-cx/of/wrap/mapstructure/v1"
+	ratelimit "github.com/cisco-cx/of/wrap/ratelimit/v2"
+	clock "github.com/cisco-cx/of/wrap/time/v2"
 	"github.com/stretchr/testify/require"
 )
 
@@ -60,23 +66,102 @@ func TestIdentifyNodes(t *testing.T) {
 	require.Equal(t, "94da3cdef371267fe04525b6889c94a9", md5sum)
 }
 
+// Represents of_v2.Auditor, keeping records in memory.
+type testAuditor struct {
+	records []of_v2.AuditRecord
+}
+
+func (a *testAuditor) Audit(records []of_v2.AuditRecord) error {
+	a.records = append(a.records, records...)
+	return nil
+}
+
+func (a *testAuditor) Close() error {
+	return nil
+}
+
+// Test FaultToAlerts during maintenance.
+func TestFaultToAlertsMaintenance(t *testing.T) {
+	tmpFile, err := ioutil.TempFile("", "maintenance.*.yaml")
+	require.NoError(t, err)
+	defer os.Remove(tmpFile.Name())
+
+	c := &of.ACIConfig{}
+	c.Application = t.Name()
+	c.AlertsCFGFile = "test/alerts.yaml"
+	c.SecretsCFGFile = "test/secrets.yaml"
+	handler := &aci.Handler{Config: c, Log: logger.New()}
+	handler.InitHandler()
+
+	window := "windows:\n- name: lab\n  start: 2020-01-01T00:00:00Z\n  end: 2100-01-01T00:00:00Z\n  action: %s\n  scope:\n    aci_clusters: [%s]\n"
+
+	// Other cluster in maintenance.
+	require.NoError(t, ioutil.WriteFile(tmpFile.Name(), []byte(fmt.Sprintf(window, "drop", "other-aci")), 0644))
+	handler.Maintenance, err = maintenance.NewSchedule(tmpFile.Name(), logger_v2.New())
+	require.NoError(t, err)
+	alerts, err := handler.FaultsToAlerts(getFaults(t), getNodes(t))
+	require.NoError(t, err)
+	require.NotEmpty(t, alerts)
+	count := len(alerts)
+
+	// Firing alerts are dropped, and audited as suppressed.
+	audit := &testAuditor{}
+	handler.Audit = audit
+	require.NoError(t, ioutil.WriteFile(tmpFile.Name(), []byte(fmt.Sprintf(window, "drop", "lab-aci")), 0644))
+	require.NoError(t, handler.Maintenance.Load())
+	alerts, err = handler.FaultsToAlerts(getFaults(t), getNodes(t))
+	require.NoError(t, err)
+	require.True(t, len(alerts) < count)
+	for _, alert := range alerts {
+		require.False(t, alert.EndsAt.IsZero())
+	}
+	require.Len(t, audit.records, count-len(alerts))
+	for _, record := range audit.records {
+		require.Equal(t, of_v2.AuditSuppressed, record.Action)
+		require.Equal(t, "during maintenance", record.Reason)
+		require.Contains(t, record.EventID, "fault-")
+		require.NotEmpty(t, record.Alert)
+	}
+	handler.Audit = nil
+
+	// Firing alerts are tagged.
+	require.NoError(t, ioutil.WriteFile(tmpFile.Name(), []byte(fmt.Sprintf(window, "tag", "lab-aci")), 0644))
+	require.NoError(t, handler.Maintenance.Load())
+	alerts, err = handler.FaultsToAlerts(getFaults(t), getNodes(t))
+	require.NoError(t, err)
+	require.Len(t, alerts, count)
+	for _, alert := range alerts {
+		if alert.EndsAt.IsZero() {
+			require.Equal(t, "lab", alert.Annotations["maintenance"])
+			require.NotContains(t, alert.Labels, "maintenance")
+		}
+	}
+}
+
 //Test Throttle
 func TestThrottle(t *testing.T) {
 	c := &of.ACIConfig{}
 	c.Throttle = true
-	c.PostTime = 300
-	c.SleepTime = 100
-	c.SendTime = 30000
+	c.AmLimit = of_v2.BatchLimitConfig{MaxBatch: 200}
 
 	count := 0
-	f := func(start int, end int) {
+	f := func(start int, end int) error {
 		count += 1
 		fmt.Printf("start : %d, end : %d, count : %d\n", start, end, count)
+		return nil
 	}
-	handler := &aci.Handler{Config: c, Log: logger.New()}
+	limiter, err := ratelimit.NewLimiter(&c.AmLimit, t.Name(), &clock.Clock{}, logger_v2.New())
+	require.NoError(t, err)
+	handler := &aci.Handler{Config: c, Log: logger.New(), Limiter: limiter}
 	handler.Throttle(15000, f)
 	fmt.Printf("count : %d\n", count)
 	require.Equal(t, 75, count)
+
+	// All alerts are sent at once without limiter.
+	count = 0
+	handler.Limiter = nil
+	handler.Throttle(15000, f)
+	require.Equal(t, 1, count)
 }
 
 // Returns faults in faultJson file.
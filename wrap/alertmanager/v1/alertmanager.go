@@ -1,52 +1,81 @@
 package v1
 
 import (
-	"bytes"
-	"encoding/json"
-	"fmt"
-	"io/ioutil"
+	nethttp "net/http"
 
+	of_v2 "github.com/cisco-cx/of/pkg/v2"
+	am_v2 "github.com/cisco-cx/of/wrap/alertmanager/v2"
 	http "github.com/cisco-cx/of/wrap/http/v1"
+	prometheus "github.com/cisco-cx/of/wrap/prometheus/client_golang/v2"
 )
 
 type AlertService struct {
-	Version string
-	AmURL   string
+	Version    string
+	AmURL      string
+	Peers      []string               // Alertmanager peers alerts are posted to, AmURL if empty.
+	Policy     of_v2.AMSuccessPolicy  // When posting to Peers succeeds, all if empty.
+	APIVersion of_v2.AMAPIVersion     // Empty or auto to detect on first post to a peer.
+	Posts      *prometheus.CounterVec // Counts posts by peer and result, if set.
+	Client     *nethttp.Client        // Posts alerts, a client without auth, TLS or timeout if nil.
+	Queue      of_v2.AlertQueue       // Alerts are written to it for delivery with Post, if set.
+	Sinks      of_v2.Notifier         // Alerts are sent to it instead of Deliver, if set. Usually a sink.Composite including Deliver.
+
+	versions am_v2.APIVersions
 }
 
-// Send alerts to Alertmanager
+// Send alerts to Sinks if set, otherwise deliver them to Alertmanager.
 func (a *AlertService) Notify(alerts []*Alert) error {
-	amAlertsURL := fmt.Sprintf("%s/api/v1/alerts", a.AmURL)
-
-	b := []byte{}
-	b, err := json.Marshal(alerts)
-	if err != nil {
-		return err
+	v2Alerts := toV2(alerts)
+	if a.Sinks != nil {
+		return a.Sinks.Notify(&v2Alerts)
 	}
+	return a.Deliver(&v2Alerts)
+}
 
-	req, err := http.NewRequest("POST", amAlertsURL, bytes.NewBuffer(b))
-	if err != nil {
-		return err
+// Send alerts to every Alertmanager peer, or to Queue if set.
+// Implements of_v2.Notifier through of_v2.NotifierFunc, as alertmanager sink.
+func (a *AlertService) Deliver(alerts *[]of_v2.Alert) error {
+	if a.Queue != nil {
+		return a.Queue.Notify(alerts)
 	}
+	return a.Post(*alerts)
+}
 
-	req.Header.Set("User-Agent", a.Version)
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := http.NewClient().Do(req)
-	if err != nil {
-		return err
+// Post alerts to every Alertmanager peer.
+func (a *AlertService) Post(alerts []of_v2.Alert) error {
+	peers := a.Peers
+	if len(peers) == 0 {
+		peers = []string{a.AmURL}
 	}
-
-	body, err := ioutil.ReadAll(resp.Body)
-	defer resp.Body.Close()
-	if err != nil {
-		return err
+	c := a.Client
+	if c == nil {
+		c = http.NewClient()
 	}
+	postable := am_v2.PostableAlerts(alerts)
+	return am_v2.FanOut(peers, a.Policy, a.Posts, func(peer string) error {
+		version, err := a.versions.Get(c, a.APIVersion, peer)
+		if err != nil {
+			return err
+		}
+		return am_v2.PostAlerts(c, peer, version, a.Version, postable)
+	})
+}
 
-	if resp.StatusCode/100 != 2 {
-		err := fmt.Errorf("POST to AlertManager on %q returned HTTP %d:  %s", amAlertsURL, resp.StatusCode, body)
-		return err
+// Convert alerts to of_v2.Alert.
+func toV2(alerts []*Alert) []of_v2.Alert {
+	v2Alerts := make([]of_v2.Alert, len(alerts))
+	for i, alert := range alerts {
+		labels := make(map[string]string, len(alert.Labels))
+		for k, v := range alert.Labels {
+			labels[string(k)] = string(v)
+		}
+		v2Alerts[i] = of_v2.Alert{
+			Labels:       labels,
+			Annotations:  alert.Annotations,
+			StartsAt:     alert.StartsAt,
+			EndsAt:       alert.EndsAt,
+			GeneratorURL: alert.GeneratorURL,
+		}
 	}
-
-	return nil
+	return v2Alerts
 }
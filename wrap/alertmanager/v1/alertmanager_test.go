@@ -0,0 +1,32 @@
+package v1_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	of "github.com/cisco-cx/of/pkg/v1"
+	of_v2 "github.com/cisco-cx/of/pkg/v2"
+	alertmanager "github.com/cisco-cx/of/wrap/alertmanager/v1"
+	"github.com/stretchr/testify/require"
+)
+
+// Test alerts are posted to the configured API version.
+func TestNotify(t *testing.T) {
+	var paths []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		var posted []of_v2.PostableAlert
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&posted))
+		require.Equal(t, []of_v2.PostableAlert{{Labels: map[string]string{"alertname": "a"}}}, posted)
+	}))
+	defer ts.Close()
+
+	alerts := []*alertmanager.Alert{{Labels: of.LabelMap{"alertname": "a"}}}
+	for _, version := range []of_v2.AMAPIVersion{of_v2.AMAPIV1, of_v2.AMAPIV2} {
+		as := alertmanager.AlertService{AmURL: ts.URL, APIVersion: version}
+		require.NoError(t, as.Notify(alerts))
+	}
+	require.Equal(t, []string{"/api/v1/alerts", "/api/v2/alerts"}, paths)
+}
@@ -1,114 +1,84 @@
 package v2
 
 import (
-	"bytes"
-	"encoding/json"
-	"fmt"
-	"io/ioutil"
-	"time"
+	nethttp "net/http"
 
 	of "github.com/cisco-cx/of/pkg/v2"
 	http "github.com/cisco-cx/of/wrap/http/v2"
 	logger "github.com/cisco-cx/of/wrap/logrus/v2"
+	prometheus "github.com/cisco-cx/of/wrap/prometheus/client_golang/v2"
+	sink "github.com/cisco-cx/of/wrap/sink/v2"
 )
 
 type AlertService struct {
-	Version   string
-	AmURL     string
-	Throttle  bool
-	PostTime  int
-	SleepTime int
-	SendTime  int
-	Log       *logger.Logger
-	DryRun    bool
+	Version    string
+	AmURL      string
+	Peers      []string               // Alertmanager peers alerts are posted to, AmURL if empty.
+	Policy     of.AMSuccessPolicy     // When posting to Peers succeeds, all if empty.
+	APIVersion of.AMAPIVersion        // Empty or auto to detect on first post to a peer.
+	Posts      *prometheus.CounterVec // Counts posts by peer and result, if set.
+	Client     *nethttp.Client        // Posts alerts, a client without auth, TLS or timeout if nil.
+	Limiter    of.BatchLimiter        // Spaces posts, all alerts are posted at once if nil.
+	Log        *logger.Logger
+	DryRun     bool // Deprecated: alerts are only logged, use the log sink of package sink instead.
+
+	versions APIVersions
 }
 
-// Send alerts to Alertmanager
-func (a *AlertService) notify(alerts []of.Alert) error {
-	amAlertsURL := fmt.Sprintf("%s/api/v1/alerts", a.AmURL)
-
-	b := []byte{}
-	b, err := json.Marshal(alerts)
-	if err != nil {
-		return err
+// Peers alerts are posted to.
+func (a *AlertService) peers() []string {
+	if len(a.Peers) == 0 {
+		return []string{a.AmURL}
 	}
+	return a.Peers
+}
 
-	req, err := http.NewRequest("POST", amAlertsURL, bytes.NewBuffer(b))
-	if err != nil {
-		return err
+// Client alerts are posted with.
+func (a *AlertService) client() *nethttp.Client {
+	if a.Client == nil {
+		return http.NewClient()
 	}
+	return a.Client
+}
 
-	req.Header.Set("User-Agent", a.Version)
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := http.NewClient().Do(req)
-	if err != nil {
+// Send alerts to every Alertmanager peer.
+func (a *AlertService) notify(alerts []of.Alert) error {
+	postable := PostableAlerts(alerts)
+	c := a.client()
+	err := FanOut(a.peers(), a.Policy, a.Posts, func(peer string) error {
+		version, err := a.versions.Get(c, a.APIVersion, peer)
+		if err != nil {
+			return err
+		}
+		if err = PostAlerts(c, peer, version, a.Version, postable); err != nil {
+			a.Log.WithError(err).Errorf("Failed to post alerts to %s.", peer)
+		}
 		return err
-	}
-
-	body, err := ioutil.ReadAll(resp.Body)
-	defer resp.Body.Close()
+	})
 	if err != nil {
-		return err
-	}
-
-	if resp.StatusCode/100 != 2 {
-		err := fmt.Errorf("POST to AlertManager on %q returned HTTP %d:  %s", amAlertsURL, resp.StatusCode, body)
 		a.Log.WithError(err).Errorf("")
-		return err
 	}
-
-	return nil
+	return err
 }
 
-// Divide alerts into smaller chunks and spread posting to Alertmanager over a.SendTime milliseconds.
+// Post alerts to Alertmanager, in batches spaced by Limiter.
+// Returns the last error, if posting any batch failed.
 func (a *AlertService) Notify(alerts *[]of.Alert) error {
 
 	if a.DryRun == true {
-		for _, alert := range *alerts {
-			a.Log.WithFields(map[string]interface{}{
-				"labels":       alert.Labels,
-				"annotations":  alert.Annotations,
-				"startsAt":     alert.StartsAt,
-				"endsAt":       alert.EndsAt,
-				"generatorURL": alert.GeneratorURL,
-			}).Infof("Dry run.")
-		}
-		return nil
+		dryRun := sink.Log{Log: a.Log, DryRun: true}
+		return dryRun.Notify(alerts)
 	}
 
-	totalCount := len(*alerts)
-	// Send all alerts in a single post to Alertmanager, if Throttle is disabled
-	// or a.SendTime is less than time needed for a single post.
-	if a.Throttle == false || a.SendTime <= a.PostTime+a.SleepTime {
-		return a.notify((*alerts)[0:totalCount])
+	// Send all alerts in a single post to Alertmanager, if no limiter is set.
+	if a.Limiter == nil {
+		return a.notify(*alerts)
 	}
-
-	// Max num. of requests that can be send in a.SendTime.
-	maxRequests := a.SendTime / (a.PostTime + a.SleepTime)
-	start := 0
-	if totalCount > maxRequests {
-		chunkSize := totalCount / maxRequests
-
-		end := chunkSize
-		for end <= totalCount {
-			err := a.notify((*alerts)[start:end])
-			if err != nil {
-				a.Log.WithError(err).Errorf("Failed to send alerts chunk[%d:%d]", start, end)
-			}
-			start = end
-			end = start + chunkSize
-			time.Sleep(time.Duration(a.SleepTime) * time.Millisecond)
-		}
-	}
-
-	// Handle condition where totalCount is not divisible by maxRequests.
-	if start < totalCount {
-		err := a.notify((*alerts)[start:totalCount])
+	return a.Limiter.Do(len(*alerts), func(start int, end int) error {
+		err := a.notify((*alerts)[start:end])
 		if err != nil {
-			a.Log.WithError(err).Errorf("Failed to send alerts chunk[%d:%d]", start, totalCount)
+			a.Log.WithError(err).Errorf("Failed to send alerts chunk[%d:%d]", start, end)
 		}
-		time.Sleep(time.Duration(a.SleepTime) * time.Millisecond)
-	}
-	return nil
+		return err
+	})
 }
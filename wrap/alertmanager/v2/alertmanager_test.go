@@ -2,6 +2,8 @@ package v2_test
 
 import (
 	"bytes"
+	nethttp "net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
@@ -9,6 +11,8 @@ import (
 	am "github.com/cisco-cx/of/wrap/alertmanager/v2"
 	http "github.com/cisco-cx/of/wrap/http/v2"
 	logger "github.com/cisco-cx/of/wrap/logrus/v2"
+	ratelimit "github.com/cisco-cx/of/wrap/ratelimit/v2"
+	clock "github.com/cisco-cx/of/wrap/time/v2"
 	"github.com/stretchr/testify/require"
 )
 
@@ -57,3 +61,24 @@ func TestDryRun(t *testing.T) {
 	require.Error(t, err)
 
 }
+
+// Test alerts are posted in batches of the limiter.
+func TestNotifyLimiter(t *testing.T) {
+	var posts int
+	ts := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		posts++
+	}))
+	defer ts.Close()
+
+	limiter, err := ratelimit.NewLimiter(&of.BatchLimitConfig{MaxBatch: 2}, t.Name(), &clock.Clock{}, logger.New())
+	require.NoError(t, err)
+	as := am.AlertService{
+		AmURL:      ts.URL,
+		APIVersion: of.AMAPIV2,
+		Limiter:    limiter,
+		Log:        logger.New(),
+	}
+	alerts := make([]of.Alert, 5)
+	require.NoError(t, as.Notify(&alerts))
+	require.Equal(t, 3, posts)
+}
@@ -0,0 +1,126 @@
+package v2
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	nethttp "net/http"
+	"strings"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+	http "github.com/cisco-cx/of/wrap/http/v2"
+)
+
+// Parse API version, empty is auto.
+func ParseAPIVersion(version string) (of.AMAPIVersion, error) {
+	switch v := of.AMAPIVersion(strings.ToLower(version)); v {
+	case "":
+		return of.AMAPIAuto, nil
+	case of.AMAPIAuto, of.AMAPIV1, of.AMAPIV2:
+		return v, nil
+	}
+	return "", fmt.Errorf("%s %q, expected auto, v1 or v2", of.ErrUnknownAMAPIVersion, version)
+}
+
+// URL to post alerts to, for API version.
+func AlertsURL(amURL string, version of.AMAPIVersion) string {
+	if version == of.AMAPIV1 {
+		return strings.TrimSuffix(amURL, "/") + "/api/v1/alerts"
+	}
+	return strings.TrimSuffix(amURL, "/") + "/api/v2/alerts"
+}
+
+// Detect API version through /api/v2/status, served since Alertmanager 0.16.
+// Returns v1 if it is not found.
+func DetectAPIVersion(c *nethttp.Client, amURL string) (of.AMAPIVersion, error) {
+	resp, err := c.Get(strings.TrimSuffix(amURL, "/") + "/api/v2/status")
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	ioutil.ReadAll(resp.Body)
+
+	switch {
+	case resp.StatusCode/100 == 2:
+		return of.AMAPIV2, nil
+	case resp.StatusCode == nethttp.StatusNotFound:
+		return of.AMAPIV1, nil
+	}
+	return "", fmt.Errorf("Detecting Alertmanager API version on %q returned HTTP %d", amURL, resp.StatusCode)
+}
+
+// Convert alerts to the postableAlerts schema.
+func PostableAlerts(alerts []of.Alert) []of.PostableAlert {
+	postable := make([]of.PostableAlert, len(alerts))
+	for i := range alerts {
+		alert := &alerts[i]
+		postable[i] = of.PostableAlert{
+			Labels:       alert.Labels,
+			Annotations:  alert.Annotations,
+			GeneratorURL: alert.GeneratorURL,
+		}
+		if alert.StartsAt.IsZero() == false {
+			postable[i].StartsAt = &alert.StartsAt
+		}
+		if alert.EndsAt.IsZero() == false {
+			postable[i].EndsAt = &alert.EndsAt
+		}
+	}
+	return postable
+}
+
+// Post alerts to API version, returning an *of.AMError if Alertmanager rejects them.
+func PostAlerts(c *nethttp.Client, amURL string, version of.AMAPIVersion, userAgent string, alerts []of.PostableAlert) error {
+	url := AlertsURL(amURL, version)
+	b, err := json.Marshal(alerts)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", url, bytes.NewBuffer(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode/100 != 2 {
+		return ParseError(url, resp.StatusCode, body)
+	}
+	return nil
+}
+
+// Parse error body returned by Alertmanager.
+// API v2 returns {"code": .., "message": ..} or a JSON string,
+// API v1 returns {"status": "error", "errorType": .., "error": ..}.
+func ParseError(url string, statusCode int, body []byte) *of.AMError {
+	e := &of.AMError{URL: url, StatusCode: statusCode, Code: statusCode}
+
+	var v1 struct {
+		ErrorType string `json:"errorType"`
+		Error     string `json:"error"`
+	}
+	var message string
+	switch {
+	case json.Unmarshal(body, e) == nil && e.Message != "":
+	case json.Unmarshal(body, &v1) == nil && v1.Error != "":
+		e.ErrorType = v1.ErrorType
+		e.Message = v1.Error
+	case json.Unmarshal(body, &message) == nil:
+		e.Message = message
+	default:
+		e.Message = strings.TrimSpace(string(body))
+	}
+	return e
+}
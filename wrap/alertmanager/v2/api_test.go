@@ -0,0 +1,91 @@
+package v2_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+	am "github.com/cisco-cx/of/wrap/alertmanager/v2"
+	logger "github.com/cisco-cx/of/wrap/logrus/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// Test API versions are parsed, empty is auto.
+func TestParseAPIVersion(t *testing.T) {
+	for in, expected := range map[string]of.AMAPIVersion{"": of.AMAPIAuto, "auto": of.AMAPIAuto, "V2": of.AMAPIV2, "v1": of.AMAPIV1} {
+		v, err := am.ParseAPIVersion(in)
+		require.NoError(t, err)
+		require.Equal(t, expected, v)
+	}
+	_, err := am.ParseAPIVersion("v3")
+	require.EqualError(t, err, `Unknown Alertmanager API version. "v3", expected auto, v1 or v2`)
+}
+
+// Test version is detected by /api/v2/status.
+func TestDetectAPIVersion(t *testing.T) {
+	mux := http.NewServeMux()
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	v, err := am.DetectAPIVersion(ts.Client(), ts.URL)
+	require.NoError(t, err)
+	require.Equal(t, of.AMAPIV1, v)
+
+	mux.HandleFunc("/api/v2/status", func(w http.ResponseWriter, r *http.Request) {})
+	v, err = am.DetectAPIVersion(ts.Client(), ts.URL+"/")
+	require.NoError(t, err)
+	require.Equal(t, of.AMAPIV2, v)
+}
+
+// Test error bodies of both API versions are parsed.
+func TestParseError(t *testing.T) {
+	e := am.ParseError("http://am/api/v2/alerts", 400, []byte(`{"code": 601, "message": "labels in body is required"}`))
+	require.Equal(t, 601, e.Code)
+	require.Equal(t, "labels in body is required", e.Message)
+	require.EqualError(t, e, `POST to AlertManager on "http://am/api/v2/alerts" returned HTTP 400: labels in body is required`)
+
+	e = am.ParseError("http://am/api/v2/alerts", 400, []byte(`"start time must be before end time"`))
+	require.Equal(t, 400, e.Code)
+	require.Equal(t, "start time must be before end time", e.Message)
+
+	e = am.ParseError("http://am/api/v1/alerts", 400, []byte(`{"status":"error","errorType":"bad_data","error":"no alerts"}`))
+	require.Equal(t, "bad_data", e.ErrorType)
+	require.Equal(t, "no alerts", e.Message)
+
+	e = am.ParseError("http://am/api/v1/alerts", 502, []byte("Bad Gateway\n"))
+	require.Equal(t, "Bad Gateway", e.Message)
+}
+
+// Test alerts are posted in the postableAlerts schema, to the detected version.
+func TestNotifyAPIv2(t *testing.T) {
+	var posted []map[string]interface{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v2/status", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("/api/v2/alerts", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&posted))
+		if len(posted) > 1 {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"code": 400, "message": "too many alerts"}`))
+		}
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	as := am.AlertService{AmURL: ts.URL, Log: logger.New()}
+	startsAt := time.Date(2019, 4, 26, 3, 46, 57, 0, time.UTC)
+	alerts := []of.Alert{{Labels: map[string]string{"alertname": "a"}, StartsAt: startsAt}}
+	require.NoError(t, as.Notify(&alerts))
+	require.Equal(t, []map[string]interface{}{
+		{"labels": map[string]interface{}{"alertname": "a"}, "startsAt": "2019-04-26T03:46:57Z"},
+	}, posted)
+
+	alerts = append(alerts, of.Alert{Labels: map[string]string{"alertname": "b"}})
+	err := as.Notify(&alerts)
+	require.Error(t, err)
+	amErr, ok := err.(*of.AMError)
+	require.True(t, ok)
+	require.Equal(t, "too many alerts", amErr.Message)
+}
@@ -0,0 +1,135 @@
+package v2
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	nethttp "net/http"
+	"net/url"
+	"strings"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+	http "github.com/cisco-cx/of/wrap/http/v2"
+)
+
+// Queries alerts and manages silences through Alertmanager API v2. Silences and alerts
+// are shared by clustered peers, so requests go to the first peer that answers.
+type APIClient struct {
+	Peers   []string
+	Client  *nethttp.Client // A client without auth, TLS or timeout if nil.
+	Version string          // Sent as User-Agent.
+}
+
+// Silences matching filter, all if it is empty.
+func (c *APIClient) Silences(filter []of.SilenceMatcher) ([]of.Silence, error) {
+	var silences []of.Silence
+	err := c.do("GET", "/api/v2/silences"+query(filter), nil, &silences)
+	return silences, err
+}
+
+// Add silence s, returning its ID.
+func (c *APIClient) AddSilence(s *of.Silence) (string, error) {
+	if len(s.Matchers) == 0 {
+		return "", of.ErrNoSilenceMatchers
+	}
+	var res struct {
+		SilenceID string `json:"silenceID"`
+	}
+	err := c.do("POST", "/api/v2/silences", s, &res)
+	return res.SilenceID, err
+}
+
+// Expire silence with id.
+func (c *APIClient) ExpireSilence(id string) error {
+	return c.do("DELETE", "/api/v2/silence/"+url.PathEscape(id), nil, nil)
+}
+
+// Alerts matching filter, including silenced and inhibited ones.
+func (c *APIClient) Alerts(filter []of.SilenceMatcher) ([]of.GettableAlert, error) {
+	var alerts []of.GettableAlert
+	err := c.do("GET", "/api/v2/alerts"+query(filter), nil, &alerts)
+	return alerts, err
+}
+
+// Query string of filter.
+func query(filter []of.SilenceMatcher) string {
+	if len(filter) == 0 {
+		return ""
+	}
+	v := url.Values{}
+	for _, m := range filter {
+		v.Add("filter", MatcherString(m))
+	}
+	return "?" + v.Encode()
+}
+
+// Send request with body as JSON to the first peer that answers, decoding the response to out if set.
+// Errors of Alertmanager are returned as they are, as other peers would return them too,
+// unless it is overloaded or failing.
+func (c *APIClient) do(method string, path string, body interface{}, out interface{}) error {
+	if len(c.Peers) == 0 {
+		return of.ErrNoAMPeers
+	}
+	var b []byte
+	if body != nil {
+		var err error
+		if b, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+
+	var errs []string
+	for _, peer := range c.Peers {
+		err := c.request(method, strings.TrimSuffix(peer, "/")+path, b, out)
+		if err == nil {
+			return nil
+		}
+		if e, ok := err.(*of.AMError); ok == true && e.Overloaded() == false {
+			return err
+		}
+		errs = append(errs, err.Error())
+	}
+	return fmt.Errorf("No Alertmanager peer answered: %s", strings.Join(errs, "; "))
+}
+
+// Send request to url.
+func (c *APIClient) request(method string, url string, body []byte, out interface{}) error {
+	var r io.Reader
+	if body != nil {
+		r = bytes.NewReader(body)
+	}
+	req, err := http.NewRequest(method, url, r)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", c.Version)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	client := c.Client
+	if client == nil {
+		client = http.NewClient()
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode/100 != 2 {
+		e := ParseError(url, resp.StatusCode, b)
+		e.Method = method
+		return e
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(b, out)
+}
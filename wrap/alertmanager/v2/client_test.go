@@ -0,0 +1,138 @@
+package v2_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+	am "github.com/cisco-cx/of/wrap/alertmanager/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// Fake Alertmanager, keeping silences in memory.
+func fakeSilencesAM(t *testing.T, silences map[string]*of.Silence, alerts []of.GettableAlert) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v2/silences", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "of/test", r.Header.Get("User-Agent"))
+		if r.Method == "POST" {
+			s := &of.Silence{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(s))
+			s.ID = fmt.Sprintf("s%d", len(silences))
+			s.Status = &of.SilenceStatus{State: "active"}
+			silences[s.ID] = s
+			json.NewEncoder(w).Encode(map[string]string{"silenceID": s.ID})
+			return
+		}
+		list := []*of.Silence{}
+		for _, s := range silences {
+			list = append(list, s)
+		}
+		json.NewEncoder(w).Encode(list)
+	})
+	mux.HandleFunc("/api/v2/silence/", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "DELETE", r.Method)
+		s, ok := silences[r.URL.Path[len("/api/v2/silence/"):]]
+		if ok == false {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode("silence not found")
+			return
+		}
+		s.Status.State = "expired"
+	})
+	mux.HandleFunc("/api/v2/alerts", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, []string{`alert_fingerprint=~".+"`, `subsystem="epc"`}, r.URL.Query()["filter"])
+		json.NewEncoder(w).Encode(alerts)
+	})
+	return httptest.NewServer(mux)
+}
+
+// Test silences are added, listed and expired through the first peer that answers.
+func TestAPIClientSilences(t *testing.T) {
+	silences := make(map[string]*of.Silence)
+	ts := fakeSilencesAM(t, silences, nil)
+	defer ts.Close()
+
+	// The first peer is down.
+	down := httptest.NewServer(nil)
+	down.Close()
+	c := &am.APIClient{Peers: []string{down.URL, ts.URL + "/"}, Client: ts.Client(), Version: "of/test"}
+
+	start := time.Date(2020, 5, 1, 22, 22, 53, 0, time.UTC)
+	s := &of.Silence{
+		Matchers:  []of.SilenceMatcher{{Name: "source_address", Value: "10.0.0.1"}},
+		StartsAt:  start,
+		EndsAt:    start.Add(time.Hour),
+		CreatedBy: "noc",
+		Comment:   "Maintenance",
+	}
+	id, err := c.AddSilence(s)
+	require.NoError(t, err)
+	require.Equal(t, "s0", id)
+	require.Equal(t, s.Matchers, silences["s0"].Matchers)
+
+	list, err := c.Silences(nil)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	require.Equal(t, "Maintenance", list[0].Comment)
+	require.Equal(t, "active", list[0].Status.State)
+
+	require.NoError(t, c.ExpireSilence("s0"))
+	require.Equal(t, "expired", silences["s0"].Status.State)
+
+	// Errors of Alertmanager are not retried on other peers.
+	err = c.ExpireSilence("s1")
+	amErr, ok := err.(*of.AMError)
+	require.True(t, ok)
+	require.Equal(t, `DELETE to AlertManager on "`+ts.URL+`/api/v2/silence/s1" returned HTTP 404: silence not found`, amErr.Error())
+
+	_, err = c.AddSilence(&of.Silence{})
+	require.Equal(t, of.ErrNoSilenceMatchers, err)
+
+	c.Peers = []string{down.URL}
+	_, err = c.Silences(nil)
+	require.Contains(t, err.Error(), "No Alertmanager peer answered")
+}
+
+// Test alerts are queried with filters.
+func TestAPIClientAlerts(t *testing.T) {
+	alerts := []of.GettableAlert{{
+		Alert:       of.Alert{Labels: map[string]string{"alertname": "fanDown"}},
+		Fingerprint: "ab12",
+		Status:      of.AlertStatus{State: "suppressed", SilencedBy: []string{"s0"}},
+	}}
+	ts := fakeSilencesAM(t, nil, alerts)
+	defer ts.Close()
+
+	c := &am.APIClient{Peers: []string{ts.URL}, Version: "of/test"}
+	filter, err := am.ParseMatchers([]string{"alert_fingerprint=~.+", "subsystem=epc"})
+	require.NoError(t, err)
+	res, err := c.Alerts(filter)
+	require.NoError(t, err)
+	require.Equal(t, alerts, res)
+}
+
+// Test alerts are loaded from YAML and JSON files.
+func TestLoadAlerts(t *testing.T) {
+	alerts, err := am.LoadAlerts("test/alerts.yaml")
+	require.NoError(t, err)
+	require.Equal(t, []of.Alert{
+		{
+			Labels:      map[string]string{"alertname": "fanDown", "alert_severity": "critical", "source_address": "10.0.0.1"},
+			Annotations: map[string]string{"summary": "Fan 1 down"},
+			StartsAt:    time.Date(2020, 5, 1, 22, 22, 53, 0, time.UTC),
+		},
+		{Labels: map[string]string{"alertname": "linkDown"}, GeneratorURL: "http://of.invalid/linkDown"},
+	}, alerts)
+
+	f, err := ioutil.TempFile("", "alerts-*.json")
+	require.NoError(t, err)
+	defer f.Close()
+	f.WriteString(`[{"labels": {"alertname": "a"}}, {"annotations": {"summary": "no labels"}}]`)
+	_, err = am.LoadAlerts(f.Name())
+	require.Contains(t, err.Error(), of.ErrNoAlertLabels.Error())
+}
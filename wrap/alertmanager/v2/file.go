@@ -0,0 +1,28 @@
+package v2
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+	"github.com/ghodss/yaml"
+)
+
+// Load alerts from a JSON or YAML file, a list in the format posted to Alertmanager.
+// Every alert requires labels, times left out are set by Alertmanager.
+func LoadAlerts(path string) ([]of.Alert, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var alerts []of.Alert
+	if err = yaml.Unmarshal(b, &alerts); err != nil {
+		return nil, fmt.Errorf("Failed to parse alerts in %s: %s", path, err.Error())
+	}
+	for i, alert := range alerts {
+		if len(alert.Labels) == 0 {
+			return nil, fmt.Errorf("%s alerts[%d] in %s", of.ErrNoAlertLabels, i, path)
+		}
+	}
+	return alerts, nil
+}
@@ -0,0 +1,84 @@
+package v2
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+)
+
+// Label names of matchers.
+var labelName = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// Parse matcher like name=value, name!=value, name=~regex or name!~regex.
+// Values may be double quoted.
+func ParseMatcher(s string) (of.SilenceMatcher, error) {
+	m := of.SilenceMatcher{}
+	i := strings.IndexAny(s, "=!")
+	if i < 0 {
+		return m, fmt.Errorf("%s %q, expected name=value, name!=value, name=~regex or name!~regex", of.ErrInvalidMatcher, s)
+	}
+	m.Name, s = strings.TrimSpace(s[:i]), s[i:]
+	if labelName.MatchString(m.Name) == false {
+		return m, fmt.Errorf("%s Invalid label name %q", of.ErrInvalidMatcher, m.Name)
+	}
+
+	equal := true
+	switch {
+	case strings.HasPrefix(s, "=~"):
+		m.IsRegex, s = true, s[2:]
+	case strings.HasPrefix(s, "!~"):
+		m.IsRegex, equal, s = true, false, s[2:]
+	case strings.HasPrefix(s, "!="):
+		equal, s = false, s[2:]
+	case strings.HasPrefix(s, "="):
+		s = s[1:]
+	default:
+		return m, fmt.Errorf("%s Invalid operator in %q", of.ErrInvalidMatcher, m.Name+s)
+	}
+	if equal == false {
+		m.IsEqual = &equal
+	}
+
+	m.Value = strings.TrimSpace(s)
+	if strings.HasPrefix(m.Value, `"`) == true {
+		v, err := strconv.Unquote(m.Value)
+		if err != nil {
+			return m, fmt.Errorf("%s Invalid quoted value %s", of.ErrInvalidMatcher, m.Value)
+		}
+		m.Value = v
+	}
+	if m.IsRegex == true {
+		if _, err := regexp.Compile("^(?:" + m.Value + ")$"); err != nil {
+			return m, fmt.Errorf("%s %s", of.ErrInvalidMatcher, err.Error())
+		}
+	}
+	return m, nil
+}
+
+// Parse matchers, see ParseMatcher.
+func ParseMatchers(matchers []string) ([]of.SilenceMatcher, error) {
+	parsed := make([]of.SilenceMatcher, len(matchers))
+	for i, s := range matchers {
+		m, err := ParseMatcher(s)
+		if err != nil {
+			return nil, err
+		}
+		parsed[i] = m
+	}
+	return parsed, nil
+}
+
+// m as a filter of Alertmanager API v2, like name="value".
+func MatcherString(m of.SilenceMatcher) string {
+	op := "="
+	if m.IsEqual != nil && *m.IsEqual == false {
+		op = "!="
+	}
+	if m.IsRegex == true {
+		op = op[:1] + "~"
+	}
+	return m.Name + op + strconv.Quote(m.Value)
+}
@@ -0,0 +1,37 @@
+package v2_test
+
+import (
+	"testing"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+	am "github.com/cisco-cx/of/wrap/alertmanager/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// Test matchers are parsed, and formatted as filters.
+func TestParseMatcher(t *testing.T) {
+	notEqual := false
+	for in, expected := range map[string]of.SilenceMatcher{
+		"alertname=fanDown":         {Name: "alertname", Value: "fanDown"},
+		`source_address="10.0.0.1"`: {Name: "source_address", Value: "10.0.0.1"},
+		"subsystem=~ep.*":           {Name: "subsystem", Value: "ep.*", IsRegex: true},
+		"subsystem!=epc":            {Name: "subsystem", Value: "epc", IsEqual: &notEqual},
+		`subsystem!~"e=c"`:          {Name: "subsystem", Value: "e=c", IsRegex: true, IsEqual: &notEqual},
+		"summary=":                  {Name: "summary"},
+	} {
+		m, err := am.ParseMatcher(in)
+		require.NoError(t, err, in)
+		require.Equal(t, expected, m, in)
+	}
+
+	for _, in := range []string{"alertname", "1a=b", "a!b", `a="b`, "a=~(", "=b"} {
+		_, err := am.ParseMatcher(in)
+		require.Error(t, err, in)
+		require.Contains(t, err.Error(), of.ErrInvalidMatcher.Error(), in)
+	}
+
+	matchers, err := am.ParseMatchers([]string{"a=b", `c!~"d|e"`})
+	require.NoError(t, err)
+	require.Equal(t, `a="b"`, am.MatcherString(matchers[0]))
+	require.Equal(t, `c!~"d|e"`, am.MatcherString(matchers[1]))
+}
@@ -0,0 +1,134 @@
+package v2
+
+import (
+	"fmt"
+	"net"
+	nethttp "net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+	prometheus "github.com/cisco-cx/of/wrap/prometheus/client_golang/v2"
+)
+
+// Parse success policy, empty is all.
+func ParsePolicy(policy string) (of.AMSuccessPolicy, error) {
+	switch p := of.AMSuccessPolicy(strings.ToLower(policy)); p {
+	case "":
+		return of.AMPolicyAll, nil
+	case of.AMPolicyAny, of.AMPolicyAll:
+		return p, nil
+	}
+	return "", fmt.Errorf("%s %q, expected any or all", of.ErrUnknownAMPolicy, policy)
+}
+
+// Resolves Alertmanager peer URLs.
+type PeerResolver struct {
+	Scheme    string                                                        // Of URLs for SRV targets, defaults to http.
+	LookupSRV func(service, proto, name string) (string, []*net.SRV, error) // Defaults to net.LookupSRV.
+}
+
+// Peer URLs from comma separated addresses, followed by the targets of SRV name, if set.
+// Handlers resolve peers once at startup, later changes of the SRV record are not seen.
+func (r *PeerResolver) Resolve(addresses string, srv string) ([]string, error) {
+	var peers []string
+	for _, address := range strings.Split(addresses, ",") {
+		if address = strings.TrimSpace(address); address != "" {
+			peers = append(peers, strings.TrimSuffix(address, "/"))
+		}
+	}
+
+	if srv != "" {
+		lookup := r.LookupSRV
+		if lookup == nil {
+			lookup = net.LookupSRV
+		}
+		scheme := r.Scheme
+		if scheme == "" {
+			scheme = "http"
+		}
+		_, targets, err := lookup("", "", srv)
+		if err != nil {
+			return nil, err
+		}
+		for _, target := range targets {
+			host := strings.TrimSuffix(target.Target, ".")
+			peers = append(peers, scheme+"://"+net.JoinHostPort(host, strconv.Itoa(int(target.Port))))
+		}
+	}
+
+	if len(peers) == 0 {
+		return nil, of.ErrNoAMPeers
+	}
+	return peers, nil
+}
+
+// Post to every peer concurrently, counting results in posts if set.
+// Returns an *of.AMPeersError if the results fail policy.
+func FanOut(peers []string, policy of.AMSuccessPolicy, posts *prometheus.CounterVec, post func(peer string) error) error {
+	errs := make([]error, len(peers))
+	var wg sync.WaitGroup
+	for i, peer := range peers {
+		wg.Add(1)
+		go func(i int, peer string) {
+			defer wg.Done()
+			errs[i] = post(peer)
+		}(i, peer)
+	}
+	wg.Wait()
+
+	failed := make(map[string]error)
+	for i, peer := range peers {
+		result := "success"
+		if errs[i] != nil {
+			result = "failure"
+			failed[peer] = errs[i]
+		}
+		if posts != nil {
+			posts.Incr(map[string]string{"peer": peer, "result": result})
+		}
+	}
+
+	if len(failed) == 0 || (policy == of.AMPolicyAny && len(failed) < len(peers)) {
+		return nil
+	}
+	// A single peer fails with its own error, as before fan-out.
+	if len(peers) == 1 {
+		return errs[0]
+	}
+	return &of.AMPeersError{Policy: policy, Peers: len(peers), Errors: failed}
+}
+
+// Keeps API versions of peers, detected once if set to auto.
+type APIVersions struct {
+	mu       sync.Mutex
+	detected map[string]of.AMAPIVersion
+}
+
+// API version to post to peer.
+func (v *APIVersions) Get(c *nethttp.Client, version of.AMAPIVersion, peer string) (of.AMAPIVersion, error) {
+	if version != "" && version != of.AMAPIAuto {
+		return version, nil
+	}
+
+	v.mu.Lock()
+	detected, ok := v.detected[peer]
+	v.mu.Unlock()
+	if ok == true {
+		return detected, nil
+	}
+
+	// Detected without holding the lock, so a slow peer doesn't hold up others.
+	detected, err := DetectAPIVersion(c, peer)
+	if err != nil {
+		return "", err
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.detected == nil {
+		v.detected = make(map[string]of.AMAPIVersion)
+	}
+	v.detected[peer] = detected
+	return detected, nil
+}
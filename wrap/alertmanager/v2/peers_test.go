@@ -0,0 +1,127 @@
+package v2_test
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+	am "github.com/cisco-cx/of/wrap/alertmanager/v2"
+	logger "github.com/cisco-cx/of/wrap/logrus/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// Test policies are parsed, empty is all.
+func TestParsePolicy(t *testing.T) {
+	p, err := am.ParsePolicy("")
+	require.NoError(t, err)
+	require.Equal(t, of.AMPolicyAll, p)
+	p, err = am.ParsePolicy("Any")
+	require.NoError(t, err)
+	require.Equal(t, of.AMPolicyAny, p)
+	_, err = am.ParsePolicy("most")
+	require.Error(t, err)
+}
+
+// Test peers are resolved from addresses and SRV records.
+func TestPeerResolver(t *testing.T) {
+	r := am.PeerResolver{
+		LookupSRV: func(service, proto, name string) (string, []*net.SRV, error) {
+			require.Equal(t, "_web._tcp.alertmanager.local", name)
+			return "", []*net.SRV{
+				{Target: "am-0.alertmanager.local.", Port: 9093},
+				{Target: "am-1.alertmanager.local.", Port: 9093},
+			}, nil
+		},
+	}
+
+	peers, err := r.Resolve("http://am-a:9093/, http://am-b:9093", "")
+	require.NoError(t, err)
+	require.Equal(t, []string{"http://am-a:9093", "http://am-b:9093"}, peers)
+
+	peers, err = r.Resolve("", "_web._tcp.alertmanager.local")
+	require.NoError(t, err)
+	require.Equal(t, []string{"http://am-0.alertmanager.local:9093", "http://am-1.alertmanager.local:9093"}, peers)
+
+	_, err = r.Resolve(" ", "")
+	require.Equal(t, of.ErrNoAMPeers, err)
+}
+
+// Test posts to every peer are judged by policy.
+func TestFanOut(t *testing.T) {
+	peers := []string{"http://am-a", "http://am-b"}
+	post := func(peer string) error {
+		if peer == "http://am-b" {
+			return errors.New("connection refused")
+		}
+		return nil
+	}
+
+	require.NoError(t, am.FanOut(peers, of.AMPolicyAny, nil, post))
+	err := am.FanOut(peers, of.AMPolicyAll, nil, post)
+	require.EqualError(t, err, "Posting to 1 of 2 Alertmanager peers failed, policy all: http://am-b: connection refused")
+
+	// A single peer fails with its own error.
+	err = am.FanOut(peers[1:], of.AMPolicyAny, nil, post)
+	require.EqualError(t, err, "connection refused")
+}
+
+// Test alerts are posted to every peer.
+func TestNotifyPeers(t *testing.T) {
+	var posts int
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v2/alerts" {
+			posts++
+		}
+	})
+	ts1 := httptest.NewServer(handler)
+	defer ts1.Close()
+	ts2 := httptest.NewServer(handler)
+	defer ts2.Close()
+
+	as := am.AlertService{
+		Peers:      []string{ts1.URL, ts2.URL},
+		APIVersion: of.AMAPIV2,
+		Log:        logger.New(),
+	}
+	alerts := []of.Alert{{Labels: map[string]string{"alertname": "a"}}}
+	require.NoError(t, as.Notify(&alerts))
+	require.Equal(t, 2, posts)
+
+	// Unreachable peer fails policy all, but not any.
+	ts2.Close()
+	require.Error(t, as.Notify(&alerts))
+	as.Policy = of.AMPolicyAny
+	require.NoError(t, as.Notify(&alerts))
+}
+
+// Test a hung peer doesn't hold up detecting the API version of others.
+func TestAPIVersionsSlowPeer(t *testing.T) {
+	hung := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-hung
+	}))
+	defer slow.Close()
+	defer close(hung)
+	fast := httptest.NewServer(http.NotFoundHandler())
+	defer fast.Close()
+
+	v := &am.APIVersions{}
+	go v.Get(slow.Client(), of.AMAPIAuto, slow.URL)
+	time.Sleep(50 * time.Millisecond)
+
+	detected := make(chan of.AMAPIVersion)
+	go func() {
+		version, _ := v.Get(fast.Client(), of.AMAPIAuto, fast.URL)
+		detected <- version
+	}()
+	select {
+	case version := <-detected:
+		require.Equal(t, of.AMAPIV1, version)
+	case <-time.After(time.Second):
+		require.FailNow(t, "Detection was held up by the hung peer")
+	}
+}
@@ -0,0 +1,109 @@
+package v2
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+)
+
+// Represents alerts of a subsystem and source.
+type AlertGroup struct {
+	Subsystem string
+	Source    string
+	Alerts    []of.GettableAlert
+}
+
+// Group alerts by their subsystem and source_address labels, sorted by both.
+// Alerts in groups are sorted by start, then name.
+func GroupAlerts(alerts []of.GettableAlert) []AlertGroup {
+	index := make(map[[2]string]int)
+	var groups []AlertGroup
+	for _, alert := range alerts {
+		key := [2]string{labelOrNone(alert.Labels, "subsystem"), labelOrNone(alert.Labels, "source_address")}
+		i, ok := index[key]
+		if ok == false {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, AlertGroup{Subsystem: key[0], Source: key[1]})
+		}
+		groups[i].Alerts = append(groups[i].Alerts, alert)
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Subsystem != groups[j].Subsystem {
+			return groups[i].Subsystem < groups[j].Subsystem
+		}
+		return groups[i].Source < groups[j].Source
+	})
+	for _, g := range groups {
+		sort.SliceStable(g.Alerts, func(i, j int) bool {
+			if g.Alerts[i].StartsAt.Equal(g.Alerts[j].StartsAt) == false {
+				return g.Alerts[i].StartsAt.Before(g.Alerts[j].StartsAt)
+			}
+			return g.Alerts[i].Labels["alertname"] < g.Alerts[j].Labels["alertname"]
+		})
+	}
+	return groups
+}
+
+// Value of label name, or '-' if it is not set.
+func labelOrNone(labels map[string]string, name string) string {
+	return orNone(labels[name])
+}
+
+// v, or '-' if it is empty.
+func orNone(v string) string {
+	if v == "" {
+		return "-"
+	}
+	return v
+}
+
+// Write groups of alerts as tables, one per group.
+func WriteAlertGroups(w io.Writer, groups []AlertGroup) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for i, g := range groups {
+		if i > 0 {
+			fmt.Fprintln(tw)
+		}
+		fmt.Fprintf(tw, "subsystem=%s source_address=%s (%d)\n", g.Subsystem, g.Source, len(g.Alerts))
+		fmt.Fprintln(tw, "  STATE\tSEVERITY\tALERTNAME\tFINGERPRINT\tSTARTS")
+		for _, a := range g.Alerts {
+			fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\t%s\n",
+				orNone(a.Status.State),
+				labelOrNone(a.Labels, "alert_severity"),
+				labelOrNone(a.Labels, "alertname"),
+				labelOrNone(a.Labels, "alert_fingerprint"),
+				a.StartsAt.UTC().Format(time.RFC3339),
+			)
+		}
+	}
+	return tw.Flush()
+}
+
+// Write silences as a table, sorted by end.
+func WriteSilences(w io.Writer, silences []of.Silence) error {
+	sort.SliceStable(silences, func(i, j int) bool {
+		return silences[i].EndsAt.Before(silences[j].EndsAt)
+	})
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tSTATE\tMATCHERS\tENDS\tCREATED BY\tCOMMENT")
+	for _, s := range silences {
+		state := ""
+		if s.Status != nil {
+			state = s.Status.State
+		}
+		matchers := make([]string, len(s.Matchers))
+		for i, m := range s.Matchers {
+			matchers[i] = MatcherString(m)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			s.ID, orNone(state), strings.Join(matchers, " "), s.EndsAt.UTC().Format(time.RFC3339), s.CreatedBy, s.Comment)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,69 @@
+package v2_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+	am "github.com/cisco-cx/of/wrap/alertmanager/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// Alert with labels as pairs of label and value, started at start.
+func gettable(start time.Time, state string, pairs ...string) of.GettableAlert {
+	labels := make(map[string]string)
+	for i := 0; i < len(pairs); i += 2 {
+		labels[pairs[i]] = pairs[i+1]
+	}
+	return of.GettableAlert{Alert: of.Alert{Labels: labels, StartsAt: start}, Status: of.AlertStatus{State: state}}
+}
+
+// Test alerts are grouped by subsystem and source, and written as tables.
+func TestWriteAlertGroups(t *testing.T) {
+	start := time.Date(2020, 5, 1, 22, 22, 53, 0, time.UTC)
+	alerts := []of.GettableAlert{
+		gettable(start.Add(time.Minute), "active", "alertname", "linkDown", "subsystem", "epc", "source_address", "10.0.0.1", "alert_fingerprint", "F1"),
+		gettable(start, "suppressed", "alertname", "fanDown", "subsystem", "epc", "source_address", "10.0.0.1", "alert_severity", "critical"),
+		gettable(start, "active", "alertname", "apicFault", "source_address", "apic1"),
+		gettable(start, "active", "alertname", "cpuHigh", "subsystem", "epc", "source_address", "10.0.0.2"),
+	}
+	groups := am.GroupAlerts(alerts)
+	require.Len(t, groups, 3)
+	require.Equal(t, "-", groups[0].Subsystem)
+	require.Equal(t, "10.0.0.1", groups[1].Source)
+	require.Equal(t, "fanDown", groups[1].Alerts[0].Labels["alertname"])
+
+	var b bytes.Buffer
+	require.NoError(t, am.WriteAlertGroups(&b, groups))
+	require.Equal(t, `subsystem=- source_address=apic1 (1)
+  STATE   SEVERITY  ALERTNAME  FINGERPRINT  STARTS
+  active  -         apicFault  -            2020-05-01T22:22:53Z
+
+subsystem=epc source_address=10.0.0.1 (2)
+  STATE       SEVERITY  ALERTNAME  FINGERPRINT  STARTS
+  suppressed  critical  fanDown    -            2020-05-01T22:22:53Z
+  active      -         linkDown   F1           2020-05-01T22:23:53Z
+
+subsystem=epc source_address=10.0.0.2 (1)
+  STATE   SEVERITY  ALERTNAME  FINGERPRINT  STARTS
+  active  -         cpuHigh    -            2020-05-01T22:22:53Z
+`, b.String())
+}
+
+// Test silences are written as a table, sorted by end.
+func TestWriteSilences(t *testing.T) {
+	end := time.Date(2020, 5, 1, 22, 22, 53, 0, time.UTC)
+	silences := []of.Silence{
+		{ID: "s1", EndsAt: end.Add(time.Hour), CreatedBy: "noc", Comment: "Upgrade",
+			Matchers: []of.SilenceMatcher{{Name: "subsystem", Value: "epc"}, {Name: "alertname", Value: "link.*", IsRegex: true}}},
+		{ID: "s0", EndsAt: end, CreatedBy: "ops", Comment: "Maintenance", Status: &of.SilenceStatus{State: "expired"},
+			Matchers: []of.SilenceMatcher{{Name: "source_address", Value: "10.0.0.1"}}},
+	}
+	var b bytes.Buffer
+	require.NoError(t, am.WriteSilences(&b, silences))
+	require.Equal(t, `ID  STATE    MATCHERS                             ENDS                  CREATED BY  COMMENT
+s0  expired  source_address="10.0.0.1"            2020-05-01T22:22:53Z  ops         Maintenance
+s1  -        subsystem="epc" alertname=~"link.*"  2020-05-01T23:22:53Z  noc         Upgrade
+`, b.String())
+}
@@ -0,0 +1,150 @@
+package v2
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+)
+
+// Rotated files are named like audit-20190426T034657.000000000Z.log, next to Path.
+const rotatedTimeFormat = "20060102T150405.000000000Z"
+
+// Implements of.Auditor, appending one JSON record per line to Path.
+// Once the file reaches MaxSize, it is renamed with the time of rotation
+// and a new one is started. Only the newest MaxFiles rotated files are kept.
+type FileAuditor struct {
+	Path     string
+	MaxSize  int64 // Bytes, 0 to never rotate.
+	MaxFiles int   // Rotated files kept, 0 to keep all.
+	Clock    of.Clock
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// Open or create the audit file at path.
+func NewFileAuditor(path string, maxSize int64, maxFiles int, c of.Clock) (*FileAuditor, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	a := &FileAuditor{Path: path, MaxSize: maxSize, MaxFiles: maxFiles, Clock: c}
+	if err := a.open(); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// Append records, rotating the file once it is full.
+func (a *FileAuditor) Audit(records []of.AuditRecord) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.file == nil {
+		if err := a.open(); err != nil {
+			return err
+		}
+	}
+
+	for _, record := range records {
+		if record.Time.IsZero() {
+			record.Time = a.Clock.Now().UTC()
+		}
+		b, err := json.Marshal(record)
+		if err != nil {
+			return err
+		}
+		if a.MaxSize > 0 && a.size > 0 && a.size+int64(len(b))+1 > a.MaxSize {
+			if err = a.rotate(); err != nil {
+				return err
+			}
+		}
+		n, err := a.file.Write(append(b, '\n'))
+		a.size += int64(n)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close the audit file.
+func (a *FileAuditor) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.file == nil {
+		return nil
+	}
+	err := a.file.Close()
+	a.file = nil
+	return err
+}
+
+func (a *FileAuditor) open() error {
+	f, err := os.OpenFile(a.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	a.file = f
+	a.size = info.Size()
+	return nil
+}
+
+// Rename the current file, start a new one and remove rotated files beyond MaxFiles.
+func (a *FileAuditor) rotate() error {
+	if err := a.file.Close(); err != nil {
+		return err
+	}
+	a.file = nil
+	if err := os.Rename(a.Path, a.rotatedName()); err != nil {
+		return err
+	}
+	if err := a.open(); err != nil {
+		return err
+	}
+	return a.prune()
+}
+
+func (a *FileAuditor) rotatedName() string {
+	ext := filepath.Ext(a.Path)
+	base := strings.TrimSuffix(a.Path, ext)
+	return base + "-" + a.Clock.Now().UTC().Format(rotatedTimeFormat) + ext
+}
+
+// Rotated files, oldest first.
+func (a *FileAuditor) Rotated() ([]string, error) {
+	ext := filepath.Ext(a.Path)
+	files, err := filepath.Glob(strings.TrimSuffix(a.Path, ext) + "-*" + ext)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+func (a *FileAuditor) prune() error {
+	if a.MaxFiles <= 0 {
+		return nil
+	}
+	files, err := a.Rotated()
+	if err != nil {
+		return err
+	}
+	for len(files) > a.MaxFiles {
+		if err = os.Remove(files[0]); err != nil {
+			return err
+		}
+		files = files[1:]
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package v2_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+	audit "github.com/cisco-cx/of/wrap/audit/v2"
+	clock "github.com/cisco-cx/of/wrap/time/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// Enforce interface implementation.
+func TestFileAuditorInterface(t *testing.T) {
+	var _ of.Auditor = &audit.FileAuditor{}
+}
+
+// Read records from an audit file.
+func readRecords(t *testing.T, path string) []of.AuditRecord {
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	var records []of.AuditRecord
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		record := of.AuditRecord{}
+		require.NoError(t, json.Unmarshal(s.Bytes(), &record))
+		records = append(records, record)
+	}
+	require.NoError(t, s.Err())
+	return records
+}
+
+// Test records are appended as JSON lines, and the file is reopened for append.
+func TestFileAuditor(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	now := time.Date(2019, 4, 26, 3, 46, 57, 0, time.UTC)
+	c := &clock.FixedClock{T: now}
+	path := filepath.Join(dir, "audit.log")
+	a, err := audit.NewFileAuditor(path, 0, 0, c)
+	require.NoError(t, err)
+
+	record := of.AuditRecord{
+		EventID:     "1",
+		Source:      "dead::beef",
+		TrapOID:     ".1.3.6.1.4.1.8164.2.150",
+		Config:      "config1",
+		Alert:       "starTaskFailure",
+		Fingerprint: "26c5384f07068e37",
+		Action:      of.AuditGenerated,
+		PostResult:  of.AuditPostSent,
+	}
+	require.NoError(t, a.Audit([]of.AuditRecord{record}))
+	require.NoError(t, a.Close())
+
+	a, err = audit.NewFileAuditor(path, 0, 0, c)
+	require.NoError(t, err)
+	require.NoError(t, a.Audit([]of.AuditRecord{{EventID: "2", Action: of.AuditDropped, Reason: "alert disabled"}}))
+	require.NoError(t, a.Close())
+
+	records := readRecords(t, path)
+	require.Len(t, records, 2)
+	record.Time = now
+	require.Equal(t, record, records[0])
+	require.Equal(t, of.AuditDropped, records[1].Action)
+}
+
+// Test files are rotated by size, keeping the newest rotated files.
+func TestFileAuditorRotation(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	c := &clock.FixedClock{T: time.Date(2019, 4, 26, 3, 46, 57, 0, time.UTC)}
+	path := filepath.Join(dir, "audit.log")
+	a, err := audit.NewFileAuditor(path, 1, 2, c)
+	require.NoError(t, err)
+	defer a.Close()
+
+	for i := 0; i < 4; i++ {
+		require.NoError(t, a.Audit([]of.AuditRecord{{EventID: "1", Action: of.AuditGenerated}}))
+		c.Add(time.Second)
+	}
+
+	rotated, err := a.Rotated()
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		filepath.Join(dir, "audit-20190426T034659.000000000Z.log"),
+		filepath.Join(dir, "audit-20190426T034700.000000000Z.log"),
+	}, rotated)
+	require.Len(t, readRecords(t, path), 1)
+	require.Len(t, readRecords(t, rotated[0]), 1)
+}
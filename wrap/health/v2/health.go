@@ -28,6 +28,7 @@ package v2
 
 import (
 	"fmt"
+	"net/http"
 	"net/url"
 	"time"
 
@@ -57,6 +58,11 @@ func (hc *HealthChecker) Stop() error {
 }
 
 func (hc *HealthChecker) AddURL(name string, urlTarget string, timeout time.Duration) error {
+	return hc.AddURLWithTransport(name, urlTarget, timeout, nil)
+}
+
+// Add check of urlTarget, sending requests through rt, or the default transport if nil.
+func (hc *HealthChecker) AddURLWithTransport(name string, urlTarget string, timeout time.Duration, rt http.RoundTripper) error {
 	if name == "" {
 		return of.Error("The name can't be empty")
 	}
@@ -73,7 +79,8 @@ func (hc *HealthChecker) AddURL(name string, urlTarget string, timeout time.Dura
 	}
 
 	httpChecker, err := checkers.NewHTTP(&checkers.HTTPConfig{
-		URL: urlTargetParsed,
+		URL:    urlTargetParsed,
+		Client: &http.Client{Transport: rt},
 	})
 	if err != nil {
 		return err
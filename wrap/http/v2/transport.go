@@ -0,0 +1,214 @@
+package v2
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+	logger "github.com/cisco-cx/of/wrap/logrus/v2"
+	watcher "github.com/cisco-cx/of/wrap/watcher/v2"
+)
+
+// Implements http.RoundTripper, adding the auth and TLS settings of Config to requests.
+// Credential and certificate files are read by Load, and again on change after Watch.
+type Transport struct {
+	Config *of.HTTPClientConfig
+	Log    *logger.Logger
+
+	mu       sync.RWMutex
+	base     *http.Transport
+	password string
+	token    string
+	fs       []*watcher.Notifier
+}
+
+// Init Transport, loading files of cfg.
+func NewTransport(cfg *of.HTTPClientConfig, l *logger.Logger) (*Transport, error) {
+	t := &Transport{Config: cfg, Log: l}
+	if err := t.Load(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Client sending requests through t, with Config.Timeout.
+func (t *Transport) Client() *http.Client {
+	return &http.Client{Transport: t, Timeout: t.Config.Timeout}
+}
+
+// Read credential and certificate files. Current settings are kept, if a file is invalid.
+func (t *Transport) Load() error {
+	cfg := t.Config
+	password, err := readSecret(cfg.BasicAuthPasswordFile)
+	if err != nil {
+		return err
+	}
+	token, err := readSecret(cfg.BearerTokenFile)
+	if err != nil {
+		return err
+	}
+	tlsConfig, err := clientTLSConfig(cfg)
+	if err != nil {
+		return err
+	}
+	proxy := http.ProxyFromEnvironment
+	if cfg.ProxyURL != "" {
+		proxyURL, err := url.Parse(cfg.ProxyURL)
+		if err != nil {
+			return err
+		}
+		proxy = http.ProxyURL(proxyURL)
+	}
+
+	// Settings of http.DefaultTransport.
+	base := &http.Transport{
+		Proxy: proxy,
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+		TLSClientConfig:       tlsConfig,
+	}
+
+	t.mu.Lock()
+	old := t.base
+	t.base, t.password, t.token = base, password, token
+	t.mu.Unlock()
+
+	// Connections made with old certificates are not reused.
+	if old != nil {
+		old.CloseIdleConnections()
+	}
+	return nil
+}
+
+// Reload files when they change. Dirs of files are watched,
+// so files replaced through a symlink, like mounted secrets, are seen too.
+func (t *Transport) Watch() error {
+	for _, dir := range t.dirs() {
+		fs, err := watcher.NewPath(dir, t.Log)
+		if err != nil {
+			return err
+		}
+		if err = fs.Watch(); err != nil {
+			return err
+		}
+		t.fs = append(t.fs, fs)
+
+		go func() {
+			for _ = range fs.Changed {
+				if err := t.Load(); err != nil {
+					t.Log.WithError(err).Errorf("Failed to reload HTTP client credentials, keeping current ones.")
+				}
+			}
+		}()
+	}
+	return nil
+}
+
+// Stop watching files.
+func (t *Transport) Unwatch() error {
+	for _, fs := range t.fs {
+		if err := fs.Unwatch(); err != nil {
+			return err
+		}
+	}
+	t.fs = nil
+	return nil
+}
+
+// Send req with credentials. req is not modified.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.RLock()
+	base, password, token := t.base, t.password, t.token
+	t.mu.RUnlock()
+
+	if token == "" && t.Config.BasicAuthUser == "" {
+		return base.RoundTrip(req)
+	}
+
+	r := new(http.Request)
+	*r = *req
+	r.Header = make(http.Header, len(req.Header)+1)
+	for k, v := range req.Header {
+		r.Header[k] = v
+	}
+	if token != "" {
+		r.Header.Set("Authorization", "Bearer "+token)
+	} else {
+		r.SetBasicAuth(t.Config.BasicAuthUser, password)
+	}
+	return base.RoundTrip(r)
+}
+
+// Dirs of files in Config, without duplicates.
+func (t *Transport) dirs() []string {
+	var dirs []string
+	seen := make(map[string]bool)
+	files := []string{t.Config.BasicAuthPasswordFile, t.Config.BearerTokenFile, t.Config.CertFile, t.Config.KeyFile, t.Config.CAFile}
+	for _, file := range files {
+		if file == "" {
+			continue
+		}
+		dir := filepath.Dir(file)
+		if seen[dir] == false {
+			seen[dir] = true
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// Contents of file without surrounding whitespace, empty if file is not set.
+func readSecret(file string) (string, error) {
+	if file == "" {
+		return "", nil
+	}
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(b)), nil
+}
+
+// TLS settings with client certificate and CA of cfg, nil if neither is set.
+func clientTLSConfig(cfg *of.HTTPClientConfig) (*tls.Config, error) {
+	if cfg.CertFile == "" && cfg.KeyFile == "" && cfg.CAFile == "" {
+		return nil, nil
+	}
+	tlsConfig := &tls.Config{}
+	if cfg.CertFile != "" || cfg.KeyFile != "" {
+		if cfg.CertFile == "" || cfg.KeyFile == "" {
+			return nil, of.ErrKeyWithoutCert
+		}
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	if cfg.CAFile != "" {
+		b, err := ioutil.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if pool.AppendCertsFromPEM(b) == false {
+			return nil, of.ErrInvalidCA
+		}
+		tlsConfig.RootCAs = pool
+	}
+	return tlsConfig, nil
+}
@@ -0,0 +1,188 @@
+package v2_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	nethttp "net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+	http "github.com/cisco-cx/of/wrap/http/v2"
+	logger "github.com/cisco-cx/of/wrap/logrus/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// Temporary dir for credential files.
+func credentialsDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "http-client")
+	require.NoError(t, err)
+	return dir
+}
+
+// Write content to name in dir, returning its path.
+func writeFile(t *testing.T, dir string, name string, content []byte) string {
+	path := filepath.Join(dir, name)
+	require.NoError(t, ioutil.WriteFile(path, content, 0600))
+	return path
+}
+
+// Server responding with the Authorization header of requests.
+func authServer() *httptest.Server {
+	return httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		w.Write([]byte(r.Header.Get("Authorization")))
+	}))
+}
+
+// GET url with c, returning the response body.
+func get(t *testing.T, c *nethttp.Client, url string) string {
+	res, err := c.Get(url)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	b, err := ioutil.ReadAll(res.Body)
+	require.NoError(t, err)
+	return string(b)
+}
+
+// Test basic auth password is read from file, and reloaded on change.
+func TestTransportBasicAuth(t *testing.T) {
+	dir := credentialsDir(t)
+	defer os.RemoveAll(dir)
+	ts := authServer()
+	defer ts.Close()
+
+	cfg := &of.HTTPClientConfig{
+		BasicAuthUser:         "of",
+		BasicAuthPasswordFile: writeFile(t, dir, "password", []byte("secret\n")),
+	}
+	tr, err := http.NewTransport(cfg, logger.New())
+	require.NoError(t, err)
+	c := tr.Client()
+	require.Equal(t, "Basic b2Y6c2VjcmV0", get(t, c, ts.URL))
+
+	require.NoError(t, tr.Watch())
+	defer tr.Unwatch()
+	writeFile(t, dir, "password", []byte("rotated"))
+	require.Eventually(t, func() bool {
+		return get(t, c, ts.URL) == "Basic b2Y6cm90YXRlZA=="
+	}, 5*time.Second, 50*time.Millisecond)
+}
+
+// Test bearer token is read from file, and requests are not modified.
+func TestTransportBearerToken(t *testing.T) {
+	dir := credentialsDir(t)
+	defer os.RemoveAll(dir)
+	ts := authServer()
+	defer ts.Close()
+
+	cfg := &of.HTTPClientConfig{BearerTokenFile: writeFile(t, dir, "token", []byte("abc"))}
+	tr, err := http.NewTransport(cfg, logger.New())
+	require.NoError(t, err)
+
+	req, err := http.NewRequest("GET", ts.URL, nil)
+	require.NoError(t, err)
+	res, err := tr.Client().Do(req)
+	require.NoError(t, err)
+	b, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	require.NoError(t, err)
+	require.Equal(t, "Bearer abc", string(b))
+	require.Empty(t, req.Header.Get("Authorization"))
+
+	// Missing files fail loading.
+	cfg.BearerTokenFile = filepath.Join(dir, "missing")
+	_, err = http.NewTransport(cfg, logger.New())
+	require.Error(t, err)
+}
+
+// Self signed certificate and key in PEM.
+func selfSigned(t *testing.T, cn string) ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: cn},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+}
+
+// Test servers are verified with the CA file, and client certificates are sent.
+func TestTransportTLS(t *testing.T) {
+	dir := credentialsDir(t)
+	defer os.RemoveAll(dir)
+
+	clientCert, clientKey := selfSigned(t, "of")
+	clientCAs := x509.NewCertPool()
+	require.True(t, clientCAs.AppendCertsFromPEM(clientCert))
+
+	ts := httptest.NewUnstartedServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		w.Write([]byte(r.TLS.PeerCertificates[0].Subject.CommonName))
+	}))
+	ts.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	ts.StartTLS()
+	defer ts.Close()
+
+	// Server is not trusted without CA file.
+	tr, err := http.NewTransport(&of.HTTPClientConfig{}, logger.New())
+	require.NoError(t, err)
+	_, err = tr.Client().Get(ts.URL)
+	require.Error(t, err)
+
+	cfg := &of.HTTPClientConfig{
+		CAFile:   writeFile(t, dir, "ca.pem", pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw})),
+		CertFile: writeFile(t, dir, "cert.pem", clientCert),
+		KeyFile:  writeFile(t, dir, "key.pem", clientKey),
+	}
+	tr, err = http.NewTransport(cfg, logger.New())
+	require.NoError(t, err)
+	require.Equal(t, "of", get(t, tr.Client(), ts.URL))
+
+	// Invalid files fail loading, current settings are kept.
+	writeFile(t, dir, "ca.pem", []byte("none"))
+	require.Equal(t, of.ErrInvalidCA, tr.Load())
+	require.Equal(t, "of", get(t, tr.Client(), ts.URL))
+
+	_, err = http.NewTransport(&of.HTTPClientConfig{CertFile: cfg.CertFile}, logger.New())
+	require.Equal(t, of.ErrKeyWithoutCert, err)
+}
+
+// Test requests go through the proxy, and time out.
+func TestTransportProxyTimeout(t *testing.T) {
+	proxy := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		if r.URL.Path == "/slow" {
+			time.Sleep(200 * time.Millisecond)
+		}
+		w.Write([]byte(r.URL.String()))
+	}))
+	defer proxy.Close()
+
+	cfg := &of.HTTPClientConfig{ProxyURL: proxy.URL, Timeout: 100 * time.Millisecond}
+	tr, err := http.NewTransport(cfg, logger.New())
+	require.NoError(t, err)
+	c := tr.Client()
+	require.Equal(t, "http://alertmanager.invalid/-/healthy", get(t, c, "http://alertmanager.invalid/-/healthy"))
+
+	_, err = c.Get("http://alertmanager.invalid/slow")
+	require.Error(t, err)
+}
@@ -1,10 +1,13 @@
 package v2
 
+//go:generate go run ../../../tools/schemagen schema/snmp/alerts.schema snmp.go v2 SNMPAlertsSchema
+
 import (
 	json_encoding "encoding/json"
 	"errors"
 	"fmt"
 
+	of "github.com/cisco-cx/of/pkg/v2"
 	"github.com/ghodss/yaml"
 	"github.com/qri-io/jsonschema"
 )
@@ -25,6 +28,9 @@ func (j *Schema) ValidateJSON(data []byte) error {
 	if err != nil {
 		return errors.New(fmt.Sprintf("%s, %+v", err.Error(), valErr))
 	}
+	if len(valErr) != 0 {
+		return errors.New(fmt.Sprintf("%s %+v", of.ErrSchemaMismatch, valErr))
+	}
 	return nil
 }
 
@@ -36,3 +42,24 @@ func (j *Schema) ValidateYAML(data []byte) error {
 	}
 	return j.ValidateJSON(json_data)
 }
+
+// Validate given yaml data against loaded jsonschema, returning each mismatch.
+func (j *Schema) YAMLErrors(data []byte) ([]of.SchemaError, error) {
+	json_data, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	valErr, err := j.rs.ValidateBytes(json_data)
+	if err != nil {
+		return nil, err
+	}
+
+	errs := make([]of.SchemaError, len(valErr))
+	for i, e := range valErr {
+		errs[i] = of.SchemaError{Path: e.PropertyPath, Message: e.Message}
+		if e.InvalidValue != nil && e.Message != "" && e.Message[0] != '"' {
+			errs[i].Message = fmt.Sprintf("%s: %s", jsonschema.InvalidValueString(e.InvalidValue), e.Message)
+		}
+	}
+	return errs, nil
+}
@@ -99,3 +99,45 @@ func testSchema(t *testing.T, schemaFile string, yamlFile string) {
 	err = schema.ValidateYAML(yaml)
 	require.NoError(t, err)
 }
+
+// Test generated schema is same as schema file, run go generate if not.
+func TestSNMPAlertsSchemaConst(t *testing.T) {
+	schemaContent, err := ioutil.ReadFile("schema/snmp/alerts.schema")
+	require.NoError(t, err)
+	require.Equal(t, string(schemaContent), js.SNMPAlertsSchema)
+}
+
+func TestYAMLErrors(t *testing.T) {
+	schema := &js.Schema{}
+	err := schema.Load([]byte(js.SNMPAlertsSchema))
+	require.NoError(t, err)
+
+	yamlBytes := []byte(`
+cfg:
+  defaults:
+    label_mods:
+    - type: copy
+      oid: .1.3.6.1.6.3.1.1.4.1.0
+      as: valu
+  alerts:
+  - name: x
+    firing:
+      select:
+      - type: equals
+        oid: .1.3.6.1.6.3.1.1.4.1.0
+        as: value
+        values: []
+`)
+	errs, err := schema.YAMLErrors(yamlBytes)
+	require.NoError(t, err)
+	paths := make([]string, len(errs))
+	for i, e := range errs {
+		paths[i] = e.Path
+	}
+	require.ElementsMatch(t, []string{
+		"/cfg/alerts/0/firing/select/0/values",
+		"/cfg/defaults/label_mods/0",
+		"/cfg/defaults/label_mods/0/as",
+	}, paths)
+	require.Error(t, schema.ValidateYAML(yamlBytes))
+}
@@ -0,0 +1,83 @@
+package v2_test
+
+import (
+	"testing"
+	"time"
+
+	maintenance "github.com/cisco-cx/of/wrap/maintenance/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// Test matching of cron expressions.
+func TestCronMatch(t *testing.T) {
+	// Friday.
+	ts := time.Date(2020, 5, 1, 22, 30, 0, 0, time.UTC)
+	tests := []struct {
+		expr    string
+		matched bool
+	}{
+		{"* * * * *", true},
+		{"30 22 * * *", true},
+		{"31 22 * * *", false},
+		{"*/15 * * * *", true},
+		{"*/20 * * * *", false},
+		{"0-30/10 20-23 * * *", true},
+		{"30 22 1 5 *", true},
+		{"30 22 * 6 *", false},
+		{"30 22 * * 5", true},
+		{"30 22 * * 1-4", false},
+		{"30 22 2 * 5", true},  // Either day-of-month or day-of-week.
+		{"30 22 2 * 1", false}, // Neither day-of-month nor day-of-week.
+		{"30 22,23 * * *", true},
+		{"15/15 * * * *", true},
+	}
+	for _, test := range tests {
+		c, err := maintenance.ParseCron(test.expr)
+		require.NoError(t, err, test.expr)
+		require.Equal(t, test.matched, c.Match(ts), test.expr)
+	}
+
+	// Sunday as 0 or 7.
+	sunday := time.Date(2020, 5, 3, 0, 0, 0, 0, time.UTC)
+	for _, expr := range []string{"0 0 * * 0", "0 0 * * 7"} {
+		c, err := maintenance.ParseCron(expr)
+		require.NoError(t, err)
+		require.True(t, c.Match(sunday), expr)
+	}
+}
+
+// Test finding next time matching cron expressions.
+func TestCronNext(t *testing.T) {
+	// Friday.
+	ts := time.Date(2020, 5, 1, 22, 30, 20, 0, time.UTC)
+	tests := []struct {
+		expr string
+		next time.Time
+	}{
+		{"* * * * *", time.Date(2020, 5, 1, 22, 31, 0, 0, time.UTC)},
+		{"30 22 * * *", time.Date(2020, 5, 2, 22, 30, 0, 0, time.UTC)},
+		{"*/20 * * * *", time.Date(2020, 5, 1, 22, 40, 0, 0, time.UTC)},
+		{"0 2 * * 0", time.Date(2020, 5, 3, 2, 0, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)},
+		{"15 3 * 2 *", time.Date(2021, 2, 1, 3, 15, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 31 2 *", time.Time{}},
+	}
+	for _, test := range tests {
+		c, err := maintenance.ParseCron(test.expr)
+		require.NoError(t, err, test.expr)
+		next := c.Next(ts)
+		require.True(t, test.next.Equal(next), "%s: %s", test.expr, next)
+		if next.IsZero() == false {
+			require.True(t, c.Match(next), test.expr)
+		}
+	}
+}
+
+// Test invalid cron expressions.
+func TestCronInvalid(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "*/0 * * * *", "a * * * *", "5-1 * * * *"} {
+		_, err := maintenance.ParseCron(expr)
+		require.Error(t, err, expr)
+	}
+}
@@ -0,0 +1,271 @@
+package v2
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+	logger "github.com/cisco-cx/of/wrap/logrus/v2"
+	watcher "github.com/cisco-cx/of/wrap/watcher/v2"
+	yaml "github.com/cisco-cx/of/wrap/yaml/v2"
+)
+
+// Implements of.Maintenance, using windows from a schedule file.
+type Schedule struct {
+	Path    string
+	Log     *logger.Logger
+	Actions []of.MaintenanceAction // Window actions allowed, defaults to all.
+
+	mu      sync.RWMutex
+	windows []window
+	fs      *watcher.Notifier
+}
+
+// Represents a parsed maintenance window.
+type window struct {
+	of.MaintenanceWindow
+	cron     *Cron
+	location *time.Location
+	nets     []*net.IPNet
+}
+
+// Init Schedule, loading windows from path.
+func NewSchedule(path string, l *logger.Logger) (*Schedule, error) {
+	s := &Schedule{Path: path, Log: l}
+	if err := s.Load(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Load windows from schedule file. Current windows are kept, if the file is invalid.
+func (s *Schedule) Load() error {
+	f, err := os.Open(s.Path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	m := yaml.Maintenance{}
+	if err = m.Decode(f); err != nil {
+		return err
+	}
+
+	windows := make([]window, 0, len(m.Windows))
+	for i, mw := range m.Windows {
+		w, err := parseWindow(mw)
+		if err == nil && len(s.Actions) != 0 && containsAction(s.Actions, w.Action) == false {
+			err = fmt.Errorf("%s Action %q is not supported", of.ErrInvalidMaintenanceWindow, w.Action)
+		}
+		if err != nil {
+			return fmt.Errorf("windows[%d] (%s): %s", i, mw.Name, err.Error())
+		}
+		windows = append(windows, w)
+	}
+
+	s.mu.Lock()
+	s.windows = windows
+	s.mu.Unlock()
+	s.Log.WithField("path", s.Path).Infof("Loaded %d maintenance windows.", len(windows))
+	return nil
+}
+
+// Reload windows when the schedule file changes. Its dir is watched,
+// so a file replaced by rename or through a symlink, like a mounted ConfigMap, is seen too.
+func (s *Schedule) Watch() error {
+	fs, err := watcher.NewPath(filepath.Dir(s.Path), s.Log)
+	if err != nil {
+		return err
+	}
+	if err = fs.Watch(); err != nil {
+		return err
+	}
+	s.fs = fs
+
+	name := filepath.Base(s.Path)
+	go func() {
+		for path := range fs.Changed {
+			// ..data is swapped when a mounted ConfigMap is updated.
+			if base := filepath.Base(path); base != name && base != "..data" {
+				continue
+			}
+			if err := s.Load(); err != nil {
+				s.Log.WithError(err).Errorf("Failed to reload maintenance schedule, keeping current windows.")
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop watching the schedule file.
+func (s *Schedule) Unwatch() error {
+	if s.fs == nil {
+		return nil
+	}
+	return s.fs.Unwatch()
+}
+
+// Find active window with target in scope.
+func (s *Schedule) Window(target of.MaintenanceTarget, t time.Time) (of.MaintenanceWindow, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, w := range s.windows {
+		if w.active(t) && w.inScope(target) {
+			return w.MaintenanceWindow, true
+		}
+	}
+	return of.MaintenanceWindow{}, false
+}
+
+// All windows, with their status at t.
+func (s *Schedule) Windows(t time.Time) []of.MaintenanceStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	statuses := make([]of.MaintenanceStatus, len(s.windows))
+	for i, w := range s.windows {
+		statuses[i] = of.MaintenanceStatus{MaintenanceWindow: w.MaintenanceWindow, Active: w.active(t)}
+	}
+	return statuses
+}
+
+// HTTP handler func, listing windows and their status.
+func (s *Schedule) Handler(w of.ResponseWriter, r of.Request) {
+	s.Log.Tracef("Maintenance endpoint accessed.")
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(map[string]interface{}{
+		"windows": s.Windows(time.Now().UTC()),
+	})
+	if err != nil {
+		s.Log.WithError(err).Errorf("Failed to write maintenance windows.")
+	}
+}
+
+// Validate and parse maintenance window.
+func parseWindow(mw of.MaintenanceWindow) (window, error) {
+	w := window{MaintenanceWindow: mw, location: time.UTC}
+
+	switch mw.Action {
+	case "":
+		w.Action = of.MaintenanceDrop
+	case of.MaintenanceDrop, of.MaintenanceTag, of.MaintenanceDelay:
+	default:
+		return w, fmt.Errorf("%s Unknown action %q", of.ErrInvalidMaintenanceWindow, mw.Action)
+	}
+
+	if mw.Schedule != "" {
+		c, err := ParseCron(mw.Schedule)
+		if err != nil {
+			return w, err
+		}
+		w.cron = c
+		if mw.Duration <= 0 {
+			return w, fmt.Errorf("%s duration is required with schedule", of.ErrInvalidMaintenanceWindow)
+		}
+		if mw.Timezone != "" {
+			if w.location, err = time.LoadLocation(mw.Timezone); err != nil {
+				return w, err
+			}
+		}
+	} else {
+		if mw.Start.IsZero() {
+			return w, fmt.Errorf("%s start or schedule is required", of.ErrInvalidMaintenanceWindow)
+		}
+		if mw.End.IsZero() {
+			w.End = mw.Start.Add(time.Duration(mw.Duration) * time.Minute)
+		}
+		if w.End.After(w.Start) == false {
+			return w, fmt.Errorf("%s end must be after start", of.ErrInvalidMaintenanceWindow)
+		}
+	}
+
+	for _, addr := range mw.Scope.SourceAddresses {
+		_, ipNet, err := net.ParseCIDR(addr)
+		if err != nil {
+			ip := net.ParseIP(addr)
+			if ip == nil {
+				return w, fmt.Errorf("%s invalid source address %q", of.ErrInvalidMaintenanceWindow, addr)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			ipNet = &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}
+		}
+		w.nets = append(w.nets, ipNet)
+	}
+	return w, nil
+}
+
+// Check if window is active at t.
+func (w *window) active(t time.Time) bool {
+	if w.cron == nil {
+		return t.Before(w.Start) == false && t.Before(w.End)
+	}
+
+	// Check if the window started within the last duration minutes.
+	t = t.In(w.location).Truncate(time.Minute)
+	start := w.cron.Next(t.Add(-time.Duration(w.Duration) * time.Minute))
+	return start.IsZero() == false && start.After(t) == false
+}
+
+// Check if target is in scope of window.
+func (w *window) inScope(target of.MaintenanceTarget) bool {
+	if len(w.nets) != 0 {
+		ip := net.ParseIP(target.Address)
+		if ip == nil {
+			return false
+		}
+		found := false
+		for _, n := range w.nets {
+			if n.Contains(ip) {
+				found = true
+				break
+			}
+		}
+		if found == false {
+			return false
+		}
+	}
+
+	if len(w.Scope.Clusters) != 0 && contains(w.Scope.Clusters, target.Cluster) == false {
+		return false
+	}
+
+	if len(w.Scope.ACIClusters) != 0 && contains(w.Scope.ACIClusters, target.ACICluster) == false {
+		return false
+	}
+
+	for k, v := range w.Scope.Labels {
+		if target.Labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// Check if action is in actions.
+func containsAction(actions []of.MaintenanceAction, action of.MaintenanceAction) bool {
+	for _, a := range actions {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
+
+// Check if value is in values.
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
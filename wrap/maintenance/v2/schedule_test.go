@@ -0,0 +1,177 @@
+package v2_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+	http_v2 "github.com/cisco-cx/of/wrap/http/v2"
+	logger "github.com/cisco-cx/of/wrap/logrus/v2"
+	maintenance "github.com/cisco-cx/of/wrap/maintenance/v2"
+	"github.com/stretchr/testify/require"
+)
+
+const scheduleFile = "test/maintenance.yaml"
+
+// Enforce interface implementation.
+func TestScheduleInterface(t *testing.T) {
+	var _ of.Maintenance = &maintenance.Schedule{}
+}
+
+// Test one-off window.
+func TestScheduleOneOff(t *testing.T) {
+	s, err := maintenance.NewSchedule(scheduleFile, logger.New())
+	require.NoError(t, err)
+
+	in := time.Date(2020, 5, 1, 23, 0, 0, 0, time.UTC)
+	out := time.Date(2020, 5, 2, 2, 0, 0, 0, time.UTC)
+
+	w, ok := s.Window(of.MaintenanceTarget{Address: "192.168.1.28"}, in)
+	require.True(t, ok)
+	require.Equal(t, "pgw1-upgrade", w.Name)
+	require.Equal(t, of.MaintenanceDrop, w.Action)
+
+	_, ok = s.Window(of.MaintenanceTarget{Address: "dead:beef::1"}, in)
+	require.True(t, ok)
+	_, ok = s.Window(of.MaintenanceTarget{Address: "192.168.2.28"}, in)
+	require.False(t, ok)
+	_, ok = s.Window(of.MaintenanceTarget{Address: "pgw1"}, in)
+	require.False(t, ok)
+	_, ok = s.Window(of.MaintenanceTarget{Address: "192.168.1.28"}, out)
+	require.False(t, ok)
+}
+
+// Test recurring windows.
+func TestScheduleRecurring(t *testing.T) {
+	s, err := maintenance.NewSchedule(scheduleFile, logger.New())
+	require.NoError(t, err)
+
+	epc := of.MaintenanceTarget{Cluster: "pgw1", Labels: map[string]string{"subsystem": "epc"}}
+	sunday := time.Date(2020, 5, 3, 2, 0, 0, 0, time.UTC)
+	w, ok := s.Window(epc, sunday.Add(119*time.Minute))
+	require.True(t, ok)
+	require.Equal(t, "weekly-epc", w.Name)
+	_, ok = s.Window(epc, sunday.Add(120*time.Minute))
+	require.False(t, ok)
+	_, ok = s.Window(epc, sunday.Add(-time.Minute))
+	require.False(t, ok)
+	_, ok = s.Window(of.MaintenanceTarget{Cluster: "pgw1", Labels: map[string]string{"subsystem": "nso"}}, sunday)
+	require.False(t, ok)
+
+	// 03:30 in New York.
+	aci := of.MaintenanceTarget{ACICluster: "lab-aci"}
+	ny := time.Date(2020, 5, 1, 7, 30, 0, 0, time.UTC)
+	w, ok = s.Window(aci, ny.Add(14*time.Minute))
+	require.True(t, ok)
+	require.Equal(t, of.MaintenanceDelay, w.Action)
+	_, ok = s.Window(aci, ny.Add(15*time.Minute))
+	require.False(t, ok)
+	_, ok = s.Window(aci, ny.Add(-time.Hour))
+	require.False(t, ok)
+}
+
+// Test current windows are kept, if reload fails.
+func TestScheduleReload(t *testing.T) {
+	tmpFile, err := ioutil.TempFile("", "maintenance.*.yaml")
+	require.NoError(t, err)
+	defer os.Remove(tmpFile.Name())
+
+	data, err := ioutil.ReadFile(scheduleFile)
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(tmpFile.Name(), data, 0644))
+
+	s, err := maintenance.NewSchedule(tmpFile.Name(), logger.New())
+	require.NoError(t, err)
+	require.Len(t, s.Windows(time.Now()), 3)
+
+	invalid := []string{
+		"windows:\n- name: bad\n  schedule: \"* * *\"\n  duration: 10\n",
+		"windows:\n- name: bad\n  schedule: \"* * * * *\"\n",
+		"windows:\n- name: bad\n  start: 2020-05-01T22:00:00Z\n",
+		"windows:\n- name: bad\n  start: 2020-05-01T22:00:00Z\n  duration: 10\n  action: mute\n",
+		"windows:\n- name: bad\n  start: 2020-05-01T22:00:00Z\n  duration: 10\n  scope:\n    source_addresses: [pgw1]\n",
+		"windows:\n- name: bad\n  unknown: true\n",
+	}
+	for _, content := range invalid {
+		require.NoError(t, ioutil.WriteFile(tmpFile.Name(), []byte(content), 0644))
+		require.Error(t, s.Load(), content)
+		require.Len(t, s.Windows(time.Now()), 3)
+	}
+
+	require.NoError(t, ioutil.WriteFile(tmpFile.Name(), []byte("windows:\n- name: ok\n  start: 2020-05-01T22:00:00Z\n  duration: 10\n"), 0644))
+	require.NoError(t, s.Load())
+	statuses := s.Windows(time.Date(2020, 5, 1, 22, 5, 0, 0, time.UTC))
+	require.Len(t, statuses, 1)
+	require.True(t, statuses[0].Active)
+	require.Equal(t, time.Date(2020, 5, 1, 22, 10, 0, 0, time.UTC), statuses[0].End)
+}
+
+// Test windows are reloaded when the schedule file is replaced by rename.
+func TestScheduleWatchRename(t *testing.T) {
+	dir, err := ioutil.TempDir("", "maintenance")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "maintenance.yaml")
+	require.NoError(t, ioutil.WriteFile(path, []byte("windows: []\n"), 0644))
+	s, err := maintenance.NewSchedule(path, logger.New())
+	require.NoError(t, err)
+	require.NoError(t, s.Watch())
+	defer s.Unwatch()
+	require.Empty(t, s.Windows(time.Now()))
+
+	tmp := filepath.Join(dir, "maintenance.yaml.tmp")
+	require.NoError(t, ioutil.WriteFile(tmp, []byte("windows:\n- name: ok\n  start: 2020-05-01T22:00:00Z\n  duration: 10\n"), 0644))
+	require.NoError(t, os.Rename(tmp, path))
+	require.Eventually(t, func() bool {
+		return len(s.Windows(time.Now())) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+// Test windows are restricted to allowed actions.
+func TestScheduleActions(t *testing.T) {
+	s := &maintenance.Schedule{
+		Path:    scheduleFile,
+		Log:     logger.New(),
+		Actions: []of.MaintenanceAction{of.MaintenanceDrop, of.MaintenanceTag},
+	}
+	err := s.Load()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `Action "delay" is not supported`)
+	require.Empty(t, s.Windows(time.Now()))
+
+	s.Actions = append(s.Actions, of.MaintenanceDelay)
+	require.NoError(t, s.Load())
+	require.Len(t, s.Windows(time.Now()), 3)
+}
+
+// Test listing windows over HTTP.
+func TestScheduleHandler(t *testing.T) {
+	s, err := maintenance.NewSchedule(scheduleFile, logger.New())
+	require.NoError(t, err)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.Handler(http_v2.NewResponseWriter(w), r)
+	}))
+	defer ts.Close()
+
+	res, err := http.Get(ts.URL)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, "application/json", res.Header.Get("Content-Type"))
+
+	var body struct {
+		Windows []of.MaintenanceStatus `json:"windows"`
+	}
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&body))
+	require.Len(t, body.Windows, 3)
+	require.Equal(t, "pgw1-upgrade", body.Windows[0].Name)
+	require.False(t, body.Windows[0].Active)
+	require.Equal(t, []string{"lab-aci"}, body.Windows[2].Scope.ACIClusters)
+}
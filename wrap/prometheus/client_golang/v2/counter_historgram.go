@@ -28,16 +28,21 @@ type HistogramVec struct {
 	Namespace string
 	Name      string
 	Help      string
+	Buckets   []float64 // Upper bounds of buckets, prometheus.DefBuckets if empty.
 	histVec   *promclient.HistogramVec
 }
 
 // Create a new histogram vec.
 func (c *HistogramVec) Create(labels []string) error {
+	buckets := c.Buckets
+	if len(buckets) == 0 {
+		buckets = promclient.DefBuckets
+	}
 	c.histVec = promclient.NewHistogramVec(promclient.HistogramOpts{
 		Namespace: c.Namespace,
 		Name:      c.Name,
 		Help:      c.Help,
-		Buckets:   promclient.DefBuckets,
+		Buckets:   buckets,
 	}, labels)
 
 	if c.histVec == nil {
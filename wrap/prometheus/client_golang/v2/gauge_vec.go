@@ -0,0 +1,52 @@
+// Copyright 2019 Cisco Systems, Inc.
+//
+// This work incorporates works covered by the following notice:
+//
+// Copyright 2018 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+import (
+	of "github.com/cisco-cx/of/pkg/v2"
+	promclient "github.com/prometheus/client_golang/prometheus"
+)
+
+// GaugeVec represents the options required for prometheus.GaugeVec
+// and reference to the created prometheus.GaugeVec.
+type GaugeVec struct {
+	Namespace string
+	Name      string
+	Help      string
+	gaugeVec  *promclient.GaugeVec
+}
+
+// Create a new gauge vec.
+func (g *GaugeVec) Create(labels []string) error {
+	g.gaugeVec = promclient.NewGaugeVec(promclient.GaugeOpts{
+		Namespace: g.Namespace,
+		Name:      g.Name,
+		Help:      g.Help,
+	}, labels)
+
+	if g.gaugeVec == nil {
+		return of.ErrGaugeVecCreateFailed
+	}
+	return promclient.Register(g.gaugeVec)
+}
+
+// Set gauge for given label and value.
+func (g *GaugeVec) Set(labels map[string]string, value float64) error {
+	g.gaugeVec.With(promclient.Labels(labels)).Set(value)
+	return nil
+}
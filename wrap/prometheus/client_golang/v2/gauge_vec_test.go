@@ -0,0 +1,47 @@
+// Copyright 2019 Cisco Systems, Inc.
+//
+// This work incorporates works covered by the following notice:
+//
+// Copyright 2018 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2_test
+
+import (
+	"testing"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+	promclient "github.com/cisco-cx/of/wrap/prometheus/client_golang/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Ensures GaugeVec implements of.GaugeVector
+func TestGaugeVecInterface(t *testing.T) {
+	var _ of.GaugeVector = &promclient.GaugeVec{}
+}
+
+// Ensure gauge can be set.
+func TestGaugeVecSet(t *testing.T) {
+	gaugeVec := promclient.GaugeVec{Namespace: "TestAppGaugeVec", Name: "test_gauge_set", Help: "This is a test gauge."}
+	err := gaugeVec.Create([]string{"request"})
+	require.NoError(t, err)
+	require.NoError(t, gaugeVec.Set(map[string]string{"request": "get"}, 10))
+	require.NoError(t, gaugeVec.Set(map[string]string{"request": "get"}, 5))
+	require.NoError(t, gaugeVec.Set(map[string]string{"request": "post"}, 20))
+
+	// Search metrics to check if value of gauge is the last set.
+	require.Contains(t, promMetrics(t), "TestAppGaugeVec_test_gauge_set{request=\"get\"} 5")
+	require.Contains(t, promMetrics(t), "TestAppGaugeVec_test_gauge_set{request=\"post\"} 20")
+	require.Panics(t, assert.PanicTestFunc(func() { gaugeVec.Set(map[string]string{"": ""}, 0) }))
+}
@@ -0,0 +1,477 @@
+package v2
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+	logger "github.com/cisco-cx/of/wrap/logrus/v2"
+	prometheus "github.com/cisco-cx/of/wrap/prometheus/client_golang/v2"
+)
+
+const (
+	walFile        = "queue.wal"
+	deadLetterFile = "dead-letters.ndjson"
+
+	// Operations written to the log.
+	opAdd  = "add"
+	opAck  = "ack"
+	opDead = "dead"
+
+	// Log is rewritten with pending batches only, once it holds this many records
+	// and most are of batches no longer pending.
+	compactRecords = 1000
+
+	// Interval at which the age gauge is updated, while waiting.
+	gaugeInterval = 5 * time.Second
+
+	// Metric names.
+	queueDepth      = "am_queue_depth"
+	queueOldestAge  = "am_queue_oldest_age_seconds"
+	queueDeliveries = "am_queue_deliveries_count"
+)
+
+// Represents a record of the write-ahead log.
+type walRecord struct {
+	Op    string          `json:"op"`
+	ID    uint64          `json:"id"`
+	Batch *of.QueuedBatch `json:"batch,omitempty"`
+}
+
+// Represents a pending batch.
+type batch struct {
+	of.QueuedBatch
+	next time.Time // Of the next attempt.
+}
+
+// Implements of.AlertQueue, writing batches to a write-ahead log in Dir before
+// they are posted. Failed posts are retried in order, with exponential backoff.
+// Batches older than MaxAge, or rejected by Alertmanager, are moved to a dead letter file.
+// Pending batches are read back from the log on restart; attempts are counted from 0 again.
+type Queue struct {
+	of.QueueConfig
+	Post       func([]of.Alert) error // Delivers alerts, like AlertService.Notify.
+	Clock      of.Clock
+	Log        *logger.Logger
+	Depth      *prometheus.GaugeVec
+	OldestAge  *prometheus.GaugeVec
+	Deliveries *prometheus.CounterVec // By result: delivered, retried, dead_lettered.
+
+	mu       sync.Mutex
+	wal      *os.File
+	closed   bool
+	records  int // In wal.
+	pending  []*batch
+	nextID   uint64
+	wake     chan struct{}
+	done     chan struct{}
+	finished chan struct{}
+}
+
+// Init Queue in cfg.Dir, creating its metrics in namespace and reading pending batches.
+func NewQueue(cfg *of.QueueConfig, namespace string, post func([]of.Alert) error, c of.Clock, l *logger.Logger) (*Queue, error) {
+	if err := os.MkdirAll(cfg.Dir, 0755); err != nil {
+		return nil, err
+	}
+
+	q := &Queue{
+		QueueConfig: *cfg,
+		Post:        post,
+		Clock:       c,
+		Log:         l,
+		Depth: &prometheus.GaugeVec{
+			Namespace: namespace,
+			Name:      queueDepth,
+			Help:      "Batches of alerts waiting for delivery to Alertmanager.",
+		},
+		OldestAge: &prometheus.GaugeVec{
+			Namespace: namespace,
+			Name:      queueOldestAge,
+			Help:      "Age of the oldest batch of alerts waiting for delivery to Alertmanager.",
+		},
+		Deliveries: &prometheus.CounterVec{
+			Namespace: namespace,
+			Name:      queueDeliveries,
+			Help:      "Attempts to deliver queued batches of alerts, by result.",
+		},
+		wake: make(chan struct{}, 1),
+	}
+	if err := q.Depth.Create([]string{}); err != nil {
+		return nil, err
+	}
+	if err := q.OldestAge.Create([]string{}); err != nil {
+		return nil, err
+	}
+	if err := q.Deliveries.Create([]string{"result"}); err != nil {
+		return nil, err
+	}
+	if err := q.open(); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// Read pending batches from the log, and rewrite it with them only.
+func (q *Queue) open() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	path := filepath.Join(q.Dir, walFile)
+	f, err := os.Open(path)
+	if err != nil && os.IsNotExist(err) == false {
+		return err
+	}
+	if err == nil {
+		err = q.replay(f)
+		f.Close()
+		if err != nil {
+			return err
+		}
+	}
+	if len(q.pending) > 0 {
+		q.Log.Infof("Read %d batches of alerts waiting for delivery.", len(q.pending))
+	}
+	return q.compact()
+}
+
+// Restore pending batches from records in r. A record cut short by a crash ends the log.
+func (q *Queue) replay(r io.Reader) error {
+	byID := make(map[uint64]*batch)
+	d := json.NewDecoder(r)
+	for {
+		var rec walRecord
+		err := d.Decode(&rec)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			q.Log.WithError(err).Errorf("Discarding unreadable end of alert queue log.")
+			break
+		}
+		if rec.ID >= q.nextID {
+			q.nextID = rec.ID + 1
+		}
+		switch rec.Op {
+		case opAdd:
+			if rec.Batch == nil {
+				return fmt.Errorf("alert queue log: batch %d has no alerts", rec.ID)
+			}
+			b := &batch{QueuedBatch: *rec.Batch}
+			byID[rec.ID] = b
+			q.pending = append(q.pending, b)
+		case opAck, opDead:
+			delete(byID, rec.ID)
+		}
+	}
+
+	pending := q.pending[:0]
+	for _, b := range q.pending {
+		if _, ok := byID[b.ID]; ok == true {
+			pending = append(pending, b)
+		}
+	}
+	q.pending = pending
+	return nil
+}
+
+// Rewrite the log with pending batches, and open it for appending.
+func (q *Queue) compact() error {
+	if q.closed == true {
+		return nil
+	}
+	path := filepath.Join(q.Dir, walFile)
+	tmp, err := os.OpenFile(path+".tmp", os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	e := json.NewEncoder(tmp)
+	for _, b := range q.pending {
+		qb := b.QueuedBatch
+		if err = e.Encode(&walRecord{Op: opAdd, ID: b.ID, Batch: &qb}); err != nil {
+			tmp.Close()
+			return err
+		}
+	}
+	if err = tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	if err = os.Rename(path+".tmp", path); err != nil {
+		return err
+	}
+
+	if q.wal != nil {
+		q.wal.Close()
+	}
+	q.wal, err = os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	q.records = len(q.pending)
+	return nil
+}
+
+// Append rec to the log, syncing it to disk if sync is set.
+func (q *Queue) write(rec *walRecord, sync bool) error {
+	if q.closed == true {
+		return of.ErrQueueClosed
+	}
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	if _, err = q.wal.Write(append(b, '\n')); err != nil {
+		return err
+	}
+	q.records++
+	if sync == true {
+		return q.wal.Sync()
+	}
+	return nil
+}
+
+// Enqueue alerts for delivery. Alerts are on disk once this returns.
+// Fails with of.ErrQueueClosed after Close.
+func (q *Queue) Notify(alerts *[]of.Alert) error {
+	if len(*alerts) == 0 {
+		return nil
+	}
+
+	q.mu.Lock()
+	if q.closed == true {
+		q.mu.Unlock()
+		return of.ErrQueueClosed
+	}
+	b := &batch{QueuedBatch: of.QueuedBatch{
+		ID:         q.nextID,
+		EnqueuedAt: q.Clock.Now().UTC(),
+		Alerts:     append([]of.Alert(nil), (*alerts)...),
+	}}
+	qb := b.QueuedBatch
+	err := q.write(&walRecord{Op: opAdd, ID: b.ID, Batch: &qb}, true)
+	if err == nil {
+		q.nextID++
+		q.pending = append(q.pending, b)
+	} else if cerr := q.compact(); cerr != nil {
+		// Drop a record written in part, so the log stays readable.
+		q.Log.WithError(cerr).Errorf("Failed to compact alert queue log.")
+	}
+	q.mu.Unlock()
+
+	if err != nil {
+		return err
+	}
+	q.updateGauges()
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Batches waiting for delivery, oldest first.
+func (q *Queue) Pending() []of.QueuedBatch {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	batches := make([]of.QueuedBatch, len(q.pending))
+	for i, b := range q.pending {
+		batches[i] = b.QueuedBatch
+	}
+	return batches
+}
+
+// Deliver due batches in order, stopping at the first that fails, so alerts
+// are not reordered. Returns when the next attempt is due, zero if the queue is empty.
+func (q *Queue) Deliver() time.Time {
+	defer q.updateGauges()
+	for {
+		q.mu.Lock()
+		if q.closed == true || len(q.pending) == 0 {
+			q.mu.Unlock()
+			return time.Time{}
+		}
+		b := q.pending[0]
+		now := q.Clock.Now()
+		if q.MaxAge > 0 && now.Sub(b.EnqueuedAt) > q.MaxAge {
+			q.deadLetter(b, "max age exceeded")
+			q.mu.Unlock()
+			continue
+		}
+		if now.Before(b.next) {
+			q.mu.Unlock()
+			return b.next
+		}
+		alerts := b.Alerts
+		q.mu.Unlock()
+
+		// Posting may take long, new batches are accepted meanwhile.
+		err := q.Post(alerts)
+
+		q.mu.Lock()
+		b.Attempts++
+		switch {
+		case err == nil:
+			q.remove(b, opAck)
+			q.count("delivered")
+		case permanent(err):
+			b.LastError = err.Error()
+			q.deadLetter(b, "rejected by Alertmanager")
+		default:
+			b.LastError = err.Error()
+			b.next = q.Clock.Now().Add(q.backoff(b.Attempts))
+			q.count("retried")
+			q.Log.WithError(err).Errorf("Failed to deliver %d queued alerts, attempt %d, retrying at %s.", len(b.Alerts), b.Attempts, b.next.Format(time.RFC3339))
+			q.mu.Unlock()
+			return b.next
+		}
+		q.mu.Unlock()
+	}
+}
+
+// Wait before attempt+1, doubling from MinBackoff up to MaxBackoff.
+func (q *Queue) backoff(attempt int) time.Duration {
+	d := q.MinBackoff
+	if d <= 0 {
+		d = time.Second
+	}
+	for i := 1; i < attempt; i++ {
+		d *= 2
+		if q.MaxBackoff > 0 && d >= q.MaxBackoff {
+			return q.MaxBackoff
+		}
+	}
+	return d
+}
+
+// Move b to the dead letter file. Must hold q.mu.
+func (q *Queue) deadLetter(b *batch, reason string) {
+	dl := of.DeadLetter{QueuedBatch: b.QueuedBatch, Time: q.Clock.Now().UTC(), Reason: reason}
+	if err := q.appendDeadLetter(&dl); err != nil {
+		q.Log.WithError(err).Errorf("Failed to write dead letter, dropping %d alerts.", len(b.Alerts))
+	}
+	q.Log.WithField("last_error", b.LastError).Errorf("Gave up delivering %d queued alerts after %d attempts, %s.", len(b.Alerts), b.Attempts, reason)
+	q.remove(b, opDead)
+	q.count("dead_lettered")
+}
+
+func (q *Queue) appendDeadLetter(dl *of.DeadLetter) error {
+	f, err := os.OpenFile(filepath.Join(q.Dir, deadLetterFile), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(dl)
+	if err == nil {
+		_, err = f.Write(append(b, '\n'))
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// Remove b, the first pending batch, recording op. Must hold q.mu.
+func (q *Queue) remove(b *batch, op string) {
+	q.pending = q.pending[1:]
+	// A lost ack only causes the batch to be posted again.
+	if err := q.write(&walRecord{Op: op, ID: b.ID}, false); err != nil {
+		q.Log.WithError(err).Errorf("Failed to write to alert queue log.")
+	}
+	if len(q.pending) == 0 || (q.records >= compactRecords && q.records > 2*len(q.pending)) {
+		if err := q.compact(); err != nil {
+			q.Log.WithError(err).Errorf("Failed to compact alert queue log.")
+		}
+	}
+}
+
+func (q *Queue) count(result string) {
+	if q.Deliveries != nil {
+		q.Deliveries.Incr(map[string]string{"result": result})
+	}
+}
+
+// Set depth and age of the oldest batch.
+func (q *Queue) updateGauges() {
+	q.mu.Lock()
+	depth := len(q.pending)
+	var age time.Duration
+	if depth > 0 {
+		age = q.Clock.Now().Sub(q.pending[0].EnqueuedAt)
+	}
+	q.mu.Unlock()
+
+	if q.Depth != nil {
+		q.Depth.Set(map[string]string{}, float64(depth))
+	}
+	if q.OldestAge != nil {
+		q.OldestAge.Set(map[string]string{}, age.Seconds())
+	}
+}
+
+// Deliver batches in background, until Close.
+func (q *Queue) Start() {
+	q.done = make(chan struct{})
+	q.finished = make(chan struct{})
+	go func() {
+		defer close(q.finished)
+		for {
+			wait := gaugeInterval
+			if next := q.Deliver(); next.IsZero() == false {
+				if d := next.Sub(q.Clock.Now()); d < wait {
+					wait = d
+				}
+			}
+			timer := time.NewTimer(wait)
+			select {
+			case <-q.wake:
+			case <-timer.C:
+			case <-q.done:
+				timer.Stop()
+				return
+			}
+			timer.Stop()
+		}
+	}()
+}
+
+// Stop delivering, waiting for a post in progress, and close the log.
+// Pending batches are delivered after restart.
+func (q *Queue) Close() error {
+	if q.done != nil {
+		close(q.done)
+		<-q.finished
+		q.done = nil
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.closed = true
+	if q.wal == nil {
+		return nil
+	}
+	err := q.wal.Close()
+	q.wal = nil
+	return err
+}
+
+// Check if Alertmanager rejected alerts, so posting them again would fail too.
+func permanent(err error) bool {
+	switch e := err.(type) {
+	case *of.AMError:
+		return e.StatusCode >= 400 && e.StatusCode < 500 && e.StatusCode != 429
+	case *of.AMPeersError:
+		for _, peerErr := range e.Errors {
+			if permanent(peerErr) == false {
+				return false
+			}
+		}
+		return len(e.Errors) > 0
+	}
+	return false
+}
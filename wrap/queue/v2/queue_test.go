@@ -0,0 +1,222 @@
+package v2_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+	logger "github.com/cisco-cx/of/wrap/logrus/v2"
+	queue "github.com/cisco-cx/of/wrap/queue/v2"
+	clock "github.com/cisco-cx/of/wrap/time/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// Enforce AlertQueue Interface
+func TestQueueInterface(t *testing.T) {
+	var _ of.AlertQueue = &queue.Queue{}
+}
+
+// Records posts, failing with err.
+type testPoster struct {
+	mu     sync.Mutex
+	posted [][]of.Alert
+	err    error
+}
+
+func (p *testPoster) Post(alerts []of.Alert) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err != nil {
+		return p.err
+	}
+	p.posted = append(p.posted, alerts)
+	return nil
+}
+
+func (p *testPoster) Posted() [][]of.Alert {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.posted
+}
+
+// Queue in a new temporary dir, with metrics in namespace.
+func newTestQueue(t *testing.T, namespace string, c of.Clock, p *testPoster) (*queue.Queue, string) {
+	dir, err := ioutil.TempDir("", "alert-queue")
+	require.NoError(t, err)
+	return openTestQueue(t, dir, namespace, c, p), dir
+}
+
+func openTestQueue(t *testing.T, dir string, namespace string, c of.Clock, p *testPoster) *queue.Queue {
+	cfg := &of.QueueConfig{Dir: dir, MaxAge: time.Hour, MinBackoff: time.Second, MaxBackoff: 4 * time.Second}
+	q, err := queue.NewQueue(cfg, namespace, p.Post, c, logger.New())
+	require.NoError(t, err)
+	return q
+}
+
+// Alerts named names.
+func queueAlerts(names ...string) []of.Alert {
+	alerts := make([]of.Alert, len(names))
+	for i, name := range names {
+		alerts[i] = of.Alert{Labels: map[string]string{"alertname": name}}
+	}
+	return alerts
+}
+
+// Dead letters written to dir.
+func deadLetters(t *testing.T, dir string) []of.DeadLetter {
+	b, err := ioutil.ReadFile(filepath.Join(dir, "dead-letters.ndjson"))
+	require.NoError(t, err)
+	var letters []of.DeadLetter
+	d := json.NewDecoder(bytes.NewReader(b))
+	for d.More() {
+		var dl of.DeadLetter
+		require.NoError(t, d.Decode(&dl))
+		letters = append(letters, dl)
+	}
+	return letters
+}
+
+// Test batches are delivered in order.
+func TestQueueDeliver(t *testing.T) {
+	c := &clock.FixedClock{T: time.Date(2019, 4, 26, 3, 46, 57, 0, time.UTC)}
+	p := &testPoster{}
+	q, dir := newTestQueue(t, "TestQueueDeliver", c, p)
+	defer os.RemoveAll(dir)
+	defer q.Close()
+
+	a, b := queueAlerts("a"), queueAlerts("b", "c")
+	require.NoError(t, q.Notify(&a))
+	require.NoError(t, q.Notify(&b))
+	require.Len(t, q.Pending(), 2)
+	require.Equal(t, c.T, q.Pending()[0].EnqueuedAt)
+
+	require.True(t, q.Deliver().IsZero())
+	require.Equal(t, [][]of.Alert{a, b}, p.Posted())
+	require.Empty(t, q.Pending())
+
+	// Log is emptied once all batches are delivered.
+	info, err := os.Stat(filepath.Join(dir, "queue.wal"))
+	require.NoError(t, err)
+	require.Equal(t, int64(0), info.Size())
+}
+
+// Test failed batches are retried with exponential backoff, in order.
+func TestQueueBackoff(t *testing.T) {
+	c := &clock.FixedClock{T: time.Date(2019, 4, 26, 3, 46, 57, 0, time.UTC)}
+	p := &testPoster{err: errors.New("connection refused")}
+	q, dir := newTestQueue(t, "TestQueueBackoff", c, p)
+	defer os.RemoveAll(dir)
+	defer q.Close()
+
+	a, b := queueAlerts("a"), queueAlerts("b")
+	require.NoError(t, q.Notify(&a))
+	require.NoError(t, q.Notify(&b))
+
+	start := c.T
+	for _, backoff := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 4 * time.Second} {
+		next := q.Deliver()
+		require.Equal(t, c.T.Add(backoff), next)
+		// Not retried before next attempt is due.
+		require.Equal(t, next, q.Deliver())
+		c.T = next
+	}
+	pending := q.Pending()
+	require.Len(t, pending, 2)
+	require.Equal(t, 4, pending[0].Attempts)
+	require.Equal(t, "connection refused", pending[0].LastError)
+	require.Equal(t, 0, pending[1].Attempts)
+
+	p.err = nil
+	require.True(t, q.Deliver().IsZero())
+	require.Equal(t, [][]of.Alert{a, b}, p.Posted())
+	require.True(t, c.T.Sub(start) < time.Hour)
+}
+
+// Test batches past max age, or rejected by Alertmanager, are dead-lettered.
+func TestQueueDeadLetter(t *testing.T) {
+	c := &clock.FixedClock{T: time.Date(2019, 4, 26, 3, 46, 57, 0, time.UTC)}
+	p := &testPoster{err: errors.New("connection refused")}
+	q, dir := newTestQueue(t, "TestQueueDeadLetter", c, p)
+	defer os.RemoveAll(dir)
+	defer q.Close()
+
+	a := queueAlerts("a")
+	require.NoError(t, q.Notify(&a))
+	q.Deliver()
+	c.Add(time.Hour + time.Second)
+	require.True(t, q.Deliver().IsZero())
+	require.Empty(t, q.Pending())
+
+	p.err = &of.AMError{URL: "http://am", StatusCode: 400, Message: "bad alerts"}
+	b := queueAlerts("b")
+	require.NoError(t, q.Notify(&b))
+	require.True(t, q.Deliver().IsZero())
+
+	letters := deadLetters(t, dir)
+	require.Len(t, letters, 2)
+	require.Equal(t, "max age exceeded", letters[0].Reason)
+	require.Equal(t, 1, letters[0].Attempts)
+	require.Equal(t, "connection refused", letters[0].LastError)
+	require.Equal(t, a, letters[0].Alerts)
+	require.Equal(t, "rejected by Alertmanager", letters[1].Reason)
+	require.Equal(t, b, letters[1].Alerts)
+}
+
+// Test pending batches are delivered after restart, and a cut short record is discarded.
+func TestQueueRestart(t *testing.T) {
+	c := &clock.FixedClock{T: time.Date(2019, 4, 26, 3, 46, 57, 0, time.UTC)}
+	p := &testPoster{}
+	q, dir := newTestQueue(t, "TestQueueRestart", c, p)
+	defer os.RemoveAll(dir)
+
+	a, b, d := queueAlerts("a"), queueAlerts("b"), queueAlerts("d")
+	require.NoError(t, q.Notify(&a))
+	require.True(t, q.Deliver().IsZero())
+	require.NoError(t, q.Notify(&b))
+	require.NoError(t, q.Notify(&d))
+	require.NoError(t, q.Close())
+
+	f, err := os.OpenFile(filepath.Join(dir, "queue.wal"), os.O_APPEND|os.O_WRONLY, 0644)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"op":"add","id":9,"batch":{"id":9,`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	p = &testPoster{}
+	q = openTestQueue(t, dir, "TestQueueRestart_restarted", c, p)
+	defer q.Close()
+	require.Len(t, q.Pending(), 2)
+	require.True(t, q.Deliver().IsZero())
+	require.Equal(t, [][]of.Alert{b, d}, p.Posted())
+
+	// New batches do not reuse ids.
+	require.NoError(t, q.Notify(&a))
+	require.Equal(t, uint64(3), q.Pending()[0].ID)
+}
+
+// Test batches are delivered in background once queued.
+func TestQueueStart(t *testing.T) {
+	p := &testPoster{}
+	q, dir := newTestQueue(t, "TestQueueStart", &clock.Clock{}, p)
+	defer os.RemoveAll(dir)
+	q.Start()
+
+	a := queueAlerts("a")
+	require.NoError(t, q.Notify(&a))
+	require.Eventually(t, func() bool {
+		return len(p.Posted()) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, q.Close())
+
+	// Closed queues refuse alerts, and leave the log alone.
+	require.Equal(t, of.ErrQueueClosed, q.Notify(&a))
+	require.Empty(t, q.Pending())
+	require.True(t, q.Deliver().IsZero())
+}
@@ -0,0 +1,90 @@
+package v2
+
+import (
+	"sync"
+	"time"
+)
+
+// Implements of.RateLimiter as a token bucket.
+// Bucket holds up to `burst` tokens and is refilled at `rate` tokens per second.
+type TokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// Init a full token bucket.
+func NewTokenBucket(rate float64, burst int) *TokenBucket {
+	if burst < 1 {
+		burst = 1
+	}
+	return &TokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+	}
+}
+
+// Check if a single event may happen at t.
+func (b *TokenBucket) Allow(t time.Time) bool {
+	return b.AllowN(t, 1)
+}
+
+// Check if n events may happen at t. Tokens are consumed only if allowed.
+func (b *TokenBucket) AllowN(t time.Time, n int) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill(t)
+	if b.tokens < float64(n) {
+		return false
+	}
+	b.tokens -= float64(n)
+	return true
+}
+
+// Take n tokens at t, returning the wait until they are available. Tokens may
+// go below zero, so later callers wait their turn. Returns 0 if rate is not above 0.
+func (b *TokenBucket) Reserve(t time.Time, n int) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.rate <= 0 {
+		return 0
+	}
+	b.refill(t)
+	b.tokens -= float64(n)
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// Number of tokens available at t.
+func (b *TokenBucket) Tokens(t time.Time) float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill(t)
+	return b.tokens
+}
+
+// Add tokens for the time elapsed since last refill.
+func (b *TokenBucket) refill(t time.Time) {
+	if b.last.IsZero() {
+		b.last = t
+		return
+	}
+
+	elapsed := t.Sub(b.last).Seconds()
+	if elapsed <= 0 {
+		return
+	}
+	b.last = t
+	b.tokens += elapsed * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+}
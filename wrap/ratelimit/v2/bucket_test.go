@@ -0,0 +1,62 @@
+package v2_test
+
+import (
+	"testing"
+	"time"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+	ratelimit "github.com/cisco-cx/of/wrap/ratelimit/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// Enforce interface implementation.
+func TestTokenBucketInterface(t *testing.T) {
+	var _ of.RateLimiter = &ratelimit.TokenBucket{}
+}
+
+// Test burst and refill of token bucket.
+func TestTokenBucket(t *testing.T) {
+	now := time.Date(2020, 5, 1, 22, 22, 53, 0, time.UTC)
+	b := ratelimit.NewTokenBucket(2, 3)
+
+	// Burst is available at start.
+	for i := 0; i < 3; i++ {
+		require.True(t, b.Allow(now))
+	}
+	require.False(t, b.Allow(now))
+
+	// Half a second refills one token at 2/s.
+	now = now.Add(500 * time.Millisecond)
+	require.True(t, b.Allow(now))
+	require.False(t, b.Allow(now))
+
+	// Refill never exceeds burst.
+	now = now.Add(time.Minute)
+	require.Equal(t, float64(3), b.Tokens(now))
+	require.False(t, b.AllowN(now, 4))
+	require.True(t, b.AllowN(now, 3))
+}
+
+// Test bucket with burst less than 1.
+func TestTokenBucketMinBurst(t *testing.T) {
+	now := time.Date(2020, 5, 1, 22, 22, 53, 0, time.UTC)
+	b := ratelimit.NewTokenBucket(1, 0)
+	require.True(t, b.Allow(now))
+	require.False(t, b.Allow(now))
+}
+
+// Test reserved tokens are waited for in turn.
+func TestTokenBucketReserve(t *testing.T) {
+	now := time.Date(2020, 5, 1, 22, 22, 53, 0, time.UTC)
+	b := ratelimit.NewTokenBucket(2, 1)
+
+	require.Equal(t, time.Duration(0), b.Reserve(now, 1))
+	require.Equal(t, 500*time.Millisecond, b.Reserve(now, 1))
+	require.Equal(t, time.Second, b.Reserve(now, 1))
+
+	now = now.Add(time.Second)
+	require.Equal(t, 500*time.Millisecond, b.Reserve(now, 1))
+
+	// No waits without rate.
+	require.Equal(t, time.Duration(0), ratelimit.NewTokenBucket(0, 1).Reserve(now, 5))
+}
@@ -0,0 +1,176 @@
+package v2
+
+import (
+	"sync"
+	"time"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+	logger "github.com/cisco-cx/of/wrap/logrus/v2"
+	prometheus "github.com/cisco-cx/of/wrap/prometheus/client_golang/v2"
+)
+
+const (
+	// HistogramVec names.
+	limiterWaitSeconds = "limiter_wait_seconds"
+	limiterBatchSize   = "limiter_batch_size"
+
+	// Backoff after the first overloaded response, if MinBackoff is not set.
+	defaultMinBackoff = time.Second
+)
+
+// Implements of.BatchLimiter, spacing posts with a token bucket and running up
+// to MaxConcurrency at once. After overloaded responses, posts wait a backoff,
+// doubled while they continue and cleared once a post succeeds.
+type Limiter struct {
+	of.BatchLimitConfig
+	Clock     of.Clock
+	Sleep     func(time.Duration) // Defaults to time.Sleep.
+	Log       *logger.Logger
+	WaitTime  *prometheus.HistogramVec // Seconds posts waited, if set.
+	BatchSize *prometheus.HistogramVec // Items per post, if set.
+
+	bucket  *TokenBucket
+	slots   chan struct{}
+	mu      sync.Mutex
+	backoff time.Duration
+}
+
+// Init Limiter with cfg, creating its metrics in namespace.
+func NewLimiter(cfg *of.BatchLimitConfig, namespace string, c of.Clock, l *logger.Logger) (*Limiter, error) {
+	waitTime := &prometheus.HistogramVec{
+		Namespace: namespace,
+		Name:      limiterWaitSeconds,
+		Help:      "Seconds posts waited for their turn.",
+	}
+	if err := waitTime.Create([]string{}); err != nil {
+		return nil, err
+	}
+	batchSize := &prometheus.HistogramVec{
+		Namespace: namespace,
+		Name:      limiterBatchSize,
+		Help:      "Number of items per post.",
+		Buckets:   []float64{1, 10, 50, 100, 250, 500, 1000, 2500, 5000},
+	}
+	if err := batchSize.Create([]string{}); err != nil {
+		return nil, err
+	}
+
+	lm := &Limiter{
+		BatchLimitConfig: *cfg,
+		Clock:            c,
+		Log:              l,
+		WaitTime:         waitTime,
+		BatchSize:        batchSize,
+	}
+	if cfg.Rate > 0 {
+		lm.bucket = NewTokenBucket(cfg.Rate, 1)
+	}
+	concurrency := cfg.MaxConcurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	lm.slots = make(chan struct{}, concurrency)
+	return lm, nil
+}
+
+// Post n items in batches of up to MaxBatch, waiting for their turn.
+// Returns the last error, after all batches were posted.
+func (l *Limiter) Do(n int, post func(start int, end int) error) error {
+	size := n
+	if l.MaxBatch > 0 && l.MaxBatch < n {
+		size = l.MaxBatch
+	}
+
+	var wg sync.WaitGroup
+	var errMu sync.Mutex
+	var lastErr error
+	for start := 0; start < n; start += size {
+		end := start + size
+		if end > n {
+			end = n
+		}
+
+		waitStart := l.Clock.Now()
+		l.slots <- struct{}{}
+		l.wait()
+		l.observe(l.WaitTime, l.Clock.Now().Sub(waitStart).Seconds())
+		l.observe(l.BatchSize, float64(end-start))
+
+		wg.Add(1)
+		go func(start int, end int) {
+			defer func() {
+				<-l.slots
+				wg.Done()
+			}()
+			err := post(start, end)
+			l.adapt(err)
+			if err != nil {
+				errMu.Lock()
+				lastErr = err
+				errMu.Unlock()
+			}
+		}(start, end)
+	}
+	wg.Wait()
+	return lastErr
+}
+
+// Current backoff, 0 unless the receiver is overloaded.
+func (l *Limiter) Backoff() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.backoff
+}
+
+// Wait for a token, and the backoff.
+func (l *Limiter) wait() {
+	var d time.Duration
+	if l.bucket != nil {
+		d = l.bucket.Reserve(l.Clock.Now(), 1)
+	}
+	if backoff := l.Backoff(); backoff > d {
+		d = backoff
+	}
+	if d <= 0 {
+		return
+	}
+	sleep := l.Sleep
+	if sleep == nil {
+		sleep = time.Sleep
+	}
+	sleep(d)
+}
+
+// Grow backoff on overloaded responses, clear it on success.
+// Other errors, like refused connections, leave it as is.
+func (l *Limiter) adapt(err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err == nil {
+		l.backoff = 0
+		return
+	}
+	oe, ok := err.(of.OverloadError)
+	if ok == false || oe.Overloaded() == false {
+		return
+	}
+	switch {
+	case l.backoff == 0 && l.MinBackoff > 0:
+		l.backoff = l.MinBackoff
+	case l.backoff == 0:
+		l.backoff = defaultMinBackoff
+	default:
+		l.backoff *= 2
+	}
+	if l.MaxBackoff > 0 && l.backoff > l.MaxBackoff {
+		l.backoff = l.MaxBackoff
+	}
+	l.Log.WithError(err).Debugf("Receiver overloaded, waiting %s between posts.", l.backoff)
+}
+
+func (l *Limiter) observe(h *prometheus.HistogramVec, v float64) {
+	if h != nil {
+		h.Observed([]string{}, v)
+	}
+}
@@ -0,0 +1,114 @@
+package v2_test
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+	logger "github.com/cisco-cx/of/wrap/logrus/v2"
+	ratelimit "github.com/cisco-cx/of/wrap/ratelimit/v2"
+	clock "github.com/cisco-cx/of/wrap/time/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// Enforce interface implementation.
+func TestLimiterInterface(t *testing.T) {
+	var _ of.BatchLimiter = &ratelimit.Limiter{}
+}
+
+// Limiter with metrics in namespace, recording waits on a fixed clock.
+func newTestLimiter(t *testing.T, namespace string, cfg of.BatchLimitConfig) (*ratelimit.Limiter, *[]time.Duration) {
+	c := &clock.FixedClock{T: time.Date(2020, 5, 1, 22, 22, 53, 0, time.UTC)}
+	l, err := ratelimit.NewLimiter(&cfg, namespace, c, logger.New())
+	require.NoError(t, err)
+	var mu sync.Mutex
+	var waits []time.Duration
+	l.Sleep = func(d time.Duration) {
+		mu.Lock()
+		defer mu.Unlock()
+		waits = append(waits, d)
+		c.Add(d)
+	}
+	return l, &waits
+}
+
+// Test items are posted in batches, spaced by rate.
+func TestLimiter(t *testing.T) {
+	l, waits := newTestLimiter(t, "TestLimiter", of.BatchLimitConfig{Rate: 4, MaxBatch: 200})
+
+	var batches [][2]int
+	err := l.Do(450, func(start int, end int) error {
+		batches = append(batches, [2]int{start, end})
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, [][2]int{{0, 200}, {200, 400}, {400, 450}}, batches)
+	require.Equal(t, []time.Duration{250 * time.Millisecond, 250 * time.Millisecond}, *waits)
+
+	// Nothing is posted for no items.
+	require.NoError(t, l.Do(0, func(int, int) error {
+		t.Fatal("Posted no items.")
+		return nil
+	}))
+}
+
+// Test backoff grows on overloaded responses, and is cleared on success.
+func TestLimiterBackoff(t *testing.T) {
+	l, waits := newTestLimiter(t, "TestLimiterBackoff", of.BatchLimitConfig{
+		MaxBatch:   1,
+		MinBackoff: time.Second,
+		MaxBackoff: 3 * time.Second,
+	})
+
+	overloaded := &of.AMError{URL: "http://am", StatusCode: 503, Message: "unavailable"}
+	results := []error{overloaded, overloaded, errors.New("connection refused"), overloaded, overloaded, nil, nil}
+	var posts int
+	err := l.Do(len(results), func(start int, end int) error {
+		posts++
+		return results[start]
+	})
+	require.Equal(t, overloaded, err, "Last error is returned.")
+	require.Equal(t, len(results), posts)
+	require.Equal(t, []time.Duration{time.Second, 2 * time.Second, 2 * time.Second, 3 * time.Second, 3 * time.Second}, *waits)
+	require.Equal(t, time.Duration(0), l.Backoff())
+
+	// Client errors are not overload.
+	l.Do(1, func(int, int) error {
+		return &of.AMError{StatusCode: 400}
+	})
+	require.Equal(t, time.Duration(0), l.Backoff())
+
+	// Backoff starts over once cleared.
+	err = l.Do(2, func(start int, end int) error {
+		if start == 1 {
+			return overloaded
+		}
+		return nil
+	})
+	require.Equal(t, overloaded, err)
+	require.Equal(t, time.Second, l.Backoff())
+}
+
+// Test posts in flight are limited.
+func TestLimiterConcurrency(t *testing.T) {
+	l, _ := newTestLimiter(t, "TestLimiterConcurrency", of.BatchLimitConfig{MaxBatch: 1, MaxConcurrency: 3})
+
+	var inFlight, max int32
+	require.NoError(t, l.Do(20, func(int, int) error {
+		n := atomic.AddInt32(&inFlight, 1)
+		for {
+			m := atomic.LoadInt32(&max)
+			if n <= m || atomic.CompareAndSwapInt32(&max, m, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		atomic.AddInt32(&inFlight, -1)
+		return nil
+	}))
+	require.True(t, max > 1)
+	require.True(t, max <= 3)
+}
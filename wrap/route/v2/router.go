@@ -0,0 +1,403 @@
+package v2
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+	logger "github.com/cisco-cx/of/wrap/logrus/v2"
+	sink "github.com/cisco-cx/of/wrap/sink/v2"
+	watcher "github.com/cisco-cx/of/wrap/watcher/v2"
+	yaml "github.com/cisco-cx/of/wrap/yaml/v2"
+)
+
+// Creates the notifier posting alerts of receiver name to its Alertmanager peers.
+type AMFactory func(name string, peers []string, policy of.AMSuccessPolicy) (of.Notifier, error)
+
+// Implements of.Notifier, sending alerts to receivers by their labels,
+// with the routing tree from a routes file.
+type Router struct {
+	Path    string
+	Default of.Notifier // Receives alerts routed to the default receiver.
+	NewAM   AMFactory
+	Version string    // Sent to webhooks.
+	Closer  io.Closer // Closed with the router if set, like retry queues kept across reloads by NewAM.
+	Log     *logger.Logger
+
+	mu        sync.RWMutex
+	tree      *route
+	receivers map[string]of.Notifier // Without the default receiver.
+	fs        *watcher.Notifier
+}
+
+// Represents a parsed route.
+type route struct {
+	receiver string
+	match    map[string]string
+	matchRE  map[string]*regexp.Regexp
+	cont     bool
+	routes   []*route
+}
+
+// Init Router, loading routes from path.
+func NewRouter(path string, def of.Notifier, newAM AMFactory, version string, l *logger.Logger) (*Router, error) {
+	r := &Router{Path: path, Default: def, NewAM: newAM, Version: version, Log: l}
+	if err := r.Load(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Load routes and receivers from the routes file. Current ones are kept, if the file is invalid.
+func (r *Router) Load() error {
+	f, err := os.Open(r.Path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	rc := yaml.Routes{}
+	if err = rc.Decode(f); err != nil {
+		return err
+	}
+	cfg := of.RoutesConfig(rc)
+	tree, err := parseRoutes(&cfg)
+	if err != nil {
+		return err
+	}
+	receivers, err := r.newReceivers(cfg.Receivers)
+	if err != nil {
+		return err
+	}
+
+	// Swap once alerts being routed are sent, so old receivers can be closed.
+	r.mu.Lock()
+	old := r.receivers
+	r.tree, r.receivers = tree, receivers
+	r.mu.Unlock()
+	closeReceivers(old, r.Log)
+
+	r.Log.WithField("path", r.Path).Infof("Loaded routes with %d receivers.", len(receivers))
+	return nil
+}
+
+// Reload routes when the routes file changes. Its dir is watched,
+// so a file replaced by rename or through a symlink, like a mounted ConfigMap, is seen too.
+func (r *Router) Watch() error {
+	fs, err := watcher.NewPath(filepath.Dir(r.Path), r.Log)
+	if err != nil {
+		return err
+	}
+	if err = fs.Watch(); err != nil {
+		return err
+	}
+	r.fs = fs
+
+	name := filepath.Base(r.Path)
+	go func() {
+		for path := range fs.Changed {
+			// ..data is swapped when a mounted ConfigMap is updated.
+			if base := filepath.Base(path); base != name && base != "..data" {
+				continue
+			}
+			if err := r.Load(); err != nil {
+				r.Log.WithError(err).Errorf("Failed to reload routes, keeping current ones.")
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop watching the routes file.
+func (r *Router) Unwatch() error {
+	if r.fs == nil {
+		return nil
+	}
+	return r.fs.Unwatch()
+}
+
+// Receivers of alert with labels, in routing tree order.
+func (r *Router) Route(labels map[string]string) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.tree.route(labels)
+}
+
+// Send alerts to their receivers concurrently, in a single batch per receiver.
+// A single receiver fails with its own error, otherwise failures are returned as *of.SinksError.
+func (r *Router) Notify(alerts *[]of.Alert) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var names []string
+	batches := make(map[string][]of.Alert)
+	for _, alert := range *alerts {
+		for _, name := range r.tree.route(alert.Labels) {
+			if _, ok := batches[name]; ok == false {
+				names = append(names, name)
+			}
+			batches[name] = append(batches[name], alert)
+		}
+	}
+
+	errs := make([]error, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		n := r.Default
+		if name != of.DefaultReceiver {
+			n = r.receivers[name]
+		}
+		batch := batches[name]
+		wg.Add(1)
+		go func(i int, n of.Notifier) {
+			defer wg.Done()
+			errs[i] = n.Notify(&batch)
+		}(i, n)
+	}
+	wg.Wait()
+
+	failed := make(map[string]error)
+	for i, name := range names {
+		if errs[i] != nil {
+			failed[name] = errs[i]
+			r.Log.WithError(errs[i]).Errorf("Failed to send %d alerts to receiver %s.", len(batches[name]), name)
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	if len(names) == 1 {
+		return errs[0]
+	}
+	return &of.SinksError{Sinks: len(names), Errors: failed}
+}
+
+// Default and every receiver, for sink.Queues.
+func (r *Router) Notifiers() []of.Notifier {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	notifiers := []of.Notifier{r.Default}
+	for _, n := range r.receivers {
+		notifiers = append(notifiers, n)
+	}
+	return notifiers
+}
+
+// Stop watching the routes file, and close receivers and the default receiver.
+func (r *Router) Close() error {
+	err := r.Unwatch()
+	r.mu.Lock()
+	closeReceivers(r.receivers, r.Log)
+	r.receivers = nil
+	r.mu.Unlock()
+	if c, ok := r.Default.(io.Closer); ok == true {
+		if cerr := c.Close(); cerr != nil {
+			err = cerr
+		}
+	}
+	if r.Closer != nil {
+		if cerr := r.Closer.Close(); cerr != nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// Notifiers of receivers, by name.
+func (r *Router) newReceivers(receivers []of.RouteReceiver) (map[string]of.Notifier, error) {
+	notifiers := make(map[string]of.Notifier)
+	for i, rr := range receivers {
+		n, err := r.newReceiver(rr)
+		if err != nil {
+			closeReceivers(notifiers, r.Log)
+			return nil, fmt.Errorf("receivers[%d] (%s): %s", i, rr.Name, err.Error())
+		}
+		notifiers[rr.Name] = n
+	}
+	return notifiers, nil
+}
+
+// Notifier of receiver, sending alerts to each of its sinks.
+func (r *Router) newReceiver(rr of.RouteReceiver) (of.Notifier, error) {
+	cfg := of.SinkConfig{File: rr.File}
+	var am of.Notifier
+	if rr.Alertmanager != nil {
+		cfg.Sinks = append(cfg.Sinks, of.SinkAlertmanager)
+		var err error
+		if am, err = r.NewAM(rr.Name, rr.Alertmanager.URLs, rr.Alertmanager.Policy); err != nil {
+			return nil, err
+		}
+	}
+	if rr.Webhook != nil {
+		cfg.Sinks = append(cfg.Sinks, of.SinkWebhook)
+		cfg.WebhookURL = rr.Webhook.URL
+		cfg.WebhookTemplate = rr.Webhook.Template
+		cfg.WebhookSecretFile = rr.Webhook.SecretFile
+		cfg.WebhookTimeout = rr.Webhook.Timeout
+	}
+	if rr.File != "" {
+		cfg.Sinks = append(cfg.Sinks, of.SinkFile)
+	}
+	if rr.Stdout == true {
+		cfg.Sinks = append(cfg.Sinks, of.SinkStdout)
+	}
+	if rr.Log == true {
+		cfg.Sinks = append(cfg.Sinks, of.SinkLog)
+	}
+	if rr.Trap != nil {
+		cfg.Sinks = append(cfg.Sinks, of.SinkTrap)
+		cfg.Trap = *rr.Trap
+	}
+	if rr.Syslog != nil {
+		cfg.Sinks = append(cfg.Sinks, of.SinkSyslog)
+		cfg.Syslog = *rr.Syslog
+	}
+	return sink.NewComposite(&cfg, r.Version, am, r.Log)
+}
+
+// Close receivers holding files.
+func closeReceivers(receivers map[string]of.Notifier, l *logger.Logger) {
+	for name, n := range receivers {
+		if c, ok := n.(io.Closer); ok == true {
+			if err := c.Close(); err != nil {
+				l.WithError(err).Errorf("Failed to close receiver %s.", name)
+			}
+		}
+	}
+}
+
+// Validate routes file, returning the parsed routing tree.
+func parseRoutes(cfg *of.RoutesConfig) (*route, error) {
+	names := map[string]bool{of.DefaultReceiver: true}
+	for i, rr := range cfg.Receivers {
+		if err := validateReceiver(rr); err != nil {
+			return nil, fmt.Errorf("%s receivers[%d] (%s): %s", of.ErrInvalidRoutes, i, rr.Name, err.Error())
+		}
+		if names[rr.Name] == true {
+			return nil, fmt.Errorf("%s receivers[%d]: receiver %q is defined more than once, or reserved", of.ErrInvalidRoutes, i, rr.Name)
+		}
+		names[rr.Name] = true
+	}
+
+	if len(cfg.Route.Match) > 0 || len(cfg.Route.MatchRE) > 0 || cfg.Route.Continue == true {
+		return nil, fmt.Errorf("%s route: root route must match every alert, without continue", of.ErrInvalidRoutes)
+	}
+	return parseRoute(cfg.Route, of.DefaultReceiver, "route", names)
+}
+
+// Parse r and its child routes, inheriting receiver if r has none.
+func parseRoute(r of.Route, receiver string, path string, names map[string]bool) (*route, error) {
+	pr := &route{receiver: r.Receiver, match: r.Match, cont: r.Continue}
+	if pr.receiver == "" {
+		pr.receiver = receiver
+	}
+	if names[pr.receiver] == false {
+		return nil, fmt.Errorf("%s %s: undefined receiver %q", of.ErrInvalidRoutes, path, pr.receiver)
+	}
+	if len(r.MatchRE) > 0 {
+		pr.matchRE = make(map[string]*regexp.Regexp, len(r.MatchRE))
+		for label, expr := range r.MatchRE {
+			re, err := regexp.Compile("^(?:" + expr + ")$")
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: match_re %s: %s", of.ErrInvalidRoutes, path, label, err.Error())
+			}
+			pr.matchRE[label] = re
+		}
+	}
+	for i, child := range r.Routes {
+		cr, err := parseRoute(child, pr.receiver, fmt.Sprintf("%s.routes[%d]", path, i), names)
+		if err != nil {
+			return nil, err
+		}
+		pr.routes = append(pr.routes, cr)
+	}
+	return pr, nil
+}
+
+// Check receiver has a name and at least one sink.
+func validateReceiver(rr of.RouteReceiver) error {
+	if strings.TrimSpace(rr.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+	if rr.Alertmanager == nil && rr.Webhook == nil && rr.File == "" && rr.Stdout == false && rr.Log == false && rr.Trap == nil && rr.Syslog == nil {
+		return fmt.Errorf("alertmanager, webhook, file, stdout, log, trap or syslog is required")
+	}
+	if rr.Trap != nil && len(rr.Trap.Targets) == 0 {
+		return of.ErrNoTrapTargets
+	}
+	if rr.Syslog != nil && rr.Syslog.Address == "" {
+		return of.ErrNoSyslogAddress
+	}
+	if rr.Alertmanager != nil {
+		if len(rr.Alertmanager.URLs) == 0 {
+			return of.ErrNoAMPeers
+		}
+		for _, u := range rr.Alertmanager.URLs {
+			if strings.HasPrefix(u, "http") == false {
+				return fmt.Errorf("alertmanager URL %q must begin with http/https", u)
+			}
+		}
+		switch rr.Alertmanager.Policy {
+		case "", of.AMPolicyAny, of.AMPolicyAll:
+		default:
+			return fmt.Errorf("%s %q", of.ErrUnknownAMPolicy, rr.Alertmanager.Policy)
+		}
+	}
+	if rr.Webhook != nil && rr.Webhook.URL == "" {
+		return of.ErrNoWebhookURL
+	}
+	return nil
+}
+
+// Receivers of labels, nil if r does not match them.
+func (r *route) route(labels map[string]string) []string {
+	if r.matches(labels) == false {
+		return nil
+	}
+	var receivers []string
+	for _, child := range r.routes {
+		matched := child.route(labels)
+		for _, name := range matched {
+			if contains(receivers, name) == false {
+				receivers = append(receivers, name)
+			}
+		}
+		if matched != nil && child.cont == false {
+			break
+		}
+	}
+	if len(receivers) == 0 {
+		receivers = []string{r.receiver}
+	}
+	return receivers
+}
+
+// Check if labels match every matcher of r.
+func (r *route) matches(labels map[string]string) bool {
+	for label, value := range r.match {
+		if labels[label] != value {
+			return false
+		}
+	}
+	for label, re := range r.matchRE {
+		if re.MatchString(labels[label]) == false {
+			return false
+		}
+	}
+	return true
+}
+
+// Check if name is in names.
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
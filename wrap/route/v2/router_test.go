@@ -0,0 +1,173 @@
+package v2_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+	logger "github.com/cisco-cx/of/wrap/logrus/v2"
+	route "github.com/cisco-cx/of/wrap/route/v2"
+	"github.com/stretchr/testify/require"
+)
+
+const routesFile = "test/routes.yaml"
+
+// Enforce Notifier Interface
+func TestRouterInterface(t *testing.T) {
+	var _ of.Notifier = &route.Router{}
+}
+
+// Notifier recording alerts, failing with err.
+type testNotifier struct {
+	mu     sync.Mutex
+	alerts []of.Alert
+	err    error
+}
+
+func (n *testNotifier) Notify(alerts *[]of.Alert) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.alerts = append(n.alerts, *alerts...)
+	return n.err
+}
+
+// Factory recording Alertmanager receivers by their last peer.
+type testAMs map[string]*testNotifier
+
+func (ams testAMs) New(name string, peers []string, policy of.AMSuccessPolicy) (of.Notifier, error) {
+	n := &testNotifier{}
+	ams[peers[len(peers)-1]] = n
+	return n, nil
+}
+
+// Alert with labels, as pairs of label and value.
+func labeled(pairs ...string) of.Alert {
+	labels := make(map[string]string)
+	for i := 0; i < len(pairs); i += 2 {
+		labels[pairs[i]] = pairs[i+1]
+	}
+	return of.Alert{Labels: labels}
+}
+
+// Test alerts are routed by label matchers, child routes and continue.
+func TestRouterRoute(t *testing.T) {
+	r, err := route.NewRouter(routesFile, &testNotifier{}, testAMs{}.New, "1.0", logger.New())
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"default"}, r.Route(map[string]string{"subsystem": "mme"}))
+	require.Equal(t, []string{"epc"}, r.Route(map[string]string{"subsystem": "epc"}))
+	require.Equal(t, []string{"epc-critical"}, r.Route(map[string]string{"subsystem": "epc", "alert_severity": "critical"}))
+	require.Equal(t, []string{"esc", "archive"}, r.Route(map[string]string{"subsystem": "esc"}))
+	require.Equal(t, []string{"esc"}, r.Route(map[string]string{"subsystem": "vnf"}))
+	// match_re is anchored.
+	require.Equal(t, []string{"default"}, r.Route(map[string]string{"subsystem": "xesc"}))
+}
+
+// Test alerts are sent to their receivers in batches, and failures reported by receiver.
+func TestRouterNotify(t *testing.T) {
+	def, ams := &testNotifier{}, testAMs{}
+	r, err := route.NewRouter(routesFile, def, ams.New, "1.0", logger.New())
+	require.NoError(t, err)
+
+	mme, epc, epc2 := labeled("subsystem", "mme"), labeled("subsystem", "epc"), labeled("subsystem", "epc", "alertname", "b")
+	critical := labeled("subsystem", "epc", "alert_severity", "critical")
+	alerts := []of.Alert{mme, epc, critical, epc2}
+	require.NoError(t, r.Notify(&alerts))
+	require.Equal(t, []of.Alert{mme}, def.alerts)
+	require.Equal(t, []of.Alert{epc, epc2}, ams["http://am-epc:9093"].alerts)
+	require.Equal(t, []of.Alert{critical}, ams["http://am-noc:9093"].alerts)
+
+	def.err = errors.New("connection refused")
+	err = r.Notify(&alerts)
+	sinksErr, ok := err.(*of.SinksError)
+	require.True(t, ok)
+	require.Equal(t, 3, sinksErr.Sinks)
+	require.Equal(t, map[string]error{"default": def.err}, sinksErr.Errors)
+
+	// A single receiver fails with its own error.
+	alerts = []of.Alert{mme}
+	require.Equal(t, def.err, r.Notify(&alerts))
+	require.NoError(t, r.Close())
+}
+
+// Write routes to a file in a new temporary dir.
+func writeRoutes(t *testing.T, dir string, routes string) string {
+	path := filepath.Join(dir, "routes.yaml")
+	require.NoError(t, ioutil.WriteFile(path, []byte(routes), 0644))
+	return path
+}
+
+// Test invalid routes files are rejected.
+func TestRouterInvalid(t *testing.T) {
+	dir, err := ioutil.TempDir("", "routes")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	for name, routes := range map[string]string{
+		"undefined receiver": "route:\n  routes:\n  - match: {a: b}\n    receiver: missing\n",
+		"root matcher":       "route:\n  match: {a: b}\n",
+		"invalid regexp":     "route:\n  routes:\n  - match_re: {a: '('}\n",
+		"duplicate receiver": "route: {}\nreceivers:\n- name: a\n  log: true\n- name: a\n  log: true\n",
+		"reserved receiver":  "route: {}\nreceivers:\n- name: default\n  log: true\n",
+		"empty receiver":     "route: {}\nreceivers:\n- name: a\n",
+		"no peers":           "route: {}\nreceivers:\n- name: a\n  alertmanager: {}\n",
+		"no trap targets":    "route: {}\nreceivers:\n- name: a\n  trap: {}\n",
+		"no syslog address":  "route: {}\nreceivers:\n- name: a\n  syslog: {}\n",
+		"unknown field":      "route: {}\nrecievers: []\n",
+		"empty":              "\n",
+	} {
+		_, err := route.NewRouter(writeRoutes(t, dir, routes), &testNotifier{}, testAMs{}.New, "1.0", logger.New())
+		require.Error(t, err, name)
+	}
+}
+
+// Test routes are reloaded on change, and kept if the file is invalid.
+func TestRouterWatch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "routes")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := writeRoutes(t, dir, "route: {}\n")
+	r, err := route.NewRouter(path, &testNotifier{}, testAMs{}.New, "1.0", logger.New())
+	require.NoError(t, err)
+	require.NoError(t, r.Watch())
+	defer r.Close()
+
+	epc := map[string]string{"subsystem": "epc"}
+	require.Equal(t, []string{"default"}, r.Route(epc))
+
+	writeRoutes(t, dir, "route:\n  routes:\n  - match: {subsystem: epc}\n    receiver: epc\nreceivers:\n- name: epc\n  log: true\n")
+	require.Eventually(t, func() bool {
+		return r.Route(epc)[0] == "epc"
+	}, 5*time.Second, 10*time.Millisecond)
+
+	writeRoutes(t, dir, "route:\n  receiver: missing\n")
+	require.Error(t, r.Load())
+	require.Equal(t, []string{"epc"}, r.Route(epc))
+}
+
+// Test routes are reloaded when the routes file is replaced by rename.
+func TestRouterWatchRename(t *testing.T) {
+	dir, err := ioutil.TempDir("", "routes")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := writeRoutes(t, dir, "route: {}\n")
+	r, err := route.NewRouter(path, &testNotifier{}, testAMs{}.New, "1.0", logger.New())
+	require.NoError(t, err)
+	require.NoError(t, r.Watch())
+	defer r.Close()
+
+	tmp := filepath.Join(dir, "routes.yaml.tmp")
+	require.NoError(t, ioutil.WriteFile(tmp, []byte("route:\n  routes:\n  - match: {subsystem: epc}\n    receiver: epc\nreceivers:\n- name: epc\n  log: true\n"), 0644))
+	require.NoError(t, os.Rename(tmp, path))
+	epc := map[string]string{"subsystem": "epc"}
+	require.Eventually(t, func() bool {
+		return r.Route(epc)[0] == "epc"
+	}, 5*time.Second, 10*time.Millisecond)
+}
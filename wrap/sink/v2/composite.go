@@ -0,0 +1,212 @@
+package v2
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+	logger "github.com/cisco-cx/of/wrap/logrus/v2"
+	prometheus "github.com/cisco-cx/of/wrap/prometheus/client_golang/v2"
+	syslog "github.com/cisco-cx/of/wrap/syslog/v2"
+	clock "github.com/cisco-cx/of/wrap/time/v2"
+	trap "github.com/cisco-cx/of/wrap/trap/v2"
+)
+
+// Represents a named sink of a Composite.
+type Sink struct {
+	Name     string
+	Notifier of.Notifier
+}
+
+// Implements of.Notifier, sending alerts to every sink.
+// Failing sinks do not keep alerts from the others.
+type Composite struct {
+	Sinks  []Sink
+	Policy of.SinkPolicy          // When sending to Sinks succeeds, all if empty.
+	Posts  *prometheus.CounterVec // Counts alerts sent by sink and result, if set.
+	Log    *logger.Logger
+}
+
+// Parse success policy, empty is all.
+func ParsePolicy(policy string) (of.SinkPolicy, error) {
+	switch p := of.SinkPolicy(strings.ToLower(policy)); p {
+	case "":
+		return of.SinkPolicyAll, nil
+	case of.SinkPolicyAny, of.SinkPolicyAll:
+		return p, nil
+	}
+	return "", fmt.Errorf("%s %q, expected any or all", of.ErrUnknownSinkPolicy, policy)
+}
+
+// Parse sinks separated by ',', alertmanager if empty.
+func ParseSinks(sinks string) ([]of.SinkType, error) {
+	var types []of.SinkType
+	seen := make(map[of.SinkType]bool)
+	for _, s := range strings.Split(sinks, ",") {
+		t := of.SinkType(strings.ToLower(strings.TrimSpace(s)))
+		switch t {
+		case "":
+			continue
+		case of.SinkAlertmanager, of.SinkWebhook, of.SinkFile, of.SinkStdout, of.SinkLog, of.SinkTrap, of.SinkSyslog:
+		default:
+			return nil, fmt.Errorf("%s %q, expected alertmanager, webhook, file, stdout, log, trap or syslog", of.ErrUnknownSink, s)
+		}
+		if seen[t] == true {
+			return nil, fmt.Errorf("%s %q", of.ErrDuplicateSink, t)
+		}
+		seen[t] = true
+		types = append(types, t)
+	}
+	if len(types) == 0 {
+		types = []of.SinkType{of.SinkAlertmanager}
+	}
+	return types, nil
+}
+
+// Init Composite with the sinks of cfg, in order. Alerts for the alertmanager sink are sent to am.
+// Dry runs only have the log sink.
+func NewComposite(cfg *of.SinkConfig, version string, am of.Notifier, l *logger.Logger) (*Composite, error) {
+	types := cfg.Sinks
+	if len(types) == 0 {
+		types = []of.SinkType{of.SinkAlertmanager}
+	}
+	if cfg.DryRun == true {
+		types = []of.SinkType{of.SinkLog}
+	}
+
+	c := &Composite{Policy: cfg.Policy, Log: l}
+	for _, t := range types {
+		var n of.Notifier
+		switch t {
+		case of.SinkAlertmanager:
+			n = am
+		case of.SinkWebhook:
+			w, err := NewWebhook(cfg, version)
+			if err != nil {
+				return nil, err
+			}
+			n = w
+		case of.SinkFile:
+			if cfg.File == "" {
+				return nil, of.ErrNoSinkFile
+			}
+			s, err := OpenFile(cfg.File)
+			if err != nil {
+				return nil, err
+			}
+			n = s
+		case of.SinkStdout:
+			n = &Stream{W: os.Stdout}
+		case of.SinkLog:
+			n = &Log{Log: l, DryRun: cfg.DryRun}
+		case of.SinkTrap:
+			s, err := trap.NewSender(&cfg.Trap, &clock.Clock{}, l)
+			if err != nil {
+				return nil, err
+			}
+			n = s
+		case of.SinkSyslog:
+			w, err := syslog.NewWriter(&cfg.Syslog, &clock.Clock{}, l)
+			if err != nil {
+				return nil, err
+			}
+			n = w
+		default:
+			return nil, fmt.Errorf("%s %q", of.ErrUnknownSink, t)
+		}
+		c.Sinks = append(c.Sinks, Sink{Name: string(t), Notifier: n})
+	}
+	return c, nil
+}
+
+// Sink named name, nil if there is none. Its notifier may be replaced, like to queue alerts for it.
+func (c *Composite) Sink(name string) *Sink {
+	for i := range c.Sinks {
+		if c.Sinks[i].Name == name {
+			return &c.Sinks[i]
+		}
+	}
+	return nil
+}
+
+// Send alerts to every sink concurrently. Failures are logged and counted, and returned if they fail Policy.
+// A single sink fails with its own error, otherwise failures are returned as *of.SinksError.
+func (c *Composite) Notify(alerts *[]of.Alert) error {
+	errs := make([]error, len(c.Sinks))
+	var wg sync.WaitGroup
+	for i, s := range c.Sinks {
+		wg.Add(1)
+		go func(i int, s Sink) {
+			defer wg.Done()
+			errs[i] = s.Notifier.Notify(alerts)
+		}(i, s)
+	}
+	wg.Wait()
+
+	failed := make(map[string]error)
+	for i, s := range c.Sinks {
+		result := "success"
+		if errs[i] != nil {
+			result = "failure"
+			failed[s.Name] = errs[i]
+			c.Log.WithError(errs[i]).Errorf("Failed to send %d alerts to sink %s.", len(*alerts), s.Name)
+		}
+		if c.Posts != nil {
+			c.Posts.Incr(map[string]string{"sink": s.Name, "result": result})
+		}
+	}
+
+	if len(failed) == 0 || (c.Policy == of.SinkPolicyAny && len(failed) < len(c.Sinks)) {
+		return nil
+	}
+	if len(c.Sinks) == 1 {
+		return errs[0]
+	}
+	return &of.SinksError{Sinks: len(c.Sinks), Errors: failed}
+}
+
+// Close sinks that hold files or queues. Returns the last error.
+func (c *Composite) Close() error {
+	var err error
+	for _, s := range c.Sinks {
+		if closer, ok := s.Notifier.(io.Closer); ok == true {
+			if cerr := closer.Close(); cerr != nil {
+				err = cerr
+			}
+		}
+	}
+	return err
+}
+
+// Notifiers of every sink.
+func (c *Composite) Notifiers() []of.Notifier {
+	notifiers := make([]of.Notifier, len(c.Sinks))
+	for i, s := range c.Sinks {
+		notifiers[i] = s.Notifier
+	}
+	return notifiers
+}
+
+// Implemented by notifiers sending alerts on to other notifiers, like Composite.
+type Forwarder interface {
+	Notifiers() []of.Notifier
+}
+
+// Check if n, or a notifier n forwards to, queues alerts for later delivery.
+func Queues(n of.Notifier) bool {
+	// Forwarders are checked first, they close like an of.AlertQueue.
+	switch n := n.(type) {
+	case Forwarder:
+		for _, next := range n.Notifiers() {
+			if Queues(next) == true {
+				return true
+			}
+		}
+	case of.AlertQueue:
+		return true
+	}
+	return false
+}
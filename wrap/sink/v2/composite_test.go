@@ -0,0 +1,154 @@
+package v2_test
+
+import (
+	"errors"
+	"testing"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+	logger "github.com/cisco-cx/of/wrap/logrus/v2"
+	prometheus "github.com/cisco-cx/of/wrap/prometheus/client_golang/v2"
+	queue "github.com/cisco-cx/of/wrap/queue/v2"
+	sink "github.com/cisco-cx/of/wrap/sink/v2"
+	syslog "github.com/cisco-cx/of/wrap/syslog/v2"
+	trap "github.com/cisco-cx/of/wrap/trap/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// Enforce Notifier Interface
+func TestCompositeInterface(t *testing.T) {
+	var _ of.Notifier = &sink.Composite{}
+}
+
+// Test sinks are parsed, alertmanager if none are set.
+func TestParseSinks(t *testing.T) {
+	sinks, err := sink.ParseSinks("")
+	require.NoError(t, err)
+	require.Equal(t, []of.SinkType{of.SinkAlertmanager}, sinks)
+
+	sinks, err = sink.ParseSinks("Alertmanager, webhook,stdout,trap,syslog")
+	require.NoError(t, err)
+	require.Equal(t, []of.SinkType{of.SinkAlertmanager, of.SinkWebhook, of.SinkStdout, of.SinkTrap, of.SinkSyslog}, sinks)
+
+	_, err = sink.ParseSinks("alertmanager,kafka")
+	require.Error(t, err)
+	_, err = sink.ParseSinks("log,log")
+	require.Error(t, err)
+}
+
+// Notifier recording alerts, failing with err.
+type testSink struct {
+	alerts []of.Alert
+	err    error
+}
+
+func (s *testSink) Notify(alerts *[]of.Alert) error {
+	s.alerts = append(s.alerts, *alerts...)
+	return s.err
+}
+
+// Test alerts are sent to every sink, with failures reported by sink.
+func TestComposite(t *testing.T) {
+	posts := &prometheus.CounterVec{Namespace: t.Name(), Name: "sink_notifications_count"}
+	require.NoError(t, posts.Create([]string{"sink", "result"}))
+
+	am, webhook := &testSink{}, &testSink{err: errors.New("connection refused")}
+	c := &sink.Composite{
+		Sinks: []sink.Sink{{Name: "alertmanager", Notifier: am}, {Name: "webhook", Notifier: webhook}},
+		Posts: posts,
+		Log:   logger.New(),
+	}
+	alerts := sinkAlerts("a")
+	err := c.Notify(&alerts)
+	require.Equal(t, alerts, am.alerts)
+	require.Equal(t, alerts, webhook.alerts)
+
+	sinksErr, ok := err.(*of.SinksError)
+	require.True(t, ok)
+	require.Equal(t, 2, sinksErr.Sinks)
+	require.Equal(t, map[string]error{"webhook": webhook.err}, sinksErr.Errors)
+	require.Equal(t, `Sending alerts to 1 of 2 sinks failed: webhook: connection refused`, err.Error())
+
+	// A single sink fails with its own error.
+	c.Sinks = c.Sinks[1:]
+	require.Equal(t, webhook.err, c.Notify(&alerts))
+
+	require.Nil(t, c.Sink("alertmanager"))
+	require.NotNil(t, c.Sink("webhook"))
+}
+
+// Test alerts accepted by any sink succeed with the any policy, failures are still counted.
+func TestCompositePolicyAny(t *testing.T) {
+	posts := &prometheus.CounterVec{Namespace: t.Name(), Name: "sink_notifications_count"}
+	require.NoError(t, posts.Create([]string{"sink", "result"}))
+
+	am, webhook := &testSink{}, &testSink{err: errors.New("connection refused")}
+	c := &sink.Composite{
+		Sinks:  []sink.Sink{{Name: "alertmanager", Notifier: am}, {Name: "webhook", Notifier: webhook}},
+		Policy: of.SinkPolicyAny,
+		Posts:  posts,
+		Log:    logger.New(),
+	}
+	alerts := sinkAlerts("a")
+	require.NoError(t, c.Notify(&alerts))
+	require.Equal(t, alerts, webhook.alerts)
+
+	// Failing every sink fails.
+	am.err = errors.New("timeout")
+	_, ok := c.Notify(&alerts).(*of.SinksError)
+	require.True(t, ok)
+}
+
+// Test policies are parsed, empty is all.
+func TestParsePolicy(t *testing.T) {
+	for in, expected := range map[string]of.SinkPolicy{"": of.SinkPolicyAll, "ANY": of.SinkPolicyAny, "all": of.SinkPolicyAll} {
+		p, err := sink.ParsePolicy(in)
+		require.NoError(t, err)
+		require.Equal(t, expected, p)
+	}
+	_, err := sink.ParsePolicy("most")
+	require.Contains(t, err.Error(), of.ErrUnknownSinkPolicy.Error())
+}
+
+// Test sinks are created from config, in order.
+func TestNewComposite(t *testing.T) {
+	am := &testSink{}
+	c, err := sink.NewComposite(&of.SinkConfig{Sinks: []of.SinkType{of.SinkLog, of.SinkAlertmanager}}, "1.0", am, logger.New())
+	require.NoError(t, err)
+	require.Len(t, c.Sinks, 2)
+	require.IsType(t, &sink.Log{}, c.Sinks[0].Notifier)
+	require.Equal(t, am, c.Sink("alertmanager").Notifier)
+	require.False(t, sink.Queues(c))
+
+	// Queued alerts are seen through the composite.
+	c.Sink("alertmanager").Notifier = &queue.Queue{}
+	require.True(t, sink.Queues(c))
+	require.NoError(t, c.Close())
+
+	c, err = sink.NewComposite(&of.SinkConfig{}, "1.0", am, logger.New())
+	require.NoError(t, err)
+	require.Equal(t, "alertmanager", c.Sinks[0].Name)
+
+	// Dry runs only log alerts.
+	c, err = sink.NewComposite(&of.SinkConfig{Sinks: []of.SinkType{of.SinkAlertmanager, of.SinkStdout}, DryRun: true}, "1.0", am, logger.New())
+	require.NoError(t, err)
+	require.Len(t, c.Sinks, 1)
+	require.Equal(t, &sink.Log{Log: c.Log, DryRun: true}, c.Sinks[0].Notifier)
+
+	_, err = sink.NewComposite(&of.SinkConfig{Sinks: []of.SinkType{of.SinkFile}}, "1.0", am, logger.New())
+	require.Equal(t, of.ErrNoSinkFile, err)
+
+	c, err = sink.NewComposite(&of.SinkConfig{Sinks: []of.SinkType{of.SinkTrap}, Trap: of.TrapConfig{Targets: []string{"localhost"}}}, "1.0", am, logger.New())
+	require.NoError(t, err)
+	require.IsType(t, &trap.Sender{}, c.Sinks[0].Notifier)
+
+	_, err = sink.NewComposite(&of.SinkConfig{Sinks: []of.SinkType{of.SinkTrap}}, "1.0", am, logger.New())
+	require.Equal(t, of.ErrNoTrapTargets, err)
+
+	c, err = sink.NewComposite(&of.SinkConfig{Sinks: []of.SinkType{of.SinkSyslog}, Syslog: of.SyslogConfig{Address: "localhost"}}, "1.0", am, logger.New())
+	require.NoError(t, err)
+	require.IsType(t, &syslog.Writer{}, c.Sinks[0].Notifier)
+	require.NoError(t, c.Close())
+
+	_, err = sink.NewComposite(&of.SinkConfig{Sinks: []of.SinkType{of.SinkSyslog}}, "1.0", am, logger.New())
+	require.Equal(t, of.ErrNoSyslogAddress, err)
+}
@@ -0,0 +1,30 @@
+package v2
+
+import (
+	of "github.com/cisco-cx/of/pkg/v2"
+	logger "github.com/cisco-cx/of/wrap/logrus/v2"
+)
+
+// Implements of.Notifier, logging alerts at info level. Also used for dry runs.
+type Log struct {
+	Log    *logger.Logger
+	DryRun bool // Log alerts as a dry run.
+}
+
+// Log every alert with its fields.
+func (l *Log) Notify(alerts *[]of.Alert) error {
+	msg := "Alert."
+	if l.DryRun == true {
+		msg = "Dry run."
+	}
+	for _, alert := range *alerts {
+		l.Log.WithFields(map[string]interface{}{
+			"labels":       alert.Labels,
+			"annotations":  alert.Annotations,
+			"startsAt":     alert.StartsAt,
+			"endsAt":       alert.EndsAt,
+			"generatorURL": alert.GeneratorURL,
+		}).Infof(msg)
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package v2_test
+
+import (
+	"bytes"
+	"testing"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+	logger "github.com/cisco-cx/of/wrap/logrus/v2"
+	sink "github.com/cisco-cx/of/wrap/sink/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// Enforce Notifier Interface
+func TestLogInterface(t *testing.T) {
+	var _ of.Notifier = &sink.Log{}
+}
+
+// Test alerts are logged with their fields.
+func TestLog(t *testing.T) {
+	output := &bytes.Buffer{}
+	log := logger.New()
+	log.SetOutput(output)
+
+	l := sink.Log{Log: log}
+	alerts := []of.Alert{of.Alert{Labels: map[string]string{"dryRun": "false"}}}
+	require.NoError(t, l.Notify(&alerts))
+	require.Contains(t, output.String(), "level=info msg=Alert. annotations=\"map[]\" endsAt=\"0001-01-01 00:00:00 +0000 UTC\" generatorURL= labels=\"map[dryRun:false]\" startsAt=\"0001-01-01 00:00:00 +0000 UTC\"")
+	require.NotContains(t, output.String(), "Dry run.")
+
+	// Dry runs are logged as such.
+	output.Reset()
+	l.DryRun = true
+	alerts = []of.Alert{of.Alert{Labels: map[string]string{"dryRun": "true"}}}
+	require.NoError(t, l.Notify(&alerts))
+	require.Contains(t, output.String(), "level=info msg=\"Dry run.\" annotations=\"map[]\" endsAt=\"0001-01-01 00:00:00 +0000 UTC\" generatorURL= labels=\"map[dryRun:true]\" startsAt=\"0001-01-01 00:00:00 +0000 UTC\"")
+}
@@ -0,0 +1,56 @@
+package v2
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+)
+
+// Implements of.Notifier, writing alerts to W as NDJSON, one alert per line.
+// Suited for log shippers tailing a file, or containers logging stdout.
+type Stream struct {
+	W io.Writer
+
+	mu sync.Mutex
+	c  io.Closer // Closed by Close, if set.
+}
+
+// Open file for appending alerts, creating it if needed.
+func OpenFile(file string) (*Stream, error) {
+	f, err := os.OpenFile(file, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &Stream{W: f, c: f}, nil
+}
+
+// Write alerts in a single write, so lines of concurrent writers to a file do not mix.
+func (s *Stream) Notify(alerts *[]of.Alert) error {
+	var b []byte
+	for _, alert := range *alerts {
+		line, err := json.Marshal(alert)
+		if err != nil {
+			return err
+		}
+		b = append(append(b, line...), '\n')
+	}
+	if len(b) == 0 {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err := s.W.Write(b)
+	return err
+}
+
+// Close file opened by OpenFile. Other writers are left open.
+func (s *Stream) Close() error {
+	if s.c == nil {
+		return nil
+	}
+	return s.c.Close()
+}
@@ -0,0 +1,58 @@
+package v2_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+	sink "github.com/cisco-cx/of/wrap/sink/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// Enforce Notifier Interface
+func TestStreamInterface(t *testing.T) {
+	var _ of.Notifier = &sink.Stream{}
+}
+
+// Alerts named names.
+func sinkAlerts(names ...string) []of.Alert {
+	alerts := make([]of.Alert, len(names))
+	for i, name := range names {
+		alerts[i] = of.Alert{Labels: map[string]string{"alertname": name}}
+	}
+	return alerts
+}
+
+// Test alerts are written one per line.
+func TestStream(t *testing.T) {
+	var b bytes.Buffer
+	s := sink.Stream{W: &b}
+	alerts := sinkAlerts("a", "b")
+	require.NoError(t, s.Notify(&alerts))
+	require.Equal(t, `{"labels":{"alertname":"a"},"annotations":null,"startsAt":"0001-01-01T00:00:00Z","endsAt":"0001-01-01T00:00:00Z","generatorURL":""}
+{"labels":{"alertname":"b"},"annotations":null,"startsAt":"0001-01-01T00:00:00Z","endsAt":"0001-01-01T00:00:00Z","generatorURL":""}
+`, b.String())
+	require.NoError(t, s.Close())
+}
+
+// Test alerts are appended to file.
+func TestStreamFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sink")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "alerts.ndjson")
+	require.NoError(t, ioutil.WriteFile(file, []byte("{}\n"), 0644))
+
+	s, err := sink.OpenFile(file)
+	require.NoError(t, err)
+	alerts := sinkAlerts("a")
+	require.NoError(t, s.Notify(&alerts))
+	require.NoError(t, s.Close())
+
+	b, err := ioutil.ReadFile(file)
+	require.NoError(t, err)
+	require.Equal(t, "{}\n"+`{"labels":{"alertname":"a"},"annotations":null,"startsAt":"0001-01-01T00:00:00Z","endsAt":"0001-01-01T00:00:00Z","generatorURL":""}`+"\n", string(b))
+}
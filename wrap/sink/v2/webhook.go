@@ -0,0 +1,114 @@
+package v2
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	nethttp "net/http"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+)
+
+// Header with the HMAC-SHA256 of webhook bodies, as sha256=<hex>.
+const SignatureHeader = "X-OF-Signature"
+
+// Implements of.Notifier, posting alerts to a webhook.
+// Body is of.WebhookMessage as JSON, or Template executed with it.
+type Webhook struct {
+	URL      string
+	Version  string
+	Template *template.Template // Renders bodies, if set.
+	Secret   []byte             // Signs bodies, if set.
+	Client   *nethttp.Client
+}
+
+// Init Webhook with template and secret files of cfg.
+func NewWebhook(cfg *of.SinkConfig, version string) (*Webhook, error) {
+	if cfg.WebhookURL == "" {
+		return nil, of.ErrNoWebhookURL
+	}
+	w := &Webhook{
+		URL:     cfg.WebhookURL,
+		Version: version,
+		Client:  &nethttp.Client{Timeout: cfg.WebhookTimeout},
+	}
+	if cfg.WebhookTemplate != "" {
+		tmpl, err := template.New(filepath.Base(cfg.WebhookTemplate)).Funcs(template.FuncMap{
+			"json": toJSON,
+		}).ParseFiles(cfg.WebhookTemplate)
+		if err != nil {
+			return nil, err
+		}
+		w.Template = tmpl
+	}
+	if cfg.WebhookSecretFile != "" {
+		b, err := ioutil.ReadFile(cfg.WebhookSecretFile)
+		if err != nil {
+			return nil, err
+		}
+		w.Secret = []byte(strings.TrimSpace(string(b)))
+	}
+	return w, nil
+}
+
+// Post alerts in a single request. Fails if the webhook does not return 2xx.
+func (w *Webhook) Notify(alerts *[]of.Alert) error {
+	body, err := w.body(of.WebhookMessage{Version: w.Version, Alerts: *alerts})
+	if err != nil {
+		return err
+	}
+
+	req, err := nethttp.NewRequest("POST", w.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", fmt.Sprintf("OF/%s", w.Version))
+	if len(w.Secret) > 0 {
+		req.Header.Set(SignatureHeader, Sign(w.Secret, body))
+	}
+
+	res, err := w.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	io.Copy(ioutil.Discard, res.Body)
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("POST to webhook on %q returned HTTP %d", w.URL, res.StatusCode)
+	}
+	return nil
+}
+
+// Request body for msg.
+func (w *Webhook) body(msg of.WebhookMessage) ([]byte, error) {
+	if w.Template == nil {
+		return json.Marshal(msg)
+	}
+	var b bytes.Buffer
+	if err := w.Template.Execute(&b, msg); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// Signature of body with secret, as sent in SignatureHeader.
+func Sign(secret []byte, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Template func, writing v as JSON.
+func toJSON(v interface{}) (string, error) {
+	b, err := json.Marshal(v)
+	return string(b), err
+}
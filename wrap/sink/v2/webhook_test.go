@@ -0,0 +1,84 @@
+package v2_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	nethttp "net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+	sink "github.com/cisco-cx/of/wrap/sink/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// Enforce Notifier Interface
+func TestWebhookInterface(t *testing.T) {
+	var _ of.Notifier = &sink.Webhook{}
+}
+
+// Represents a request received by a test webhook.
+type webhookRequest struct {
+	body      []byte
+	signature string
+}
+
+// Webhook server sending requests to reqs, responding with status.
+func webhookServer(status int, reqs chan webhookRequest) *httptest.Server {
+	return httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		reqs <- webhookRequest{body: b, signature: r.Header.Get(sink.SignatureHeader)}
+		w.WriteHeader(status)
+	}))
+}
+
+// Test alerts are posted as JSON, signed with the secret.
+func TestWebhook(t *testing.T) {
+	reqs := make(chan webhookRequest, 1)
+	ts := webhookServer(200, reqs)
+	defer ts.Close()
+
+	dir, err := ioutil.TempDir("", "sink")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	secretFile := filepath.Join(dir, "secret")
+	require.NoError(t, ioutil.WriteFile(secretFile, []byte("secret\n"), 0600))
+
+	w, err := sink.NewWebhook(&of.SinkConfig{WebhookURL: ts.URL, WebhookSecretFile: secretFile}, "1.0")
+	require.NoError(t, err)
+	alerts := sinkAlerts("a")
+	require.NoError(t, w.Notify(&alerts))
+
+	req := <-reqs
+	var msg of.WebhookMessage
+	require.NoError(t, json.Unmarshal(req.body, &msg))
+	require.Equal(t, of.WebhookMessage{Version: "1.0", Alerts: alerts}, msg)
+	require.Equal(t, sink.Sign([]byte("secret"), req.body), req.signature)
+}
+
+// Test bodies are rendered with the template, and failures returned.
+func TestWebhookTemplate(t *testing.T) {
+	reqs := make(chan webhookRequest, 1)
+	ts := webhookServer(500, reqs)
+	defer ts.Close()
+
+	dir, err := ioutil.TempDir("", "sink")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	tmpl := filepath.Join(dir, "body.tmpl")
+	require.NoError(t, ioutil.WriteFile(tmpl, []byte(`{"text": {{ len .Alerts }}, "first": {{ json (index .Alerts 0).Labels }}}`), 0644))
+
+	w, err := sink.NewWebhook(&of.SinkConfig{WebhookURL: ts.URL, WebhookTemplate: tmpl}, "1.0")
+	require.NoError(t, err)
+	alerts := sinkAlerts("a", "b")
+	require.Error(t, w.Notify(&alerts))
+
+	req := <-reqs
+	require.Equal(t, `{"text": 2, "first": {"alertname":"a"}}`, string(req.body))
+	require.Empty(t, req.signature)
+
+	_, err = sink.NewWebhook(&of.SinkConfig{}, "1.0")
+	require.Equal(t, of.ErrNoWebhookURL, err)
+}
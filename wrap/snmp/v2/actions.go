@@ -0,0 +1,59 @@
+package v2
+
+import (
+	of "github.com/cisco-cx/of/pkg/v2"
+	of_snmp "github.com/cisco-cx/of/pkg/v2/snmp"
+)
+
+// Apply on_fire actions of alertCfg, to alerts correlated with the firing alert.
+// Returns resolutions and re-raises of the correlated alerts.
+func (a *Alerter) onFire(cfgName string, alertCfg of_snmp.Alert, alert of.Alert) []of.Alert {
+	if a.Registry == nil || len(alertCfg.OnFire) == 0 {
+		return nil
+	}
+
+	now := a.Registry.Clock.Now()
+	var alerts []of.Alert
+	for _, action := range alertCfg.OnFire {
+		for _, name := range action.Alerts {
+			switch action.Type {
+			case of_snmp.ClearAction:
+				for _, fAlert := range a.Registry.Firing(name, action.Equal, alert.Labels) {
+					cAlert := copyAlert(fAlert)
+					cAlert.Annotations[of_snmp.EventTypeText] = string(of_snmp.Clearing)
+					cAlert.Annotations["cleared_by"] = alert.Labels["alertname"]
+					cAlert.EndsAt = now
+					alerts = append(alerts, cAlert)
+				}
+			case of_snmp.RaiseAction:
+				for _, cAlert := range a.Registry.Resolved(name, action.Equal, alert.Labels) {
+					fAlert := copyAlert(cAlert)
+					fAlert.Annotations[of_snmp.EventTypeText] = string(of_snmp.Firing)
+					fAlert.Annotations["raised_by"] = alert.Labels["alertname"]
+					// Keep auto clear duration of the original alert.
+					if fAlert.EndsAt.IsZero() == false {
+						fAlert.EndsAt = now.Add(cAlert.EndsAt.Sub(cAlert.StartsAt))
+					}
+					fAlert.StartsAt = now
+					alerts = append(alerts, fAlert)
+				}
+			default:
+				a.Log.WithError(of.ErrUnknownActionType).Errorf("Unknown on_fire action %s, in config: %s", action.Type, cfgName)
+			}
+		}
+	}
+
+	for _, al := range alerts {
+		a.CntrVec[onFireAlertsCount].Incr(map[string]string{
+			"config":    cfgName,
+			"alertType": al.Annotations[of_snmp.EventTypeText],
+		})
+		a.Log.WithFields(map[string]interface{}{
+			"alertType": al.Annotations[of_snmp.EventTypeText],
+			"labels":    al.Labels,
+			"firedBy":   alert.Labels,
+			"config":    cfgName,
+		}).Infof("Generating alert for on_fire action")
+	}
+	return alerts
+}
@@ -0,0 +1,102 @@
+package v2_test
+
+import (
+	"testing"
+	"time"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+	of_snmp "github.com/cisco-cx/of/pkg/v2/snmp"
+	logger "github.com/cisco-cx/of/wrap/logrus/v2"
+	snmp "github.com/cisco-cx/of/wrap/snmp/v2"
+	clock "github.com/cisco-cx/of/wrap/time/v2"
+	uuid "github.com/cisco-cx/of/wrap/uuid/v2"
+	"github.com/stretchr/testify/require"
+)
+
+var onFireConfig = `epc:
+  defaults:
+    source_type: host
+  alerts:
+  - name: starCardActive
+    label_mods:
+    - type: set
+      key: alertname
+      value: starCardActive
+    firing:
+      select:
+      - type: equals
+        oid: .1.3.6.1.6.3.1.1.4.1.0
+        as: value
+        values:
+        - .1.3.6.1.4.1.8164.2.44
+    on_fire:
+    - type: clear
+      alerts:
+      - starCardBootFailed
+      - starCard
+    - type: raise
+      alerts:
+      - starCardStandby
+`
+
+// Test firing alert clears and re-raises correlated alerts.
+func TestOnFire(t *testing.T) {
+	c := &clock.FixedClock{T: time.Date(2020, 5, 1, 22, 22, 53, 0, time.UTC)}
+	l := logger.New()
+	configs := heartbeatConfigs(t, onFireConfig)
+	mr := mibRegistry(t)
+	cntr, cntrVec := snmp.InitCounters(t.Name(), l)
+	registry := snmp.NewRegistry(c)
+	ag := snmp.Alerter{
+		Log:      l,
+		Configs:  &configs,
+		Receipts: TrapReceipts(),
+		Value:    snmp.NewValue(trapVars(), mr),
+		MR:       mr,
+		U:        &uuid.FixedUUID{},
+		Cntr:     cntr,
+		CntrVec:  cntrVec,
+		Registry: registry,
+	}
+
+	bootFailed := suppressAlert("starCardBootFailed", "192.168.1.28", of_snmp.Firing)
+	otherSource := suppressAlert("starCard", "192.168.1.29", of_snmp.Firing)
+	standby := suppressAlert("starCardStandby", "192.168.1.28", of_snmp.Firing)
+	standby.EndsAt = c.Now().Add(10 * time.Minute)
+	standby.StartsAt = c.Now()
+	registry.Observe([]of.Alert{bootFailed, otherSource, standby})
+	cleared := copyLabels(standby)
+	cleared.Annotations = map[string]string{of_snmp.EventTypeText: string(of_snmp.Clearing)}
+	registry.Observe([]of.Alert{cleared})
+
+	c.Add(time.Minute)
+	alerts := ag.Alert([]string{"epc"})
+	require.Len(t, alerts, 3)
+	require.Equal(t, "starCardActive", alerts[0].Labels["alertname"])
+
+	// Firing alert for same source is cleared.
+	require.Equal(t, bootFailed.Labels, alerts[1].Labels)
+	require.Equal(t, string(of_snmp.Clearing), alerts[1].Annotations["event_type"])
+	require.Equal(t, "starCardActive", alerts[1].Annotations["cleared_by"])
+	require.Equal(t, c.Now(), alerts[1].EndsAt)
+
+	// Resolved alert is re-raised, with same auto clear duration.
+	require.Equal(t, standby.Labels, alerts[2].Labels)
+	require.Equal(t, string(of_snmp.Firing), alerts[2].Annotations["event_type"])
+	require.Equal(t, "starCardActive", alerts[2].Annotations["raised_by"])
+	require.Equal(t, c.Now(), alerts[2].StartsAt)
+	require.Equal(t, c.Now().Add(10*time.Minute), alerts[2].EndsAt)
+
+	metrics := promMetrics(t)
+	require.Contains(t, metrics, t.Name()+`_on_fire_alerts_count{alertType="clear",config="epc"} 1`)
+	require.Contains(t, metrics, t.Name()+`_on_fire_alerts_count{alertType="error",config="epc"} 1`)
+}
+
+// Copy of alert with only labels.
+func copyLabels(alert of.Alert) of.Alert {
+	labels := make(map[string]string)
+	for k, v := range alert.Labels {
+		labels[k] = v
+	}
+	return of.Alert{Labels: labels}
+}
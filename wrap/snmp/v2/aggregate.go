@@ -0,0 +1,185 @@
+package v2
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+	of_snmp "github.com/cisco-cx/of/pkg/v2/snmp"
+	logger "github.com/cisco-cx/of/wrap/logrus/v2"
+	prometheus "github.com/cisco-cx/of/wrap/prometheus/client_golang/v2"
+)
+
+// Aggregates repeated firing alerts, firing a single alert once enough occur within a window.
+type Aggregator struct {
+	Clock   of.Clock
+	Log     *logger.Logger
+	CntrVec map[string]*prometheus.CounterVec
+
+	mu     sync.Mutex
+	groups map[string]*aggregateGroup
+}
+
+// Represents occurrences of an alert, for a group.
+type aggregateGroup struct {
+	window      time.Duration
+	occurrences []time.Time
+	alert       of.Alert // Labels are kept from the first occurrence, to fire a single alert.
+	fired       bool
+}
+
+// Init Aggregator.
+func NewAggregator(clock of.Clock, l *logger.Logger, cntrVec map[string]*prometheus.CounterVec) *Aggregator {
+	return &Aggregator{
+		Clock:   clock,
+		Log:     l,
+		CntrVec: cntrVec,
+		groups:  make(map[string]*aggregateGroup),
+	}
+}
+
+// Record an occurrence of firing alert for alertCfg.
+// Returns the aggregated alert and true, once min_count occurrences are seen within window.
+func (g *Aggregator) Observe(cfgName string, alertCfg of_snmp.Alert, alert of.Alert) (of.Alert, bool) {
+	agg := alertCfg.Aggregate
+	if agg == nil {
+		return alert, true
+	}
+
+	t := alert.StartsAt
+	if t.IsZero() {
+		t = g.Clock.Now()
+	}
+	window := time.Duration(agg.Window) * time.Second
+	key := g.key(cfgName, alertCfg, alert)
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	grp, ok := g.groups[key]
+	if ok == false {
+		grp = &aggregateGroup{alert: copyAlert(alert)}
+		g.groups[key] = grp
+	}
+	grp.window = window
+
+	// Forget occurrences that are out of window.
+	occurrences := grp.occurrences[:0]
+	for _, o := range grp.occurrences {
+		if t.Sub(o) < window {
+			occurrences = append(occurrences, o)
+		}
+	}
+	grp.occurrences = append(occurrences, t)
+	if c, ok := g.CntrVec[alertsAggregatedCount]; ok == true {
+		c.Incr(map[string]string{
+			"config": cfgName,
+			"alert":  alertCfg.Name,
+		})
+	}
+
+	if len(grp.occurrences) < agg.MinCount {
+		g.Log.WithFields(map[string]interface{}{
+			"config":      cfgName,
+			"alert":       alertCfg.Name,
+			"occurrences": len(grp.occurrences),
+			"min_count":   agg.MinCount,
+		}).Debugf("Alert held back for aggregation.")
+		return alert, false
+	}
+
+	grp.fired = true
+	aggAlert := copyAlert(grp.alert)
+	for k, v := range alert.Annotations {
+		aggAlert.Annotations[k] = v
+	}
+	firstSeen := grp.occurrences[0]
+	lastSeen := grp.occurrences[len(grp.occurrences)-1]
+	aggAlert.Annotations["occurrences"] = strconv.Itoa(len(grp.occurrences))
+	aggAlert.Annotations["first_seen"] = firstSeen.Format(time.RFC3339)
+	aggAlert.Annotations["last_seen"] = lastSeen.Format(time.RFC3339)
+	aggAlert.StartsAt = firstSeen
+
+	// Alert auto-clears, if no occurrences are seen in window.
+	aggAlert.EndsAt = lastSeen.Add(window)
+	aggAlert.Labels[of_snmp.FingerprintText] = fingerprint(aggAlert)
+	return aggAlert, true
+}
+
+// Forget occurrences of alert, when it is cleared.
+func (g *Aggregator) Reset(cfgName string, alertCfg of_snmp.Alert, alert of.Alert) {
+	if alertCfg.Aggregate == nil {
+		return
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	delete(g.groups, g.key(cfgName, alertCfg, alert))
+}
+
+// Forget groups without occurrences in window. Returns clearing alerts for groups that had fired.
+func (g *Aggregator) Sweep() []of.Alert {
+	now := g.Clock.Now()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	var alerts []of.Alert
+	for key, grp := range g.groups {
+		lastSeen := grp.occurrences[len(grp.occurrences)-1]
+		if now.Sub(lastSeen) < grp.window {
+			continue
+		}
+
+		delete(g.groups, key)
+		if grp.fired == false {
+			continue
+		}
+
+		cAlert := copyAlert(grp.alert)
+		cAlert.Annotations[string(of_snmp.EventTypeText)] = string(of_snmp.Clearing)
+		cAlert.Annotations["last_seen"] = lastSeen.Format(time.RFC3339)
+		cAlert.StartsAt = grp.occurrences[0]
+		cAlert.EndsAt = lastSeen.Add(grp.window)
+		cAlert.Labels[of_snmp.FingerprintText] = fingerprint(cAlert)
+		alerts = append(alerts, cAlert)
+	}
+	return alerts
+}
+
+// Key identifying the group an alert belongs to.
+func (g *Aggregator) key(cfgName string, alertCfg of_snmp.Alert, alert of.Alert) string {
+	keys := alertCfg.Aggregate.GroupBy
+	if len(keys) == 0 {
+		for k, _ := range alert.Labels {
+			if k == "alert_oid" || k == of_snmp.FingerprintText {
+				continue
+			}
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+	}
+
+	parts := []string{cfgName, alertCfg.Name}
+	for _, k := range keys {
+		parts = append(parts, k+"="+alert.Labels[k])
+	}
+	return strings.Join(parts, "\xff")
+}
+
+// Deep copy of alert labels and annotations.
+func copyAlert(alert of.Alert) of.Alert {
+	newAlert := alert
+	newAlert.Labels = make(map[string]string, len(alert.Labels))
+	for k, v := range alert.Labels {
+		newAlert.Labels[k] = v
+	}
+	newAlert.Annotations = make(map[string]string, len(alert.Annotations))
+	for k, v := range alert.Annotations {
+		newAlert.Annotations[k] = v
+	}
+	return newAlert
+}
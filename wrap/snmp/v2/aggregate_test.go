@@ -0,0 +1,127 @@
+package v2_test
+
+import (
+	"testing"
+	"time"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+	of_snmp "github.com/cisco-cx/of/pkg/v2/snmp"
+	logger "github.com/cisco-cx/of/wrap/logrus/v2"
+	snmp "github.com/cisco-cx/of/wrap/snmp/v2"
+	clock "github.com/cisco-cx/of/wrap/time/v2"
+	"github.com/stretchr/testify/require"
+)
+
+var aggregateCfg = of_snmp.Alert{
+	Name: "authFailure",
+	Aggregate: &of_snmp.Aggregate{
+		Window:   60,
+		GroupBy:  []string{"source_address"},
+		MinCount: 3,
+	},
+}
+
+func aggregateAlert(source string, eventID string, t time.Time) of.Alert {
+	return of.Alert{
+		Labels: map[string]string{
+			"alertname":      "authFailure",
+			"source_address": source,
+			"alert_oid":      ".1.3.6.1.6.3.1.1.5.5",
+		},
+		Annotations: map[string]string{
+			"event_type": "firing",
+			"event_id":   eventID,
+		},
+		StartsAt: t,
+	}
+}
+
+// Test alert fires once min_count occurrences are seen within window, and clears after window.
+func TestAggregator(t *testing.T) {
+	start := time.Date(2020, 5, 1, 22, 22, 53, 0, time.UTC)
+	c := &clock.FixedClock{T: start}
+	l := logger.New()
+	_, cntrVec := snmp.InitCounters(t.Name(), l)
+	g := snmp.NewAggregator(c, l, cntrVec)
+
+	// Below min_count.
+	for i, id := range []string{"1", "2"} {
+		_, fire := g.Observe("epc", aggregateCfg, aggregateAlert("192.168.1.28", id, start.Add(time.Duration(i*10)*time.Second)))
+		require.False(t, fire)
+	}
+
+	// Other groups are counted separately.
+	_, fire := g.Observe("epc", aggregateCfg, aggregateAlert("192.168.1.29", "x", start))
+	require.False(t, fire)
+
+	alert, fire := g.Observe("epc", aggregateCfg, aggregateAlert("192.168.1.28", "3", start.Add(20*time.Second)))
+	require.True(t, fire)
+	require.Equal(t, "3", alert.Annotations["occurrences"])
+	require.Equal(t, "2020-05-01T22:22:53Z", alert.Annotations["first_seen"])
+	require.Equal(t, "2020-05-01T22:23:13Z", alert.Annotations["last_seen"])
+	require.Equal(t, "3", alert.Annotations["event_id"])
+	require.Equal(t, start, alert.StartsAt)
+	require.Equal(t, start.Add(80*time.Second), alert.EndsAt)
+	require.NotEmpty(t, alert.Labels[of_snmp.FingerprintText])
+	fingerprint := alert.Labels[of_snmp.FingerprintText]
+
+	// Further occurrences keep the alert firing.
+	alert, fire = g.Observe("epc", aggregateCfg, aggregateAlert("192.168.1.28", "4", start.Add(30*time.Second)))
+	require.True(t, fire)
+	require.Equal(t, "4", alert.Annotations["occurrences"])
+	require.Equal(t, fingerprint, alert.Labels[of_snmp.FingerprintText])
+
+	// Window has not passed.
+	c.Add(60 * time.Second)
+	require.Empty(t, g.Sweep())
+
+	// Window passed without new occurrences.
+	c.Add(30 * time.Second)
+	alerts := g.Sweep()
+	require.Len(t, alerts, 1)
+	require.Equal(t, string(of_snmp.Clearing), alerts[0].Annotations["event_type"])
+	require.Equal(t, start.Add(90*time.Second), alerts[0].EndsAt)
+	require.Equal(t, fingerprint, alerts[0].Labels[of_snmp.FingerprintText])
+	require.Empty(t, g.Sweep())
+
+	metrics := promMetrics(t)
+	require.Contains(t, metrics, t.Name()+`_alerts_aggregated_count{alert="authFailure",config="epc"} 5`)
+}
+
+// Test occurrences out of window are not counted.
+func TestAggregatorWindow(t *testing.T) {
+	start := time.Date(2020, 5, 1, 22, 22, 53, 0, time.UTC)
+	c := &clock.FixedClock{T: start}
+	g := snmp.NewAggregator(c, logger.New(), nil)
+
+	for i := 0; i < 5; i++ {
+		_, fire := g.Observe("epc", aggregateCfg, aggregateAlert("192.168.1.28", "1", start.Add(time.Duration(i*40)*time.Second)))
+		require.False(t, fire)
+	}
+
+	// Groups that never fired are forgotten without clearing alert.
+	c.Add(10 * time.Minute)
+	require.Empty(t, g.Sweep())
+}
+
+// Test clearing alert resets occurrences.
+func TestAggregatorReset(t *testing.T) {
+	start := time.Date(2020, 5, 1, 22, 22, 53, 0, time.UTC)
+	c := &clock.FixedClock{T: start}
+	g := snmp.NewAggregator(c, logger.New(), nil)
+
+	g.Observe("epc", aggregateCfg, aggregateAlert("192.168.1.28", "1", start))
+	g.Observe("epc", aggregateCfg, aggregateAlert("192.168.1.28", "2", start))
+	g.Reset("epc", aggregateCfg, aggregateAlert("192.168.1.28", "3", start))
+	_, fire := g.Observe("epc", aggregateCfg, aggregateAlert("192.168.1.28", "4", start))
+	require.False(t, fire)
+}
+
+// Test alerts without aggregation are not held back.
+func TestAggregatorDisabled(t *testing.T) {
+	g := snmp.NewAggregator(&clock.FixedClock{}, logger.New(), nil)
+	in := aggregateAlert("192.168.1.28", "1", time.Time{})
+	alert, fire := g.Observe("epc", of_snmp.Alert{Name: "authFailure"}, in)
+	require.True(t, fire)
+	require.Equal(t, in, alert)
+}
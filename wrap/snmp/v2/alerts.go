@@ -24,6 +24,15 @@ type Alerter struct {
 	CntrVec        map[string]*prometheus.CounterVec
 	LogUnknown     bool
 	ForwardUnknown bool
+	Aggregator     *Aggregator
+	Heartbeats     *HeartbeatTracker
+	Suppressor     *Suppressor
+	Registry       *Registry
+	Maintenance    *MaintenanceGuard
+	Tracer         of_snmp.Tracer      // Explains select decisions and mods, if set.
+	Audit          *AuditTrail         // Collects alert decisions, if set.
+	Clock          of.Clock            // Current time, for alerts ends at. Defaults to wall clock.
+	Rules          of_snmp.RuleTracker // Counts hits of alert configs, if set.
 }
 
 // Iterate through configs in configNames and generate all possible Alerts.
@@ -64,7 +73,11 @@ func (a *Alerter) Alert(cfgNames []string) []of.Alert {
 			"SNMPTrapOIDValue": trapV,
 		}).Tracef("Trying to identify device.")
 
-		if a.deviceIdentified(cfg.Defaults.DeviceIdentifiers) == false {
+		applicable := a.deviceIdentified(cfg.Defaults.DeviceIdentifiers)
+		if a.Tracer != nil {
+			a.Tracer.Config(cfgName, applicable)
+		}
+		if applicable == false {
 			a.Log.WithFields(map[string]interface{}{
 				"PduSecurity":      a.Receipts.Snmptrapd.PduSecurity,
 				"config":           cfgName,
@@ -85,25 +98,45 @@ func (a *Alerter) Alert(cfgNames []string) []of.Alert {
 			a.Log.Tracef("Checking Alert no. %d (%v) in config: %s", aNum, alertCfg.Name, cfgName)
 			var enabled = a.enabled(cfg.Defaults.Enabled, alertCfg.Enabled)
 
+			// Heartbeats are tracked, missingHeartbeat alerts are raised in background.
+			if alertCfg.Heartbeat != nil {
+				if trapV != alertCfg.Heartbeat.Oid {
+					continue
+				}
+				alertMatchedConfig = true
+				a.ruleHit(cfgName, aNum, alertCfg, of_snmp.RuleMatched)
+				if enabled == false {
+					a.Log.Tracef("Alert no. %d (%v), not enabled in config: %s", aNum, alertCfg.Name, cfgName)
+					a.ruleHit(cfgName, aNum, alertCfg, of_snmp.RuleDisabled)
+					a.audit(cfgName, aNum, alertCfg, of.Alert{}, of.AuditDropped, "alert disabled")
+					continue
+				}
+				allAlerts = append(allAlerts, a.heartbeat(cfgName, cfg, alertCfg, fixedAnnotations)...)
+				continue
+			}
+
 			// Check if trap Vars have any alert matching firing conditions.
 			fAlert, err := a.matchAlerts(cfg, alertCfg, of_snmp.Firing, fixedAnnotations)
+			if err != nil && err != of.ErrNoMatch {
+				a.ruleHit(cfgName, aNum, alertCfg, of_snmp.RuleError)
+				a.audit(cfgName, aNum, alertCfg, of.Alert{}, of.AuditFailed, err.Error())
+			}
 			if err == nil {
 				alertMatchedAlertCfg = true
 				alertMatchedConfig = true
+				a.ruleHit(cfgName, aNum, alertCfg, of_snmp.RuleMatched)
 
 				if enabled == false {
 					// Printing alert no., since alert name can be nil.
 					a.Log.Tracef("Alert no. %d (%v), not enabled in config: %s", aNum, alertCfg.Name, cfgName)
+					a.ruleHit(cfgName, aNum, alertCfg, of_snmp.RuleDisabled)
+					a.audit(cfgName, aNum, alertCfg, fAlert, of.AuditDropped, "alert disabled")
 					continue
 				}
 
 				fAlert.Annotations[string(of_snmp.EventTypeText)] = string(of_snmp.Firing)
 				// Setting `alert_oid` as the value of of_snmp.SNMPTrapOID
 				fAlert.Labels["alert_oid"] = fAlert.Annotations["event_oid"]
-				a.CntrVec[alertsGeneratedCount].Incr(map[string]string{
-					"alertType": "firing",
-					"alert_oid": fAlert.Labels["alert_oid"],
-				})
 				a.StartsAt(&fAlert)
 				a.AutoClear(cfg.Defaults.EndsAt, alertCfg.EndsAt, &fAlert)
 
@@ -111,7 +144,49 @@ func (a *Alerter) Alert(cfgNames []string) []of.Alert {
 				fingerprint := a.Fingerprint(fAlert)
 				fAlert.Labels[of_snmp.FingerprintText] = fingerprint
 
+				// Drop, tag or delay alert, during maintenance.
+				var fire bool
+				if fAlert, fire = a.maintenance(cfgName, cfg, fAlert); fire == false {
+					a.traceHeld(fAlert, "during maintenance")
+					a.audit(cfgName, aNum, alertCfg, fAlert, of.AuditSuppressed, "during maintenance")
+					continue
+				}
+
+				// Hold back alert, until enough occurrences are seen within window.
+				if fAlert, fire = a.aggregate(cfgName, alertCfg, fAlert); fire == false {
+					a.traceHeld(fAlert, "aggregating occurrences")
+					a.audit(cfgName, aNum, alertCfg, fAlert, of.AuditSuppressed, "aggregating occurrences")
+					continue
+				}
+
+				// Hold back or tag alert, while its parent is firing.
+				if fAlert, fire = a.suppress(cfgName, alertCfg, fAlert); fire == false {
+					a.traceHeld(fAlert, "suppressed by parent")
+					a.audit(cfgName, aNum, alertCfg, fAlert, of.AuditSuppressed, "suppressed by parent")
+					continue
+				}
+
+				// Counted once emitted, occurrences held back for aggregation are not.
+				a.CntrVec[alertsGeneratedCount].Incr(map[string]string{
+					"alertType": "firing",
+					"alert_oid": fAlert.Labels["alert_oid"],
+				})
 				allAlerts = append(allAlerts, fAlert)
+				a.traceAlert(fAlert)
+				a.ruleHit(cfgName, aNum, alertCfg, of_snmp.RuleFired)
+				switch {
+				case fAlert.Annotations[of_snmp.SuppressedText] != "":
+					a.audit(cfgName, aNum, alertCfg, fAlert, of.AuditSuppressed, "tagged, suppressed by parent")
+				case fAlert.Annotations[of.MaintenanceText] != "":
+					a.audit(cfgName, aNum, alertCfg, fAlert, of.AuditSuppressed, "tagged during maintenance")
+				default:
+					a.audit(cfgName, aNum, alertCfg, fAlert, of.AuditGenerated, "firing")
+				}
+				onFireAlerts := a.onFire(cfgName, alertCfg, fAlert)
+				for _, oAlert := range onFireAlerts {
+					a.audit(cfgName, aNum, alertCfg, oAlert, of.AuditGenerated, "on_fire")
+				}
+				allAlerts = append(allAlerts, onFireAlerts...)
 				a.Log.WithFields(map[string]interface{}{
 					"alertType":   "firing",
 					"labels":      fAlert.Labels,
@@ -136,22 +211,31 @@ func (a *Alerter) Alert(cfgNames []string) []of.Alert {
 
 			// Check if trap Vars have any alerts matching clearing conditions.
 			cAlert, err := a.matchAlerts(cfg, alertCfg, of_snmp.Clearing, fixedAnnotations)
+			if err != nil && err != of.ErrNoMatch {
+				a.ruleHit(cfgName, aNum, alertCfg, of_snmp.RuleError)
+				a.audit(cfgName, aNum, alertCfg, of.Alert{}, of.AuditFailed, err.Error())
+			}
 			if err == nil {
 				alertMatchedAlertCfg = true
 				alertMatchedConfig = true
+				a.ruleHit(cfgName, aNum, alertCfg, of_snmp.RuleMatched)
 
 				if enabled == false {
 					// Printing alert no., since alert name can be nil.
 					a.Log.Tracef("Alert no. %d (%v), not enabled in config: %s", aNum, alertCfg.Name, cfgName)
+					a.ruleHit(cfgName, aNum, alertCfg, of_snmp.RuleDisabled)
+					a.audit(cfgName, aNum, alertCfg, cAlert, of.AuditDropped, "alert disabled")
 					continue
 				}
 
 				// Add end time to clearing alerts.
 				cAlert.Annotations[string(of_snmp.EventTypeText)] = string(of_snmp.Clearing)
-				cAlert.EndsAt = time.Now().UTC()
+				cAlert.EndsAt = a.now()
 				a.EndsAt(&cAlert)
+				a.resetAggregate(cfgName, alertCfg, cAlert)
 
 				a.Cntr[clearingEventCount].Incr()
+				cleared := false
 				// For `selects` under firing.
 				for _, s := range alertCfg.Firing["select"] {
 					// Add each OID under values as `alert_oid`
@@ -173,6 +257,8 @@ func (a *Alerter) Alert(cfgNames []string) []of.Alert {
 								"alert_oid": v,
 							})
 							a.Log.WithError(err).Errorf("Failed to marshal clear alert, %+v", cAlert)
+							a.ruleHit(cfgName, aNum, alertCfg, of_snmp.RuleError)
+							a.audit(cfgName, aNum, alertCfg, cAlert, of.AuditFailed, err.Error())
 							continue
 						}
 						newCAlert := of.Alert{}
@@ -183,10 +269,15 @@ func (a *Alerter) Alert(cfgNames []string) []of.Alert {
 								"alert_oid": v,
 							})
 							a.Log.WithError(err).Errorf("Failed to unmarshal clear alert, %s", string(alertJson))
+							a.ruleHit(cfgName, aNum, alertCfg, of_snmp.RuleError)
+							a.audit(cfgName, aNum, alertCfg, cAlert, of.AuditFailed, err.Error())
 							continue
 						}
 
 						allAlerts = append(allAlerts, newCAlert)
+						a.traceAlert(newCAlert)
+						a.audit(cfgName, aNum, alertCfg, newCAlert, of.AuditGenerated, "clearing")
+						cleared = true
 						a.Log.WithFields(map[string]interface{}{
 							"alertType":   "clearing",
 							"labels":      cAlert.Labels,
@@ -208,6 +299,9 @@ func (a *Alerter) Alert(cfgNames []string) []of.Alert {
 						a.Log.Tracef("alert_json : %+v", string(alertJson))
 					}
 				}
+				if cleared == true {
+					a.ruleHit(cfgName, aNum, alertCfg, of_snmp.RuleCleared)
+				}
 			}
 
 			// Alert not generated for `alertCfg`
@@ -273,12 +367,91 @@ func (a *Alerter) EndsAt(alert *of.Alert) {
 
 func (a *Alerter) AutoClear(defEndsAt int, alertEndsAt int, alert *of.Alert) {
 	if defEndsAt != 0 {
-		alert.EndsAt = time.Now().UTC().Add(time.Duration(defEndsAt) * time.Minute)
+		alert.EndsAt = a.now().Add(time.Duration(defEndsAt) * time.Minute)
 	}
 
 	if alertEndsAt != 0 {
-		alert.EndsAt = time.Now().UTC().Add(time.Duration(alertEndsAt) * time.Minute)
+		alert.EndsAt = a.now().Add(time.Duration(alertEndsAt) * time.Minute)
+	}
+}
+
+// Current time in UTC.
+func (a *Alerter) now() time.Time {
+	if a.Clock != nil {
+		return a.Clock.Now().UTC()
 	}
+	return time.Now().UTC()
+}
+
+// Check firing alert against maintenance windows.
+func (a *Alerter) maintenance(cfgName string, cfg of_snmp.Config, alert of.Alert) (of.Alert, bool) {
+	if a.Maintenance == nil {
+		return alert, true
+	}
+
+	target := of.MaintenanceTarget{
+		Address: a.Receipts.Snmptrapd.Source.Address,
+		Labels:  alert.Labels,
+	}
+	if cfg.Defaults.SourceType == of_snmp.ClusterType {
+		target.Cluster = a.cluster(cfg.Defaults.Clusters)
+	}
+	return a.Maintenance.Check(cfgName, target, alert)
+}
+
+// Name of the cluster, the source address belongs to.
+func (a *Alerter) cluster(clusters map[string]of_snmp.Cluster) string {
+	for clusterName, cluster := range clusters {
+		for _, ip := range cluster.SourceAddresses {
+			if ip == a.Receipts.Snmptrapd.Source.Address {
+				return clusterName
+			}
+		}
+	}
+	return ""
+}
+
+// Aggregate firing alert, if aggregation is configured for alertCfg.
+func (a *Alerter) aggregate(cfgName string, alertCfg of_snmp.Alert, alert of.Alert) (of.Alert, bool) {
+	if a.Aggregator == nil {
+		return alert, true
+	}
+	return a.Aggregator.Observe(cfgName, alertCfg, alert)
+}
+
+// Check firing alert against suppressed_by rules.
+func (a *Alerter) suppress(cfgName string, alertCfg of_snmp.Alert, alert of.Alert) (of.Alert, bool) {
+	if a.Suppressor == nil {
+		return alert, true
+	}
+	return a.Suppressor.Suppress(cfgName, alertCfg, alert)
+}
+
+// Forget aggregated occurrences, when alert is cleared.
+func (a *Alerter) resetAggregate(cfgName string, alertCfg of_snmp.Alert, alert of.Alert) {
+	if a.Aggregator == nil {
+		return
+	}
+	a.Aggregator.Reset(cfgName, alertCfg, alert)
+}
+
+// Record heartbeat for the source. Returns clearing missingHeartbeat alert, if heartbeats had stopped.
+func (a *Alerter) heartbeat(cfgName string, cfg of_snmp.Config, alertCfg of_snmp.Alert, fixedAnnotations map[string]string) []of.Alert {
+	if a.Heartbeats == nil {
+		return nil
+	}
+
+	alert, err := a.prepareAlert(cfg, alertCfg, nil, fixedAnnotations)
+	if err != nil {
+		a.Log.WithError(err).Errorf("Failed to prepare heartbeat for config: %s", cfgName)
+		return nil
+	}
+	a.Log.WithFields(map[string]interface{}{
+		"config": cfgName,
+		"source": a.Receipts.Snmptrapd.Source,
+		"oid":    alertCfg.Heartbeat.Oid,
+	}).Tracef("Heartbeat received.")
+	return a.Heartbeats.Beat(cfgName, *alertCfg.Heartbeat, alert)
 }
 
 // Create alert for unknown SNMP trap.
@@ -335,6 +508,7 @@ func (a *Alerter) Unknown(level string) []of.Alert {
 	alert.Labels["source_hostname"] = a.Receipts.Snmptrapd.Source.Hostname
 
 	alert.Labels[of_snmp.FingerprintText] = a.Fingerprint(alert)
+	a.traceAlert(alert)
 
 	return []of.Alert{alert}
 }
@@ -355,7 +529,7 @@ func (a *Alerter) matchAlerts(cfg of_snmp.Config, alertCfg of_snmp.Alert, alertT
 	}
 
 	// Check if trap Vars have any alerts matching select conditions.
-	matched, err := a.selected(selects)
+	matched, err := a.selected(alertCfg.Name, alertType, selects)
 	if err != nil {
 		a.Log.WithError(err).Errorf("Error while trying to match alert.")
 		return alert, err
@@ -375,6 +549,13 @@ func (a *Alerter) matchAlerts(cfg of_snmp.Config, alertCfg of_snmp.Alert, alertT
 	}).Debugf("Alert matched.")
 
 	// Prepare alert since the selects have matched.
+	return a.prepareAlert(cfg, alertCfg, selects, fixedAnnotations)
+}
+
+// Prepare alert for alertCfg, applying default, alert and select specific mods.
+func (a *Alerter) prepareAlert(cfg of_snmp.Config, alertCfg of_snmp.Alert, selects []of_snmp.Select, fixedAnnotations map[string]string) (of.Alert, error) {
+
+	var alert = of.Alert{}
 
 	// Init new Alert
 	alert.Labels = make(map[string]string)
@@ -386,7 +567,7 @@ func (a *Alerter) matchAlerts(cfg of_snmp.Config, alertCfg of_snmp.Alert, alertT
 	}
 
 	// Preparing base alert.
-	err = a.prepareBaseAlert(&alert, &cfg)
+	err := a.prepareBaseAlert(&alert, &cfg)
 	if err != nil {
 		a.Log.WithError(err).Errorf("Error while preparing base alert.")
 		return alert, err
@@ -394,14 +575,14 @@ func (a *Alerter) matchAlerts(cfg of_snmp.Config, alertCfg of_snmp.Alert, alertT
 
 	// Apply alert specific labels.
 	alert.Annotations["event_id"] = a.U.UUID()
-	err = a.applyMod(&alert.Labels, alertCfg.LabelMods)
+	err = a.applyMod("labels", &alert.Labels, alertCfg.LabelMods)
 	if err != nil {
 		a.Log.WithError(err).Errorf("Error while applying alert mods to labels.")
 		return alert, err
 	}
 
 	// Apply alert specific annotations.
-	err = a.applyMod(&alert.Annotations, alertCfg.AnnotationMods)
+	err = a.applyMod("annotations", &alert.Annotations, alertCfg.AnnotationMods)
 	if err != nil {
 		a.Log.WithError(err).Errorf("Error while applying alert mods to annotations.")
 		return alert, err
@@ -418,7 +599,7 @@ func (a *Alerter) matchAlerts(cfg of_snmp.Config, alertCfg of_snmp.Alert, alertT
 	for _, sel := range selects {
 
 		// Apply select specific annotations.
-		err = a.applyMod(&alert.Annotations, sel.AnnotationMods)
+		err = a.applyMod("annotations", &alert.Annotations, sel.AnnotationMods)
 		if err != nil {
 			a.Log.WithError(err).Errorf("Error while applying alert mods to annotations.")
 			return alert, err
@@ -430,7 +611,7 @@ func (a *Alerter) matchAlerts(cfg of_snmp.Config, alertCfg of_snmp.Alert, alertT
 }
 
 // Check if give selects are applicable.
-func (a *Alerter) selected(selects []of_snmp.Select) (bool, error) {
+func (a *Alerter) selected(alertName string, alertType of_snmp.EventType, selects []of_snmp.Select) (bool, error) {
 	// If none selects are mentioned, then match should fail.
 	if len(selects) == 0 {
 		return false, nil
@@ -441,6 +622,7 @@ func (a *Alerter) selected(selects []of_snmp.Select) (bool, error) {
 		// Find value based on the of_snmp.As type.
 		resolvedValue, err := a.Value.ValueAs(sel.Oid, sel.As)
 		if err != nil {
+			a.traceSelect(alertName, alertType, sel, resolvedValue, false, err)
 			a.Log.WithError(err).Errorf("Failed to resolve %s as %s.", sel.Oid, sel.As)
 			return false, err
 		}
@@ -453,6 +635,7 @@ func (a *Alerter) selected(selects []of_snmp.Select) (bool, error) {
 				break
 			}
 		}
+		a.traceSelect(alertName, alertType, sel, resolvedValue, valueFound, nil)
 
 		// If value is not found in of_snmp.Config.Alerts[x].Select[y].Values, stop checking for other selects.
 		if valueFound == false {
@@ -498,14 +681,14 @@ func (a *Alerter) prepareBaseAlert(alert *of.Alert, cfg *of_snmp.Config) error {
 	}
 
 	// Apply default mods to Labels
-	err := a.applyMod(&(alert.Labels), cfg.Defaults.LabelMods)
+	err := a.applyMod("labels", &(alert.Labels), cfg.Defaults.LabelMods)
 	if err != nil {
 		a.Log.WithError(err).Errorf("Failed to apply default mods to labels.")
 		return err
 	}
 
 	// Apply default mods to Annotations
-	err = a.applyMod(&(alert.Annotations), cfg.Defaults.AnnotationMods)
+	err = a.applyMod("annotations", &(alert.Annotations), cfg.Defaults.AnnotationMods)
 	if err != nil {
 		a.Log.WithError(err).Errorf("Failed to apply default mods to annotations.")
 		return err
@@ -568,11 +751,13 @@ func (a *Alerter) fixedAnnotations() map[string]string {
 	return fixedAnnotations
 }
 
-func (a *Alerter) applyMod(mapPtr *map[string]string, mods []of_snmp.Mod) error {
+func (a *Alerter) applyMod(target string, mapPtr *map[string]string, mods []of_snmp.Mod) error {
 
 	// Init modifier
 	m := Modifier{
-		V: a.Value,
+		V:      a.Value,
+		Tracer: a.Tracer,
+		Target: target,
 	}
 
 	a.Log.WithField("value", a.Value).Tracef("Mod values")
@@ -586,6 +771,41 @@ func (a *Alerter) applyMod(mapPtr *map[string]string, mods []of_snmp.Mod) error
 	return nil
 }
 
+// Report select decision to Tracer.
+func (a *Alerter) traceSelect(alertName string, alertType of_snmp.EventType, sel of_snmp.Select, value string, matched bool, err error) {
+	if a.Tracer != nil {
+		a.Tracer.Select(alertName, alertType, sel, value, matched, err)
+	}
+}
+
+// Count result for alert config.
+func (a *Alerter) ruleHit(cfgName string, aNum int, alertCfg of_snmp.Alert, result of_snmp.RuleResult) {
+	if a.Rules != nil {
+		a.Rules.Hit(cfgName, ruleName(aNum, alertCfg), result)
+	}
+}
+
+// Report generated alert to Tracer.
+func (a *Alerter) traceAlert(alert of.Alert) {
+	if a.Tracer != nil {
+		a.Tracer.Alert(alert)
+	}
+}
+
+// Add decision for alert of config to the audit trail.
+func (a *Alerter) audit(cfgName string, aNum int, alertCfg of_snmp.Alert, alert of.Alert, action of.AuditAction, reason string) {
+	if a.Audit != nil {
+		a.Audit.Add(cfgName, ruleName(aNum, alertCfg), alert, action, reason)
+	}
+}
+
+// Report alert not sent to Tracer.
+func (a *Alerter) traceHeld(alert of.Alert, reason string) {
+	if a.Tracer != nil {
+		a.Tracer.Held(alert, reason)
+	}
+}
+
 // Update source info based on of.TrapSource
 func (a *Alerter) updateSource(alert *of.Alert) {
 	alert.Labels["source_address"] = a.Receipts.Snmptrapd.Source.Address
@@ -636,6 +856,11 @@ func (a *Alerter) deviceIdentified(identifiers []string) bool {
 // true 				false 				Disabled
 // true 				true 				Enabled
 func (a *Alerter) enabled(defEnabled *bool, alertEnabled *bool) bool {
+	return isEnabled(defEnabled, alertEnabled)
+}
+
+// Decide if alert is enabled, as described for Alerter.enabled.
+func isEnabled(defEnabled *bool, alertEnabled *bool) bool {
 	// If both are undefined.
 	if defEnabled == nil && alertEnabled == nil {
 		return true
@@ -662,6 +887,11 @@ func (a *Alerter) enabled(defEnabled *bool, alertEnabled *bool) bool {
 
 // Fingerprint the alert.
 func (a *Alerter) Fingerprint(al of.Alert) string {
+	return fingerprint(al)
+}
+
+// Fingerprint alert labels, ignoring any existing fingerprint.
+func fingerprint(al of.Alert) string {
 	labels := make(prommodel.LabelSet)
 	for k, v := range al.Labels {
 		if k == "alert_fingerprint" {
@@ -15,6 +15,7 @@ import (
 	of_snmp "github.com/cisco-cx/of/pkg/v2/snmp"
 	logger "github.com/cisco-cx/of/wrap/logrus/v2"
 	snmp "github.com/cisco-cx/of/wrap/snmp/v2"
+	clock "github.com/cisco-cx/of/wrap/time/v2"
 	uuid "github.com/cisco-cx/of/wrap/uuid/v2"
 	yaml "github.com/cisco-cx/of/wrap/yaml/v2"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -34,6 +35,23 @@ func TestAlertFire(t *testing.T) {
 	}
 }
 
+// Test occurrences held back for aggregation are not counted as generated.
+func TestAlertFireAggregated(t *testing.T) {
+	ag := newAlerter(t)
+	cfg := (*ag.Configs)["epc"]
+	for i := range cfg.Alerts {
+		cfg.Alerts[i].Aggregate = &of_snmp.Aggregate{Window: 60, GroupBy: []string{"source_address"}, MinCount: 3}
+	}
+	ag.Aggregator = snmp.NewAggregator(&clock.FixedClock{T: time.Date(2020, 5, 1, 22, 22, 53, 0, time.UTC)}, ag.Log, nil)
+
+	var fired int
+	for i := 0; i < 4; i++ {
+		fired += len(ag.Alert([]string{"epc"}))
+	}
+	require.Equal(t, 2, fired)
+	require.Contains(t, promMetrics(t), `TestAlertFireAggregated_alerts_generated_count{alertType="firing",alert_oid=".1.3.6.1.4.1.8164.1.2.1.1.1"} 2`)
+}
+
 // Test Alerts firing.
 func fireAlert(ag *snmp.Alerter, count int, t *testing.T) {
 
@@ -0,0 +1,53 @@
+package v2
+
+import (
+	of "github.com/cisco-cx/of/pkg/v2"
+	of_snmp "github.com/cisco-cx/of/pkg/v2/snmp"
+)
+
+// Collects alert decisions taken for an event, to be audited once alerts are posted.
+type AuditTrail struct {
+	EventID string
+	Source  string
+	TrapOID string
+	Clock   of.Clock
+	Records []of.AuditRecord
+}
+
+// Add decision for alert of config.
+func (t *AuditTrail) Add(cfgName string, alertName string, alert of.Alert, action of.AuditAction, reason string) {
+	t.Records = append(t.Records, of.AuditRecord{
+		Time:        t.Clock.Now().UTC(),
+		EventID:     t.EventID,
+		Source:      t.Source,
+		TrapOID:     t.TrapOID,
+		Config:      cfgName,
+		Alert:       alertName,
+		Fingerprint: alert.Labels[of_snmp.FingerprintText],
+		Action:      action,
+		Reason:      reason,
+	})
+}
+
+// Add alerts generated in the background, with source taken from their labels.
+func (t *AuditTrail) AddAlerts(alerts []of.Alert, reason string) {
+	for _, alert := range alerts {
+		t.Add("", alert.Labels["alertname"], alert, of.AuditGenerated, reason)
+		if source := alert.Labels["source_address"]; source != "" {
+			t.Records[len(t.Records)-1].Source = source
+		}
+	}
+}
+
+// Set the result of posting alerts on generated alerts.
+func (t *AuditTrail) Posted(result string, err error) {
+	for i := range t.Records {
+		if t.Records[i].Action != of.AuditGenerated {
+			continue
+		}
+		t.Records[i].PostResult = result
+		if err != nil {
+			t.Records[i].PostError = err.Error()
+		}
+	}
+}
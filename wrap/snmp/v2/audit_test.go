@@ -0,0 +1,148 @@
+package v2_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+	logger "github.com/cisco-cx/of/wrap/logrus/v2"
+	queue "github.com/cisco-cx/of/wrap/queue/v2"
+	snmp "github.com/cisco-cx/of/wrap/snmp/v2"
+	clock "github.com/cisco-cx/of/wrap/time/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// Represents of.Auditor, keeping records in memory.
+type testAuditor struct {
+	records []of.AuditRecord
+}
+
+func (a *testAuditor) Audit(records []of.AuditRecord) error {
+	a.records = append(a.records, records...)
+	return nil
+}
+
+func (a *testAuditor) Close() error {
+	return nil
+}
+
+// Represents of.Notifier, failing every post.
+type failingAlertService struct{}
+
+func (as *failingAlertService) Notify(*[]of.Alert) error {
+	return errors.New("connection refused")
+}
+
+// Test decisions for alerts are audited with the result of posting them.
+func TestServiceAudit(t *testing.T) {
+	s := initService(t, t.Name())
+	a := &testAuditor{}
+	s.Audit = a
+
+	docs := []of.Document{}
+	require.NoError(t, json.Unmarshal([]byte(StarEvents), &docs))
+	events := make([]*of.PostableEvent, len(docs))
+	for i, doc := range docs {
+		events[i] = &of.PostableEvent{Document: doc}
+	}
+	require.NoError(t, s.Handle(events))
+
+	type decision struct {
+		trapOID, alert, fingerprint, reason string
+	}
+	var decisions []decision
+	for _, record := range a.records {
+		require.Equal(t, "9dcc77fc-dda5-4edf-a683-64f2589036d6", record.EventID)
+		require.Equal(t, "dead::beef", record.Source)
+		require.Equal(t, "config1", record.Config)
+		require.Equal(t, of.AuditGenerated, record.Action)
+		require.Equal(t, of.AuditPostSent, record.PostResult)
+		require.False(t, record.Time.IsZero())
+		decisions = append(decisions, decision{record.TrapOID, record.Alert, record.Fingerprint, record.Reason})
+	}
+	require.Equal(t, []decision{
+		{".1.3.6.1.4.1.8164.2.150", "starTaskFailure", "26c5384f07068e37", "firing"},
+		{".1.3.6.1.4.1.8164.2.150", "starTaskRestart", "60dfb17ae277c3c8", "firing"},
+		{".1.3.6.1.4.1.8164.2.151", "starTaskFailure", "26c5384f07068e37", "clearing"},
+		{".1.3.6.1.4.1.8164.2.151", "starTaskRestart", "a8e70c001d09366f", "firing"},
+	}, decisions)
+
+	// Failed posts are audited with the error.
+	a.records = nil
+	s.As = &failingAlertService{}
+	require.Error(t, s.Handle(events[:1]))
+	require.Len(t, a.records, 2)
+	require.Equal(t, of.AuditPostFailed, a.records[0].PostResult)
+	require.Equal(t, "connection refused", a.records[0].PostError)
+
+	// Queued alerts are kept for retry, the event is accepted.
+	dir, err := ioutil.TempDir("", "alert-queue")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	as := s.As
+	q, err := queue.NewQueue(&of.QueueConfig{Dir: dir}, t.Name(), func(alerts []of.Alert) error {
+		return as.Notify(&alerts)
+	}, &clock.Clock{}, logger.New())
+	require.NoError(t, err)
+	defer q.Close()
+	s.As = q
+	a.records = nil
+	require.NoError(t, s.Handle(events[:1]))
+	require.Len(t, a.records, 2)
+	require.Equal(t, of.AuditPostQueued, a.records[0].PostResult)
+	q.Deliver()
+	require.Len(t, q.Pending(), 1)
+	require.Equal(t, "connection refused", q.Pending()[0].LastError)
+}
+
+// Test alerts tagged, or released in the background, are audited.
+func TestServiceAuditBackground(t *testing.T) {
+	s := initService(t, t.Name())
+	a := &testAuditor{}
+	s.Audit = a
+	c := &clock.FixedClock{T: time.Date(2020, 5, 1, 22, 22, 53, 0, time.UTC)}
+	m := &testMaintenance{window: of.MaintenanceWindow{Name: "upgrade", Action: of.MaintenanceTag}, on: true}
+	s.Maintenance = snmp.NewMaintenanceGuard(m, c, s.Log, nil)
+	var sent []of.Alert
+	s.As = of.NotifierFunc(func(alerts *[]of.Alert) error {
+		sent = append(sent, *alerts...)
+		return nil
+	})
+
+	docs := []of.Document{}
+	require.NoError(t, json.Unmarshal([]byte(StarEvents), &docs))
+	events := []*of.PostableEvent{&of.PostableEvent{Document: docs[0]}}
+
+	// Tagged alerts are audited as suppressed.
+	require.NoError(t, s.Handle(events))
+	require.Len(t, a.records, 2)
+	for _, record := range a.records {
+		require.Equal(t, of.AuditSuppressed, record.Action)
+		require.Equal(t, "tagged during maintenance", record.Reason)
+	}
+
+	// Delayed alerts are audited when released.
+	m.window.Action = of.MaintenanceDelay
+	a.records = nil
+	require.NoError(t, s.Handle(events))
+	require.Len(t, a.records, 2)
+	require.Equal(t, "during maintenance", a.records[0].Reason)
+
+	m.on = false
+	a.records, sent = nil, nil
+	s.Sweep()
+	require.Len(t, sent, 2)
+	require.Len(t, a.records, 2)
+	for _, record := range a.records {
+		require.Equal(t, "9dcc77fc-dda5-4edf-a683-64f2589036d6", record.EventID)
+		require.Equal(t, "dead::beef", record.Source)
+		require.Equal(t, of.AuditGenerated, record.Action)
+		require.Equal(t, "maintenance ended", record.Reason)
+		require.Equal(t, of.AuditPostSent, record.PostResult)
+		require.NotEmpty(t, record.Fingerprint)
+	}
+}
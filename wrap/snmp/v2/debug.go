@@ -0,0 +1,164 @@
+package v2
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+	of_snmp "github.com/cisco-cx/of/pkg/v2/snmp"
+	logger "github.com/cisco-cx/of/wrap/logrus/v2"
+)
+
+// Implements of_snmp.EventLog, keeping the last Size events in memory.
+type EventLog struct {
+	Size int
+	Log  *logger.Logger
+
+	mu     sync.RWMutex
+	events []of_snmp.DebugEvent // Ring buffer, next is the oldest once full.
+	next   int
+	lastID uint64
+	traced map[string]bool
+}
+
+// Init EventLog keeping the last size events.
+func NewEventLog(size int, l *logger.Logger) *EventLog {
+	return &EventLog{
+		Size:   size,
+		Log:    l,
+		events: make([]of_snmp.DebugEvent, 0, size),
+		traced: make(map[string]bool),
+	}
+}
+
+// Add event, setting its ID. The oldest event is replaced, once Size events are kept.
+func (e *EventLog) Add(event *of_snmp.DebugEvent) {
+	if e.Size <= 0 {
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.lastID++
+	event.ID = e.lastID
+	if len(e.events) < e.Size {
+		e.events = append(e.events, *event)
+		return
+	}
+	e.events[e.next] = *event
+	e.next = (e.next + 1) % len(e.events)
+}
+
+// Events matching filter, newest first.
+func (e *EventLog) Events(filter of_snmp.DebugFilter) []of_snmp.DebugEvent {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	events := make([]of_snmp.DebugEvent, 0)
+	for i := 0; i < len(e.events); i++ {
+		// Walk back from the newest event.
+		event := e.events[(e.next-1-i+2*len(e.events))%len(e.events)]
+		if filter.Source != "" && event.Source != filter.Source {
+			continue
+		}
+		if filter.TrapOID != "" && event.TrapOID != filter.TrapOID {
+			continue
+		}
+		if filter.Outcome != "" && event.Outcome != filter.Outcome {
+			continue
+		}
+		events = append(events, event)
+		if filter.Limit > 0 && len(events) >= filter.Limit {
+			break
+		}
+	}
+	return events
+}
+
+// Enable or disable logging traces of events from source address.
+func (e *EventLog) SetTrace(source string, enabled bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if enabled == true {
+		e.traced[source] = true
+		return
+	}
+	delete(e.traced, source)
+}
+
+// Check if traces of events from source address are logged.
+func (e *EventLog) Traced(source string) bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.traced[source]
+}
+
+// Source addresses for which traces are logged, sorted.
+func (e *EventLog) TracedSources() []string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	sources := make([]string, 0, len(e.traced))
+	for source := range e.traced {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+	return sources
+}
+
+// HTTP handler func, listing recent events newest first.
+// Filtered by query params source, trap_oid, outcome and limit.
+func (e *EventLog) Handler(w of.ResponseWriter, r of.Request) {
+	e.Log.Tracef("Debug events endpoint accessed.")
+	query := r.URL.Query()
+	filter := of_snmp.DebugFilter{
+		Source:  query.Get("source"),
+		TrapOID: query.Get("trap_oid"),
+		Outcome: of_snmp.EventOutcome(query.Get("outcome")),
+	}
+	if limit := query.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		filter.Limit = n
+	}
+	e.write(w, map[string]interface{}{
+		"events": e.Events(filter),
+	})
+}
+
+// HTTP handler func, listing sources for which traces are logged.
+// With POST, query params source and enabled toggle tracing for a source first.
+func (e *EventLog) TraceHandler(w of.ResponseWriter, r of.Request) {
+	e.Log.Tracef("Debug trace endpoint accessed.")
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		query := r.URL.Query()
+		source := query.Get("source")
+		enabled, err := strconv.ParseBool(query.Get("enabled"))
+		if source == "" || err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		e.SetTrace(source, enabled)
+		e.Log.Infof("Tracing of events from %s set to %t.", source, enabled)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	e.write(w, map[string]interface{}{
+		"sources": e.TracedSources(),
+	})
+}
+
+func (e *EventLog) write(w of.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		e.Log.WithError(err).Errorf("Failed to write debug events.")
+	}
+}
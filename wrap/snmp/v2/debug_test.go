@@ -0,0 +1,97 @@
+package v2_test
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+	of_snmp "github.com/cisco-cx/of/pkg/v2/snmp"
+	http "github.com/cisco-cx/of/wrap/http/v2"
+	logger "github.com/cisco-cx/of/wrap/logrus/v2"
+	snmp "github.com/cisco-cx/of/wrap/snmp/v2"
+	clock "github.com/cisco-cx/of/wrap/time/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// Enforce EventLog Interface
+func TestEventLogInterface(t *testing.T) {
+	var _ of_snmp.EventLog = &snmp.EventLog{}
+}
+
+// Test the last events are kept, and listed newest first by filter.
+func TestEventLog(t *testing.T) {
+	e := snmp.NewEventLog(3, logger.New())
+	for _, source := range []string{"a", "b", "a", "b"} {
+		e.Add(&of_snmp.DebugEvent{Source: source, Outcome: of_snmp.EventNoAlert})
+	}
+
+	events := e.Events(of_snmp.DebugFilter{})
+	require.Len(t, events, 3)
+	require.Equal(t, []uint64{4, 3, 2}, []uint64{events[0].ID, events[1].ID, events[2].ID})
+
+	events = e.Events(of_snmp.DebugFilter{Source: "b"})
+	require.Len(t, events, 2)
+	require.Equal(t, uint64(4), events[0].ID)
+
+	require.Len(t, e.Events(of_snmp.DebugFilter{Limit: 1}), 1)
+	require.Empty(t, e.Events(of_snmp.DebugFilter{Outcome: of_snmp.EventAlerted}))
+
+	e.SetTrace("a", true)
+	require.True(t, e.Traced("a"))
+	require.False(t, e.Traced("b"))
+	e.SetTrace("a", false)
+	require.Empty(t, e.TracedSources())
+}
+
+// Test events handled by the service are kept with their trace and alerts.
+func TestServiceDebugEvents(t *testing.T) {
+	s := initService(t, t.Name())
+	s.Debug = snmp.NewEventLog(10, s.Log)
+	receivedAt := time.Date(2020, 5, 1, 22, 22, 53, 0, time.UTC)
+	s.Clock = &clock.FixedClock{T: receivedAt}
+
+	docs := []of.Document{}
+	require.NoError(t, json.Unmarshal([]byte(StarEvents), &docs))
+	events := make([]*of.PostableEvent, len(docs))
+	for i, doc := range docs {
+		events[i] = &of.PostableEvent{Document: doc}
+	}
+	require.NoError(t, s.Handle(events))
+
+	kept := s.Debug.Events(of_snmp.DebugFilter{TrapOID: ".1.3.6.1.4.1.8164.2.150"})
+	require.Len(t, kept, 1)
+	require.Equal(t, "dead::beef", kept[0].Source)
+	require.Equal(t, receivedAt, kept[0].ReceivedAt)
+	require.Equal(t, of_snmp.EventAlerted, kept[0].Outcome)
+	require.NotEmpty(t, kept[0].Alerts)
+	require.Equal(t, `event from "dead::beef", trap .1.3.6.1.4.1.8164.2.150, 7 vars`, kept[0].Trace[0])
+	require.Equal(t, "  lookup: config1", kept[0].Trace[1])
+
+	// Events are listed over HTTP, filtered by query params.
+	rec := httptest.NewRecorder()
+	s.Debug.Handler(http.NewResponseWriter(rec), httptest.NewRequest("GET", "/api/v2/debug/events?source=dead::beef&limit=1", nil))
+	require.Equal(t, 200, rec.Code)
+	var body struct {
+		Events []of_snmp.DebugEvent `json:"events"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	require.Len(t, body.Events, 1)
+	require.Equal(t, ".1.3.6.1.4.1.8164.2.151", body.Events[0].TrapOID)
+
+	rec = httptest.NewRecorder()
+	s.Debug.Handler(http.NewResponseWriter(rec), httptest.NewRequest("GET", "/api/v2/debug/events?limit=x", nil))
+	require.Equal(t, 400, rec.Code)
+
+	// Tracing is toggled per source.
+	rec = httptest.NewRecorder()
+	s.Debug.TraceHandler(http.NewResponseWriter(rec), httptest.NewRequest("POST", "/api/v2/debug/trace?source=dead::beef&enabled=true", nil))
+	require.Equal(t, 200, rec.Code)
+	require.JSONEq(t, `{"sources": ["dead::beef"]}`, rec.Body.String())
+	require.True(t, s.Debug.Traced("dead::beef"))
+
+	rec = httptest.NewRecorder()
+	s.Debug.TraceHandler(http.NewResponseWriter(rec), httptest.NewRequest("POST", "/api/v2/debug/trace?source=dead::beef", nil))
+	require.Equal(t, 400, rec.Code)
+}
@@ -0,0 +1,266 @@
+package v2
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+	of_snmp "github.com/cisco-cx/of/pkg/v2/snmp"
+	logger "github.com/cisco-cx/of/wrap/logrus/v2"
+	prometheus "github.com/cisco-cx/of/wrap/prometheus/client_golang/v2"
+	clock "github.com/cisco-cx/of/wrap/time/v2"
+	uuid "github.com/cisco-cx/of/wrap/uuid/v2"
+)
+
+// Implements of_snmp.Differ, running each event through Lookup and Alerter of both runtimes.
+// Like Evaluator, stateful processing such as aggregation and suppression is not applied.
+type Differ struct {
+	Old     *Runtime
+	New     *Runtime
+	Log     *logger.Logger
+	Cntr    map[string]*prometheus.Counter
+	CntrVec map[string]*prometheus.CounterVec
+}
+
+// Implements of_snmp.Tracer, collecting alerts with the config and alert config generating them.
+type alertCollector struct {
+	cfgName   string
+	alertName string
+	alerts    []collectedAlert
+}
+
+// Represents an alert, and the config and alert config generating it.
+type collectedAlert struct {
+	cfgName   string
+	alertName string
+	alert     of.Alert
+}
+
+// Identifies alerts of an alert config.
+type diffKey struct {
+	change    of_snmp.ChangeType
+	cfgName   string
+	alertName string
+	alertType string
+}
+
+// Compare alerts generated for events, with old and new runtimes.
+func (d *Differ) Diff(events []*of.PostableEvent) of_snmp.DiffReport {
+	diffs := make(map[diffKey]*of_snmp.AlertDiff)
+	configs := make(map[string]*of_snmp.ConfigDiff)
+	config := func(cfgName string) *of_snmp.ConfigDiff {
+		if _, ok := configs[cfgName]; ok == false {
+			configs[cfgName] = &of_snmp.ConfigDiff{Config: cfgName}
+		}
+		return configs[cfgName]
+	}
+	record := func(change of_snmp.ChangeType, idx int, event *of.PostableEvent, before *collectedAlert, after *collectedAlert) {
+		c := before
+		if c == nil {
+			c = after
+		}
+		key := diffKey{change: change, cfgName: c.cfgName, alertName: c.alertName, alertType: alertType(c.alert)}
+		diff, ok := diffs[key]
+		if ok == false {
+			diff = &of_snmp.AlertDiff{
+				Change: change,
+				Config: c.cfgName,
+				Alert:  c.alertName,
+				Type:   key.alertType,
+				Example: of_snmp.DiffExample{
+					Event:  idx,
+					Source: event.Document.Receipts.Snmptrapd.Source.Address,
+				},
+			}
+			if before != nil {
+				diff.Example.Old = before.alert.Labels
+			}
+			if after != nil {
+				diff.Example.New = after.alert.Labels
+			}
+			diffs[key] = diff
+		}
+		diff.Count++
+	}
+
+	for idx, event := range events {
+		oldAlerts := d.evaluate(d.Old, event)
+		newAlerts := d.evaluate(d.New, event)
+
+		for key, before := range oldAlerts {
+			after, ok := newAlerts[key]
+			switch {
+			case ok == false:
+				record(of_snmp.AlertRemoved, idx, event, &before, nil)
+				config(before.cfgName).Removed++
+			case labelsEqual(before.alert.Labels, after.alert.Labels) == false:
+				record(of_snmp.AlertChanged, idx, event, &before, &after)
+				config(before.cfgName).Changed++
+			default:
+				config(before.cfgName).Unchanged++
+			}
+		}
+		for key, after := range newAlerts {
+			if _, ok := oldAlerts[key]; ok == false {
+				record(of_snmp.AlertAdded, idx, event, nil, &after)
+				config(after.cfgName).Added++
+			}
+		}
+	}
+
+	report := of_snmp.DiffReport{Events: len(events)}
+	for _, diff := range diffs {
+		report.Alerts = append(report.Alerts, *diff)
+	}
+	sort.Slice(report.Alerts, func(i, j int) bool {
+		a, b := report.Alerts[i], report.Alerts[j]
+		if a.Change != b.Change {
+			return changeOrder(a.Change) < changeOrder(b.Change)
+		}
+		if a.Config != b.Config {
+			return a.Config < b.Config
+		}
+		if a.Alert != b.Alert {
+			return a.Alert < b.Alert
+		}
+		return a.Type < b.Type
+	})
+	for _, c := range configs {
+		report.Configs = append(report.Configs, *c)
+	}
+	sort.Slice(report.Configs, func(i, j int) bool {
+		return report.Configs[i].Config < report.Configs[j].Config
+	})
+	return report
+}
+
+// Alerts generated for event with runtime, keyed by config, alert config, type and alert OID.
+func (d *Differ) evaluate(rt *Runtime, event *of.PostableEvent) map[string]collectedAlert {
+	c := &alertCollector{}
+	e := Evaluator{
+		Runtime: rt,
+		Log:     d.Log,
+		U:       &uuid.FixedUUID{},
+		Clock:   &clock.FixedClock{T: defaultTestTime},
+		Cntr:    d.Cntr,
+		CntrVec: d.CntrVec,
+		Tracer:  c,
+	}
+	e.Evaluate([]*of.PostableEvent{event})
+
+	alerts := make(map[string]collectedAlert)
+	for _, a := range c.alerts {
+		base := strings.Join([]string{a.cfgName, a.alertName, alertType(a.alert), a.alert.Labels["alert_oid"]}, "/")
+		key := base
+		// Alerts generated more than once for an event are told apart by occurrence.
+		for n := 1; ; n++ {
+			if _, ok := alerts[key]; ok == false {
+				break
+			}
+			key = fmt.Sprintf("%s#%d", base, n)
+		}
+		alerts[key] = a
+	}
+	return alerts
+}
+
+func (c *alertCollector) Event(*of.PostableEvent) {
+	c.cfgName, c.alertName = "", ""
+}
+
+func (c *alertCollector) Lookup([]string, error) {}
+
+func (c *alertCollector) Config(cfgName string, applicable bool) {
+	c.cfgName, c.alertName = cfgName, ""
+}
+
+func (c *alertCollector) Select(alertName string, eventType of_snmp.EventType, sel of_snmp.Select, value string, matched bool, err error) {
+	c.alertName = alertName
+}
+
+func (c *alertCollector) Mod(string, of_snmp.Mod, string, bool, error) {}
+
+func (c *alertCollector) Alert(alert of.Alert) {
+	c.alerts = append(c.alerts, collectedAlert{cfgName: c.cfgName, alertName: c.alertName, alert: alert})
+}
+
+func (c *alertCollector) Held(of.Alert, string) {}
+
+// Check if labels are equal, ignoring fingerprint.
+func labelsEqual(a map[string]string, b map[string]string) bool {
+	return len(labelsDiff(a, b)) == 0
+}
+
+// Differences of labels, ignoring fingerprint, like -key="old", +key="new" and key: "old" -> "new".
+func labelsDiff(before map[string]string, after map[string]string) []string {
+	keys := make(map[string]string) // Keys in either, values are ignored.
+	for k, v := range before {
+		keys[k] = v
+	}
+	for k, v := range after {
+		keys[k] = v
+	}
+
+	var diff []string
+	for _, k := range sortedKeys(keys) {
+		if k == of_snmp.FingerprintText {
+			continue
+		}
+		o, inOld := before[k]
+		n, inNew := after[k]
+		switch {
+		case inOld == false:
+			diff = append(diff, fmt.Sprintf("+%s=%q", k, n))
+		case inNew == false:
+			diff = append(diff, fmt.Sprintf("-%s=%q", k, o))
+		case o != n:
+			diff = append(diff, fmt.Sprintf("%s: %q -> %q", k, o, n))
+		}
+	}
+	return diff
+}
+
+// Order of changes in report.
+func changeOrder(change of_snmp.ChangeType) int {
+	switch change {
+	case of_snmp.AlertAdded:
+		return 0
+	case of_snmp.AlertRemoved:
+		return 1
+	}
+	return 2
+}
+
+// Write report in readable form, with an example of each difference.
+func WriteDiff(w io.Writer, report of_snmp.DiffReport) error {
+	fmt.Fprintf(w, "%d events, %d differences.\n", report.Events, len(report.Alerts))
+	for _, diff := range report.Alerts {
+		alertName := diff.Alert
+		if alertName == "" {
+			alertName = "unknown"
+		}
+		fmt.Fprintf(w, "\n%s: %s/%s %s, %d alerts\n", diff.Change, diff.Config, alertName, diff.Type, diff.Count)
+		fmt.Fprintf(w, "  e.g. event %d from %q\n", diff.Example.Event, diff.Example.Source)
+		switch diff.Change {
+		case of_snmp.AlertAdded:
+			fmt.Fprintf(w, "    %s\n", labelsString(diff.Example.New))
+		case of_snmp.AlertRemoved:
+			fmt.Fprintf(w, "    %s\n", labelsString(diff.Example.Old))
+		default:
+			for _, d := range labelsDiff(diff.Example.Old, diff.Example.New) {
+				fmt.Fprintf(w, "    %s\n", d)
+			}
+		}
+	}
+
+	fmt.Fprintf(w, "\nSummary by config:\n")
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "  config\tunchanged\tadded\tremoved\tchanged\n")
+	for _, c := range report.Configs {
+		fmt.Fprintf(tw, "  %s\t%d\t%d\t%d\t%d\n", c.Config, c.Unchanged, c.Added, c.Removed, c.Changed)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,110 @@
+package v2_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	of_snmp "github.com/cisco-cx/of/pkg/v2/snmp"
+	logger "github.com/cisco-cx/of/wrap/logrus/v2"
+	snmp "github.com/cisco-cx/of/wrap/snmp/v2"
+	yaml "github.com/cisco-cx/of/wrap/yaml/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// Enforce Differ Interface
+func TestDifferInterface(t *testing.T) {
+	var _ of_snmp.Differ = &snmp.Differ{}
+}
+
+// Runtime with configs in content.
+func diffRuntime(t *testing.T, content string, l *logger.Logger) *snmp.Runtime {
+	cfg := yaml.Configs{}
+	require.NoError(t, cfg.Decode(strings.NewReader(content)))
+	configs := of_snmp.V2Config(cfg)
+
+	mr := mibRegistry(t)
+	lookup := snmp.Lookup{Configs: configs, MR: mr, Log: l}
+	require.NoError(t, lookup.Build())
+	return &snmp.Runtime{Configs: &configs, MR: mr, Lookup: &lookup}
+}
+
+// Test alerts added, removed and changed by new configs.
+func TestDiff(t *testing.T) {
+	l := logger.New()
+	// starCard severity is raised, and starCardBootFailed fires for another trap.
+	newContent := strings.Replace(YamlContent, `      value: error
+    - type: set
+      key: alert_name`, `      value: critical
+    - type: set
+      key: alert_name`, 1)
+	newContent = strings.Replace(newContent, "        - .1.3.6.1.4.1.8164.2.9\n", "        - .1.3.6.1.4.1.8164.2.44\n", 1)
+	require.NotEqual(t, YamlContent, newContent)
+
+	cntr, cntrVec := snmp.InitCounters(t.Name(), l)
+	d := snmp.Differ{
+		Old:     diffRuntime(t, YamlContent, l),
+		New:     diffRuntime(t, newContent, l),
+		Log:     l,
+		Cntr:    cntr,
+		CntrVec: cntrVec,
+	}
+
+	events, err := snmp.ParseEvents(strings.NewReader(`
+source=192.168.1.28 .1.3.6.1.6.3.1.1.4.1=.1.3.6.1.4.1.8164.2.44 .1.3.6.1.4.1.8164.1.2.1.1.1=14
+source=192.168.1.29 .1.3.6.1.6.3.1.1.4.1=.1.3.6.1.4.1.8164.2.44 .1.3.6.1.4.1.8164.1.2.1.1.1=2
+source=192.168.1.30 .1.3.6.1.6.3.1.1.4.1=.1.3.6.1.4.1.8164.2.9 .1.3.6.1.4.1.8164.1.2.1.1.1=3
+source=192.168.1.28 .1.3.6.1.6.3.1.1.4.1=.1.3.6.1.4.1.8164.2.5 .1.3.6.1.4.1.8164.1.2.1.1.1=14
+`))
+	require.NoError(t, err)
+
+	report := d.Diff(events)
+	require.Equal(t, 4, report.Events)
+	// Clearing alerts are generated for each firing value, with alert_oid set to it.
+	require.Len(t, report.Alerts, 6)
+
+	added := report.Alerts[1]
+	require.Equal(t, of_snmp.AlertAdded, added.Change)
+	require.Equal(t, "epc", added.Config)
+	require.Equal(t, "starCardBootFailed", added.Alert)
+	require.Equal(t, "firing", added.Type)
+	require.Equal(t, 2, added.Count)
+	require.Equal(t, 0, added.Example.Event)
+	require.Nil(t, added.Example.Old)
+	require.Equal(t, "14", added.Example.New["star_slot_num"])
+
+	removed := report.Alerts[3]
+	require.Equal(t, of_snmp.AlertRemoved, removed.Change)
+	require.Equal(t, "starCardBootFailed", removed.Alert)
+	require.Equal(t, "firing", removed.Type)
+	require.Equal(t, 1, removed.Count)
+	require.Equal(t, 2, removed.Example.Event)
+	require.Equal(t, "192.168.1.30", removed.Example.Source)
+	require.Nil(t, removed.Example.New)
+
+	changed := report.Alerts[4]
+	require.Equal(t, of_snmp.AlertChanged, changed.Change)
+	require.Equal(t, "starCard", changed.Alert)
+	require.Equal(t, "clearing", changed.Type)
+	require.Equal(t, 4, changed.Count)
+
+	changed = report.Alerts[5]
+	require.Equal(t, of_snmp.AlertChanged, changed.Change)
+	require.Equal(t, "starCard", changed.Alert)
+	require.Equal(t, "firing", changed.Type)
+	require.Equal(t, 2, changed.Count)
+	require.Equal(t, "error", changed.Example.Old["alert_severity"])
+	require.Equal(t, "critical", changed.Example.New["alert_severity"])
+
+	require.Equal(t, []of_snmp.ConfigDiff{
+		of_snmp.ConfigDiff{Config: "epc", Unchanged: 0, Added: 3, Removed: 2, Changed: 6},
+	}, report.Configs)
+
+	var b bytes.Buffer
+	require.NoError(t, snmp.WriteDiff(&b, report))
+	out := b.String()
+	require.Contains(t, out, "4 events, 6 differences.\n")
+	require.Contains(t, out, "\nadded: epc/starCardBootFailed firing, 2 alerts\n  e.g. event 0 from \"192.168.1.28\"\n")
+	require.Contains(t, out, "\nchanged: epc/starCard firing, 2 alerts\n  e.g. event 0 from \"192.168.1.28\"\n    alert_severity: \"error\" -> \"critical\"\n")
+	require.Contains(t, out, "  config  unchanged  added  removed  changed\n  epc     0          3      2        6\n")
+}
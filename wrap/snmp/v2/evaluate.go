@@ -0,0 +1,192 @@
+package v2
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"time"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+	of_snmp "github.com/cisco-cx/of/pkg/v2/snmp"
+	logger "github.com/cisco-cx/of/wrap/logrus/v2"
+	prometheus "github.com/cisco-cx/of/wrap/prometheus/client_golang/v2"
+)
+
+// Evaluates events offline against a runtime, returning alerts instead of sending them.
+// Storm control, aggregation, heartbeats, suppression and maintenance depend on state
+// built across events, and are not applied.
+type Evaluator struct {
+	Runtime        *Runtime
+	Log            *logger.Logger
+	U              of.UUIDGen
+	Clock          of.Clock // Timestamps events without one, and sets alerts ends at.
+	Cntr           map[string]*prometheus.Counter
+	CntrVec        map[string]*prometheus.CounterVec
+	ForwardUnknown bool
+	Tracer         of_snmp.Tracer // Explains lookup, select decisions and mods, if set.
+}
+
+// Generate alerts for events, the way AlertHandler does.
+func (e *Evaluator) Evaluate(events []*of.PostableEvent) []of.Alert {
+	alerter := Alerter{
+		Log:            e.Log,
+		Configs:        e.Runtime.Configs,
+		MR:             e.Runtime.MR,
+		U:              e.U,
+		Cntr:           e.Cntr,
+		CntrVec:        e.CntrVec,
+		ForwardUnknown: e.ForwardUnknown,
+		Tracer:         e.Tracer,
+		Clock:          e.Clock,
+	}
+
+	alerts := make([]of.Alert, 0)
+	for _, event := range events {
+		snmptrapd := &event.Document.Receipts.Snmptrapd
+		if snmptrapd.Timestamp == "" && e.Clock != nil {
+			snmptrapd.Timestamp = e.Clock.Now().UTC().Format(time.RFC3339)
+		}
+		if e.Tracer != nil {
+			e.Tracer.Event(event)
+		}
+
+		cfgNames, err := e.Runtime.Lookup.Find(&snmptrapd.Vars)
+		if e.Tracer != nil {
+			e.Tracer.Lookup(cfgNames, err)
+		}
+		if err != nil {
+			e.Log.WithError(err).Errorf("Lookup failed.")
+			continue
+		}
+
+		alerter.Receipts = &event.Document.Receipts
+		alerter.Value = NewValue(&snmptrapd.Vars, e.Runtime.MR)
+		if len(cfgNames) == 0 {
+			alerts = append(alerts, alerter.Unknown("lookup")...)
+			continue
+		}
+		alerts = append(alerts, alerter.Alert(cfgNames)...)
+	}
+	return alerts
+}
+
+// Read events, as a JSON array or stream of PostableEvent, or in compact form.
+// In compact form each line is an event, with space separated oid=value trap vars,
+// and optional source=, hostname=, pdu_security= and timestamp= fields.
+// Values containing spaces are double quoted. Lines starting with # are ignored.
+//
+//	source=10.0.0.1 .1.3.6.1.6.3.1.1.4.1.0=.1.3.6.1.4.1.8164.2.13 .1.3.6.1.4.1.8164.1.2.1.1.1="slot 7"
+func ParseEvents(r io.Reader) ([]*of.PostableEvent, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return nil, nil
+	}
+
+	var events []*of.PostableEvent
+	switch trimmed[0] {
+	case '[':
+		if err := json.Unmarshal(trimmed, &events); err != nil {
+			return nil, err
+		}
+	case '{':
+		d := json.NewDecoder(bytes.NewReader(trimmed))
+		for d.More() {
+			event := &of.PostableEvent{}
+			if err := d.Decode(event); err != nil {
+				return nil, err
+			}
+			events = append(events, event)
+		}
+	default:
+		for n, line := range strings.Split(string(trimmed), "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			event, err := parseCompactEvent(line)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %s", n+1, err.Error())
+			}
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+// Parse an event in compact form.
+func parseCompactEvent(line string) (*of.PostableEvent, error) {
+	fields, err := splitFields(line)
+	if err != nil {
+		return nil, err
+	}
+
+	event := &of.PostableEvent{}
+	snmptrapd := &event.Document.Receipts.Snmptrapd
+	for _, field := range fields {
+		idx := strings.Index(field, "=")
+		if idx <= 0 {
+			return nil, fmt.Errorf("%q is not a key=value pair", field)
+		}
+		key, value := field[:idx], field[idx+1:]
+		if strings.HasPrefix(value, `"`) {
+			if value, err = strconv.Unquote(value); err != nil {
+				return nil, fmt.Errorf("invalid quoted value of %s", key)
+			}
+		}
+
+		switch {
+		case strings.HasPrefix(key, "."):
+			snmptrapd.Vars = append(snmptrapd.Vars, of.TrapVar{Oid: key, Value: value})
+		case key == "source":
+			snmptrapd.Source.Address = value
+		case key == "hostname":
+			snmptrapd.Source.Hostname = value
+		case key == "pdu_security":
+			snmptrapd.PduSecurity = value
+		case key == "timestamp":
+			snmptrapd.Timestamp = value
+		default:
+			return nil, fmt.Errorf("unknown key %q, OIDs start with a dot", key)
+		}
+	}
+	return event, nil
+}
+
+// Split line on spaces, outside of double quotes.
+func splitFields(line string) ([]string, error) {
+	var fields []string
+	var field strings.Builder
+	quoted, escaped := false, false
+	for _, c := range line {
+		switch {
+		case escaped:
+			escaped = false
+		case quoted && c == '\\':
+			escaped = true
+		case c == '"':
+			quoted = !quoted
+		case quoted == false && (c == ' ' || c == '\t'):
+			if field.Len() != 0 {
+				fields = append(fields, field.String())
+				field.Reset()
+			}
+			continue
+		}
+		field.WriteRune(c)
+	}
+	if quoted == true {
+		return nil, fmt.Errorf("unterminated quote")
+	}
+	if field.Len() != 0 {
+		fields = append(fields, field.String())
+	}
+	return fields, nil
+}
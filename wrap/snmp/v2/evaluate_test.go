@@ -0,0 +1,116 @@
+package v2_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+	of_snmp "github.com/cisco-cx/of/pkg/v2/snmp"
+	logger "github.com/cisco-cx/of/wrap/logrus/v2"
+	snmp "github.com/cisco-cx/of/wrap/snmp/v2"
+	clock "github.com/cisco-cx/of/wrap/time/v2"
+	uuid "github.com/cisco-cx/of/wrap/uuid/v2"
+	yaml "github.com/cisco-cx/of/wrap/yaml/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// Enforce Tracer Interface
+func TestTracerInterface(t *testing.T) {
+	var _ of_snmp.Tracer = &snmp.TextTracer{}
+}
+
+// Test events in compact form.
+func TestParseEventsCompact(t *testing.T) {
+	events, err := snmp.ParseEvents(strings.NewReader(`
+# starCardTempOverheat
+source=192.168.1.28 hostname=localhost .1.3.6.1.6.3.1.1.4.1=.1.3.6.1.4.1.8164.2.44 .1.3.6.1.2.1.1.3.0="(123) 0:00:01.23"
+
+.1.3.6.1.6.3.1.1.4.1=.1.3.6.1.4.1.8164.2.5 timestamp=2019-04-26T03:46:57Z
+`))
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+
+	snmptrapd := events[0].Document.Receipts.Snmptrapd
+	require.Equal(t, of.TrapSource{Address: "192.168.1.28", Hostname: "localhost"}, snmptrapd.Source)
+	require.Equal(t, []of.TrapVar{
+		of.TrapVar{Oid: ".1.3.6.1.6.3.1.1.4.1", Value: ".1.3.6.1.4.1.8164.2.44"},
+		of.TrapVar{Oid: ".1.3.6.1.2.1.1.3.0", Value: "(123) 0:00:01.23"},
+	}, snmptrapd.Vars)
+	require.Equal(t, "2019-04-26T03:46:57Z", events[1].Document.Receipts.Snmptrapd.Timestamp)
+
+	_, err = snmp.ParseEvents(strings.NewReader(".1.3.6.1.6.3.1.1.4.1=.1.3.6.1.4.1.8164.2.5\nslot=1\n"))
+	require.EqualError(t, err, `line 2: unknown key "slot", OIDs start with a dot`)
+
+	_, err = snmp.ParseEvents(strings.NewReader(`.1.3.6.1.2.1.1.3.0="(123) 0:00:01.23`))
+	require.EqualError(t, err, "line 1: unterminated quote")
+}
+
+// Test events as JSON array or stream.
+func TestParseEventsJSON(t *testing.T) {
+	events, err := snmp.ParseEvents(strings.NewReader(testEvents))
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+
+	stream := `{"document": {"receipts": {"snmptrapd": {"source": {"address": "10.0.0.1"}}}}}
+{"document": {"receipts": {"snmptrapd": {"source": {"address": "10.0.0.2"}}}}}`
+	events, err = snmp.ParseEvents(strings.NewReader(stream))
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	require.Equal(t, "10.0.0.2", events[1].Document.Receipts.Snmptrapd.Source.Address)
+}
+
+// Test decisions are explained for each event.
+func TestEvaluate(t *testing.T) {
+	cfg := yaml.Configs{}
+	require.NoError(t, cfg.Decode(strings.NewReader(YamlContent)))
+	configs := of_snmp.V2Config(cfg)
+
+	l := logger.New()
+	mr := mibRegistry(t)
+	lookup := snmp.Lookup{Configs: configs, MR: mr, Log: l}
+	require.NoError(t, lookup.Build())
+
+	cntr, cntrVec := snmp.InitCounters(t.Name(), l)
+	var b bytes.Buffer
+	e := snmp.Evaluator{
+		Runtime: &snmp.Runtime{Configs: &configs, MR: mr, Lookup: &lookup},
+		Log:     l,
+		U:       &uuid.FixedUUID{},
+		Clock:   &clock.FixedClock{T: time.Date(2019, 4, 26, 3, 46, 57, 0, time.UTC)},
+		Cntr:    cntr,
+		CntrVec: cntrVec,
+		Tracer:  &snmp.TextTracer{W: &b},
+	}
+
+	events, err := snmp.ParseEvents(strings.NewReader(`
+source=192.168.1.28 .1.3.6.1.6.3.1.1.4.1=.1.3.6.1.4.1.8164.2.44 .1.3.6.1.4.1.8164.1.2.1.1.1=14
+source=192.168.1.28 .1.3.6.1.6.3.1.1.4.1=.1.3.6.1.4.1.8164.2.5
+source=192.168.1.28 .1.3.6.1.6.3.1.1.4.1=.1.3.6.1.4.1.9.9.9
+`))
+	require.NoError(t, err)
+
+	alerts := e.Evaluate(events)
+	require.Len(t, alerts, 1)
+	require.Equal(t, "starCard", alerts[0].Labels["alert_name"])
+	require.Equal(t, "14", alerts[0].Labels["star_slot_num"])
+	require.Equal(t, time.Date(2019, 4, 26, 3, 46, 57, 0, time.UTC), alerts[0].StartsAt)
+
+	trace := b.String()
+	for _, line := range []string{
+		`event from "192.168.1.28", 2 vars`,
+		`  lookup: epc`,
+		`  config epc`,
+		`    alert starCard firing: .1.3.6.1.6.3.1.1.4.1 as value = ".1.3.6.1.4.1.8164.2.44", matched`,
+		`      labels set vendor = "cisco"`,
+		`      labels copy .1.3.6.1.4.1.8164.1.2.1.1.1 as value to star_slot_num = "14"`,
+		`    alert starCardBootFailed firing: .1.3.6.1.6.3.1.1.4.1 as value = ".1.3.6.1.4.1.8164.2.44", not in [".1.3.6.1.4.1.8164.2.9"]`,
+		`    alert starCard clearing: .1.3.6.1.6.3.1.1.4.1 as value = ".1.3.6.1.4.1.8164.2.5", matched`,
+		`      labels copy .1.3.6.1.4.1.8164.1.2.1.1.1 as value to star_slot_num failed, alert dropped: OID not present in trap vars.`,
+		`  lookup: no configs, unknown trap`,
+	} {
+		require.Contains(t, trace, line+"\n")
+	}
+	require.Contains(t, trace, `    => error alert {alert_fingerprint=`)
+}
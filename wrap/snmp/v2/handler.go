@@ -2,19 +2,30 @@ package v2
 
 import (
 	"fmt"
+	"io"
+	nethttp "net/http"
 	"net/url"
+	"strings"
+	"time"
 
 	of "github.com/cisco-cx/of/pkg/v2"
 	health "github.com/cisco-cx/of/wrap/health/v2"
 	http "github.com/cisco-cx/of/wrap/http/v2"
 	logger "github.com/cisco-cx/of/wrap/logrus/v2"
+	maintenance "github.com/cisco-cx/of/wrap/maintenance/v2"
 )
 
+// Interval at which alerts generated in background are published.
+const sweepInterval = time.Second
+
 type Handler struct {
-	Config *of.SNMPConfig
-	server *http.Server
-	SNMP   *Service
-	Log    *logger.Logger
+	Config      *of.SNMPConfig
+	server      *http.Server
+	SNMP        *Service
+	Log         *logger.Logger
+	Maintenance *maintenance.Schedule
+	done        chan struct{}
+	swept       chan struct{} // Closed when sweep returns.
 }
 
 func (h *Handler) Run() {
@@ -25,17 +36,27 @@ func (h *Handler) Run() {
 		WriteTimeout:  h.Config.AMTimeout,
 	}
 
-	// Add health check.
+	// Add health check for every Alertmanager peer.
 	hc := health.New()
-	amUrl, err := url.Parse(h.Config.AMAddress)
-	if err != nil {
-		h.Log.WithError(err).Fatalf("Failed to parse the Alerts manager address: %s", h.Config.AMAddress)
+	var rt nethttp.RoundTripper
+	if h.SNMP.AMTransport != nil {
+		rt = h.SNMP.AMTransport
+	}
+	peers := h.Config.AMPeers
+	if len(peers) == 0 {
+		peers = []string{h.Config.AMAddress}
 	}
+	for _, peer := range peers {
+		amUrl, err := url.Parse(peer)
+		if err != nil {
+			h.Log.WithError(err).Fatalf("Failed to parse the Alerts manager address: %s", peer)
+		}
 
-	healthUrl, _ := url.Parse("/-/healthy")
-	err = hc.AddURL("health_check", amUrl.ResolveReference(healthUrl).String(), h.Config.AMTimeout)
-	if err != nil {
-		h.Log.WithError(err).Fatalf("Failed to add health check.")
+		healthUrl, _ := url.Parse("/-/healthy")
+		err = hc.AddURLWithTransport(peer, amUrl.ResolveReference(healthUrl).String(), h.Config.AMTimeout, rt)
+		if err != nil {
+			h.Log.WithError(err).Fatalf("Failed to add health check.")
+		}
 	}
 
 	h.Log.Debugf("Init health check.")
@@ -52,13 +73,26 @@ func (h *Handler) Run() {
 	// Handling health check.
 	h.server.HandleFunc("/health", func(w of.ResponseWriter, r of.Request) {
 		h.Log.Tracef("Health endpoint accessed.")
-		err := hc.State("health_check")
-		if err != nil {
+		// Unhealthy if peers fail the policy, failed peers are reported either way.
+		var failed []string
+		for _, peer := range peers {
+			if err := hc.State(peer); err != nil {
+				failed = append(failed, fmt.Sprintf("%s: %s", peer, err.Error()))
+			}
+		}
+		if len(failed) != 0 && (h.Config.AMPolicy != of.AMPolicyAny || len(failed) == len(peers)) {
 			w.WriteHeader(http.StatusInternalServerError)
-			fmt.Fprint(w, err.Error())
+			fmt.Fprint(w, strings.Join(failed, "\n"))
 			return
 		}
+		// Still healthy with last good configs, but report failed reload.
 		w.WriteHeader(http.StatusOK)
+		if len(failed) != 0 {
+			fmt.Fprintf(w, "%s\n", strings.Join(failed, "\n"))
+		}
+		if err := h.SNMP.ReloadError(); err != nil {
+			fmt.Fprintf(w, "Config reload failed, running last good configs: %s", err.Error())
+		}
 	})
 	h.Log.Debugf("Added health handler.")
 
@@ -77,13 +111,33 @@ func (h *Handler) Run() {
 	h.server.HandleFunc("/api/v2/events", h.SNMP.AlertHandler)
 	h.Log.Debugf("Added event handler.")
 
+	h.server.HandleFunc("/api/v2/rules", h.SNMP.RulesHandler)
+	h.Log.Debugf("Added rules handler.")
+
+	// Listing recent events with decision traces.
+	if h.SNMP.Debug != nil {
+		h.server.HandleFunc("/api/v2/debug/events", h.SNMP.Debug.Handler)
+		h.server.HandleFunc("/api/v2/debug/trace", h.SNMP.Debug.TraceHandler)
+		h.Log.Debugf("Added debug handlers.")
+	}
+
+	// Listing maintenance windows.
+	if h.Maintenance != nil {
+		h.server.HandleFunc("/api/v2/maintenance", h.Maintenance.Handler)
+		h.Log.Debugf("Added maintenance handler.")
+	}
+
 	// Starting health check.
-	err = hc.Start()
+	err := hc.Start()
 	if err != nil {
 		h.Log.WithError(err).Fatalf("Failed to start at health check.")
 	}
 	h.Log.Debugf("Started health check.")
 
+	// Publish alerts generated in background.
+	h.done, h.swept = make(chan struct{}), make(chan struct{})
+	go h.sweep(h.done, h.swept)
+
 	h.Log.Infof("Starting SNMP handler server.")
 	// Starting SNMP server.
 	err = h.server.ListenAndServe()
@@ -92,6 +146,55 @@ func (h *Handler) Run() {
 	}
 }
 
+// Stop the server, waiting for requests in flight and the running sweep,
+// before closing files and sinks they write to.
 func (h *Handler) Shutdown() error {
-	return h.server.Shutdown()
+	err := h.server.Shutdown()
+	if h.done != nil {
+		close(h.done)
+		<-h.swept
+		h.done = nil
+	}
+	if h.SNMP.Recorder != nil {
+		if err := h.SNMP.Recorder.Close(); err != nil {
+			h.Log.WithError(err).Errorf("Failed to close recording.")
+		}
+	}
+	if h.SNMP.Audit != nil {
+		if err := h.SNMP.Audit.Close(); err != nil {
+			h.Log.WithError(err).Errorf("Failed to close audit log.")
+		}
+	}
+	if h.SNMP.Queue != nil {
+		if err := h.SNMP.Queue.Close(); err != nil {
+			h.Log.WithError(err).Errorf("Failed to close alert queue.")
+		}
+	}
+	if c, ok := h.SNMP.As.(io.Closer); ok == true {
+		if err := c.Close(); err != nil {
+			h.Log.WithError(err).Errorf("Failed to close alert sinks.")
+		}
+	}
+	if h.SNMP.AMTransport != nil {
+		if err := h.SNMP.AMTransport.Unwatch(); err != nil {
+			h.Log.WithError(err).Errorf("Failed to stop watching Alertmanager client credentials.")
+		}
+	}
+	return err
+}
+
+// Periodically publish alerts generated in background, until done is closed.
+// Closes swept on return.
+func (h *Handler) sweep(done chan struct{}, swept chan struct{}) {
+	defer close(swept)
+	ticker := time.NewTicker(sweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			h.SNMP.Sweep()
+		case <-done:
+			return
+		}
+	}
 }
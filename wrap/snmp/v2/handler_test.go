@@ -39,6 +39,7 @@ func TestHandlerRun(t *testing.T) {
 	require.NoError(t, err)
 	defer srv.Shutdown()
 	service := initService(t, "testingHandler")
+	service.Debug = snmp.NewEventLog(10, service.Log)
 	h := snmp.Handler{
 		SNMP:   service,
 		Log:    service.Log,
@@ -51,7 +52,7 @@ func TestHandlerRun(t *testing.T) {
 	checkResponse(t, 200, "http://"+cfg.ListenAddress, "Handler Test")
 
 	// Test health check
-	checkResponse(t, 500, "http://"+cfg.ListenAddress+"/health", "Received status code '502' does not match expected status code '200'")
+	checkResponse(t, 500, "http://"+cfg.ListenAddress+"/health", "http://"+addr+": Received status code '502' does not match expected status code '200'")
 
 	// Test metrics
 	message := getResponse(t, 200, "http://"+cfg.ListenAddress+"/metrics")
@@ -85,6 +86,34 @@ func TestHandlerRun(t *testing.T) {
 	res, err := c.Do(req)
 	require.NoError(t, err)
 	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	// Test rules
+	message = getResponse(t, 200, "http://"+cfg.ListenAddress+"/api/v2/rules")
+	require.Contains(t, message, `"rules"`)
+
+	// Test debug events
+	message = getResponse(t, 200, "http://"+cfg.ListenAddress+"/api/v2/debug/events?outcome=alerted")
+	require.Contains(t, message, `"outcome":"alerted"`)
+
+	// Retry queue is closed on shutdown.
+	q := &testQueue{}
+	h.SNMP.Queue = q
+	require.NoError(t, h.Shutdown())
+	require.True(t, q.closed)
+}
+
+// Represents of.AlertQueue, recording if it was closed.
+type testQueue struct {
+	closed bool
+}
+
+func (q *testQueue) Notify(*[]of.Alert) error {
+	return nil
+}
+
+func (q *testQueue) Close() error {
+	q.closed = true
+	return nil
 }
 
 // HTTP client to hit server and check response.
@@ -0,0 +1,193 @@
+package v2
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+	of_snmp "github.com/cisco-cx/of/pkg/v2/snmp"
+	logger "github.com/cisco-cx/of/wrap/logrus/v2"
+)
+
+// Firing missingHeartbeat alerts are resent at this interval, while heartbeats are missing.
+const heartbeatResendInterval = time.Minute
+
+// Tracks arrival of heartbeat traps per source, and raises missingHeartbeat alerts when they stop.
+// Tracking starts with the first heartbeat received from a source.
+type HeartbeatTracker struct {
+	Clock of.Clock
+	Log   *logger.Logger
+
+	mu      sync.Mutex
+	sources map[string]*heartbeatSource
+}
+
+// Represents heartbeat state of a source, for a config.
+type heartbeatSource struct {
+	config    string
+	heartbeat of_snmp.Heartbeat
+	labels    map[string]string
+	lastSeen  time.Time
+	missing   bool
+	since     time.Time
+	lastSent  time.Time
+}
+
+// Init HeartbeatTracker.
+func NewHeartbeatTracker(clock of.Clock, l *logger.Logger) *HeartbeatTracker {
+	return &HeartbeatTracker{
+		Clock:   clock,
+		Log:     l,
+		sources: make(map[string]*heartbeatSource),
+	}
+}
+
+// Record arrival of heartbeat for the source in alert labels.
+// Returns clearing missingHeartbeat alert, if heartbeats were missing for the source.
+func (h *HeartbeatTracker) Beat(cfgName string, hb of_snmp.Heartbeat, alert of.Alert) []of.Alert {
+	now := h.Clock.Now()
+	key := heartbeatKey(cfgName, hb.Oid, alert.Labels["source_address"])
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	s, ok := h.sources[key]
+	if ok == false {
+		s = &heartbeatSource{config: cfgName}
+		h.sources[key] = s
+		h.Log.WithFields(map[string]interface{}{
+			"config": cfgName,
+			"oid":    hb.Oid,
+			"source": alert.Labels["source_address"],
+		}).Debugf("Tracking heartbeat.")
+	}
+	s.heartbeat = hb
+	s.labels = alert.Labels
+	s.lastSeen = now
+
+	if s.missing == false {
+		return nil
+	}
+
+	s.missing = false
+	h.Log.WithFields(map[string]interface{}{
+		"config": cfgName,
+		"oid":    hb.Oid,
+		"source": alert.Labels["source_address"],
+	}).Infof("Heartbeat resumed.")
+	return []of.Alert{h.alert(s, of_snmp.Clearing, now)}
+}
+
+// Fire missingHeartbeat alerts for sources past interval + grace, and resend them while heartbeats are missing.
+func (h *HeartbeatTracker) Sweep() []of.Alert {
+	now := h.Clock.Now()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var alerts []of.Alert
+	for _, s := range h.sources {
+		if s.missing == true {
+			if now.Sub(s.lastSent) >= heartbeatResendInterval {
+				s.lastSent = now
+				alerts = append(alerts, h.alert(s, of_snmp.Firing, now))
+			}
+			continue
+		}
+
+		if now.Sub(s.lastSeen) > s.timeout() {
+			s.missing = true
+			s.since = now
+			s.lastSent = now
+			h.Log.WithFields(map[string]interface{}{
+				"config":   s.config,
+				"oid":      s.heartbeat.Oid,
+				"source":   s.labels["source_address"],
+				"lastSeen": s.lastSeen,
+			}).Warningf("Heartbeat missing.")
+			alerts = append(alerts, h.alert(s, of_snmp.Firing, now))
+		}
+	}
+	return alerts
+}
+
+// Apply heartbeat settings from reloaded configs, keeping the tracked state.
+// Sources of heartbeats that are no longer configured are forgotten.
+func (h *HeartbeatTracker) Reload(configs *of_snmp.V2Config) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for key, s := range h.sources {
+		hb, ok := findHeartbeat(configs, s.config, s.heartbeat.Oid)
+		if ok == false {
+			h.Log.WithFields(map[string]interface{}{
+				"config": s.config,
+				"oid":    s.heartbeat.Oid,
+				"source": s.labels["source_address"],
+			}).Infof("Heartbeat no longer configured, forgetting source.")
+			delete(h.sources, key)
+			continue
+		}
+		s.heartbeat = hb
+	}
+}
+
+// Find heartbeat with given OID, in config cfgName.
+func findHeartbeat(configs *of_snmp.V2Config, cfgName string, oid string) (of_snmp.Heartbeat, bool) {
+	cfg, ok := (*configs)[cfgName]
+	if ok == false {
+		return of_snmp.Heartbeat{}, false
+	}
+	for _, alertCfg := range cfg.Alerts {
+		if alertCfg.Heartbeat != nil && alertCfg.Heartbeat.Oid == oid {
+			return *alertCfg.Heartbeat, true
+		}
+	}
+	return of_snmp.Heartbeat{}, false
+}
+
+// Prepare missingHeartbeat alert for given source.
+func (h *HeartbeatTracker) alert(s *heartbeatSource, eventType of_snmp.EventType, now time.Time) of.Alert {
+	alert := of.Alert{
+		Labels:   make(map[string]string),
+		StartsAt: s.since,
+	}
+	for k, v := range s.labels {
+		if k == of_snmp.FingerprintText {
+			continue
+		}
+		alert.Labels[k] = v
+	}
+	alert.Labels["alertname"] = of_snmp.HeartbeatName
+	alert.Labels["alert_oid"] = s.heartbeat.Oid
+	alert.Labels["config"] = s.config
+
+	alert.Annotations = map[string]string{
+		string(of_snmp.EventTypeText): string(eventType),
+		"source_address":              s.labels["source_address"],
+		"source_hostname":             s.labels["source_hostname"],
+		"heartbeat_oid":               s.heartbeat.Oid,
+		"heartbeat_interval":          strconv.Itoa(s.heartbeat.Interval),
+		"heartbeat_grace":             strconv.Itoa(s.heartbeat.Grace),
+		"last_seen":                   s.lastSeen.Format(time.RFC3339),
+		"description": fmt.Sprintf("No heartbeat %s received from %s for %s, since %s.",
+			s.heartbeat.Oid, s.labels["source_address"], s.config, s.lastSeen.Format(time.RFC3339)),
+	}
+	if eventType == of_snmp.Clearing {
+		alert.EndsAt = now
+	}
+	alert.Labels[of_snmp.FingerprintText] = fingerprint(alert)
+	return alert
+}
+
+// Duration without heartbeat, after which it is considered missing.
+func (s *heartbeatSource) timeout() time.Duration {
+	return time.Duration(s.heartbeat.Interval+s.heartbeat.Grace) * time.Second
+}
+
+// Key identifying a heartbeat source.
+func heartbeatKey(cfgName string, oid string, source string) string {
+	return cfgName + "/" + oid + "/" + source
+}
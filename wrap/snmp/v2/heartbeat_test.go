@@ -0,0 +1,130 @@
+package v2_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+	of_snmp "github.com/cisco-cx/of/pkg/v2/snmp"
+	logger "github.com/cisco-cx/of/wrap/logrus/v2"
+	snmp "github.com/cisco-cx/of/wrap/snmp/v2"
+	clock "github.com/cisco-cx/of/wrap/time/v2"
+	uuid "github.com/cisco-cx/of/wrap/uuid/v2"
+	yaml "github.com/cisco-cx/of/wrap/yaml/v2"
+	"github.com/stretchr/testify/require"
+)
+
+var heartbeatConfig = `epc:
+  defaults:
+    source_type: host
+    label_mods:
+    - type: set
+      key: vendor
+      value: cisco
+  alerts:
+  - name: starHeartbeat
+    label_mods:
+    - type: set
+      key: alert_severity
+      value: critical
+    heartbeat:
+      oid: .1.3.6.1.4.1.8164.2.44
+      interval: 300
+      grace: 60
+`
+
+func heartbeatConfigs(t *testing.T, content string) of_snmp.V2Config {
+	cfg := yaml.Configs{}
+	err := cfg.Decode(strings.NewReader(content))
+	require.NoError(t, err)
+	return of_snmp.V2Config(cfg)
+}
+
+// Test missingHeartbeat fires after interval + grace, and clears when heartbeats resume.
+func TestHeartbeat(t *testing.T) {
+	c := &clock.FixedClock{T: time.Date(2020, 5, 1, 22, 22, 53, 0, time.UTC)}
+	l := logger.New()
+	configs := heartbeatConfigs(t, heartbeatConfig)
+	mr := mibRegistry(t)
+	cntr, cntrVec := snmp.InitCounters(t.Name(), l)
+	tracker := snmp.NewHeartbeatTracker(c, l)
+	ag := snmp.Alerter{
+		Log:        l,
+		Configs:    &configs,
+		Receipts:   TrapReceipts(),
+		Value:      snmp.NewValue(trapVars(), mr),
+		MR:         mr,
+		U:          &uuid.FixedUUID{},
+		Cntr:       cntr,
+		CntrVec:    cntrVec,
+		Heartbeats: tracker,
+	}
+
+	// Heartbeat is matched by lookup.
+	lookup := snmp.Lookup{Configs: configs, MR: mr, Log: l}
+	require.NoError(t, lookup.Build())
+	cfgNames, err := lookup.Find(trapVars())
+	require.NoError(t, err)
+	require.Equal(t, []string{"epc"}, cfgNames)
+
+	// Heartbeats don't generate alerts.
+	require.Empty(t, ag.Alert(cfgNames))
+	c.Add(360 * time.Second)
+	require.Empty(t, tracker.Sweep())
+
+	// Heartbeat missing past interval + grace.
+	c.Add(time.Second)
+	alerts := tracker.Sweep()
+	require.Len(t, alerts, 1)
+	require.Equal(t, map[string]string{
+		"alertname":         of_snmp.HeartbeatName,
+		"alert_oid":         ".1.3.6.1.4.1.8164.2.44",
+		"alert_severity":    "critical",
+		"vendor":            "cisco",
+		"config":            "epc",
+		"source_address":    "192.168.1.28",
+		"source_hostname":   "localhost",
+		"alert_fingerprint": alerts[0].Labels[of_snmp.FingerprintText],
+	}, alerts[0].Labels)
+	require.Equal(t, string(of_snmp.Firing), alerts[0].Annotations["event_type"])
+	require.Equal(t, "2020-05-01T22:22:53Z", alerts[0].Annotations["last_seen"])
+	require.True(t, alerts[0].EndsAt.IsZero())
+	fingerprint := alerts[0].Labels[of_snmp.FingerprintText]
+
+	// Resent while missing.
+	require.Empty(t, tracker.Sweep())
+	c.Add(time.Minute)
+	alerts = tracker.Sweep()
+	require.Len(t, alerts, 1)
+	require.Equal(t, string(of_snmp.Firing), alerts[0].Annotations["event_type"])
+
+	// Heartbeat resumed.
+	alerts = ag.Alert(cfgNames)
+	require.Len(t, alerts, 1)
+	require.Equal(t, string(of_snmp.Clearing), alerts[0].Annotations["event_type"])
+	require.Equal(t, c.Now(), alerts[0].EndsAt)
+	require.Equal(t, fingerprint, alerts[0].Labels[of_snmp.FingerprintText])
+	require.Empty(t, tracker.Sweep())
+}
+
+// Test tracked heartbeats survive config reload.
+func TestHeartbeatReload(t *testing.T) {
+	c := &clock.FixedClock{T: time.Date(2020, 5, 1, 22, 22, 53, 0, time.UTC)}
+	tracker := snmp.NewHeartbeatTracker(c, logger.New())
+	hb := of_snmp.Heartbeat{Oid: ".1.3.6.1.4.1.8164.2.44", Interval: 300, Grace: 60}
+	alert := of.Alert{Labels: map[string]string{"source_address": "192.168.1.28"}}
+	tracker.Beat("epc", hb, alert)
+
+	// Interval is updated from reloaded config.
+	configs := heartbeatConfigs(t, strings.Replace(heartbeatConfig, "interval: 300", "interval: 30", 1))
+	tracker.Reload(&configs)
+	c.Add(91 * time.Second)
+	require.Len(t, tracker.Sweep(), 1)
+
+	// Heartbeat removed from config.
+	configs = heartbeatConfigs(t, strings.Replace(heartbeatConfig, "8164.2.44", "8164.2.45", 1))
+	tracker.Reload(&configs)
+	c.Add(time.Hour)
+	require.Empty(t, tracker.Sweep())
+}
@@ -0,0 +1,81 @@
+package v2
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	of_snmp "github.com/cisco-cx/of/pkg/v2/snmp"
+)
+
+// Represents JUnit XML report, with a test suite per test file.
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// Write results as JUnit XML, for CI.
+func WriteJUnit(w io.Writer, results []of_snmp.TestResult) error {
+	report := junitTestSuites{}
+	suites := make(map[string]int) // Index of suite of each file.
+	var seconds []float64          // Duration of each suite.
+	for _, r := range results {
+		idx, ok := suites[r.File]
+		if ok == false {
+			idx = len(report.Suites)
+			suites[r.File] = idx
+			report.Suites = append(report.Suites, junitTestSuite{Name: r.File})
+			seconds = append(seconds, 0)
+		}
+		suite := &report.Suites[idx]
+
+		tc := junitTestCase{
+			Name:      r.Name,
+			Classname: r.File,
+			Time:      fmt.Sprintf("%.3f", r.Duration.Seconds()),
+		}
+		if len(r.Failures) != 0 {
+			tc.Failure = &junitFailure{
+				Message: fmt.Sprintf("%d differences in alerts", len(r.Failures)),
+				Text:    strings.Join(r.Failures, "\n"),
+			}
+			suite.Failures++
+		}
+		suite.Tests++
+		suite.Cases = append(suite.Cases, tc)
+		seconds[idx] += r.Duration.Seconds()
+		suite.Time = fmt.Sprintf("%.3f", seconds[idx])
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	e := xml.NewEncoder(w)
+	e.Indent("", "  ")
+	if err := e.Encode(report); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,36 @@
+package v2_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	of_snmp "github.com/cisco-cx/of/pkg/v2/snmp"
+	snmp "github.com/cisco-cx/of/wrap/snmp/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// Test results are written as a test suite per file.
+func TestWriteJUnit(t *testing.T) {
+	results := []of_snmp.TestResult{
+		of_snmp.TestResult{File: "epc.yaml", Name: "fires", Duration: 1500 * time.Millisecond},
+		of_snmp.TestResult{File: "epc.yaml", Name: "clears", Failures: []string{"missing clearing alert {}", "unexpected firing alert {}"}},
+		of_snmp.TestResult{File: "nso.yaml", Name: "fires"},
+	}
+
+	var b bytes.Buffer
+	require.NoError(t, snmp.WriteJUnit(&b, results))
+	require.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites>
+  <testsuite name="epc.yaml" tests="2" failures="1" time="1.500">
+    <testcase name="fires" classname="epc.yaml" time="1.500"></testcase>
+    <testcase name="clears" classname="epc.yaml" time="0.000">
+      <failure message="2 differences in alerts">missing clearing alert {}&#xA;unexpected firing alert {}</failure>
+    </testcase>
+  </testsuite>
+  <testsuite name="nso.yaml" tests="1" failures="0" time="0.000">
+    <testcase name="fires" classname="nso.yaml" time="0.000"></testcase>
+  </testsuite>
+</testsuites>
+`, b.String())
+}
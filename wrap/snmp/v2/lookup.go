@@ -71,6 +71,18 @@ func (l *Lookup) Build() error {
 				// For each select in config.Alerts.Firing
 				l.buildFromSelects(configName, selects)
 			}
+
+			// Heartbeat is matched by snmpTrapOID value.
+			if alert.Heartbeat != nil {
+				l.buildFromSelects(configName, []of_snmp.Select{
+					of_snmp.Select{
+						Type:   of_snmp.Equals,
+						Oid:    of_snmp.SNMPTrapOID,
+						As:     of_snmp.Value,
+						Values: []string{alert.Heartbeat.Oid},
+					},
+				})
+			}
 		}
 	}
 	l.Log.Tracef("Lookup Map : %+v", l.lm)
@@ -0,0 +1,123 @@
+package v2
+
+import (
+	"sync"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+	of_snmp "github.com/cisco-cx/of/pkg/v2/snmp"
+	logger "github.com/cisco-cx/of/wrap/logrus/v2"
+	prometheus "github.com/cisco-cx/of/wrap/prometheus/client_golang/v2"
+)
+
+// Drops, tags or delays firing alerts during maintenance windows.
+// Clearing alerts are always sent, so alerts fired before a window are resolved.
+type MaintenanceGuard struct {
+	Schedule of.Maintenance
+	Clock    of.Clock
+	Log      *logger.Logger
+	CntrVec  map[string]*prometheus.CounterVec
+
+	mu      sync.Mutex
+	delayed map[string]delayedAlert // Delayed alerts, by fingerprint.
+}
+
+// Represents an alert delayed until its window ends.
+type delayedAlert struct {
+	config string
+	target of.MaintenanceTarget
+	alert  of.Alert
+}
+
+// Init MaintenanceGuard.
+func NewMaintenanceGuard(schedule of.Maintenance, clock of.Clock, l *logger.Logger, cntrVec map[string]*prometheus.CounterVec) *MaintenanceGuard {
+	return &MaintenanceGuard{
+		Schedule: schedule,
+		Clock:    clock,
+		Log:      l,
+		CntrVec:  cntrVec,
+		delayed:  make(map[string]delayedAlert),
+	}
+}
+
+// Check firing alert against maintenance windows.
+// Returns alert to send and true, or false if alert is dropped or delayed.
+func (m *MaintenanceGuard) Check(cfgName string, target of.MaintenanceTarget, alert of.Alert) (of.Alert, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.check(cfgName, target, alert)
+}
+
+// Forget delayed alerts that are being cleared.
+func (m *MaintenanceGuard) Observe(alerts []of.Alert) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, alert := range alerts {
+		if alert.Annotations[of_snmp.EventTypeText] == string(of_snmp.Clearing) {
+			delete(m.delayed, alert.Labels[of_snmp.FingerprintText])
+		}
+	}
+}
+
+// Returns delayed alerts, whose maintenance window ended.
+func (m *MaintenanceGuard) Sweep() []of.Alert {
+	now := m.Clock.Now()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var alerts []of.Alert
+	for fp, d := range m.delayed {
+		if _, ok := m.Schedule.Window(d.target, now); ok == true {
+			continue
+		}
+
+		delete(m.delayed, fp)
+		if d.alert.EndsAt.IsZero() == false && now.After(d.alert.EndsAt) {
+			continue
+		}
+		m.Log.WithFields(map[string]interface{}{
+			"config": d.config,
+			"labels": d.alert.Labels,
+		}).Debugf("Releasing alert, maintenance ended.")
+		alerts = append(alerts, d.alert)
+	}
+	return alerts
+}
+
+// Check alert against windows, expects lock to be held.
+func (m *MaintenanceGuard) check(cfgName string, target of.MaintenanceTarget, alert of.Alert) (of.Alert, bool) {
+	w, ok := m.Schedule.Window(target, m.Clock.Now())
+	if ok == false {
+		return alert, true
+	}
+
+	if c, ok := m.CntrVec[alertsMutedCount]; ok == true {
+		c.Incr(map[string]string{
+			"config": cfgName,
+			"action": string(w.Action),
+		})
+	}
+	m.Log.WithFields(map[string]interface{}{
+		"config":  cfgName,
+		"labels":  alert.Labels,
+		"window":  w.Name,
+		"action":  w.Action,
+		"address": target.Address,
+	}).Debugf("Alert in maintenance.")
+
+	switch w.Action {
+	case of.MaintenanceTag:
+		// Tag is an annotation, so clearing alert keeps matching labels.
+		tagged := copyAlert(alert)
+		tagged.Annotations[of.MaintenanceText] = w.Name
+		return tagged, true
+	case of.MaintenanceDelay:
+		m.delayed[alert.Labels[of_snmp.FingerprintText]] = delayedAlert{
+			config: cfgName,
+			target: target,
+			alert:  alert,
+		}
+	}
+	return alert, false
+}
@@ -0,0 +1,99 @@
+package v2_test
+
+import (
+	"testing"
+	"time"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+	of_snmp "github.com/cisco-cx/of/pkg/v2/snmp"
+	logger "github.com/cisco-cx/of/wrap/logrus/v2"
+	snmp "github.com/cisco-cx/of/wrap/snmp/v2"
+	clock "github.com/cisco-cx/of/wrap/time/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// Maintenance with a single window, active for all targets when on.
+type testMaintenance struct {
+	window of.MaintenanceWindow
+	on     bool
+}
+
+func (m *testMaintenance) Window(target of.MaintenanceTarget, t time.Time) (of.MaintenanceWindow, bool) {
+	return m.window, m.on
+}
+
+func (m *testMaintenance) Windows(t time.Time) []of.MaintenanceStatus {
+	return []of.MaintenanceStatus{of.MaintenanceStatus{MaintenanceWindow: m.window, Active: m.on}}
+}
+
+var maintenanceTarget = of.MaintenanceTarget{Address: "192.168.1.28"}
+
+// Test alerts are dropped during maintenance.
+func TestMaintenanceDrop(t *testing.T) {
+	c := &clock.FixedClock{T: time.Date(2020, 5, 1, 22, 22, 53, 0, time.UTC)}
+	l := logger.New()
+	_, cntrVec := snmp.InitCounters(t.Name(), l)
+	m := &testMaintenance{window: of.MaintenanceWindow{Name: "upgrade", Action: of.MaintenanceDrop}}
+	g := snmp.NewMaintenanceGuard(m, c, l, cntrVec)
+
+	alert := suppressAlert("starCardDown", "192.168.1.28", of_snmp.Firing)
+	_, send := g.Check("epc", maintenanceTarget, alert)
+	require.True(t, send)
+
+	m.on = true
+	_, send = g.Check("epc", maintenanceTarget, alert)
+	require.False(t, send)
+
+	m.on = false
+	require.Empty(t, g.Sweep())
+
+	metrics := promMetrics(t)
+	require.Contains(t, metrics, t.Name()+`_alerts_muted_count{action="drop",config="epc"} 1`)
+}
+
+// Test alerts are tagged during maintenance.
+func TestMaintenanceTag(t *testing.T) {
+	c := &clock.FixedClock{T: time.Date(2020, 5, 1, 22, 22, 53, 0, time.UTC)}
+	m := &testMaintenance{window: of.MaintenanceWindow{Name: "upgrade", Action: of.MaintenanceTag}, on: true}
+	g := snmp.NewMaintenanceGuard(m, c, logger.New(), nil)
+
+	alert := suppressAlert("starCardDown", "192.168.1.28", of_snmp.Firing)
+	tagged, send := g.Check("epc", maintenanceTarget, alert)
+	require.True(t, send)
+	require.Equal(t, "upgrade", tagged.Annotations[of.MaintenanceText])
+	require.Equal(t, alert.Labels, tagged.Labels)
+	require.NotContains(t, alert.Annotations, of.MaintenanceText)
+}
+
+// Test alerts are delayed until maintenance ends.
+func TestMaintenanceDelay(t *testing.T) {
+	c := &clock.FixedClock{T: time.Date(2020, 5, 1, 22, 22, 53, 0, time.UTC)}
+	m := &testMaintenance{window: of.MaintenanceWindow{Name: "upgrade", Action: of.MaintenanceDelay}, on: true}
+	g := snmp.NewMaintenanceGuard(m, c, logger.New(), nil)
+
+	alert := suppressAlert("starCardDown", "192.168.1.28", of_snmp.Firing)
+	_, send := g.Check("epc", maintenanceTarget, alert)
+	require.False(t, send)
+	require.Empty(t, g.Sweep())
+
+	m.on = false
+	require.Equal(t, []of.Alert{alert}, g.Sweep())
+	require.Empty(t, g.Sweep())
+
+	// Delayed alert cleared during maintenance.
+	m.on = true
+	_, send = g.Check("epc", maintenanceTarget, alert)
+	require.False(t, send)
+	g.Observe([]of.Alert{suppressAlert("starCardDown", "192.168.1.28", of_snmp.Clearing)})
+	m.on = false
+	require.Empty(t, g.Sweep())
+
+	// Delayed alert expired during maintenance.
+	m.on = true
+	alert.EndsAt = c.Now().Add(time.Minute)
+	_, send = g.Check("epc", maintenanceTarget, alert)
+	require.False(t, send)
+	c.Add(2 * time.Minute)
+	m.on = false
+	require.Empty(t, g.Sweep())
+}
@@ -6,8 +6,10 @@ import (
 )
 
 type Modifier struct {
-	Map *map[string]string // Pointer to map to be modified
-	V   *Value             // Gets value for given oid, based on the `of_snmp.As` type
+	Map    *map[string]string // Pointer to map to be modified
+	V      *Value             // Gets value for given oid, based on the `of_snmp.As` type
+	Tracer of_snmp.Tracer     // Explains applied mods, if set.
+	Target string             // Name of the map being modified, labels or annotations, for Tracer.
 }
 
 // Apply given mods to Map.
@@ -45,6 +47,7 @@ func (m *Modifier) Set(mod of_snmp.Mod) error {
 
 	// Set operation.
 	(*m.Map)[mod.Key] = mod.Value
+	m.trace(mod, mod.Value, true, nil)
 	return nil
 }
 
@@ -64,6 +67,7 @@ func (m *Modifier) Copy(mod of_snmp.Mod) error {
 	// Get value for key based on of_snmp.As type.
 	value, err := m.V.ValueAs(mod.Oid, mod.As)
 	if err != nil {
+		m.trace(mod, "", false, err)
 		// Bubble up error if drop on error is set.
 		if mod.OnError == of_snmp.Drop {
 			return err
@@ -74,14 +78,24 @@ func (m *Modifier) Copy(mod of_snmp.Mod) error {
 	// If map is not present copy value to map
 	if len(mod.Map) == 0 {
 		(*m.Map)[mod.ToKey] = value
+		m.trace(mod, value, true, nil)
 		return nil
 	}
 
 	// Check if value is a key in map,
 	if v, ok := mod.Map[value]; ok == true {
 		(*m.Map)[mod.ToKey] = v
+		m.trace(mod, v, true, nil)
 		return nil
 	}
 
+	m.trace(mod, value, false, nil)
 	return nil
 }
+
+// Report applied or skipped mod to Tracer.
+func (m *Modifier) trace(mod of_snmp.Mod, value string, applied bool, err error) {
+	if m.Tracer != nil {
+		m.Tracer.Mod(m.Target, mod, value, applied, err)
+	}
+}
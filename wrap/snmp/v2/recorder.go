@@ -0,0 +1,269 @@
+package v2
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+	logger "github.com/cisco-cx/of/wrap/logrus/v2"
+)
+
+const (
+	// Recording files are named like events-20190426T034657.000000000Z.ndjson.gz,
+	// so they sort in the order they were started.
+	recordPrefix     = "events-"
+	recordSuffix     = ".ndjson.gz"
+	recordTimeFormat = "20060102T150405.000000000Z"
+
+	// Interval after which a new file is started, so old events can be removed in time.
+	recordRotateInterval = time.Hour
+)
+
+// Implements of_snmp.Recorder, appending events to gzipped NDJSON files in Dir.
+// Events without @timestamp are stamped with the time they were received,
+// so they can be replayed at the original speed.
+type Recorder struct {
+	Dir           string
+	FileSize      int64         // Bytes, after which a new file is started.
+	RetentionSize int64         // Bytes of files kept, 0 to keep all.
+	RetentionTime time.Duration // Age of files kept, 0 to keep all.
+	Clock         of.Clock
+	Log           *logger.Logger
+
+	mu     sync.Mutex
+	file   *os.File
+	gz     *gzip.Writer
+	cw     *countingWriter
+	opened time.Time
+}
+
+// Counts bytes written to w.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// Create a recorder writing to cfg.RecordDir, creating it if needed.
+func NewRecorder(cfg *of.SNMPConfig, c of.Clock, l *logger.Logger) (*Recorder, error) {
+	if err := os.MkdirAll(cfg.RecordDir, 0755); err != nil {
+		return nil, err
+	}
+	return &Recorder{
+		Dir:           cfg.RecordDir,
+		FileSize:      cfg.RecordFileSize,
+		RetentionSize: cfg.RecordRetentionSize,
+		RetentionTime: cfg.RecordRetentionTime,
+		Clock:         c,
+		Log:           l,
+	}, nil
+}
+
+// Append events to the current file, flushing them to disk.
+func (r *Recorder) Record(events []*of.PostableEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.Clock.Now().UTC()
+	if r.file == nil || (r.FileSize > 0 && r.cw.n >= r.FileSize) || now.Sub(r.opened) >= recordRotateInterval {
+		if err := r.rotate(now); err != nil {
+			return err
+		}
+	}
+
+	for _, event := range events {
+		e := *event
+		if e.Timestamp == "" {
+			e.Timestamp = now.Format(time.RFC3339Nano)
+		}
+		b, err := json.Marshal(&e)
+		if err != nil {
+			return err
+		}
+		if _, err = r.gz.Write(append(b, '\n')); err != nil {
+			return err
+		}
+	}
+	return r.gz.Flush()
+}
+
+// Close the current file.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.close()
+}
+
+func (r *Recorder) close() error {
+	if r.file == nil {
+		return nil
+	}
+	err := r.gz.Close()
+	if cerr := r.file.Close(); err == nil {
+		err = cerr
+	}
+	r.file, r.gz, r.cw = nil, nil, nil
+	return err
+}
+
+// Start a new file, and remove files beyond retention.
+func (r *Recorder) rotate(now time.Time) error {
+	if err := r.close(); err != nil {
+		r.Log.WithError(err).Errorf("Failed to close recording file.")
+	}
+
+	name := filepath.Join(r.Dir, recordPrefix+now.Format(recordTimeFormat)+recordSuffix)
+	f, err := os.OpenFile(name, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	r.file = f
+	r.cw = &countingWriter{w: f}
+	r.gz = gzip.NewWriter(r.cw)
+	r.opened = now
+	r.Log.Debugf("Recording events to %s.", name)
+
+	r.prune(now, filepath.Base(name))
+	return nil
+}
+
+// Remove files older than RetentionTime, then oldest files until RetentionSize is met.
+// The current file is kept.
+func (r *Recorder) prune(now time.Time, current string) {
+	infos, err := ioutil.ReadDir(r.Dir)
+	if err != nil {
+		r.Log.WithError(err).Errorf("Failed to list recording files.")
+		return
+	}
+
+	var kept []os.FileInfo
+	var total int64
+	for _, info := range infos {
+		if isRecordingFile(info.Name()) == false || info.Name() == current {
+			continue
+		}
+		if r.RetentionTime > 0 && now.Sub(info.ModTime()) > r.RetentionTime {
+			r.remove(info.Name())
+			continue
+		}
+		kept = append(kept, info)
+		total += info.Size()
+	}
+
+	// ReadDir sorts by name, oldest first.
+	for _, info := range kept {
+		if r.RetentionSize <= 0 || total <= r.RetentionSize {
+			break
+		}
+		r.remove(info.Name())
+		total -= info.Size()
+	}
+}
+
+func (r *Recorder) remove(name string) {
+	if err := os.Remove(filepath.Join(r.Dir, name)); err != nil {
+		r.Log.WithError(err).Errorf("Failed to remove recording file %s.", name)
+		return
+	}
+	r.Log.Debugf("Removed recording file %s.", name)
+}
+
+// Check if name is of a file written by Recorder.
+func isRecordingFile(name string) bool {
+	return strings.HasPrefix(name, recordPrefix) && strings.HasSuffix(name, recordSuffix)
+}
+
+// Recording files in dir, in the order they were written.
+func RecordingFiles(dir string) ([]string, error) {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, info := range infos {
+		if isRecordingFile(info.Name()) {
+			files = append(files, filepath.Join(dir, info.Name()))
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// Read events from a recording file. Files still being written end without
+// a gzip footer, events up to the last flush are returned for them.
+func ReadRecording(r io.Reader) ([]*of.PostableEvent, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	var events []*of.PostableEvent
+	d := json.NewDecoder(gz)
+	for {
+		event := &of.PostableEvent{}
+		err := d.Decode(event)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return events, nil
+		}
+		if err != nil {
+			return events, fmt.Errorf("event %d: %s", len(events)+1, err.Error())
+		}
+		events = append(events, event)
+	}
+}
+
+// Read events from files and dirs of recording files, in order.
+// Files ending in .gz are read as recordings, others with ParseEvents.
+func LoadEvents(paths []string) ([]*of.PostableEvent, error) {
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if info.IsDir() == false {
+			files = append(files, path)
+			continue
+		}
+		dirFiles, err := RecordingFiles(path)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, dirFiles...)
+	}
+
+	var events []*of.PostableEvent
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+		var fileEvents []*of.PostableEvent
+		if strings.HasSuffix(file, ".gz") {
+			fileEvents, err = ReadRecording(f)
+		} else {
+			fileEvents, err = ParseEvents(f)
+		}
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", file, err.Error())
+		}
+		events = append(events, fileEvents...)
+	}
+	return events, nil
+}
@@ -0,0 +1,139 @@
+package v2_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+	of_snmp "github.com/cisco-cx/of/pkg/v2/snmp"
+	http "github.com/cisco-cx/of/wrap/http/v2"
+	logger "github.com/cisco-cx/of/wrap/logrus/v2"
+	snmp "github.com/cisco-cx/of/wrap/snmp/v2"
+	clock "github.com/cisco-cx/of/wrap/time/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// Enforce Recorder Interface
+func TestRecorderInterface(t *testing.T) {
+	var _ of_snmp.Recorder = &snmp.Recorder{}
+}
+
+// Recorder writing to a new temporary dir.
+func newTestRecorder(t *testing.T, c of.Clock) (*snmp.Recorder, string) {
+	dir, err := ioutil.TempDir("", "snmp-recording")
+	require.NoError(t, err)
+	r, err := snmp.NewRecorder(&of.SNMPConfig{RecordDir: dir}, c, logger.New())
+	require.NoError(t, err)
+	return r, dir
+}
+
+// Test events are recorded, and read back while and after the file is written.
+func TestRecorder(t *testing.T) {
+	c := &clock.FixedClock{T: time.Date(2019, 4, 26, 3, 46, 57, 0, time.UTC)}
+	r, dir := newTestRecorder(t, c)
+	defer os.RemoveAll(dir)
+
+	events := append(TrapEvents(), TrapEvents()...)
+	events[1].Timestamp = "2019-04-26T03:46:00.5Z"
+	require.NoError(t, r.Record(events))
+	require.Equal(t, "", events[0].Timestamp, "Events received are not modified.")
+
+	files, err := snmp.RecordingFiles(dir)
+	require.NoError(t, err)
+	require.Equal(t, []string{filepath.Join(dir, "events-20190426T034657.000000000Z.ndjson.gz")}, files)
+
+	// Flushed events are read, before the file is closed.
+	recorded, err := snmp.LoadEvents([]string{dir})
+	require.NoError(t, err)
+	require.Len(t, recorded, len(events))
+
+	require.NoError(t, r.Close())
+	recorded, err = snmp.LoadEvents(files)
+	require.NoError(t, err)
+	require.Len(t, recorded, len(events))
+	require.Equal(t, "2019-04-26T03:46:57Z", recorded[0].Timestamp)
+	require.Equal(t, "2019-04-26T03:46:00.5Z", recorded[1].Timestamp)
+	require.Equal(t, events[0].Document, recorded[0].Document)
+}
+
+// Test files are rotated by size and interval, and removed beyond retention.
+func TestRecorderRotation(t *testing.T) {
+	start := time.Date(2019, 4, 26, 3, 46, 57, 0, time.UTC)
+	c := &clock.FixedClock{T: start}
+	r, dir := newTestRecorder(t, c)
+	defer os.RemoveAll(dir)
+	r.FileSize = 1
+
+	events := TrapEvents()
+	for i := 0; i < 3; i++ {
+		require.NoError(t, r.Record(events))
+		c.Add(time.Second)
+	}
+	files, err := snmp.RecordingFiles(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 3)
+
+	// Files are kept within the size.
+	info, err := os.Stat(files[0])
+	require.NoError(t, err)
+	r.FileSize = 0
+	r.RetentionSize = 2 * info.Size()
+	c.Add(time.Hour)
+	require.NoError(t, r.Record(events))
+	files, err = snmp.RecordingFiles(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 3)
+	require.Equal(t, "events-20190426T034658.000000000Z.ndjson.gz", filepath.Base(files[0]))
+
+	// Files older than retention time are removed.
+	r.RetentionSize = 0
+	r.RetentionTime = time.Hour
+	old := c.Now().Add(-2 * time.Hour)
+	require.NoError(t, os.Chtimes(files[0], old, old))
+	c.Add(time.Hour)
+	require.NoError(t, r.Record(events))
+	files, err = snmp.RecordingFiles(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 3)
+	require.Equal(t, "events-20190426T034659.000000000Z.ndjson.gz", filepath.Base(files[0]))
+	require.NoError(t, r.Close())
+}
+
+// Test events received by the service are recorded.
+func TestSNMPServiceRecord(t *testing.T) {
+	s := initService(t, t.Name())
+	r, dir := newTestRecorder(t, &clock.Clock{})
+	defer os.RemoveAll(dir)
+	s.Recorder = r
+
+	addr := "localhost:24933"
+	srv := http.NewServer(&of.HTTPConfig{ListenAddress: addr}, t.Name(), nil)
+	srv.HandleFunc("/", s.AlertHandler)
+	require.NoError(t, srv.ListenAndServe())
+	defer srv.Shutdown()
+
+	docs := []of.Document{}
+	require.NoError(t, json.Unmarshal([]byte(StarEvents), &docs))
+	events := make([]*of.PostableEvent, len(docs))
+	for i, doc := range docs {
+		events[i] = &of.PostableEvent{Document: doc}
+	}
+	data, err := json.Marshal(events)
+	require.NoError(t, err)
+
+	res, err := http.NewClient().Post("http://"+addr, "application/json", bytes.NewReader(data))
+	require.NoError(t, err)
+	res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	require.NoError(t, r.Close())
+	recorded, err := snmp.LoadEvents([]string{dir})
+	require.NoError(t, err)
+	require.Len(t, recorded, len(events))
+	require.Equal(t, events[0].Document, recorded[0].Document)
+}
@@ -0,0 +1,136 @@
+package v2
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+	of_snmp "github.com/cisco-cx/of/pkg/v2/snmp"
+)
+
+const (
+	// Firing alerts without endsAt are considered active for this long after they were last seen.
+	// Same as Alertmanager's default resolve_timeout.
+	activeAlertTimeout = 5 * time.Minute
+	// Resolved alerts are remembered for this long, to be re-raised.
+	resolvedAlertTimeout = time.Hour
+)
+
+// Keeps track of firing and resolved alerts that were sent.
+type Registry struct {
+	Clock of.Clock
+
+	mu       sync.Mutex
+	active   map[string]registeredAlert // Firing alerts, by fingerprint.
+	resolved map[string]registeredAlert // Resolved alerts, by fingerprint.
+}
+
+// Represents an alert, until it expires.
+type registeredAlert struct {
+	alert   of.Alert
+	expires time.Time
+}
+
+// Init Registry.
+func NewRegistry(clock of.Clock) *Registry {
+	return &Registry{
+		Clock:    clock,
+		active:   make(map[string]registeredAlert),
+		resolved: make(map[string]registeredAlert),
+	}
+}
+
+// Record firing and clearing alerts, that are being sent.
+func (r *Registry) Observe(alerts []of.Alert) {
+	now := r.Clock.Now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, alert := range alerts {
+		fp := alert.Labels[of_snmp.FingerprintText]
+		if alert.Annotations[of_snmp.EventTypeText] == string(of_snmp.Clearing) {
+			if ra, ok := r.active[fp]; ok == true {
+				delete(r.active, fp)
+				r.resolved[fp] = registeredAlert{alert: ra.alert, expires: now.Add(resolvedAlertTimeout)}
+			}
+			continue
+		}
+
+		expires := alert.EndsAt
+		if expires.IsZero() {
+			expires = now.Add(activeAlertTimeout)
+		}
+		delete(r.resolved, fp)
+		r.active[fp] = registeredAlert{alert: alert, expires: expires}
+	}
+}
+
+// Check if alert with given fingerprint is firing.
+func (r *Registry) Active(fp string) bool {
+	now := r.Clock.Now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ra, ok := r.active[fp]
+	return ok == true && now.After(ra.expires) == false
+}
+
+// Firing alerts named alertname, with same values as labels for keys in equal.
+func (r *Registry) Firing(alertname string, equal []string, labels map[string]string) []of.Alert {
+	return r.match(r.active, alertname, equal, labels)
+}
+
+// Resolved alerts named alertname, with same values as labels for keys in equal.
+func (r *Registry) Resolved(alertname string, equal []string, labels map[string]string) []of.Alert {
+	return r.match(r.resolved, alertname, equal, labels)
+}
+
+// Forget expired alerts.
+func (r *Registry) Sweep() {
+	now := r.Clock.Now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, alerts := range []map[string]registeredAlert{r.active, r.resolved} {
+		for fp, ra := range alerts {
+			if now.After(ra.expires) {
+				delete(alerts, fp)
+			}
+		}
+	}
+}
+
+// Find unexpired alerts in alerts, matching alertname and labels. Keys in equal default to source_address.
+func (r *Registry) match(alerts map[string]registeredAlert, alertname string, equal []string, labels map[string]string) []of.Alert {
+	if len(equal) == 0 {
+		equal = []string{"source_address"}
+	}
+	now := r.Clock.Now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var matched []of.Alert
+	for _, ra := range alerts {
+		if ra.alert.Labels["alertname"] != alertname || now.After(ra.expires) {
+			continue
+		}
+
+		equals := true
+		for _, k := range equal {
+			if ra.alert.Labels[k] != labels[k] {
+				equals = false
+				break
+			}
+		}
+		if equals == true {
+			matched = append(matched, ra.alert)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		return matched[i].Labels[of_snmp.FingerprintText] < matched[j].Labels[of_snmp.FingerprintText]
+	})
+	return matched
+}
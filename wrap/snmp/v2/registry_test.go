@@ -0,0 +1,46 @@
+package v2_test
+
+import (
+	"testing"
+	"time"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+	of_snmp "github.com/cisco-cx/of/pkg/v2/snmp"
+	snmp "github.com/cisco-cx/of/wrap/snmp/v2"
+	clock "github.com/cisco-cx/of/wrap/time/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// Test firing and resolved alerts are tracked, until they expire.
+func TestRegistry(t *testing.T) {
+	c := &clock.FixedClock{T: time.Date(2020, 5, 1, 22, 22, 53, 0, time.UTC)}
+	r := snmp.NewRegistry(c)
+
+	card := suppressAlert("starCard", "192.168.1.28", of_snmp.Firing)
+	card.Labels["slot"] = "1"
+	r.Observe([]of.Alert{card})
+	fp := card.Labels[of_snmp.FingerprintText]
+	require.True(t, r.Active(fp))
+	require.Equal(t, []of.Alert{card}, r.Firing("starCard", nil, map[string]string{"source_address": "192.168.1.28"}))
+	require.Empty(t, r.Firing("starCard", []string{"slot"}, map[string]string{"slot": "2"}))
+	require.Empty(t, r.Firing("starChassis", nil, card.Labels))
+	require.Empty(t, r.Resolved("starCard", nil, card.Labels))
+
+	// Clearing moves alert to resolved.
+	cleared := suppressAlert("starCard", "192.168.1.28", of_snmp.Clearing)
+	cleared.Labels = card.Labels
+	r.Observe([]of.Alert{cleared})
+	require.False(t, r.Active(fp))
+	require.Equal(t, []of.Alert{card}, r.Resolved("starCard", nil, card.Labels))
+
+	// Firing again.
+	r.Observe([]of.Alert{card})
+	require.True(t, r.Active(fp))
+	require.Empty(t, r.Resolved("starCard", nil, card.Labels))
+
+	// Expired without endsAt.
+	c.Add(6 * time.Minute)
+	require.False(t, r.Active(fp))
+	r.Sweep()
+	require.Empty(t, r.Firing("starCard", nil, card.Labels))
+}
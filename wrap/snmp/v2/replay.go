@@ -0,0 +1,97 @@
+package v2
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+	logger "github.com/cisco-cx/of/wrap/logrus/v2"
+)
+
+// Implements of_snmp.Replayer, sending events spaced the way they were received.
+// Events received at the same time are sent together.
+type Replayer struct {
+	Speed float64                         // Factor of the original speed, 0 to send at maximum speed.
+	Send  func([]*of.PostableEvent) error // Like HTTPSender.Send or Service.Handle.
+	Clock of.Clock
+	Sleep func(time.Duration) // Defaults to time.Sleep.
+	Log   *logger.Logger
+}
+
+// Send events, returning the number sent before an error.
+func (r *Replayer) Replay(events []*of.PostableEvent) (int, error) {
+	sleep := r.Sleep
+	if sleep == nil {
+		sleep = time.Sleep
+	}
+
+	start := r.Clock.Now()
+	var first time.Time
+	sent := 0
+	for i := 0; i < len(events); {
+		t, ok := eventTime(events[i])
+		j := i + 1
+		for ; j < len(events); j++ {
+			tj, okj := eventTime(events[j])
+			if okj != ok || tj.Equal(t) == false {
+				break
+			}
+		}
+
+		// Events without time are sent right away.
+		if ok == true && r.Speed > 0 {
+			if first.IsZero() {
+				first = t
+			}
+			due := start.Add(time.Duration(float64(t.Sub(first)) / r.Speed))
+			if wait := due.Sub(r.Clock.Now()); wait > 0 {
+				sleep(wait)
+			}
+		}
+
+		if err := r.Send(events[i:j]); err != nil {
+			return sent, err
+		}
+		sent += j - i
+		r.Log.Debugf("Replayed %d of %d events.", sent, len(events))
+		i = j
+	}
+	return sent, nil
+}
+
+// Time event was received, from @timestamp or else the snmptrapd timestamp.
+func eventTime(event *of.PostableEvent) (time.Time, bool) {
+	if t, err := time.Parse(time.RFC3339Nano, event.Timestamp); err == nil {
+		return t, true
+	}
+	if t, err := time.Parse(time.RFC3339Nano, event.Document.Receipts.Snmptrapd.Timestamp); err == nil {
+		return t, true
+	}
+	return time.Time{}, false
+}
+
+// Posts events to the events endpoint of a running handler.
+type HTTPSender struct {
+	URL    string // Like http://localhost:80/api/v2/events.
+	Client *http.Client
+}
+
+// Post events as a JSON array.
+func (h *HTTPSender) Send(events []*of.PostableEvent) error {
+	b, err := json.Marshal(events)
+	if err != nil {
+		return err
+	}
+	resp, err := h.Client.Post(h.URL, "application/json", bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s returned status %d", h.URL, resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package v2_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+	of_snmp "github.com/cisco-cx/of/pkg/v2/snmp"
+	logger "github.com/cisco-cx/of/wrap/logrus/v2"
+	snmp "github.com/cisco-cx/of/wrap/snmp/v2"
+	clock "github.com/cisco-cx/of/wrap/time/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// Enforce Replayer Interface
+func TestReplayerInterface(t *testing.T) {
+	var _ of_snmp.Replayer = &snmp.Replayer{}
+}
+
+// Events received at times, offset from a base time.
+func replayEvents(offsets ...time.Duration) []*of.PostableEvent {
+	base := time.Date(2019, 4, 26, 3, 46, 57, 0, time.UTC)
+	events := make([]*of.PostableEvent, len(offsets))
+	for i, offset := range offsets {
+		events[i] = &of.PostableEvent{Timestamp: base.Add(offset).Format(time.RFC3339Nano)}
+	}
+	return events
+}
+
+// Replay events at speed, returning batch sizes sent and waits between them.
+func replay(t *testing.T, speed float64, events []*of.PostableEvent) ([]int, []time.Duration) {
+	c := &clock.FixedClock{T: time.Now()}
+	var batches []int
+	var waits []time.Duration
+	r := snmp.Replayer{
+		Speed: speed,
+		Clock: c,
+		Log:   logger.New(),
+		Sleep: func(d time.Duration) {
+			waits = append(waits, d)
+			c.Add(d)
+		},
+		Send: func(events []*of.PostableEvent) error {
+			batches = append(batches, len(events))
+			return nil
+		},
+	}
+	sent, err := r.Replay(events)
+	require.NoError(t, err)
+	require.Equal(t, len(events), sent)
+	return batches, waits
+}
+
+// Test events are spaced as received, scaled by speed.
+func TestReplay(t *testing.T) {
+	events := replayEvents(0, 0, time.Second, 3*time.Second)
+
+	batches, waits := replay(t, 1, events)
+	require.Equal(t, []int{2, 1, 1}, batches)
+	require.Equal(t, []time.Duration{time.Second, 2 * time.Second}, waits)
+
+	batches, waits = replay(t, 2, events)
+	require.Equal(t, []int{2, 1, 1}, batches)
+	require.Equal(t, []time.Duration{500 * time.Millisecond, time.Second}, waits)
+
+	_, waits = replay(t, 0, events)
+	require.Empty(t, waits)
+
+	// Events without time are sent right away.
+	events = append(events, &of.PostableEvent{})
+	batches, waits = replay(t, 1, events)
+	require.Equal(t, []int{2, 1, 1, 1}, batches)
+	require.Len(t, waits, 2)
+}
+
+// Test events are posted, and errors are returned with the number sent.
+func TestHTTPSender(t *testing.T) {
+	var received []*of.PostableEvent
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var events []*of.PostableEvent
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&events))
+		received = append(received, events...)
+		if len(received) > 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer ts.Close()
+
+	sender := snmp.HTTPSender{URL: ts.URL, Client: ts.Client()}
+	r := snmp.Replayer{
+		Clock: &clock.Clock{},
+		Log:   logger.New(),
+		Send:  sender.Send,
+	}
+	sent, err := r.Replay(replayEvents(0, 0, time.Second, 2*time.Second))
+	require.EqualError(t, err, ts.URL+" returned status 503")
+	require.Equal(t, 2, sent)
+	require.Len(t, received, 3)
+}
@@ -0,0 +1,122 @@
+package v2
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+	of_snmp "github.com/cisco-cx/of/pkg/v2/snmp"
+	prometheus "github.com/cisco-cx/of/wrap/prometheus/client_golang/v2"
+)
+
+// GaugeVec names.
+const ruleLastMatchTimestamp = "rule_last_match_timestamp_seconds"
+
+// Implements of_snmp.RuleTracker, counting hits by config and alert name.
+type RuleStats struct {
+	Clock     of.Clock
+	CntrVec   map[string]*prometheus.CounterVec
+	LastMatch *prometheus.GaugeVec
+
+	mu    sync.Mutex
+	rules map[ruleKey]*ruleHits
+}
+
+// Identifies an alert config.
+type ruleKey struct {
+	config string
+	alert  string
+}
+
+// Represents hits of an alert config.
+type ruleHits struct {
+	hits      map[of_snmp.RuleResult]uint64
+	lastMatch time.Time
+}
+
+// Init RuleStats, creating the last match gauge in namespace.
+func NewRuleStats(namespace string, clock of.Clock, cntrVec map[string]*prometheus.CounterVec) (*RuleStats, error) {
+	lastMatch := &prometheus.GaugeVec{
+		Namespace: namespace,
+		Name:      ruleLastMatchTimestamp,
+		Help:      "Unix time at which selects of an alert config last matched.",
+	}
+	if err := lastMatch.Create([]string{"config", "alert"}); err != nil {
+		return nil, err
+	}
+	return &RuleStats{
+		Clock:     clock,
+		CntrVec:   cntrVec,
+		LastMatch: lastMatch,
+		rules:     make(map[ruleKey]*ruleHits),
+	}, nil
+}
+
+// Count result for alert of config.
+func (r *RuleStats) Hit(cfgName string, alertName string, result of_snmp.RuleResult) {
+	now := r.Clock.Now()
+	labels := map[string]string{"config": cfgName, "alert": alertName}
+
+	r.mu.Lock()
+	key := ruleKey{config: cfgName, alert: alertName}
+	rh, ok := r.rules[key]
+	if ok == false {
+		rh = &ruleHits{hits: make(map[of_snmp.RuleResult]uint64)}
+		r.rules[key] = rh
+	}
+	rh.hits[result]++
+	if result == of_snmp.RuleMatched {
+		rh.lastMatch = now
+	}
+	r.mu.Unlock()
+
+	if result == of_snmp.RuleMatched && r.LastMatch != nil {
+		r.LastMatch.Set(labels, float64(now.Unix()))
+	}
+	if cntrVec, ok := r.CntrVec[ruleHitsCount]; ok == true {
+		cntrVec.Incr(map[string]string{"config": cfgName, "alert": alertName, "result": string(result)})
+	}
+}
+
+// Every alert config in configs with its hits, ordered by config, with alerts in config order.
+// Hits of alert configs no longer in configs are not listed.
+func (r *RuleStats) Rules(configs *of_snmp.V2Config) []of_snmp.Rule {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rules := make([]of_snmp.Rule, 0)
+	for cfgName, cfg := range *configs {
+		for aNum, alertCfg := range cfg.Alerts {
+			rule := of_snmp.Rule{
+				Config:  cfgName,
+				Alert:   ruleName(aNum, alertCfg),
+				Enabled: isEnabled(cfg.Defaults.Enabled, alertCfg.Enabled),
+				Hits:    make(map[of_snmp.RuleResult]uint64),
+			}
+			if rh, ok := r.rules[ruleKey{config: rule.Config, alert: rule.Alert}]; ok == true {
+				for result, n := range rh.hits {
+					rule.Hits[result] = n
+				}
+				if rh.lastMatch.IsZero() == false {
+					lastMatch := rh.lastMatch.UTC()
+					rule.LastMatch = &lastMatch
+				}
+			}
+			rules = append(rules, rule)
+		}
+	}
+	sort.SliceStable(rules, func(i, j int) bool {
+		return rules[i].Config < rules[j].Config
+	})
+	return rules
+}
+
+// Name of alert config, or its position in config if it has none.
+func ruleName(aNum int, alertCfg of_snmp.Alert) string {
+	if alertCfg.Name != "" {
+		return alertCfg.Name
+	}
+	return fmt.Sprintf("alerts[%d]", aNum)
+}
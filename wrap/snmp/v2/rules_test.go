@@ -0,0 +1,78 @@
+package v2_test
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+	of_snmp "github.com/cisco-cx/of/pkg/v2/snmp"
+	http "github.com/cisco-cx/of/wrap/http/v2"
+	snmp "github.com/cisco-cx/of/wrap/snmp/v2"
+	clock "github.com/cisco-cx/of/wrap/time/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// Enforce RuleTracker Interface
+func TestRuleTrackerInterface(t *testing.T) {
+	var _ of_snmp.RuleTracker = &snmp.RuleStats{}
+}
+
+// Test hits are counted by config and alert name, and listed for every rule.
+func TestRuleStats(t *testing.T) {
+	s := initService(t, t.Name())
+	now := time.Date(2019, 4, 26, 3, 46, 57, 0, time.UTC)
+	rules, err := snmp.NewRuleStats(t.Name(), &clock.FixedClock{T: now}, s.CntrVec)
+	require.NoError(t, err)
+	s.Rules = rules
+
+	docs := []of.Document{}
+	require.NoError(t, json.Unmarshal([]byte(StarEvents), &docs))
+	events := make([]*of.PostableEvent, len(docs))
+	for i, doc := range docs {
+		events[i] = &of.PostableEvent{Document: doc}
+	}
+	require.NoError(t, s.Handle(events))
+
+	lastMatch := now
+	require.Equal(t, []of_snmp.Rule{
+		of_snmp.Rule{
+			Config:    "config1",
+			Alert:     "starTaskFailure",
+			Enabled:   true,
+			Hits:      map[of_snmp.RuleResult]uint64{of_snmp.RuleMatched: 2, of_snmp.RuleFired: 1, of_snmp.RuleCleared: 1},
+			LastMatch: &lastMatch,
+		},
+		of_snmp.Rule{
+			Config:    "config1",
+			Alert:     "starTaskRestart",
+			Enabled:   true,
+			Hits:      map[of_snmp.RuleResult]uint64{of_snmp.RuleMatched: 2, of_snmp.RuleFired: 2},
+			LastMatch: &lastMatch,
+		},
+	}, rules.Rules(s.Configs))
+
+	// Hits of alerts no longer in configs are not listed.
+	rules.Hit("config1", "alerts[5]", of_snmp.RuleDisabled)
+	rules.Hit("config1", "starTaskRestart", of_snmp.RuleError)
+	listed := rules.Rules(s.Configs)
+	require.Len(t, listed, 2)
+	require.Equal(t, uint64(1), listed[1].Hits[of_snmp.RuleError])
+
+	metrics := promMetrics(t)
+	require.Contains(t, metrics, `TestRuleStats_rule_hits_count{alert="starTaskFailure",config="config1",result="fired"} 1`)
+	require.Contains(t, metrics, `TestRuleStats_rule_hits_count{alert="starTaskRestart",config="config1",result="matched"} 2`)
+	require.Contains(t, metrics, `TestRuleStats_rule_last_match_timestamp_seconds{alert="starTaskFailure",config="config1"} 1.556250417e+09`)
+
+	rec := httptest.NewRecorder()
+	s.RulesHandler(http.NewResponseWriter(rec), httptest.NewRequest("GET", "/api/v2/rules", nil))
+	require.Equal(t, 200, rec.Code)
+	var body struct {
+		Rules []of_snmp.Rule `json:"rules"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	require.Len(t, body.Rules, 2)
+	require.Equal(t, "starTaskFailure", body.Rules[0].Alert)
+	require.Equal(t, uint64(1), body.Rules[0].Hits[of_snmp.RuleCleared])
+}
@@ -0,0 +1,186 @@
+package v2
+
+import (
+	"sync"
+	"sync/atomic"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+	of_snmp "github.com/cisco-cx/of/pkg/v2/snmp"
+	concatenator "github.com/cisco-cx/of/wrap/concatenator/v2"
+	logger "github.com/cisco-cx/of/wrap/logrus/v2"
+	mib_registry "github.com/cisco-cx/of/wrap/mib/v2"
+	yaml "github.com/cisco-cx/of/wrap/yaml/v2"
+)
+
+// Configs, MIBs and lookup used to process events.
+// Swapped as a whole on reload, so a request is processed with a single version.
+type Runtime struct {
+	Configs *of_snmp.V2Config
+	MR      of.MIBRegistry
+	Lookup  of_snmp.Lookup
+}
+
+// Holds the runtime in use and the outcome of the last reload.
+type reloader struct {
+	current atomic.Value // *Runtime
+	loading sync.Mutex   // Serializes reloads.
+
+	mu  sync.Mutex
+	err error
+}
+
+// Load configs and MIBs, and build lookup.
+func LoadRuntime(l *logger.Logger, cfg *of.SNMPConfig) (*Runtime, error) {
+
+	// Concatenate configs files.
+	c := concatenator.Files{
+		Path: cfg.ConfigDir,
+		Ext:  "yaml",
+	}
+
+	r, err := c.Concat()
+	if err != nil {
+		l.WithError(err).Errorf("Failed to concat config files in %s.", cfg.ConfigDir)
+		return nil, err
+	}
+
+	// Decode configs files.
+	configs := yaml.Configs{}
+	err = configs.Decode(r)
+	if err != nil {
+		l.WithError(err).Errorf("Failed to decode config files in %s.", cfg.ConfigDir)
+		return nil, err
+	}
+
+	v2Config := of_snmp.V2Config(configs)
+
+	mr, err := LoadMIBs(l, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate configs, before using them.
+	v, err := NewValidator(mr)
+	if err != nil {
+		return nil, err
+	}
+	diags, err := v.ValidateDir(cfg.ConfigDir)
+	if err != nil {
+		l.WithError(err).Errorf("Failed to validate config files in %s.", cfg.ConfigDir)
+		return nil, err
+	}
+	for _, d := range diags {
+		if d.Severity == of_snmp.SeverityError {
+			l.Errorf("%s", d.String())
+		} else {
+			l.Warningf("%s", d.String())
+		}
+	}
+	if count := CountErrors(diags); count != 0 {
+		l.Errorf("Found %d errors in config files in %s.", count, cfg.ConfigDir)
+		return nil, of.ErrInvalidConfig
+	}
+
+	// Prepare lookup.
+	lookup := Lookup{Configs: v2Config, MR: mr, Log: l}
+	err = lookup.Build()
+	if err != nil {
+		l.WithError(err).Errorf("Failed to build lookup.")
+		return nil, err
+	}
+
+	return &Runtime{Configs: &v2Config, MR: mr, Lookup: &lookup}, nil
+}
+
+// Load MIBs from cache file, or MIBs dir.
+func LoadMIBs(l *logger.Logger, cfg *of.SNMPConfig) (of.MIBRegistry, error) {
+	// Prepare MIBS registry
+	var err error
+	mr := mib_registry.New()
+
+	readerMIB := &mib_registry.MIBHandler{
+		MapMIB: make(map[string]of.MIB),
+	}
+
+	if cfg.CacheFile != "none" {
+		err = readerMIB.LoadCacheFromFile(cfg.CacheFile)
+		if err != nil {
+			l.WithError(err).Errorf("Failed to load MIBs from cache.")
+			return nil, err
+		}
+	} else {
+		if cfg.SNMPMibsDir == "" {
+			l.Errorf("Failed to load MIBs, no cache path or SNMP MIBs Dir.")
+			return nil, of.ErrMIBsNotConfigured
+		}
+		err = readerMIB.LoadJSONFromDir(cfg.SNMPMibsDir)
+		if err != nil {
+			l.WithError(err).Errorf("Failed to load MIBs from MIBS dir.")
+			return nil, err
+		}
+	}
+
+	err = mr.Load(readerMIB.MapMIB)
+	if err != nil {
+		l.WithError(err).Errorf("Failed to load MIBs.")
+		return nil, err
+	}
+
+	return mr, nil
+}
+
+// Load configs, MIBs and lookup, and swap them in, without interrupting requests being processed.
+// If loading fails, the runtime in use is kept and the error is returned by ReloadError.
+func (s Service) Reload() error {
+	if s.reloader == nil {
+		return of.ErrReloadNotSupported
+	}
+
+	s.reloader.loading.Lock()
+	defer s.reloader.loading.Unlock()
+	rt, err := LoadRuntime(s.Log, s.SNMPConfig)
+
+	s.reloader.mu.Lock()
+	defer s.reloader.mu.Unlock()
+	if err != nil {
+		s.reloader.err = err
+		s.incrReload("failure")
+		s.Log.WithError(err).Errorf("Failed to reload configs, keeping last good configs.")
+		return err
+	}
+
+	s.reloader.current.Store(rt)
+	s.reloader.err = nil
+	if s.Heartbeats != nil {
+		s.Heartbeats.Reload(rt.Configs)
+	}
+	s.incrReload("success")
+	s.Log.Infof("Reloaded %d configs.", len(*rt.Configs))
+	return nil
+}
+
+// Error of last reload, nil if it succeeded.
+func (s Service) ReloadError() error {
+	if s.reloader == nil {
+		return nil
+	}
+	s.reloader.mu.Lock()
+	defer s.reloader.mu.Unlock()
+	return s.reloader.err
+}
+
+// Runtime in use.
+func (s Service) Runtime() *Runtime {
+	if s.reloader != nil {
+		if rt, ok := s.reloader.current.Load().(*Runtime); ok == true {
+			return rt
+		}
+	}
+	return &Runtime{Configs: s.Configs, MR: s.MR, Lookup: s.Lookup}
+}
+
+func (s Service) incrReload(result string) {
+	if c, ok := s.CntrVec[configReloadsCount]; ok == true {
+		c.Incr(map[string]string{"result": result})
+	}
+}
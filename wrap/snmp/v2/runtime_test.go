@@ -0,0 +1,68 @@
+package v2_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+	logger "github.com/cisco-cx/of/wrap/logrus/v2"
+	mib_registry "github.com/cisco-cx/of/wrap/mib/v2"
+	snmp "github.com/cisco-cx/of/wrap/snmp/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// Test configs are swapped on reload, and last good configs kept if invalid.
+func TestServiceReload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snmp-configs")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	mh := &mib_registry.MIBHandler{MapMIB: map[string]of.MIB{
+		"1.3.6.1.4.1.8164.2.150": of.MIB{Name: "starTaskFailed"},
+	}}
+	cacheFile := filepath.Join(dir, "mibs.cache")
+	require.NoError(t, mh.WriteCacheToFile(cacheFile))
+
+	configDir := filepath.Join(dir, "configs")
+	require.NoError(t, os.Mkdir(configDir, 0755))
+	configFile := filepath.Join(configDir, "configs.yaml")
+	require.NoError(t, ioutil.WriteFile(configFile, []byte(YamlConfigs+"\n"), 0644))
+
+	l := logger.New()
+	cntr, cntrVec := snmp.InitCounters(t.Name(), l)
+	cfg := &of.SNMPConfig{ConfigDir: configDir, CacheFile: cacheFile}
+	s, err := snmp.NewService(l, cfg, cntr, cntrVec)
+	require.NoError(t, err)
+
+	rt := s.Runtime()
+	require.Len(t, *rt.Configs, 1)
+	require.NoError(t, s.ReloadError())
+
+	// Invalid config, last good configs are kept.
+	require.NoError(t, ioutil.WriteFile(configFile, []byte("config1: [invalid"), 0644))
+	require.Error(t, s.Reload())
+	require.Error(t, s.ReloadError())
+	require.Equal(t, rt, s.Runtime())
+
+	// Valid config, swapped in.
+	require.NoError(t, ioutil.WriteFile(configFile, []byte(YamlConfigs+"\n"), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(configDir, "empty.yaml"), []byte("config2:\n  defaults:\n    enabled: true\n"), 0644))
+	require.NoError(t, s.Reload())
+	require.NoError(t, s.ReloadError())
+	require.NotEqual(t, rt, s.Runtime())
+	require.Len(t, *s.Runtime().Configs, 2)
+
+	metrics := promMetrics(t)
+	require.Contains(t, metrics, t.Name()+`_config_reloads_count{result="failure"} 1`)
+	require.Contains(t, metrics, t.Name()+`_config_reloads_count{result="success"} 1`)
+}
+
+// Test reload needs service created with NewService.
+func TestServiceReloadNotSupported(t *testing.T) {
+	s := initService(t, t.Name())
+	require.Equal(t, of.ErrReloadNotSupported, s.Reload())
+	require.NoError(t, s.ReloadError())
+	require.Equal(t, s.Configs, s.Runtime().Configs)
+}
@@ -1,18 +1,22 @@
 package v2
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
+	"strings"
 
 	of "github.com/cisco-cx/of/pkg/v2"
 	of_snmp "github.com/cisco-cx/of/pkg/v2/snmp"
 	am "github.com/cisco-cx/of/wrap/alertmanager/v2"
-	concatenator "github.com/cisco-cx/of/wrap/concatenator/v2"
 	herodot "github.com/cisco-cx/of/wrap/herodot/v2"
+	http "github.com/cisco-cx/of/wrap/http/v2"
 	logger "github.com/cisco-cx/of/wrap/logrus/v2"
-	mib_registry "github.com/cisco-cx/of/wrap/mib/v2"
 	prometheus "github.com/cisco-cx/of/wrap/prometheus/client_golang/v2"
+	ratelimit "github.com/cisco-cx/of/wrap/ratelimit/v2"
+	sink "github.com/cisco-cx/of/wrap/sink/v2"
+	clock "github.com/cisco-cx/of/wrap/time/v2"
 	uuid "github.com/cisco-cx/of/wrap/uuid/v2"
-	yaml "github.com/cisco-cx/of/wrap/yaml/v2"
 )
 
 const (
@@ -28,124 +32,142 @@ const (
 	unknownAlertsCount      = "unknown_alerts_count"
 	HandlerRestarted        = "handler_restarted"
 	alertsGenerationFailed  = "alerts_generation_failed_count"
+	trapsDroppedCount       = "traps_dropped_count"
+	trapsSampledCount       = "traps_sampled_count"
+	alertsAggregatedCount   = "alerts_aggregated_count"
+	alertsSuppressedCount   = "alerts_suppressed_count"
+	onFireAlertsCount       = "on_fire_alerts_count"
+	alertsMutedCount        = "alerts_muted_count"
+	configReloadsCount      = "config_reloads_count"
+	ruleHitsCount           = "rule_hits_count"
+	amPostsCount            = "am_posts_count"
+	sinkNotificationsCount  = "sink_notifications_count"
 )
 
 type Service struct {
-	Writer     of.Writer
-	Log        *logger.Logger
-	Configs    *of_snmp.V2Config
-	MR         of.MIBRegistry
-	U          of.UUIDGen
-	Lookup     of_snmp.Lookup
-	As         of.Notifier
-	Cntr       map[string]*prometheus.Counter
-	CntrVec    map[string]*prometheus.CounterVec
-	SNMPConfig *of.SNMPConfig
+	Writer      of.Writer
+	Log         *logger.Logger
+	Configs     *of_snmp.V2Config // Initial configs, use Runtime for those in use.
+	MR          of.MIBRegistry    // Initial MIBs, use Runtime for those in use.
+	U           of.UUIDGen
+	Lookup      of_snmp.Lookup // Initial lookup, use Runtime for the one in use.
+	As          of.Notifier
+	AM          *am.AlertService // Posts alerts to Alertmanager, as the alertmanager sink of As.
+	Cntr        map[string]*prometheus.Counter
+	CntrVec     map[string]*prometheus.CounterVec
+	SNMPConfig  *of.SNMPConfig
+	Clock       of.Clock
+	Storm       *StormGuard
+	Aggregator  *Aggregator
+	Heartbeats  *HeartbeatTracker
+	Suppressor  *Suppressor
+	Registry    *Registry
+	Maintenance *MaintenanceGuard
+	Recorder    of_snmp.Recorder // Records events as received, if set.
+	Rules       *RuleStats       // Counts hits of alert configs, if set.
+	Debug       *EventLog        // Keeps recent events with decision traces, if set.
+	Audit       of.Auditor       // Records alert decisions, if set.
+	AMTransport *http.Transport  // Posts alerts and checks health of Alertmanager peers.
+	Queue       of.AlertQueue    // Retry queue of the alertmanager sink, closed on shutdown if set.
+
+	reloader *reloader // Runtime swapped by Reload.
 }
 
 func NewService(l *logger.Logger, cfg *of.SNMPConfig, cntr map[string]*prometheus.Counter, cntrVec map[string]*prometheus.CounterVec) (*Service, error) {
 
-	// Concatenate configs files.
-	c := concatenator.Files{
-		Path: cfg.ConfigDir,
-		Ext:  "yaml",
-	}
-
-	r, err := c.Concat()
+	rt, err := LoadRuntime(l, cfg)
 	if err != nil {
-		l.WithError(err).Errorf("Failed to concat config files in %s.", cfg.ConfigDir)
 		return nil, err
 	}
 
-	// Decode configs files.
-	configs := yaml.Configs{}
-	err = configs.Decode(r)
+	transport, err := http.NewTransport(&cfg.AMClient, l)
 	if err != nil {
-		l.WithError(err).Errorf("Failed to decode config files in %s.", cfg.ConfigDir)
 		return nil, err
 	}
 
-	v2Config := of_snmp.V2Config(configs)
-
-	// Prepare MIBS registry
-	mr := mib_registry.New()
-
-	readerMIB := &mib_registry.MIBHandler{
-		MapMIB: make(map[string]of.MIB),
+	// Setup alert service.
+	as := am.AlertService{
+		Version:    cfg.Version,
+		AmURL:      cfg.AMAddress,
+		Peers:      cfg.AMPeers,
+		Policy:     cfg.AMPolicy,
+		APIVersion: cfg.AMAPIVersion,
+		Posts:      cntrVec[amPostsCount],
+		Client:     transport.Client(),
+		Log:        l,
 	}
 
-	if cfg.CacheFile != "none" {
-		err = readerMIB.LoadCacheFromFile(cfg.CacheFile)
+	if cfg.Throttle == true {
+		limiter, err := ratelimit.NewLimiter(&cfg.AMLimit, cfg.Application, &clock.Clock{}, l)
 		if err != nil {
-			l.WithError(err).Fatalf("Failed to load MIBs from cache.")
-		}
-	} else {
-		if cfg.SNMPMibsDir == "" {
-			l.Fatalf("Failed to load MIBs, no cache path or SNMP MIBs Dir.")
-		}
-		err = readerMIB.LoadJSONFromDir(cfg.SNMPMibsDir)
-		if err != nil {
-			l.WithError(err).Fatalf("Failed to load MIBs from MIBS dir.")
+			return nil, err
 		}
+		as.Limiter = limiter
 	}
 
-	err = mr.Load(readerMIB.MapMIB)
+	// Send alerts to sinks of cfg, dry runs only log them.
+	sinkCfg := cfg.Sinks
+	sinkCfg.DryRun = cfg.DryRun
+	sinks, err := sink.NewComposite(&sinkCfg, cfg.Version, &as, l)
 	if err != nil {
-		l.WithError(err).Fatalf("Failed to load MIBs.")
+		return nil, err
 	}
+	sinks.Posts = cntrVec[sinkNotificationsCount]
 
-	// Setup alert service.
-	as := am.AlertService{
-		Version:   cfg.Version,
-		AmURL:     cfg.AMAddress,
-		Throttle:  cfg.Throttle,
-		PostTime:  cfg.PostTime,
-		SleepTime: cfg.SleepTime,
-		SendTime:  cfg.SendTime,
-		Log:       l,
-		DryRun:    cfg.DryRun,
-	}
-
-	// Prepare lookup.
-	lookup := Lookup{Configs: v2Config, MR: mr, Log: l}
-	err = lookup.Build()
+	u := uuid.UUID{}
+	registry := NewRegistry(&clock.Clock{})
+	rules, err := NewRuleStats(cfg.Application, &clock.Clock{}, cntrVec)
 	if err != nil {
-		l.WithError(err).Errorf("Failed to build lookup.")
 		return nil, err
 	}
 
-	u := uuid.UUID{}
-
 	// INIT SNMP service.
 	s := &Service{
 		Writer:     herodot.New(l),
 		Log:        l,
-		MR:         mr,
-		Configs:    &v2Config,
+		MR:         rt.MR,
+		Configs:    rt.Configs,
 		U:          &u,
-		As:         &as,
-		Lookup:     &lookup,
+		As:         sinks,
+		AM:         &as,
+		Lookup:     rt.Lookup,
 		Cntr:       cntr,
 		CntrVec:    cntrVec,
 		SNMPConfig: cfg,
+		Clock:      &clock.Clock{},
+		Storm:      NewStormGuard(cfg, &clock.Clock{}, l, cntrVec),
+		Aggregator: NewAggregator(&clock.Clock{}, l, cntrVec),
+		Heartbeats: NewHeartbeatTracker(&clock.Clock{}, l),
+		Registry:   registry,
+		Suppressor: NewSuppressor(&clock.Clock{}, l, cntrVec, registry),
+		Rules:      rules,
+		reloader:   &reloader{},
 	}
+	s.AMTransport = transport
+	if cfg.DebugEvents > 0 {
+		s.Debug = NewEventLog(cfg.DebugEvents, l)
+	}
+	s.reloader.current.Store(rt)
 	return s, nil
 }
 
 // Search lookup to find configs that match Trap Vars values.
-func (s Service) lookupConfigs(events []*of.PostableEvent) [][]string {
+// Returns configs and lookup errors, by event index.
+func (s Service) lookupConfigs(rt *Runtime, events []*of.PostableEvent) ([][]string, []error) {
 	configs := make([][]string, len(events))
+	errs := make([]error, len(events))
 	for idx, event := range events {
 		s.Cntr[eventsReceivedCount].Incr()
 		s.Log.Tracef("Event[%d] %+v", idx, event)
-		cfgs, err := s.Lookup.Find(&event.Document.Receipts.Snmptrapd.Vars)
+		cfgs, err := rt.Lookup.Find(&event.Document.Receipts.Snmptrapd.Vars)
 		if err != nil {
 			s.Log.WithError(err).Errorf("Lookup failed.")
+			errs[idx] = err
 			continue
 		}
 		configs[idx] = cfgs
 		if len(cfgs) == 0 {
-			valueLookup := NewValue(&event.Document.Receipts.Snmptrapd.Vars, s.MR)
+			valueLookup := NewValue(&event.Document.Receipts.Snmptrapd.Vars, rt.MR)
 			trapV, _ := valueLookup.Value(of_snmp.SNMPTrapOID)
 			trapVStrShort, _ := valueLookup.ValueStrShort(of_snmp.SNMPTrapOID)
 			s.Log.WithFields(map[string]interface{}{
@@ -157,7 +179,7 @@ func (s Service) lookupConfigs(events []*of.PostableEvent) [][]string {
 			}).Debugf("No match found at lookup")
 		}
 	}
-	return configs
+	return configs, errs
 }
 
 // HTTP handler func to recieve SNMP traps.
@@ -168,27 +190,67 @@ func (s Service) AlertHandler(w of.ResponseWriter, r of.Request) {
 	if err := json.NewDecoder(r.Body).Decode(&events); err != nil {
 		s.Log.WithError(err).Errorf("Failed to decode events.")
 		s.Writer.WriteError(w, r, herodot.ErrBadRequest.WithError(err.Error()))
+		return
+	}
+
+	if s.Recorder != nil {
+		if err := s.Recorder.Record(events); err != nil {
+			s.Log.WithError(err).Errorf("Failed to record events.")
+		}
 	}
+
+	err := s.Handle(events)
+	if err != nil {
+		s.Writer.WriteCode(w, r, 503, nil)
+		return
+	}
+	s.Writer.WriteCode(w, r, 200, nil)
+}
+
+// Generate alerts for events and publish them.
+func (s Service) Handle(events []*of.PostableEvent) error {
 	s.Log.Infof("Received %d events.", len(events))
 
-	configs := s.lookupConfigs(events)
+	// Process all events with the same runtime, even if it is swapped meanwhile.
+	rt := s.Runtime()
+	events, dropped := s.admitEvents(events)
+	configs, lookupErrs := s.lookupConfigs(rt, events)
+
+	var trails []*AuditTrail
+	for _, event := range dropped {
+		trapV, _ := NewValue(&event.Document.Receipts.Snmptrapd.Vars, rt.MR).Value(of_snmp.SNMPTrapOID)
+		s.debugEvent(event, trapV, of_snmp.EventDropped, nil, nil)
+		if trail := s.auditTrail(event, trapV); trail != nil {
+			trail.Add("", "", of.Alert{}, of.AuditDropped, "above global storm limit")
+			trails = append(trails, trail)
+		}
+	}
 
 	alerter := Alerter{
 		Log:            s.Log,
-		Configs:        s.Configs,
-		MR:             s.MR,
+		Configs:        rt.Configs,
+		MR:             rt.MR,
 		U:              s.U,
 		Cntr:           s.Cntr,
 		CntrVec:        s.CntrVec,
 		LogUnknown:     s.SNMPConfig.LogUnknown,
 		ForwardUnknown: s.SNMPConfig.ForwardUnknown,
+		Aggregator:     s.Aggregator,
+		Heartbeats:     s.Heartbeats,
+		Suppressor:     s.Suppressor,
+		Registry:       s.Registry,
+		Maintenance:    s.Maintenance,
+	}
+	// Set only if present, an interface holding a nil pointer is not nil.
+	if s.Rules != nil {
+		alerter.Rules = s.Rules
 	}
 
 	var alerts []of.Alert
 	for index, event := range events {
 		snmptrapd := event.Document.Receipts.Snmptrapd
 		alerter.Receipts = &event.Document.Receipts
-		alerter.Value = NewValue(&snmptrapd.Vars, s.MR)
+		alerter.Value = NewValue(&snmptrapd.Vars, rt.MR)
 
 		trapV, _ := alerter.Value.Value(of_snmp.SNMPTrapOID)
 		s.Log.WithFields(map[string]interface{}{
@@ -197,11 +259,63 @@ func (s Service) AlertHandler(w of.ResponseWriter, r of.Request) {
 			"source":           snmptrapd.Source,
 			"SNMPTrapOIDValue": trapV,
 		}).Infof("Processing event")
+		// Explain decisions for the event, if kept for debugging.
+		var trace *bytes.Buffer
+		if s.Debug != nil {
+			trace = &bytes.Buffer{}
+			tracer := &TextTracer{W: trace}
+			tracer.Event(event)
+			tracer.Lookup(configs[index], lookupErrs[index])
+			alerter.Tracer = tracer
+		}
+		trail := s.auditTrail(event, trapV)
+		alerter.Audit = trail
+
+		var eventAlerts []of.Alert
+		outcome := of_snmp.EventNoAlert
 		if len(configs[index]) != 0 {
-			alerts = append(alerts, alerter.Alert(configs[index])...)
+			cfgNames, stormAlerts := s.limitConfigs(rt, configs[index], snmptrapd.Source)
+			if trace != nil && len(cfgNames) != len(configs[index]) {
+				fmt.Fprintf(trace, "  storm control: %d of %d configs above source limit, dropped\n", len(configs[index])-len(cfgNames), len(configs[index]))
+			}
+			if trail != nil {
+				for _, cfgName := range configs[index] {
+					if containsAll(cfgNames, []string{cfgName}) == false {
+						trail.Add(cfgName, "", of.Alert{}, of.AuditDropped, "above source storm limit")
+					}
+				}
+				for _, stormAlert := range stormAlerts {
+					trail.Add("", stormAlert.Labels["alertname"], stormAlert, of.AuditGenerated, "storm control")
+				}
+			}
+			eventAlerts = append(eventAlerts, stormAlerts...)
+			if len(cfgNames) != 0 {
+				eventAlerts = append(eventAlerts, alerter.Alert(cfgNames)...)
+			}
+			if len(eventAlerts) != 0 {
+				outcome = of_snmp.EventAlerted
+			}
 		} else {
-			alerts = append(alerts, alerter.Unknown("lookup")...)
+			eventAlerts = append(eventAlerts, alerter.Unknown("lookup")...)
+			outcome = of_snmp.EventUnknown
+			if lookupErrs[index] != nil {
+				outcome = of_snmp.EventError
+			}
+			if trail != nil {
+				if lookupErrs[index] != nil {
+					trail.Add("", "", of.Alert{}, of.AuditFailed, lookupErrs[index].Error())
+				}
+				for _, unknownAlert := range eventAlerts {
+					trail.Add("", unknownAlert.Labels["alertname"], unknownAlert, of.AuditGenerated, "unknown trap")
+				}
+			}
 		}
+		s.debugEvent(event, trapV, outcome, trace, eventAlerts)
+		if trail != nil {
+			trails = append(trails, trail)
+		}
+		alerts = append(alerts, eventAlerts...)
+		alerts = append(alerts, s.observe(eventAlerts, trail)...)
 
 		s.Cntr[eventsProcessedCount].Incr()
 	}
@@ -209,10 +323,223 @@ func (s Service) AlertHandler(w of.ResponseWriter, r of.Request) {
 	err := s.As.Notify(&alerts)
 	if err != nil {
 		s.Log.WithError(err).Errorf("Failed to publish firing alert(s) for received event")
-		s.Writer.WriteCode(w, r, 503, nil)
+	}
+	s.audit(trails, err)
+	return err
+}
+
+// Trail collecting alert decisions for event, nil if not audited.
+func (s Service) auditTrail(event *of.PostableEvent, trapOID string) *AuditTrail {
+	if s.Audit == nil {
+		return nil
+	}
+	return &AuditTrail{
+		EventID: s.U.UUID(),
+		Source:  event.Document.Receipts.Snmptrapd.Source.Address,
+		TrapOID: trapOID,
+		Clock:   s.Clock,
+	}
+}
+
+// Trail collecting decisions taken in the background, nil if not audited.
+func (s Service) backgroundTrail() *AuditTrail {
+	if s.Audit == nil {
+		return nil
+	}
+	return &AuditTrail{
+		EventID: s.U.UUID(),
+		Clock:   s.Clock,
+	}
+}
+
+// Write decisions of trails, with the result of posting their alerts.
+func (s Service) audit(trails []*AuditTrail, postErr error) {
+	if s.Audit == nil || len(trails) == 0 {
 		return
 	}
-	s.Writer.WriteCode(w, r, 200, nil)
+
+	result := of.AuditPostSent
+	if sink.Queues(s.As) == true {
+		result = of.AuditPostQueued
+	}
+	switch {
+	case s.SNMPConfig != nil && s.SNMPConfig.DryRun == true:
+		result = of.AuditPostDryRun
+	case postErr != nil:
+		result = of.AuditPostFailed
+	}
+
+	var records []of.AuditRecord
+	for _, trail := range trails {
+		trail.Posted(result, postErr)
+		records = append(records, trail.Records...)
+	}
+	if err := s.Audit.Audit(records); err != nil {
+		s.Log.WithError(err).Errorf("Failed to write %d audit records.", len(records))
+	}
+}
+
+// HTTP handler func listing alert configs in use, with their hits.
+func (s Service) RulesHandler(w of.ResponseWriter, r of.Request) {
+	s.Log.Tracef("Rules endpoint accessed.")
+	var rules []of_snmp.Rule
+	if s.Rules != nil {
+		rules = s.Rules.Rules(s.Runtime().Configs)
+	}
+	s.Writer.Write(w, r, map[string]interface{}{
+		"rules": rules,
+	})
+}
+
+// Drop or sample events above the global limit.
+// Returns events admitted and events dropped.
+func (s Service) admitEvents(events []*of.PostableEvent) ([]*of.PostableEvent, []*of.PostableEvent) {
+	if s.Storm == nil {
+		return events, nil
+	}
+
+	admitted := make([]*of.PostableEvent, 0, len(events))
+	var dropped []*of.PostableEvent
+	for _, event := range events {
+		if s.Storm.AllowGlobal() == false {
+			s.Log.WithField("source", event.Document.Receipts.Snmptrapd.Source).Tracef("Event above global limit, dropped.")
+			dropped = append(dropped, event)
+			continue
+		}
+		admitted = append(admitted, event)
+	}
+
+	if len(dropped) > 0 {
+		s.Log.Warningf("Dropped %d events above global limit.", len(dropped))
+	}
+	return admitted, dropped
+}
+
+// Keep event with its outcome and trace, logging the trace if its source is traced.
+func (s Service) debugEvent(event *of.PostableEvent, trapOID string, outcome of_snmp.EventOutcome, trace *bytes.Buffer, alerts []of.Alert) {
+	if s.Debug == nil {
+		return
+	}
+
+	source := event.Document.Receipts.Snmptrapd.Source.Address
+	debugEvent := &of_snmp.DebugEvent{
+		ReceivedAt: s.Clock.Now().UTC(),
+		Source:     source,
+		TrapOID:    trapOID,
+		Outcome:    outcome,
+		Event:      event,
+		Trace:      make([]string, 0),
+		Alerts:     alerts,
+	}
+	if trace != nil {
+		debugEvent.Trace = strings.Split(strings.TrimRight(trace.String(), "\n"), "\n")
+	}
+	s.Debug.Add(debugEvent)
+
+	if s.Debug.Traced(source) {
+		s.Log.WithFields(map[string]interface{}{
+			"source":  source,
+			"outcome": outcome,
+		}).Infof("Event trace:\n%s", strings.Join(debugEvent.Trace, "\n"))
+	}
+}
+
+// Remove configs for which the source is above its limit.
+// Returns snmpTrapStorm alerts raised for the source.
+func (s Service) limitConfigs(rt *Runtime, cfgNames []string, source of.TrapSource) ([]string, []of.Alert) {
+	if s.Storm == nil {
+		return cfgNames, nil
+	}
+
+	var alerts []of.Alert
+	allowed := make([]string, 0, len(cfgNames))
+	for _, cfgName := range cfgNames {
+		cfg, ok := (*rt.Configs)[cfgName]
+		if ok == false {
+			allowed = append(allowed, cfgName)
+			continue
+		}
+
+		ok, stormAlerts := s.Storm.Allow(cfgName, cfg.Defaults.StormControl, source)
+		alerts = append(alerts, stormAlerts...)
+		if ok == false {
+			s.Log.WithFields(map[string]interface{}{
+				"config": cfgName,
+				"source": source,
+			}).Tracef("Event above source limit, dropped.")
+			continue
+		}
+		allowed = append(allowed, cfgName)
+	}
+	return allowed, alerts
+}
+
+// Record alerts being sent, as parents for suppression and targets of on_fire actions.
+// Returns child alerts released since their parent cleared, audited on trail if set.
+func (s Service) observe(alerts []of.Alert, trail *AuditTrail) []of.Alert {
+	if len(alerts) == 0 {
+		return nil
+	}
+	if s.Maintenance != nil {
+		s.Maintenance.Observe(alerts)
+	}
+	if s.Registry == nil {
+		return nil
+	}
+	s.Registry.Observe(alerts)
+	if s.Suppressor == nil {
+		return nil
+	}
+	released := s.Suppressor.Observe(alerts)
+	if trail != nil {
+		trail.AddAlerts(released, "parent cleared")
+	}
+	return released
+}
+
+// Publish alerts that are generated in the background, like clearing of trap storms.
+func (s Service) Sweep() {
+	trail := s.backgroundTrail()
+	var alerts []of.Alert
+	// Keep alerts generated, auditing them with reason.
+	generated := func(sweptAlerts []of.Alert, reason string) {
+		if trail != nil {
+			trail.AddAlerts(sweptAlerts, reason)
+		}
+		alerts = append(alerts, sweptAlerts...)
+	}
+
+	if s.Storm != nil {
+		generated(s.Storm.Sweep(), "storm control")
+	}
+	if s.Aggregator != nil {
+		generated(s.Aggregator.Sweep(), "aggregation window passed")
+	}
+	if s.Heartbeats != nil {
+		generated(s.Heartbeats.Sweep(), "missing heartbeat")
+	}
+	if s.Maintenance != nil {
+		generated(s.Maintenance.Sweep(), "maintenance ended")
+	}
+	alerts = append(alerts, s.observe(alerts, trail)...)
+	if s.Registry != nil {
+		s.Registry.Sweep()
+	}
+	if s.Suppressor != nil {
+		generated(s.Suppressor.Sweep(), "parent expired")
+	}
+	if len(alerts) == 0 {
+		return
+	}
+
+	s.Log.Infof("Generated %d alerts in background.", len(alerts))
+	err := s.As.Notify(&alerts)
+	if err != nil {
+		s.Log.WithError(err).Errorf("Failed to publish alert(s) generated in background.")
+	}
+	if trail != nil {
+		s.audit([]*AuditTrail{trail}, err)
+	}
 }
 
 // Create counters..
@@ -288,6 +615,86 @@ func InitCounters(namespace string, log *logger.Logger) (map[string]*prometheus.
 			},
 			labels: []string{"alertType", "alert_oid"},
 		},
+		vectorInfo{
+			vector: &prometheus.CounterVec{
+				Namespace: namespace,
+				Name:      trapsDroppedCount,
+				Help:      "Number of SNMP trap events dropped for being above rate limit.",
+			},
+			labels: []string{"scope", "config"},
+		},
+		vectorInfo{
+			vector: &prometheus.CounterVec{
+				Namespace: namespace,
+				Name:      trapsSampledCount,
+				Help:      "Number of SNMP trap events above rate limit, that were sampled for processing.",
+			},
+			labels: []string{"scope", "config"},
+		},
+		vectorInfo{
+			vector: &prometheus.CounterVec{
+				Namespace: namespace,
+				Name:      alertsAggregatedCount,
+				Help:      "Number of firing alert occurrences counted for aggregation.",
+			},
+			labels: []string{"config", "alert"},
+		},
+		vectorInfo{
+			vector: &prometheus.CounterVec{
+				Namespace: namespace,
+				Name:      alertsSuppressedCount,
+				Help:      "Number of firing alerts held back or tagged, while their parent alert is firing.",
+			},
+			labels: []string{"config", "parent", "action"},
+		},
+		vectorInfo{
+			vector: &prometheus.CounterVec{
+				Namespace: namespace,
+				Name:      onFireAlertsCount,
+				Help:      "Number of alerts cleared or re-raised by on_fire actions.",
+			},
+			labels: []string{"config", "alertType"},
+		},
+		vectorInfo{
+			vector: &prometheus.CounterVec{
+				Namespace: namespace,
+				Name:      alertsMutedCount,
+				Help:      "Number of firing alerts dropped, tagged or delayed during maintenance.",
+			},
+			labels: []string{"config", "action"},
+		},
+		vectorInfo{
+			vector: &prometheus.CounterVec{
+				Namespace: namespace,
+				Name:      configReloadsCount,
+				Help:      "Number of times configs were reloaded, by result.",
+			},
+			labels: []string{"result"},
+		},
+		vectorInfo{
+			vector: &prometheus.CounterVec{
+				Namespace: namespace,
+				Name:      ruleHitsCount,
+				Help:      "Number of times alert configs matched, fired, cleared, were disabled or failed.",
+			},
+			labels: []string{"config", "alert", "result"},
+		},
+		vectorInfo{
+			vector: &prometheus.CounterVec{
+				Namespace: namespace,
+				Name:      amPostsCount,
+				Help:      "Number of times alerts were posted to an Alertmanager peer, by result.",
+			},
+			labels: []string{"peer", "result"},
+		},
+		vectorInfo{
+			vector: &prometheus.CounterVec{
+				Namespace: namespace,
+				Name:      sinkNotificationsCount,
+				Help:      "Number of times alerts were sent to a sink, by result.",
+			},
+			labels: []string{"sink", "result"},
+		},
 	}
 
 	cntrVec := make(map[string]*prometheus.CounterVec)
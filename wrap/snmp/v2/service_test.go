@@ -3,6 +3,9 @@ package v2_test
 import (
 	"bytes"
 	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -11,7 +14,9 @@ import (
 	herodot "github.com/cisco-cx/of/wrap/herodot/v2"
 	http "github.com/cisco-cx/of/wrap/http/v2"
 	logger "github.com/cisco-cx/of/wrap/logrus/v2"
+	mib_registry "github.com/cisco-cx/of/wrap/mib/v2"
 	snmp "github.com/cisco-cx/of/wrap/snmp/v2"
+	clock "github.com/cisco-cx/of/wrap/time/v2"
 	uuid "github.com/cisco-cx/of/wrap/uuid/v2"
 	yaml "github.com/cisco-cx/of/wrap/yaml/v2"
 	"github.com/stretchr/testify/require"
@@ -165,6 +170,7 @@ func initService(t *testing.T, namespace string) *snmp.Service {
 		Cntr:       cntr,
 		CntrVec:    cntrVec,
 		SNMPConfig: cfg,
+		Clock:      &clock.Clock{},
 	}
 	return s
 }
@@ -176,3 +182,37 @@ func compareLabels(t *testing.T, alerts *[]of.Alert, expectedLabels []map[string
 	}
 	require.Equal(t, expectedLabels, alertLabels)
 }
+
+// Test dry runs only log alerts, as such.
+func TestServiceDryRun(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snmp-dry-run")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	mh := &mib_registry.MIBHandler{MapMIB: map[string]of.MIB{
+		"1.3.6.1.4.1.8164.2.150": of.MIB{Name: "starTaskFailed"},
+	}}
+	cacheFile := filepath.Join(dir, "mibs.cache")
+	require.NoError(t, mh.WriteCacheToFile(cacheFile))
+	configFile := filepath.Join(dir, "configs.yaml")
+	require.NoError(t, ioutil.WriteFile(configFile, []byte(YamlConfigs+"\n"), 0644))
+
+	output := &bytes.Buffer{}
+	l := logger.New()
+	l.SetOutput(output)
+	cntr, cntrVec := snmp.InitCounters(t.Name(), l)
+	cfg := &of.SNMPConfig{Application: t.Name(), ConfigDir: dir, CacheFile: cacheFile, DryRun: true}
+	cfg.Sinks.Sinks = []of.SinkType{of.SinkAlertmanager}
+	s, err := snmp.NewService(l, cfg, cntr, cntrVec)
+	require.NoError(t, err)
+
+	docs := []of.Document{}
+	require.NoError(t, json.Unmarshal([]byte(StarEvents), &docs))
+	events := make([]*of.PostableEvent, len(docs))
+	for i, doc := range docs {
+		events[i] = &of.PostableEvent{Document: doc}
+	}
+	require.NoError(t, s.Handle(events))
+	require.Contains(t, output.String(), `msg="Dry run."`)
+	require.NotContains(t, output.String(), "msg=Alert.")
+}
@@ -0,0 +1,243 @@
+package v2
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+	of_snmp "github.com/cisco-cx/of/pkg/v2/snmp"
+	logger "github.com/cisco-cx/of/wrap/logrus/v2"
+	prometheus "github.com/cisco-cx/of/wrap/prometheus/client_golang/v2"
+	ratelimit "github.com/cisco-cx/of/wrap/ratelimit/v2"
+)
+
+const (
+	// Seconds a source must stay within its limit, before snmpTrapStorm is cleared.
+	defaultStormClearAfter = 60
+	// Firing snmpTrapStorm alerts are resent at this interval, while the storm lasts.
+	stormResendInterval = time.Minute
+	// Buckets of sources not seen for this long are forgotten.
+	stormIdleTimeout = 10 * time.Minute
+)
+
+// Protects the handler from trap storms, using a global and per source token bucket.
+type StormGuard struct {
+	Clock   of.Clock
+	Log     *logger.Logger
+	CntrVec map[string]*prometheus.CounterVec
+
+	mu               sync.Mutex
+	global           *ratelimit.TokenBucket
+	globalAction     of_snmp.StormAction
+	globalSampleRate int
+	globalExcess     int
+	sources          map[string]*stormSource
+}
+
+// Represents rate limit state of a source, for a config.
+type stormSource struct {
+	config       string
+	source       of.TrapSource
+	limit        of_snmp.StormControl
+	bucket       *ratelimit.TokenBucket
+	storming     bool
+	excess       int
+	since        time.Time
+	lastExceeded time.Time
+	lastSeen     time.Time
+	lastSent     time.Time
+}
+
+// Init StormGuard. Global limit is disabled if cfg.StormGlobalRate is 0.
+func NewStormGuard(cfg *of.SNMPConfig, clock of.Clock, l *logger.Logger, cntrVec map[string]*prometheus.CounterVec) *StormGuard {
+	g := &StormGuard{
+		Clock:            clock,
+		Log:              l,
+		CntrVec:          cntrVec,
+		globalAction:     of_snmp.StormAction(cfg.StormGlobalAction),
+		globalSampleRate: cfg.StormGlobalSampleRate,
+		sources:          make(map[string]*stormSource),
+	}
+	if cfg.StormGlobalRate > 0 {
+		g.global = ratelimit.NewTokenBucket(cfg.StormGlobalRate, cfg.StormGlobalBurst)
+	}
+	return g
+}
+
+// Check if an event is within the global limit.
+func (g *StormGuard) AllowGlobal() bool {
+	if g.global == nil {
+		return true
+	}
+
+	if g.global.Allow(g.Clock.Now()) == true {
+		return true
+	}
+
+	g.mu.Lock()
+	g.globalExcess++
+	excess := g.globalExcess
+	g.mu.Unlock()
+
+	if g.sampled(g.globalAction, g.globalSampleRate, excess) {
+		g.incr(trapsSampledCount, "global", "")
+		return true
+	}
+	g.incr(trapsDroppedCount, "global", "")
+	return false
+}
+
+// Check if an event from source is within the limit set for config cfgName.
+// Returns snmpTrapStorm alert, when the source starts exceeding its limit.
+func (g *StormGuard) Allow(cfgName string, sc *of_snmp.StormControl, source of.TrapSource) (bool, []of.Alert) {
+	if sc == nil || sc.Rate <= 0 {
+		return true, nil
+	}
+
+	now := g.Clock.Now()
+	key := cfgName + "/" + source.Address
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	s, ok := g.sources[key]
+	if ok == false || s.limit != *sc {
+		// New source, or limits changed in config.
+		if ok == false {
+			s = &stormSource{config: cfgName}
+			g.sources[key] = s
+		}
+		s.limit = *sc
+		s.bucket = ratelimit.NewTokenBucket(sc.Rate, sc.Burst)
+	}
+	s.source = source
+	s.lastSeen = now
+
+	if s.bucket.Allow(now) == true {
+		return true, nil
+	}
+
+	var alerts []of.Alert
+	s.lastExceeded = now
+	if s.storming == false {
+		s.storming = true
+		s.excess = 0
+		s.since = now
+		s.lastSent = now
+		g.Log.WithFields(map[string]interface{}{
+			"config": cfgName,
+			"source": source,
+			"rate":   sc.Rate,
+			"burst":  sc.Burst,
+		}).Warningf("Trap storm detected.")
+		alerts = append(alerts, g.alert(s, of_snmp.Firing, now))
+	}
+	s.excess++
+
+	if g.sampled(sc.Action, sc.SampleRate, s.excess) {
+		g.incr(trapsSampledCount, "source", cfgName)
+		return true, alerts
+	}
+	g.incr(trapsDroppedCount, "source", cfgName)
+	return false, alerts
+}
+
+// Clear storms for sources that stayed within their limits, resend firing alerts for ongoing storms
+// and forget idle sources.
+func (g *StormGuard) Sweep() []of.Alert {
+	now := g.Clock.Now()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	var alerts []of.Alert
+	for key, s := range g.sources {
+		if s.storming == true {
+			clearAfter := s.limit.ClearAfter
+			if clearAfter <= 0 {
+				clearAfter = defaultStormClearAfter
+			}
+
+			if now.Sub(s.lastExceeded) >= time.Duration(clearAfter)*time.Second {
+				s.storming = false
+				g.Log.WithFields(map[string]interface{}{
+					"config":  s.config,
+					"source":  s.source,
+					"dropped": s.excess,
+				}).Infof("Trap storm cleared.")
+				alerts = append(alerts, g.alert(s, of_snmp.Clearing, now))
+				continue
+			}
+
+			if now.Sub(s.lastSent) >= stormResendInterval {
+				s.lastSent = now
+				alerts = append(alerts, g.alert(s, of_snmp.Firing, now))
+			}
+			continue
+		}
+
+		if now.Sub(s.lastSeen) >= stormIdleTimeout {
+			delete(g.sources, key)
+		}
+	}
+	return alerts
+}
+
+// Decide if a trap above limit is to be processed, based on action.
+func (g *StormGuard) sampled(action of_snmp.StormAction, sampleRate int, excess int) bool {
+	if action != of_snmp.StormSample || sampleRate <= 0 {
+		return false
+	}
+	return excess%sampleRate == 0
+}
+
+// Increment trap counters, if available.
+func (g *StormGuard) incr(name string, scope string, cfgName string) {
+	if c, ok := g.CntrVec[name]; ok == true {
+		c.Incr(map[string]string{
+			"scope":  scope,
+			"config": cfgName,
+		})
+	}
+}
+
+// Prepare snmpTrapStorm alert for given source.
+func (g *StormGuard) alert(s *stormSource, eventType of_snmp.EventType, now time.Time) of.Alert {
+	alert := of.Alert{
+		Labels: map[string]string{
+			"alertname":       of_snmp.StormAlertName,
+			"alert_severity":  "warning",
+			"source_address":  s.source.Address,
+			"source_hostname": s.source.Hostname,
+			"config":          s.config,
+		},
+		Annotations: map[string]string{
+			string(of_snmp.EventTypeText): string(eventType),
+			"source_address":              s.source.Address,
+			"source_hostname":             s.source.Hostname,
+			"storm_rate":                  strconv.FormatFloat(s.limit.Rate, 'f', -1, 64),
+			"storm_burst":                 strconv.Itoa(s.limit.Burst),
+			"storm_action":                string(s.action()),
+			"storm_since":                 s.since.Format(time.RFC3339),
+			"traps_above_limit":           strconv.Itoa(s.excess),
+			"description": fmt.Sprintf("Source %s is sending traps for %s faster than %s/s.",
+				s.source.Address, s.config, strconv.FormatFloat(s.limit.Rate, 'f', -1, 64)),
+		},
+		StartsAt: s.since,
+	}
+	if eventType == of_snmp.Clearing {
+		alert.EndsAt = now
+	}
+	alert.Labels[of_snmp.FingerprintText] = fingerprint(alert)
+	return alert
+}
+
+// Action applied to traps above limit.
+func (s *stormSource) action() of_snmp.StormAction {
+	if s.limit.Action == "" {
+		return of_snmp.StormDrop
+	}
+	return s.limit.Action
+}
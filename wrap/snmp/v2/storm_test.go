@@ -0,0 +1,151 @@
+package v2_test
+
+import (
+	"testing"
+	"time"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+	of_snmp "github.com/cisco-cx/of/pkg/v2/snmp"
+	logger "github.com/cisco-cx/of/wrap/logrus/v2"
+	snmp "github.com/cisco-cx/of/wrap/snmp/v2"
+	clock "github.com/cisco-cx/of/wrap/time/v2"
+	"github.com/stretchr/testify/require"
+)
+
+var stormSource = of.TrapSource{Address: "192.168.1.28", Hostname: "storming-host"}
+
+// Test per source limit, storm alert and clearing.
+func TestStormGuardSource(t *testing.T) {
+	c := &clock.FixedClock{T: time.Date(2020, 5, 1, 22, 22, 53, 0, time.UTC)}
+	l := logger.New()
+	_, cntrVec := snmp.InitCounters(t.Name(), l)
+	g := snmp.NewStormGuard(&of.SNMPConfig{}, c, l, cntrVec)
+
+	sc := &of_snmp.StormControl{Rate: 1, Burst: 2, ClearAfter: 30}
+
+	// Within burst.
+	for i := 0; i < 2; i++ {
+		ok, alerts := g.Allow("epc", sc, stormSource)
+		require.True(t, ok)
+		require.Empty(t, alerts)
+	}
+
+	// Above limit, storm alert is raised once.
+	ok, alerts := g.Allow("epc", sc, stormSource)
+	require.False(t, ok)
+	require.Len(t, alerts, 1)
+	require.Equal(t, of_snmp.StormAlertName, alerts[0].Labels["alertname"])
+	require.Equal(t, "192.168.1.28", alerts[0].Labels["source_address"])
+	require.Equal(t, "epc", alerts[0].Labels["config"])
+	require.Equal(t, string(of_snmp.Firing), alerts[0].Annotations["event_type"])
+	require.NotEmpty(t, alerts[0].Labels[of_snmp.FingerprintText])
+	require.True(t, alerts[0].EndsAt.IsZero())
+	fingerprint := alerts[0].Labels[of_snmp.FingerprintText]
+
+	ok, alerts = g.Allow("epc", sc, stormSource)
+	require.False(t, ok)
+	require.Empty(t, alerts)
+
+	// Other configs and sources are not affected.
+	ok, _ = g.Allow("nso", sc, stormSource)
+	require.True(t, ok)
+	ok, _ = g.Allow("epc", sc, of.TrapSource{Address: "192.168.1.29"})
+	require.True(t, ok)
+
+	// Storm is still active within clear_after.
+	c.Add(10 * time.Second)
+	require.Empty(t, g.Sweep())
+
+	// Storm clears after source stays within limit.
+	c.Add(30 * time.Second)
+	alerts = g.Sweep()
+	require.Len(t, alerts, 1)
+	require.Equal(t, string(of_snmp.Clearing), alerts[0].Annotations["event_type"])
+	require.Equal(t, "2", alerts[0].Annotations["traps_above_limit"])
+	require.Equal(t, c.Now(), alerts[0].EndsAt)
+	require.Equal(t, fingerprint, alerts[0].Labels[of_snmp.FingerprintText])
+
+	require.Empty(t, g.Sweep())
+
+	metrics := promMetrics(t)
+	require.Contains(t, metrics, t.Name()+`_traps_dropped_count{config="epc",scope="source"} 2`)
+}
+
+// Test firing storm alert is resent while storm lasts.
+func TestStormGuardResend(t *testing.T) {
+	c := &clock.FixedClock{T: time.Date(2020, 5, 1, 22, 22, 53, 0, time.UTC)}
+	l := logger.New()
+	g := snmp.NewStormGuard(&of.SNMPConfig{}, c, l, nil)
+
+	sc := &of_snmp.StormControl{Rate: 0.01, Burst: 1}
+	g.Allow("epc", sc, stormSource)
+	ok, alerts := g.Allow("epc", sc, stormSource)
+	require.False(t, ok)
+	require.Len(t, alerts, 1)
+
+	for i := 0; i < 3; i++ {
+		c.Add(30 * time.Second)
+		g.Allow("epc", sc, stormSource)
+	}
+	alerts = g.Sweep()
+	require.Len(t, alerts, 1)
+	require.Equal(t, string(of_snmp.Firing), alerts[0].Annotations["event_type"])
+}
+
+// Test sampling of traps above limit.
+func TestStormGuardSample(t *testing.T) {
+	c := &clock.FixedClock{T: time.Date(2020, 5, 1, 22, 22, 53, 0, time.UTC)}
+	l := logger.New()
+	_, cntrVec := snmp.InitCounters(t.Name(), l)
+	g := snmp.NewStormGuard(&of.SNMPConfig{}, c, l, cntrVec)
+
+	sc := &of_snmp.StormControl{Rate: 1, Burst: 1, Action: of_snmp.StormSample, SampleRate: 3}
+	allowed := 0
+	for i := 0; i < 10; i++ {
+		if ok, _ := g.Allow("epc", sc, stormSource); ok == true {
+			allowed++
+		}
+	}
+
+	// 1 within burst, 3 out of 9 above limit.
+	require.Equal(t, 4, allowed)
+	metrics := promMetrics(t)
+	require.Contains(t, metrics, t.Name()+`_traps_sampled_count{config="epc",scope="source"} 3`)
+	require.Contains(t, metrics, t.Name()+`_traps_dropped_count{config="epc",scope="source"} 6`)
+}
+
+// Test global limit.
+func TestStormGuardGlobal(t *testing.T) {
+	c := &clock.FixedClock{T: time.Date(2020, 5, 1, 22, 22, 53, 0, time.UTC)}
+	l := logger.New()
+	cfg := &of.SNMPConfig{StormGlobalRate: 10, StormGlobalBurst: 5}
+	g := snmp.NewStormGuard(cfg, c, l, nil)
+
+	allowed := 0
+	for i := 0; i < 10; i++ {
+		if g.AllowGlobal() == true {
+			allowed++
+		}
+	}
+	require.Equal(t, 5, allowed)
+
+	c.Add(100 * time.Millisecond)
+	require.True(t, g.AllowGlobal())
+
+	// Disabled global limit.
+	g = snmp.NewStormGuard(&of.SNMPConfig{}, c, l, nil)
+	for i := 0; i < 100; i++ {
+		require.True(t, g.AllowGlobal())
+	}
+}
+
+// Test configs without storm control are not limited.
+func TestStormGuardDisabled(t *testing.T) {
+	c := &clock.FixedClock{T: time.Date(2020, 5, 1, 22, 22, 53, 0, time.UTC)}
+	g := snmp.NewStormGuard(&of.SNMPConfig{}, c, logger.New(), nil)
+	for i := 0; i < 100; i++ {
+		ok, alerts := g.Allow("epc", nil, stormSource)
+		require.True(t, ok)
+		require.Empty(t, alerts)
+	}
+}
@@ -0,0 +1,147 @@
+package v2
+
+import (
+	"sync"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+	of_snmp "github.com/cisco-cx/of/pkg/v2/snmp"
+	logger "github.com/cisco-cx/of/wrap/logrus/v2"
+	prometheus "github.com/cisco-cx/of/wrap/prometheus/client_golang/v2"
+)
+
+// Holds back or tags child alerts, while their parent alert is firing.
+type Suppressor struct {
+	Clock    of.Clock
+	Log      *logger.Logger
+	CntrVec  map[string]*prometheus.CounterVec
+	Registry *Registry // Parent alerts are looked up in registry.
+
+	mu   sync.Mutex
+	held map[string]heldAlert // Held back child alerts, by fingerprint.
+}
+
+// Represents a child alert held back by its parent.
+type heldAlert struct {
+	config string
+	rules  []of_snmp.Suppression
+	alert  of.Alert
+	parent string
+}
+
+// Init Suppressor.
+func NewSuppressor(clock of.Clock, l *logger.Logger, cntrVec map[string]*prometheus.CounterVec, registry *Registry) *Suppressor {
+	return &Suppressor{
+		Clock:    clock,
+		Log:      l,
+		CntrVec:  cntrVec,
+		Registry: registry,
+		held:     make(map[string]heldAlert),
+	}
+}
+
+// Check firing alert against suppressed_by rules of alertCfg.
+// Returns alert to send and true, or false if alert is held back while parent is firing.
+func (s *Suppressor) Suppress(cfgName string, alertCfg of_snmp.Alert, alert of.Alert) (of.Alert, bool) {
+	if len(alertCfg.SuppressedBy) == 0 {
+		return alert, true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.suppress(cfgName, alertCfg.SuppressedBy, alert)
+}
+
+// Drop held back child alerts that are being cleared, expects alerts to be observed by registry.
+// Returns held back child alerts, that are released since their parent cleared.
+func (s *Suppressor) Observe(alerts []of.Alert) []of.Alert {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, alert := range alerts {
+		if alert.Annotations[of_snmp.EventTypeText] == string(of_snmp.Clearing) {
+			// Child cleared before its parent.
+			delete(s.held, alert.Labels[of_snmp.FingerprintText])
+		}
+	}
+	return s.release()
+}
+
+// Returns child alerts released since their parent expired.
+func (s *Suppressor) Sweep() []of.Alert {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.release()
+}
+
+// Check alert against rules, expects lock to be held.
+func (s *Suppressor) suppress(cfgName string, rules []of_snmp.Suppression, alert of.Alert) (of.Alert, bool) {
+	for _, rule := range rules {
+		parents := s.Registry.Firing(rule.Alert, rule.Equal, alert.Labels)
+		if len(parents) == 0 {
+			continue
+		}
+		parent := parents[0]
+
+		action := rule.Action
+		if action == "" {
+			action = of_snmp.SuppressHold
+		}
+		if c, ok := s.CntrVec[alertsSuppressedCount]; ok == true {
+			c.Incr(map[string]string{
+				"config": cfgName,
+				"parent": rule.Alert,
+				"action": string(action),
+			})
+		}
+		s.Log.WithFields(map[string]interface{}{
+			"config": cfgName,
+			"labels": alert.Labels,
+			"parent": parent.Labels,
+			"action": action,
+		}).Debugf("Alert suppressed by parent.")
+
+		if action == of_snmp.SuppressTag {
+			// Tag is an annotation, so clearing alert keeps matching labels.
+			tagged := copyAlert(alert)
+			tagged.Annotations[of_snmp.SuppressedText] = rule.Alert
+			return tagged, true
+		}
+
+		s.held[alert.Labels[of_snmp.FingerprintText]] = heldAlert{
+			config: cfgName,
+			rules:  rules,
+			alert:  alert,
+			parent: parent.Labels[of_snmp.FingerprintText],
+		}
+		return alert, false
+	}
+	return alert, true
+}
+
+// Release child alerts without a firing parent, expects lock to be held.
+func (s *Suppressor) release() []of.Alert {
+	now := s.Clock.Now()
+
+	var alerts []of.Alert
+	for fp, h := range s.held {
+		if s.Registry.Active(h.parent) == true {
+			continue
+		}
+
+		delete(s.held, fp)
+		if h.alert.EndsAt.IsZero() == false && now.After(h.alert.EndsAt) {
+			continue
+		}
+
+		// Child might still be suppressed by another parent.
+		alert, send := s.suppress(h.config, h.rules, h.alert)
+		if send == true {
+			s.Log.WithFields(map[string]interface{}{
+				"config": h.config,
+				"labels": alert.Labels,
+			}).Debugf("Releasing alert, parent cleared.")
+			alerts = append(alerts, alert)
+		}
+	}
+	return alerts
+}
@@ -0,0 +1,154 @@
+package v2_test
+
+import (
+	"testing"
+	"time"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+	of_snmp "github.com/cisco-cx/of/pkg/v2/snmp"
+	logger "github.com/cisco-cx/of/wrap/logrus/v2"
+	snmp "github.com/cisco-cx/of/wrap/snmp/v2"
+	clock "github.com/cisco-cx/of/wrap/time/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func suppressAlert(name string, source string, eventType of_snmp.EventType) of.Alert {
+	alert := of.Alert{
+		Labels: map[string]string{
+			"alertname":      name,
+			"source_address": source,
+		},
+		Annotations: map[string]string{
+			of_snmp.EventTypeText: string(eventType),
+		},
+	}
+	alert.Labels[of_snmp.FingerprintText] = (&snmp.Alerter{}).Fingerprint(alert)
+	return alert
+}
+
+// Observe alerts in registry and suppressor.
+func observe(r *snmp.Registry, s *snmp.Suppressor, alerts ...of.Alert) []of.Alert {
+	r.Observe(alerts)
+	return s.Observe(alerts)
+}
+
+var childCfg = of_snmp.Alert{
+	Name:         "starCardDown",
+	SuppressedBy: []of_snmp.Suppression{of_snmp.Suppression{Alert: "starChassisDown"}},
+}
+
+// Test child alert is held back while parent fires, and released when parent clears.
+func TestSuppressorHold(t *testing.T) {
+	c := &clock.FixedClock{T: time.Date(2020, 5, 1, 22, 22, 53, 0, time.UTC)}
+	l := logger.New()
+	_, cntrVec := snmp.InitCounters(t.Name(), l)
+	r := snmp.NewRegistry(c)
+	s := snmp.NewSuppressor(c, l, cntrVec, r)
+
+	child := suppressAlert("starCardDown", "192.168.1.28", of_snmp.Firing)
+
+	// No parent.
+	_, send := s.Suppress("epc", childCfg, child)
+	require.True(t, send)
+
+	// Parent firing, for same source.
+	require.Empty(t, observe(r, s, suppressAlert("starChassisDown", "192.168.1.28", of_snmp.Firing)))
+	_, send = s.Suppress("epc", childCfg, child)
+	require.False(t, send)
+
+	// Parent for other sources don't suppress.
+	_, send = s.Suppress("epc", childCfg, suppressAlert("starCardDown", "192.168.1.29", of_snmp.Firing))
+	require.True(t, send)
+
+	// Parent cleared, child is released.
+	released := observe(r, s, suppressAlert("starChassisDown", "192.168.1.28", of_snmp.Clearing))
+	require.Equal(t, []of.Alert{child}, released)
+	require.Empty(t, s.Sweep())
+
+	metrics := promMetrics(t)
+	require.Contains(t, metrics, t.Name()+`_alerts_suppressed_count{action="hold",config="epc",parent="starChassisDown"} 1`)
+}
+
+// Test held child is dropped, if it clears before parent.
+func TestSuppressorChildCleared(t *testing.T) {
+	c := &clock.FixedClock{T: time.Date(2020, 5, 1, 22, 22, 53, 0, time.UTC)}
+	r := snmp.NewRegistry(c)
+	s := snmp.NewSuppressor(c, logger.New(), nil, r)
+
+	observe(r, s, suppressAlert("starChassisDown", "192.168.1.28", of_snmp.Firing))
+	_, send := s.Suppress("epc", childCfg, suppressAlert("starCardDown", "192.168.1.28", of_snmp.Firing))
+	require.False(t, send)
+
+	require.Empty(t, observe(r, s, suppressAlert("starCardDown", "192.168.1.28", of_snmp.Clearing)))
+	require.Empty(t, observe(r, s, suppressAlert("starChassisDown", "192.168.1.28", of_snmp.Clearing)))
+}
+
+// Test child is released, when parent expires.
+func TestSuppressorParentExpired(t *testing.T) {
+	c := &clock.FixedClock{T: time.Date(2020, 5, 1, 22, 22, 53, 0, time.UTC)}
+	r := snmp.NewRegistry(c)
+	s := snmp.NewSuppressor(c, logger.New(), nil, r)
+
+	observe(r, s, suppressAlert("starChassisDown", "192.168.1.28", of_snmp.Firing))
+	_, send := s.Suppress("epc", childCfg, suppressAlert("starCardDown", "192.168.1.28", of_snmp.Firing))
+	require.False(t, send)
+
+	c.Add(4 * time.Minute)
+	require.Empty(t, s.Sweep())
+	c.Add(2 * time.Minute)
+	require.Len(t, s.Sweep(), 1)
+}
+
+// Test child is tagged while parent fires.
+func TestSuppressorTag(t *testing.T) {
+	c := &clock.FixedClock{T: time.Date(2020, 5, 1, 22, 22, 53, 0, time.UTC)}
+	r := snmp.NewRegistry(c)
+	s := snmp.NewSuppressor(c, logger.New(), nil, r)
+
+	cfg := of_snmp.Alert{
+		Name: "starCardDown",
+		SuppressedBy: []of_snmp.Suppression{
+			of_snmp.Suppression{Alert: "starChassisDown", Equal: []string{"cluster"}, Action: of_snmp.SuppressTag},
+		},
+	}
+	parent := suppressAlert("starChassisDown", "192.168.1.28", of_snmp.Firing)
+	parent.Labels["cluster"] = "pgw1"
+	observe(r, s, parent)
+
+	child := suppressAlert("starCardDown", "192.168.1.29", of_snmp.Firing)
+	child.Labels["cluster"] = "pgw1"
+	alert, send := s.Suppress("epc", cfg, child)
+	require.True(t, send)
+	require.Equal(t, "starChassisDown", alert.Annotations[of_snmp.SuppressedText])
+	require.Equal(t, child.Labels, alert.Labels)
+	require.Empty(t, child.Annotations[of_snmp.SuppressedText])
+}
+
+// Test clearing alert resolves tagged child.
+func TestSuppressorTagCleared(t *testing.T) {
+	c := &clock.FixedClock{T: time.Date(2020, 5, 1, 22, 22, 53, 0, time.UTC)}
+	r := snmp.NewRegistry(c)
+	s := snmp.NewSuppressor(c, logger.New(), nil, r)
+
+	cfg := of_snmp.Alert{
+		Name: "starCardDown",
+		SuppressedBy: []of_snmp.Suppression{
+			of_snmp.Suppression{Alert: "starChassisDown", Action: of_snmp.SuppressTag},
+		},
+	}
+	observe(r, s, suppressAlert("starChassisDown", "192.168.1.28", of_snmp.Firing))
+
+	// Fire and tag child.
+	alert, send := s.Suppress("epc", cfg, suppressAlert("starCardDown", "192.168.1.28", of_snmp.Firing))
+	require.True(t, send)
+	require.Equal(t, "starChassisDown", alert.Annotations[of_snmp.SuppressedText])
+	require.Empty(t, observe(r, s, alert))
+	fp := alert.Labels[of_snmp.FingerprintText]
+	require.True(t, r.Active(fp))
+
+	// Clear child, which resolves the tagged alert.
+	clear := suppressAlert("starCardDown", "192.168.1.28", of_snmp.Clearing)
+	require.Equal(t, alert.Labels, clear.Labels)
+	require.Empty(t, observe(r, s, clear))
+	require.False(t, r.Active(fp))
+}
@@ -0,0 +1,187 @@
+package v2
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+	of_snmp "github.com/cisco-cx/of/pkg/v2/snmp"
+	logger "github.com/cisco-cx/of/wrap/logrus/v2"
+	prometheus "github.com/cisco-cx/of/wrap/prometheus/client_golang/v2"
+	clock "github.com/cisco-cx/of/wrap/time/v2"
+	uuid "github.com/cisco-cx/of/wrap/uuid/v2"
+)
+
+// Time of the fixed clock, for test cases without time.
+var defaultTestTime = time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Implements of_snmp.Tester, running traps through Alerter with a fixed UUID and clock.
+// Like Evaluator, stateful processing such as aggregation and suppression is not applied.
+type Tester struct {
+	Runtime *Runtime
+	Log     *logger.Logger
+	Cntr    map[string]*prometheus.Counter
+	CntrVec map[string]*prometheus.CounterVec
+}
+
+// Run test cases of file, returning a result for each.
+func (t *Tester) Run(name string, file of_snmp.TestFile) []of_snmp.TestResult {
+	results := make([]of_snmp.TestResult, len(file.Tests))
+	for i, tc := range file.Tests {
+		start := time.Now()
+		results[i] = of_snmp.TestResult{
+			File:     name,
+			Name:     tc.Name,
+			Failures: t.runCase(tc),
+			Duration: time.Since(start),
+		}
+	}
+	return results
+}
+
+// Run traps of test case, returning failures.
+func (t *Tester) runCase(tc of_snmp.TestCase) []string {
+	now := defaultTestTime
+	if tc.Time != "" {
+		var err error
+		if now, err = time.Parse(time.RFC3339, tc.Time); err != nil {
+			return []string{fmt.Sprintf("invalid time %q, expected RFC3339", tc.Time)}
+		}
+	}
+	for _, exp := range tc.ExpectedAlerts {
+		if exp.Type != "" && exp.Type != "firing" && exp.Type != "clearing" {
+			return []string{fmt.Sprintf("invalid expected alert type %q, expected firing or clearing", exp.Type)}
+		}
+	}
+
+	events := make([]*of.PostableEvent, len(tc.Traps))
+	for i, trap := range tc.Traps {
+		event := &of.PostableEvent{}
+		event.Document.Receipts.Snmptrapd = of.Snmptrapd{
+			Timestamp:   trap.Timestamp,
+			Source:      of.TrapSource{Address: trap.Source, Hostname: trap.Hostname},
+			Vars:        trap.Vars,
+			PduSecurity: trap.PduSecurity,
+		}
+		events[i] = event
+	}
+
+	e := Evaluator{
+		Runtime: t.Runtime,
+		Log:     t.Log,
+		U:       &uuid.FixedUUID{},
+		Clock:   &clock.FixedClock{T: now},
+		Cntr:    t.Cntr,
+		CntrVec: t.CntrVec,
+	}
+	return compareAlerts(tc.ExpectedAlerts, e.Evaluate(events))
+}
+
+// Match each expected alert with a distinct generated alert.
+// Returns missing expected alerts, with differences to the closest generated alert, and unexpected alerts.
+func compareAlerts(expected []of_snmp.ExpectedAlert, alerts []of.Alert) []string {
+	matched := make([]bool, len(alerts))
+	var missing []of_snmp.ExpectedAlert
+	for _, exp := range expected {
+		found := false
+		for i, alert := range alerts {
+			if matched[i] == false && len(alertDiff(exp, alert)) == 0 {
+				matched[i] = true
+				found = true
+				break
+			}
+		}
+		if found == false {
+			missing = append(missing, exp)
+		}
+	}
+
+	var failures []string
+	for _, exp := range missing {
+		failure := "missing " + expectedString(exp)
+		closest, closestDiff := -1, []string(nil)
+		for i, alert := range alerts {
+			if matched[i] == true {
+				continue
+			}
+			if diff := alertDiff(exp, alert); closest < 0 || len(diff) < len(closestDiff) {
+				closest, closestDiff = i, diff
+			}
+		}
+		if closest >= 0 {
+			failure += "\n  closest " + alertString(alerts[closest])
+			for _, d := range closestDiff {
+				failure += "\n    " + d
+			}
+		}
+		failures = append(failures, failure)
+	}
+	for i, alert := range alerts {
+		if matched[i] == false {
+			failures = append(failures, "unexpected "+alertString(alert))
+		}
+	}
+	return failures
+}
+
+// Differences of alert from expected alert.
+func alertDiff(exp of_snmp.ExpectedAlert, alert of.Alert) []string {
+	var diff []string
+	if exp.Type != "" && exp.Type != alertType(alert) {
+		diff = append(diff, fmt.Sprintf("type: expected %s, got %s", exp.Type, alertType(alert)))
+	}
+	diff = append(diff, mapDiff("labels", exp.Labels, alert.Labels)...)
+	diff = append(diff, mapDiff("annotations", exp.Annotations, alert.Annotations)...)
+	return diff
+}
+
+// Differences of values in m from expected values.
+func mapDiff(name string, expected map[string]string, m map[string]string) []string {
+	var diff []string
+	for _, k := range sortedKeys(expected) {
+		v, ok := m[k]
+		switch {
+		case ok == false:
+			diff = append(diff, fmt.Sprintf("%s.%s: expected %q, missing", name, k, expected[k]))
+		case v != expected[k]:
+			diff = append(diff, fmt.Sprintf("%s.%s: expected %q, got %q", name, k, expected[k], v))
+		}
+	}
+	return diff
+}
+
+// Firing or clearing, based on event type of alert.
+func alertType(alert of.Alert) string {
+	switch of_snmp.EventType(alert.Annotations[of_snmp.EventTypeText]) {
+	case of_snmp.Firing:
+		return "firing"
+	case of_snmp.Clearing:
+		return "clearing"
+	}
+	return alert.Annotations[of_snmp.EventTypeText]
+}
+
+// Expected alert in readable form.
+func expectedString(exp of_snmp.ExpectedAlert) string {
+	eventType := exp.Type
+	if eventType == "" {
+		eventType = "firing or clearing"
+	}
+	return eventType + " alert " + labelsString(exp.Labels)
+}
+
+// Generated alert in readable form.
+func alertString(alert of.Alert) string {
+	return alertType(alert) + " alert " + labelsString(alert.Labels)
+}
+
+// Labels in order, like {a="1", b="2"}.
+func labelsString(labels map[string]string) string {
+	keys := sortedKeys(labels)
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = fmt.Sprintf("%s=%q", k, labels[k])
+	}
+	return "{" + strings.Join(pairs, ", ") + "}"
+}
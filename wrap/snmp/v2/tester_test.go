@@ -0,0 +1,117 @@
+package v2_test
+
+import (
+	"strings"
+	"testing"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+	of_snmp "github.com/cisco-cx/of/pkg/v2/snmp"
+	logger "github.com/cisco-cx/of/wrap/logrus/v2"
+	snmp "github.com/cisco-cx/of/wrap/snmp/v2"
+	yaml "github.com/cisco-cx/of/wrap/yaml/v2"
+	"github.com/stretchr/testify/require"
+)
+
+const testFile = `tests:
+- name: starCard fires
+  time: 2019-04-26T03:46:57Z
+  traps:
+  - source: 192.168.1.28
+    vars:
+    - oid: .1.3.6.1.6.3.1.1.4.1
+      value: .1.3.6.1.4.1.8164.2.44
+    - oid: .1.3.6.1.4.1.8164.1.2.1.1.1
+      value: "14"
+  expected_alerts:
+  - type: firing
+    labels:
+      alert_name: starCard
+      star_slot_num: "14"
+    annotations:
+      event_id: 9dcc77fc-dda5-4edf-a683-64f2589036d6
+      event_snmptrapd_timestamp: 2019-04-26T03:46:57Z
+- name: wrong expectations
+  traps:
+  - source: 192.168.1.28
+    vars:
+    - oid: .1.3.6.1.6.3.1.1.4.1
+      value: .1.3.6.1.4.1.8164.2.44
+    - oid: .1.3.6.1.4.1.8164.1.2.1.1.1
+      value: "14"
+  - source: 192.168.1.28
+    vars:
+    - oid: .1.3.6.1.6.3.1.1.4.1
+      value: .1.3.6.1.4.1.8164.2.55
+    - oid: .1.3.6.1.4.1.8164.1.2.1.1.1
+      value: "14"
+  expected_alerts:
+  - type: clearing
+    labels:
+      alert_name: starCard
+      star_slot_num: "15"
+- name: no alerts
+  traps:
+  - source: 192.168.1.28
+    vars:
+    - oid: .1.3.6.1.6.3.1.1.4.1
+      value: .1.3.6.1.4.1.9.9.9
+- name: invalid type
+  traps: []
+  expected_alerts:
+  - type: fired
+`
+
+// Enforce Tester Interface
+func TestTesterInterface(t *testing.T) {
+	var _ of_snmp.Tester = &snmp.Tester{}
+}
+
+// Test expected alerts are compared with generated alerts.
+func TestTesterRun(t *testing.T) {
+	cfg := yaml.Configs{}
+	require.NoError(t, cfg.Decode(strings.NewReader(YamlContent)))
+	configs := of_snmp.V2Config(cfg)
+
+	l := logger.New()
+	mr := mibRegistry(t)
+	lookup := snmp.Lookup{Configs: configs, MR: mr, Log: l}
+	require.NoError(t, lookup.Build())
+
+	cntr, cntrVec := snmp.InitCounters(t.Name(), l)
+	tester := snmp.Tester{
+		Runtime: &snmp.Runtime{Configs: &configs, MR: mr, Lookup: &lookup},
+		Log:     l,
+		Cntr:    cntr,
+		CntrVec: cntrVec,
+	}
+
+	tf := yaml.TestFile{}
+	require.NoError(t, tf.Decode(strings.NewReader(testFile)))
+	results := tester.Run("epc_test.yaml", of_snmp.TestFile(tf))
+	require.Len(t, results, 4)
+
+	require.Equal(t, "epc_test.yaml", results[0].File)
+	require.Equal(t, "starCard fires", results[0].Name)
+	require.Empty(t, results[0].Failures)
+
+	// Generated firing and clearing alerts, for .2.44 and .2.55, are unexpected.
+	require.Len(t, results[1].Failures, 8)
+	require.Equal(t, `missing clearing alert {alert_name="starCard", star_slot_num="15"}`+"\n"+
+		`  closest clearing alert {alert_fingerprint="628d5080136b565b", alert_name="starCard", alert_oid=".1.3.6.1.4.1.8164.2.13", alert_severity="error", source_address="192.168.1.28", source_hostname="", star_slot_num="14", subsystem="epc", vendor="cisco"}`+"\n"+
+		`    labels.star_slot_num: expected "15", got "14"`, results[1].Failures[0])
+	require.Equal(t, `unexpected firing alert {alert_fingerprint="ae0c17a90bd9bcfa", alert_name="starCard", alert_oid="", alert_severity="error", source_address="192.168.1.28", source_hostname="", star_slot_num="14", subsystem="epc", vendor="cisco"}`, results[1].Failures[1])
+
+	require.Empty(t, results[2].Failures)
+	require.Equal(t, []string{`invalid expected alert type "fired", expected firing or clearing`}, results[3].Failures)
+}
+
+// Test unknown keys in test files are rejected.
+func TestTestFileDecode(t *testing.T) {
+	tf := yaml.TestFile{}
+	err := tf.Decode(strings.NewReader("tests:\n- name: x\n  expected:\n  - labels: {}\n"))
+	require.Error(t, err)
+
+	tf = yaml.TestFile{}
+	require.NoError(t, tf.Decode(strings.NewReader("tests:\n- name: x\n  traps:\n  - vars:\n    - oid: .1.2\n      value: v\n")))
+	require.Equal(t, []of.TrapVar{of.TrapVar{Oid: ".1.2", Value: "v"}}, tf.Tests[0].Traps[0].Vars)
+}
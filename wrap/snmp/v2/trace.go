@@ -0,0 +1,95 @@
+package v2
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+	of_snmp "github.com/cisco-cx/of/pkg/v2/snmp"
+)
+
+// Implements of_snmp.Tracer, writing decisions as indented text.
+type TextTracer struct {
+	W io.Writer
+}
+
+// Write event source and trap OID.
+func (t *TextTracer) Event(event *of.PostableEvent) {
+	snmptrapd := event.Document.Receipts.Snmptrapd
+	for _, v := range snmptrapd.Vars {
+		if v.Oid == of_snmp.SNMPTrapOID {
+			fmt.Fprintf(t.W, "event from %q, trap %s, %d vars\n", snmptrapd.Source.Address, v.Value, len(snmptrapd.Vars))
+			return
+		}
+	}
+	fmt.Fprintf(t.W, "event from %q, %d vars\n", snmptrapd.Source.Address, len(snmptrapd.Vars))
+}
+
+// Write configs found by lookup.
+func (t *TextTracer) Lookup(cfgNames []string, err error) {
+	switch {
+	case err != nil:
+		fmt.Fprintf(t.W, "  lookup failed: %s\n", err.Error())
+	case len(cfgNames) == 0:
+		fmt.Fprintf(t.W, "  lookup: no configs, unknown trap\n")
+	default:
+		fmt.Fprintf(t.W, "  lookup: %s\n", strings.Join(cfgNames, ", "))
+	}
+}
+
+// Write config being checked.
+func (t *TextTracer) Config(cfgName string, applicable bool) {
+	if applicable == false {
+		fmt.Fprintf(t.W, "  config %s: not applicable for device, skipped\n", cfgName)
+		return
+	}
+	fmt.Fprintf(t.W, "  config %s\n", cfgName)
+}
+
+// Write select decision.
+func (t *TextTracer) Select(alertName string, eventType of_snmp.EventType, sel of_snmp.Select, value string, matched bool, err error) {
+	selectsType := "firing"
+	if eventType == of_snmp.Clearing {
+		selectsType = "clearing"
+	}
+	prefix := fmt.Sprintf("    alert %s %s: %s as %s", alertName, selectsType, sel.Oid, sel.As)
+	switch {
+	case err != nil:
+		fmt.Fprintf(t.W, "%s failed: %s\n", prefix, err.Error())
+	case matched:
+		fmt.Fprintf(t.W, "%s = %q, matched\n", prefix, value)
+	default:
+		fmt.Fprintf(t.W, "%s = %q, not in %q\n", prefix, value, sel.Values)
+	}
+}
+
+// Write mod applied to labels or annotations.
+func (t *TextTracer) Mod(target string, mod of_snmp.Mod, value string, applied bool, err error) {
+	if mod.Type == of_snmp.Set {
+		fmt.Fprintf(t.W, "      %s set %s = %q\n", target, mod.Key, value)
+		return
+	}
+
+	prefix := fmt.Sprintf("      %s copy %s as %s to %s", target, mod.Oid, mod.As, mod.ToKey)
+	switch {
+	case err != nil && mod.OnError == of_snmp.Drop:
+		fmt.Fprintf(t.W, "%s failed, alert dropped: %s\n", prefix, err.Error())
+	case err != nil:
+		fmt.Fprintf(t.W, "%s failed: %s\n", prefix, err.Error())
+	case applied:
+		fmt.Fprintf(t.W, "%s = %q\n", prefix, value)
+	default:
+		fmt.Fprintf(t.W, "%s: %q not in map, skipped\n", prefix, value)
+	}
+}
+
+// Write generated alert, with its labels.
+func (t *TextTracer) Alert(alert of.Alert) {
+	fmt.Fprintf(t.W, "    => %s alert %s\n", alert.Annotations[string(of_snmp.EventTypeText)], labelsString(alert.Labels))
+}
+
+// Write alert held back or dropped, with the reason.
+func (t *TextTracer) Held(alert of.Alert, reason string) {
+	fmt.Fprintf(t.W, "    => %s alert %s not sent, %s\n", alert.Annotations[string(of_snmp.EventTypeText)], labelsString(alert.Labels), reason)
+}
@@ -0,0 +1,339 @@
+package v2
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+	of_snmp "github.com/cisco-cx/of/pkg/v2/snmp"
+	jsonschema "github.com/cisco-cx/of/wrap/jsonschema/v2"
+	yaml "github.com/cisco-cx/of/wrap/yaml/v2"
+	prommodel "github.com/prometheus/common/model"
+)
+
+// Line number in YAML decode errors.
+var yamlErrLine = regexp.MustCompile(`line (\d+)`)
+
+// Implements of_snmp.Validator, applying the JSON schema and semantic checks.
+type Validator struct {
+	MR     of.MIBRegistry // OIDs are checked against MIBs, if set.
+	schema *jsonschema.Schema
+}
+
+// Represents diagnostics of a single file.
+type fileCheck struct {
+	name  string
+	lines yaml.Lines
+	diags []of_snmp.Diagnostic
+}
+
+// Identifies copy mods writing to the same key.
+type copyTarget struct {
+	oid   string
+	as    of_snmp.As
+	toKey string
+}
+
+// Represents a value of a copy mod map, seen earlier in a config.
+type seenValue struct {
+	path  string
+	value string
+}
+
+// Init Validator with the SNMP config schema.
+func NewValidator(mr of.MIBRegistry) (*Validator, error) {
+	schema := &jsonschema.Schema{}
+	if err := schema.Load([]byte(jsonschema.SNMPAlertsSchema)); err != nil {
+		return nil, err
+	}
+	return &Validator{MR: mr, schema: schema}, nil
+}
+
+// Validate yaml files in dir, the same files that are loaded as configs.
+func (v *Validator) ValidateDir(dir string) ([]of_snmp.Diagnostic, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	var diags []of_snmp.Diagnostic
+	defined := make(map[string]string) // File defining each config.
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		diags = append(diags, v.ValidateFile(file, data)...)
+
+		configs := yaml.Configs{}
+		if configs.Decode(bytes.NewReader(data)) != nil {
+			continue
+		}
+		lines := yaml.NewLines(data)
+		for _, cfgName := range sortedConfigs(of_snmp.V2Config(configs)) {
+			if prev, ok := defined[cfgName]; ok == true {
+				diags = append(diags, of_snmp.Diagnostic{
+					File:     file,
+					Line:     lines.Line("/" + cfgName),
+					Path:     "/" + cfgName,
+					Severity: of_snmp.SeverityError,
+					Message:  fmt.Sprintf("config %q is already defined in %s", cfgName, prev),
+				})
+				continue
+			}
+			defined[cfgName] = file
+		}
+	}
+	return diags, nil
+}
+
+// Validate content of a config file.
+func (v *Validator) ValidateFile(name string, data []byte) []of_snmp.Diagnostic {
+	fc := &fileCheck{name: name, lines: yaml.NewLines(data)}
+
+	configs := yaml.Configs{}
+	if err := configs.Decode(bytes.NewReader(data)); err != nil {
+		line := 0
+		if m := yamlErrLine.FindStringSubmatch(err.Error()); m != nil {
+			line, _ = strconv.Atoi(m[1])
+		}
+		fc.diags = append(fc.diags, of_snmp.Diagnostic{
+			File:     name,
+			Line:     line,
+			Severity: of_snmp.SeverityError,
+			Message:  err.Error(),
+		})
+		return fc.diags
+	}
+
+	schemaErrs, err := v.schema.YAMLErrors(data)
+	if err != nil {
+		fc.add(of_snmp.SeverityError, "", "%s", err.Error())
+	}
+	for _, e := range schemaErrs {
+		fc.add(of_snmp.SeverityError, strings.TrimSuffix(e.Path, "/"), "%s", e.Message)
+	}
+
+	v2Config := of_snmp.V2Config(configs)
+	for _, cfgName := range sortedConfigs(v2Config) {
+		v.checkConfig(fc, cfgName, v2Config[cfgName])
+	}
+
+	sort.SliceStable(fc.diags, func(i, j int) bool {
+		return fc.diags[i].Line < fc.diags[j].Line
+	})
+	return fc.diags
+}
+
+// Semantic checks of a config.
+func (v *Validator) checkConfig(fc *fileCheck, cfgName string, cfg of_snmp.Config) {
+	base := "/" + cfgName
+	maps := make(map[copyTarget]map[string]seenValue)
+
+	v.checkMods(fc, base+"/defaults/label_mods", cfg.Defaults.LabelMods, true, maps)
+	v.checkMods(fc, base+"/defaults/annotation_mods", cfg.Defaults.AnnotationMods, false, maps)
+
+	for i, alertCfg := range cfg.Alerts {
+		path := fmt.Sprintf("%s/alerts/%d", base, i)
+		v.checkMods(fc, path+"/label_mods", alertCfg.LabelMods, true, maps)
+		v.checkMods(fc, path+"/annotation_mods", alertCfg.AnnotationMods, false, maps)
+
+		for _, selectsType := range []string{"firing", "clearing"} {
+			selectsPath := path + "/" + selectsType
+			selects := alertCfg.Firing
+			if selectsType == "clearing" {
+				selects = alertCfg.Clearing
+			}
+			if len(selects["annotation_mods"]) != 0 {
+				fc.add(of_snmp.SeverityWarning, selectsPath+"/annotation_mods", "annotation_mods under %s are ignored, set them under each select", selectsType)
+			}
+			for j, sel := range selects["select"] {
+				selPath := fmt.Sprintf("%s/select/%d", selectsPath, j)
+				v.checkOID(fc, selPath+"/oid", sel.Oid)
+				if sel.Oid == of_snmp.SNMPTrapOID && sel.As == of_snmp.Value {
+					for k, value := range sel.Values {
+						v.checkOID(fc, fmt.Sprintf("%s/values/%d", selPath, k), value)
+					}
+				}
+				v.checkMods(fc, selPath+"/annotation_mods", sel.AnnotationMods, false, maps)
+			}
+		}
+
+		// Alerts fire for each matching alert config, not only the first.
+		for j := 0; j < i; j++ {
+			if subsumes(cfg.Alerts[j].Firing["select"], alertCfg.Firing["select"]) {
+				fc.add(of_snmp.SeverityWarning, path+"/firing", "firing selects are subsumed by earlier alert %q (alerts/%d), which also fires for every matching trap", cfg.Alerts[j].Name, j)
+				break
+			}
+		}
+
+		if alertCfg.Heartbeat != nil {
+			v.checkOID(fc, path+"/heartbeat/oid", alertCfg.Heartbeat.Oid)
+		}
+		if alertCfg.Aggregate != nil {
+			checkLabelNames(fc, path+"/aggregate/group_by", alertCfg.Aggregate.GroupBy)
+		}
+		for j, s := range alertCfg.SuppressedBy {
+			checkLabelNames(fc, fmt.Sprintf("%s/suppressed_by/%d/equal", path, j), s.Equal)
+		}
+		for j, action := range alertCfg.OnFire {
+			checkLabelNames(fc, fmt.Sprintf("%s/on_fire/%d/equal", path, j), action.Equal)
+		}
+	}
+}
+
+// Check OIDs and keys of mods, and maps conflicting with earlier copy mods.
+func (v *Validator) checkMods(fc *fileCheck, path string, mods []of_snmp.Mod, labels bool, maps map[copyTarget]map[string]seenValue) {
+	for i, mod := range mods {
+		modPath := fmt.Sprintf("%s/%d", path, i)
+		switch mod.Type {
+		case of_snmp.Set:
+			if labels {
+				checkLabelName(fc, modPath+"/key", mod.Key)
+			}
+		case of_snmp.Copy:
+			if labels {
+				checkLabelName(fc, modPath+"/to_key", mod.ToKey)
+			}
+			v.checkOID(fc, modPath+"/oid", mod.Oid)
+			if len(mod.Map) == 0 {
+				continue
+			}
+
+			target := copyTarget{oid: mod.Oid, as: mod.As, toKey: mod.ToKey}
+			if _, ok := maps[target]; ok == false {
+				maps[target] = make(map[string]seenValue)
+			}
+			for _, k := range sortedKeys(mod.Map) {
+				valuePath := modPath + "/map/" + k
+				prev, ok := maps[target][k]
+				if ok == false {
+					maps[target][k] = seenValue{path: valuePath, value: mod.Map[k]}
+					continue
+				}
+				if prev.value != mod.Map[k] {
+					fc.add(of_snmp.SeverityWarning, valuePath, "map of %s to %s conflicts with line %d, %q is mapped to %q and %q", mod.Oid, mod.ToKey, fc.lines.Line(prev.path), k, prev.value, mod.Map[k])
+				}
+			}
+		}
+	}
+}
+
+// Check OID is defined in MIBs, or is an instance of an OID defined in MIBs.
+func (v *Validator) checkOID(fc *fileCheck, path string, oid string) {
+	if v.MR == nil || oid == "" {
+		return
+	}
+	num := strings.TrimPrefix(oid, ".")
+	if v.MR.MIB(num) != nil {
+		return
+	}
+	if idx := strings.LastIndex(num, "."); idx > 0 && v.MR.MIB(num[:idx]) != nil {
+		return
+	}
+	fc.add(of_snmp.SeverityWarning, path, "OID %s is not defined in MIBs", oid)
+}
+
+// Check each is a valid Prometheus label name.
+func checkLabelNames(fc *fileCheck, path string, names []string) {
+	for i, name := range names {
+		checkLabelName(fc, fmt.Sprintf("%s/%d", path, i), name)
+	}
+}
+
+// Check name is a valid Prometheus label name.
+func checkLabelName(fc *fileCheck, path string, name string) {
+	if name == "" {
+		return
+	}
+	if prommodel.LabelName(name).IsValid() == false || strings.HasPrefix(name, "__") {
+		fc.add(of_snmp.SeverityError, path, "%q is not a valid label name", name)
+	}
+}
+
+// Check if every trap matching selects b also matches selects a.
+// Each select in a must be at least as broad as a select in b, on the same OID.
+func subsumes(a []of_snmp.Select, b []of_snmp.Select) bool {
+	if len(a) == 0 || len(b) == 0 {
+		return false
+	}
+	for _, sa := range a {
+		covered := false
+		for _, sb := range b {
+			if sa.Oid == sb.Oid && sa.As == sb.As && containsAll(sa.Values, sb.Values) {
+				covered = true
+				break
+			}
+		}
+		if covered == false {
+			return false
+		}
+	}
+	return true
+}
+
+// Check if all values are in set.
+func containsAll(set []string, values []string) bool {
+	for _, v := range values {
+		found := false
+		for _, s := range set {
+			if s == v {
+				found = true
+				break
+			}
+		}
+		if found == false {
+			return false
+		}
+	}
+	return true
+}
+
+// Add diagnostic for value at path.
+func (fc *fileCheck) add(severity of_snmp.Severity, path string, format string, args ...interface{}) {
+	fc.diags = append(fc.diags, of_snmp.Diagnostic{
+		File:     fc.name,
+		Line:     fc.lines.Line(path),
+		Path:     path,
+		Severity: severity,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// Config names in order.
+func sortedConfigs(configs of_snmp.V2Config) []string {
+	names := make([]string, 0, len(configs))
+	for name := range configs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Map keys in order.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Count diagnostics with error severity.
+func CountErrors(diags []of_snmp.Diagnostic) int {
+	count := 0
+	for _, d := range diags {
+		if d.Severity == of_snmp.SeverityError {
+			count++
+		}
+	}
+	return count
+}
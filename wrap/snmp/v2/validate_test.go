@@ -0,0 +1,143 @@
+package v2_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+	of_snmp "github.com/cisco-cx/of/pkg/v2/snmp"
+	mib_registry "github.com/cisco-cx/of/wrap/mib/v2"
+	snmp "github.com/cisco-cx/of/wrap/snmp/v2"
+	"github.com/stretchr/testify/require"
+)
+
+const invalidConfig = `epc:
+  defaults:
+    label_mods:
+    - type: set
+      key: bad-label
+    - type: copy
+      oid: .1.3.6.1.4.1.8164.1.2.1.1.1
+      as: valu
+      to_key: slot
+  alerts:
+  - name: starCardDown
+    annotation_mods:
+    - type: copy
+      oid: .1.3.6.1.4.1.8164.1.2.1.1.1
+      as: value
+    firing:
+      select:
+      - type: equals
+        oid: .1.3.6.1.6.3.1.1.4.1.0
+        as: value
+        values:
+        - .1.3.6.1.4.1.8164.2.5
+        - .1.3.6.1.4.1.8164.2.6
+      - type: equals
+        oid: .1.3.6.1.4.1.8164.1.2.1.1.1
+        as: value
+        values: []
+  - name: starCardRemoved
+    label_mods:
+    - type: copy
+      oid: .1.3.6.1.4.1.8164.1.2.1.1.2
+      as: value
+      to_key: card_type
+      map:
+        "1": lc
+        "2": dc
+    firing:
+      select:
+      - type: equals
+        oid: .1.3.6.1.6.3.1.1.4.1.0
+        as: value
+        values:
+        - .1.3.6.1.4.1.8164.2.6
+        - .1.3.6.1.4.1.8164.2.99
+  - name: starCardActive
+    label_mods:
+    - type: copy
+      oid: .1.3.6.1.4.1.8164.1.2.1.1.2
+      as: value
+      to_key: card_type
+      map:
+        "2": mio
+    firing:
+      select:
+      - type: equals
+        oid: .1.3.6.1.6.3.1.1.4.1.0
+        as: value
+        values:
+        - .1.3.6.1.4.1.8164.2.6
+    suppressed_by:
+    - alert: starChassisDown
+      equal:
+      - __cluster
+`
+
+// Test schema and semantic checks, with file:line diagnostics.
+func TestValidateFile(t *testing.T) {
+	mr := mib_registry.New()
+	require.NoError(t, mr.Load(map[string]of.MIB{
+		"1.3.6.1.6.3.1.1.4.1":        of.MIB{Name: "snmpTrapOID"},
+		"1.3.6.1.4.1.8164.2.5":       of.MIB{Name: "starCardTempOverheat"},
+		"1.3.6.1.4.1.8164.2.6":       of.MIB{Name: "starCardTempOK"},
+		"1.3.6.1.4.1.8164.1.2.1.1.1": of.MIB{Name: "starSlotNum"},
+		"1.3.6.1.4.1.8164.1.2.1.1.2": of.MIB{Name: "starCardType"},
+	}))
+	v, err := snmp.NewValidator(mr)
+	require.NoError(t, err)
+
+	diags := v.ValidateFile("epc.yaml", []byte(invalidConfig))
+	got := make([]string, len(diags))
+	for i, d := range diags {
+		got[i] = d.String()
+	}
+	require.Equal(t, []string{
+		`epc.yaml:5: error: "bad-label" is not a valid label name`,
+		`epc.yaml:8: error: "valu": should be one of ["value", "value-str", "value-str-short", "oid.value", "oid.value-str", "oid.value-str-short"]`,
+		`epc.yaml:13: error: "to_key" value is required`,
+		`epc.yaml:27: error: []: array length 0 below 1 minimum items`,
+		`epc.yaml:44: warning: OID .1.3.6.1.4.1.8164.2.99 is not defined in MIBs`,
+		`epc.yaml:52: warning: map of .1.3.6.1.4.1.8164.1.2.1.1.2 to card_type conflicts with line 36, "2" is mapped to "dc" and "mio"`,
+		`epc.yaml:53: warning: firing selects are subsumed by earlier alert "starCardRemoved" (alerts/1), which also fires for every matching trap`,
+		`epc.yaml:63: error: "__cluster" is not a valid label name`,
+	}, got)
+	require.Equal(t, 5, snmp.CountErrors(diags))
+}
+
+// Test YAML errors are reported with their line.
+func TestValidateFileDecodeError(t *testing.T) {
+	v, err := snmp.NewValidator(nil)
+	require.NoError(t, err)
+
+	diags := v.ValidateFile("epc.yaml", []byte("epc:\n  alerts:\n  - name: [x\n"))
+	require.Len(t, diags, 1)
+	require.Equal(t, of_snmp.SeverityError, diags[0].Severity)
+	require.Equal(t, 3, diags[0].Line)
+}
+
+// Test configs defined in multiple files.
+func TestValidateDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snmp-configs")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "a.yaml"), []byte(YamlConfigs), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "b.yaml"), []byte("# Copy\nconfig1:\n  alerts: []\n"), 0644))
+
+	v, err := snmp.NewValidator(nil)
+	require.NoError(t, err)
+	diags, err := v.ValidateDir(dir)
+	require.NoError(t, err)
+	require.Len(t, diags, 1)
+	require.Equal(t, filepath.Join(dir, "b.yaml")+`:2: error: config "config1" is already defined in `+filepath.Join(dir, "a.yaml"), diags[0].String())
+
+	// Example configs are valid.
+	diags, err = v.ValidateDir("../../../example/snmp/v2")
+	require.NoError(t, err)
+	require.Empty(t, diags)
+}
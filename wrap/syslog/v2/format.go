@@ -0,0 +1,212 @@
+package v2
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+)
+
+// Syslog severities (RFC 5424 6.2.1).
+const (
+	Emergency     = 0
+	Alert         = 1
+	Critical      = 2
+	Error         = 3
+	Warning       = 4
+	Notice        = 5
+	Informational = 6
+	Debug         = 7
+)
+
+// Syslog severities by alert_severity, in lower case.
+var severities = map[string]int{
+	"emergency":     Emergency,
+	"emerg":         Emergency,
+	"alert":         Alert,
+	"critical":      Critical,
+	"crit":          Critical,
+	"major":         Error,
+	"error":         Error,
+	"err":           Error,
+	"minor":         Warning,
+	"warning":       Warning,
+	"warn":          Warning,
+	"notice":        Notice,
+	"informational": Informational,
+	"info":          Informational,
+	"debug":         Debug,
+}
+
+// Syslog facilities by name (RFC 5424 6.2.1).
+var facilities = map[string]int{
+	"kern": 0, "user": 1, "mail": 2, "daemon": 3, "auth": 4, "syslog": 5, "lpr": 6, "news": 7,
+	"uucp": 8, "cron": 9, "authpriv": 10, "ftp": 11, "ntp": 12, "security": 13, "console": 14, "solaris-cron": 15,
+	"local0": 16, "local1": 17, "local2": 18, "local3": 19, "local4": 20, "local5": 21, "local6": 22, "local7": 23,
+}
+
+// Private enterprise number of structured data IDs, if not configured.
+// Reserved for documentation (RFC 5612), like the registration of OF-ALERT-MIB.
+const defaultEnterprise = "32473"
+
+// Matches private enterprise numbers of SD-IDs, with optional sub-identifiers (RFC 5424 7.2.2).
+var enterpriseRE = regexp.MustCompile(`^[0-9]+(\.[0-9]+)*$`)
+
+// Max. length of SD-PARAM names (RFC 5424 6.3.3).
+const maxParamName = 32
+
+// Facility code of name, case insensitive.
+func Facility(name string) (int, error) {
+	f, ok := facilities[strings.ToLower(name)]
+	if ok == false {
+		return 0, fmt.Errorf("%s %q", of.ErrUnknownSyslogFacility, name)
+	}
+	return f, nil
+}
+
+// Syslog severity of alert, by its alert_severity label. Alerts with unknown severity are
+// warnings, resolved alerts are informational.
+func Severity(alert of.Alert, now time.Time) int {
+	if resolved(alert, now) == true {
+		return Informational
+	}
+	if s, ok := severities[strings.ToLower(alert.Labels["alert_severity"])]; ok == true {
+		return s
+	}
+	return Warning
+}
+
+// Whether alert ended at or before now.
+func resolved(alert of.Alert, now time.Time) bool {
+	return alert.EndsAt.IsZero() == false && alert.EndsAt.After(now) == false
+}
+
+// Represents the header fields of messages.
+type header struct {
+	facility   int
+	hostname   string
+	appName    string
+	procID     int
+	enterprise string // Of SD-IDs.
+}
+
+// Text of alert, its name, state and description or summary.
+func text(alert of.Alert, now time.Time) string {
+	state := "firing"
+	if resolved(alert, now) == true {
+		state = "resolved"
+	}
+	msg := alert.Labels["alertname"] + " " + state
+	description := alert.Annotations["description"]
+	if description == "" {
+		description = alert.Annotations["summary"]
+	}
+	if description != "" {
+		msg += ": " + description
+	}
+	return msg
+}
+
+// Format alert as an RFC 5424 message, with labels and annotations as structured data.
+func formatRFC5424(h *header, alert of.Alert, now time.Time) []byte {
+	msgID := "FIRING"
+	if resolved(alert, now) == true {
+		msgID = "RESOLVED"
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "<%d>1 %s %s %s %d %s ",
+		h.facility*8+Severity(alert, now),
+		now.UTC().Format("2006-01-02T15:04:05.000000Z07:00"),
+		headerField(h.hostname, 255),
+		headerField(h.appName, 48),
+		h.procID,
+		msgID,
+	)
+	writeSD(&b, "labels@"+h.enterprise, alert.Labels)
+	writeSD(&b, "annotations@"+h.enterprise, alert.Annotations)
+	if len(alert.Labels) == 0 && len(alert.Annotations) == 0 {
+		b.WriteString("-")
+	}
+	b.WriteString(" ")
+	b.WriteString(text(alert, now))
+	return []byte(b.String())
+}
+
+// Format alert as an RFC 3164 message, with labels appended to the text.
+func formatRFC3164(h *header, alert of.Alert, now time.Time) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "<%d>%s %s %s[%d]: %s",
+		h.facility*8+Severity(alert, now),
+		now.Format(time.Stamp),
+		headerField(h.hostname, 255),
+		headerField(h.appName, 32),
+		h.procID,
+		text(alert, now),
+	)
+	for _, k := range sortedKeys(alert.Labels) {
+		fmt.Fprintf(&b, " %s=%s", k, strconv.Quote(alert.Labels[k]))
+	}
+	return []byte(b.String())
+}
+
+// Write an SD-ELEMENT of params to b, nothing if there are none.
+func writeSD(b *strings.Builder, id string, params map[string]string) {
+	if len(params) == 0 {
+		return
+	}
+	b.WriteString("[" + id)
+	for _, k := range sortedKeys(params) {
+		b.WriteString(" " + paramName(k) + `="`)
+		b.WriteString(strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(params[k]))
+		b.WriteString(`"`)
+	}
+	b.WriteString("]")
+}
+
+// name as an SD-PARAM name, with invalid characters replaced by '_' and truncated to 32 characters.
+func paramName(name string) string {
+	n := []byte(name)
+	for i, c := range n {
+		if c <= ' ' || c >= 127 || c == '=' || c == ']' || c == '"' {
+			n[i] = '_'
+		}
+	}
+	if len(n) > maxParamName {
+		n = n[:maxParamName]
+	}
+	if len(n) == 0 {
+		return "_"
+	}
+	return string(n)
+}
+
+// v as a header field of at most max printable ASCII characters, or the nil value '-'.
+func headerField(v string, max int) string {
+	f := []byte(v)
+	for i, c := range f {
+		if c <= ' ' || c >= 127 {
+			f[i] = '_'
+		}
+	}
+	if len(f) > max {
+		f = f[:max]
+	}
+	if len(f) == 0 {
+		return "-"
+	}
+	return string(f)
+}
+
+// Keys of m, sorted.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,51 @@
+package v2_test
+
+import (
+	"testing"
+	"time"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+	syslog "github.com/cisco-cx/of/wrap/syslog/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// Test alert_severity maps to syslog severities.
+func TestSeverity(t *testing.T) {
+	now := time.Date(2020, 5, 1, 22, 22, 53, 0, time.UTC)
+	for severity, expected := range map[string]int{
+		"critical":      syslog.Critical,
+		"Critical":      syslog.Critical,
+		"major":         syslog.Error,
+		"error":         syslog.Error,
+		"minor":         syslog.Warning,
+		"warning":       syslog.Warning,
+		"informational": syslog.Informational,
+		"Info":          syslog.Informational,
+		"debug":         syslog.Debug,
+		"":              syslog.Warning,
+		"unknown":       syslog.Warning,
+	} {
+		alert := of.Alert{Labels: map[string]string{"alert_severity": severity}}
+		require.Equal(t, expected, syslog.Severity(alert, now), severity)
+	}
+
+	// Resolved alerts are informational.
+	alert := of.Alert{Labels: map[string]string{"alert_severity": "critical"}, EndsAt: now}
+	require.Equal(t, syslog.Informational, syslog.Severity(alert, now))
+	alert.EndsAt = now.Add(time.Minute)
+	require.Equal(t, syslog.Critical, syslog.Severity(alert, now))
+}
+
+// Test facilities are looked up by name.
+func TestFacility(t *testing.T) {
+	f, err := syslog.Facility("local0")
+	require.NoError(t, err)
+	require.Equal(t, 16, f)
+
+	f, err = syslog.Facility("DAEMON")
+	require.NoError(t, err)
+	require.Equal(t, 3, f)
+
+	_, err = syslog.Facility("local8")
+	require.Contains(t, err.Error(), of.ErrUnknownSyslogFacility.Error())
+}
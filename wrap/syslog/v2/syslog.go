@@ -0,0 +1,230 @@
+package v2
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+	logger "github.com/cisco-cx/of/wrap/logrus/v2"
+)
+
+const (
+	defaultPort     = "514"
+	defaultTLSPort  = "6514"
+	defaultFacility = "local0"
+	defaultAppName  = "of"
+)
+
+// Implements of.Notifier, sending a syslog message for every alert.
+// TCP and TLS connections are kept open, and reopened once a write fails.
+type Writer struct {
+	of.SyslogConfig
+	Clock of.Clock
+	Log   *logger.Logger
+
+	header header
+	format func(*header, of.Alert, time.Time) []byte
+	tls    *tls.Config
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// Init Writer with cfg. Collectors are connected to on the first alert.
+func NewWriter(cfg *of.SyslogConfig, c of.Clock, l *logger.Logger) (*Writer, error) {
+	w := &Writer{SyslogConfig: *cfg, Clock: c, Log: l}
+	if w.Address == "" {
+		return nil, of.ErrNoSyslogAddress
+	}
+	if w.Transport == "" {
+		w.Transport = of.SyslogUDP
+	}
+	if w.Format == "" {
+		w.Format = of.SyslogRFC5424
+	}
+	if w.Facility == "" {
+		w.Facility = defaultFacility
+	}
+	if w.AppName == "" {
+		w.AppName = defaultAppName
+	}
+	if w.Enterprise == "" {
+		w.Enterprise = defaultEnterprise
+	}
+	if enterpriseRE.MatchString(w.Enterprise) == false {
+		return nil, fmt.Errorf("%s %q", of.ErrInvalidSyslogEnterprise, w.Enterprise)
+	}
+	if w.Hostname == "" {
+		w.Hostname, _ = os.Hostname()
+	}
+
+	port := defaultPort
+	switch w.Transport {
+	case of.SyslogUDP, of.SyslogTCP:
+	case of.SyslogTLS:
+		port = defaultTLSPort
+		t, err := clientTLSConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+		w.tls = t
+	default:
+		return nil, fmt.Errorf("%s %q, expected udp, tcp or tls", of.ErrUnknownSyslogTransport, w.Transport)
+	}
+	if _, _, err := net.SplitHostPort(w.Address); err != nil {
+		w.Address = net.JoinHostPort(w.Address, port)
+	}
+
+	switch w.Format {
+	case of.SyslogRFC5424:
+		w.format = formatRFC5424
+	case of.SyslogRFC3164:
+		w.format = formatRFC3164
+	default:
+		return nil, fmt.Errorf("%s %q, expected rfc5424 or rfc3164", of.ErrUnknownSyslogFormat, w.Format)
+	}
+
+	facility, err := Facility(w.Facility)
+	if err != nil {
+		return nil, err
+	}
+	w.header = header{facility: facility, hostname: w.Hostname, appName: w.AppName, procID: os.Getpid(), enterprise: w.Enterprise}
+	return w, nil
+}
+
+// TLS settings with client certificate and CA of cfg.
+func clientTLSConfig(cfg *of.SyslogConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{}
+	if cfg.CertFile != "" || cfg.KeyFile != "" {
+		if cfg.CertFile == "" || cfg.KeyFile == "" {
+			return nil, of.ErrKeyWithoutCert
+		}
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	if cfg.CAFile != "" {
+		b, err := ioutil.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if pool.AppendCertsFromPEM(b) == false {
+			return nil, of.ErrInvalidCA
+		}
+		tlsConfig.RootCAs = pool
+	}
+	return tlsConfig, nil
+}
+
+// Send a message for every alert, in order.
+func (w *Writer) Notify(alerts *[]of.Alert) error {
+	now := w.Clock.Now()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.conn != nil && w.alive() == false {
+		w.disconnect()
+	}
+	for _, alert := range *alerts {
+		if err := w.write(w.frame(w.format(&w.header, alert, now))); err != nil {
+			return fmt.Errorf("Failed to send alert %s to syslog %s: %s", alert.Labels["alertname"], w.Address, err.Error())
+		}
+	}
+	return nil
+}
+
+// msg framed for the transport, prefixed by its length on streams (RFC 6587 3.4.1).
+func (w *Writer) frame(msg []byte) []byte {
+	if w.Transport == of.SyslogUDP {
+		return msg
+	}
+	return append([]byte(strconv.Itoa(len(msg))+" "), msg...)
+}
+
+// Write b, reconnecting once if the write fails.
+func (w *Writer) write(b []byte) error {
+	for attempt := 0; ; attempt++ {
+		if w.conn == nil {
+			if err := w.connect(); err != nil {
+				return err
+			}
+		}
+		if w.Timeout > 0 {
+			w.conn.SetWriteDeadline(time.Now().Add(w.Timeout))
+		}
+		_, err := w.conn.Write(b)
+		if err == nil {
+			return nil
+		}
+		w.disconnect()
+		if attempt > 0 {
+			return err
+		}
+		w.Log.WithError(err).Warningf("Syslog connection to %s failed, reconnecting.", w.Address)
+	}
+}
+
+// Whether the collector kept the stream connection open, checked once per batch as writes
+// to closed connections often succeed. Collectors do not send anything, so a read only
+// returns once the connection is closed.
+func (w *Writer) alive() bool {
+	if w.Transport == of.SyslogUDP {
+		return true
+	}
+	w.conn.SetReadDeadline(time.Now().Add(time.Millisecond))
+	_, err := w.conn.Read(make([]byte, 1))
+	if err == io.EOF {
+		return false
+	}
+	if ne, ok := err.(net.Error); ok == true && ne.Timeout() == true {
+		return true
+	}
+	return err == nil
+}
+
+// Connect to the collector.
+func (w *Writer) connect() error {
+	d := &net.Dialer{Timeout: w.Timeout}
+	var err error
+	switch w.Transport {
+	case of.SyslogTLS:
+		w.conn, err = tls.DialWithDialer(d, "tcp", w.Address, w.tls)
+	case of.SyslogTCP:
+		w.conn, err = d.Dial("tcp", w.Address)
+	default:
+		w.conn, err = d.Dial("udp", w.Address)
+	}
+	if err != nil {
+		w.conn = nil
+		return err
+	}
+	w.Log.Debugf("Connected to syslog %s over %s.", w.Address, w.Transport)
+	return nil
+}
+
+// Close the connection, if any.
+func (w *Writer) disconnect() error {
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
+
+// Close the connection to the collector.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.disconnect()
+}
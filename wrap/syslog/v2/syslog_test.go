@@ -0,0 +1,241 @@
+package v2_test
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+	logger "github.com/cisco-cx/of/wrap/logrus/v2"
+	syslog "github.com/cisco-cx/of/wrap/syslog/v2"
+	clock "github.com/cisco-cx/of/wrap/time/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// Enforce Notifier Interface
+func TestWriterInterface(t *testing.T) {
+	var _ of.Notifier = &syslog.Writer{}
+}
+
+var syslogNow = time.Date(2020, 5, 1, 22, 22, 53, 0, time.UTC)
+
+// Alert named name, with severity and an annotation needing escapes.
+func syslogAlert(name string) of.Alert {
+	return of.Alert{
+		Labels:      map[string]string{"alertname": name, "alert_severity": "critical", "source_address": "10.0.0.1"},
+		Annotations: map[string]string{"summary": `Fan "1" [tray] down`},
+	}
+}
+
+// Writer with cfg, on a fixed clock.
+func newTestWriter(t *testing.T, cfg of.SyslogConfig) *syslog.Writer {
+	cfg.Hostname = "of-host"
+	w, err := syslog.NewWriter(&cfg, &clock.FixedClock{T: syslogNow}, logger.New())
+	require.NoError(t, err)
+	return w
+}
+
+// Read a datagram from conn.
+func readDatagram(t *testing.T, conn net.PacketConn) string {
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+	b := make([]byte, 4096)
+	n, _, err := conn.ReadFrom(b)
+	require.NoError(t, err)
+	return string(b[:n])
+}
+
+// Read an octet-counted message from r.
+func readFrame(t *testing.T, r *bufio.Reader) string {
+	l, err := r.ReadString(' ')
+	require.NoError(t, err)
+	n, err := strconv.Atoi(strings.TrimSpace(l))
+	require.NoError(t, err)
+	b := make([]byte, n)
+	_, err = io.ReadFull(r, b)
+	require.NoError(t, err)
+	return string(b)
+}
+
+// Test alerts are sent as RFC 5424 messages with structured data over UDP.
+func TestWriterRFC5424(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	w := newTestWriter(t, of.SyslogConfig{Address: conn.LocalAddr().String()})
+	defer w.Close()
+	alert := syslogAlert("fanDown")
+	resolved := alert
+	resolved.EndsAt = syslogNow
+	alerts := []of.Alert{alert, resolved, {}}
+	require.NoError(t, w.Notify(&alerts))
+
+	pid := os.Getpid()
+	require.Equal(t, fmt.Sprintf(`<130>1 2020-05-01T22:22:53.000000Z of-host of %d FIRING `+
+		`[labels@32473 alert_severity="critical" alertname="fanDown" source_address="10.0.0.1"]`+
+		`[annotations@32473 summary="Fan \"1\" [tray\] down"] fanDown firing: Fan "1" [tray] down`, pid), readDatagram(t, conn))
+	require.Contains(t, readDatagram(t, conn), fmt.Sprintf(`<134>1 2020-05-01T22:22:53.000000Z of-host of %d RESOLVED [labels@32473`, pid))
+	require.Equal(t, fmt.Sprintf(`<132>1 2020-05-01T22:22:53.000000Z of-host of %d FIRING -  firing`, pid), readDatagram(t, conn))
+}
+
+// Test structured data IDs have the configured enterprise number.
+func TestWriterEnterprise(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	w := newTestWriter(t, of.SyslogConfig{Address: conn.LocalAddr().String(), Enterprise: "99999.1"})
+	defer w.Close()
+	alerts := []of.Alert{syslogAlert("fanDown")}
+	require.NoError(t, w.Notify(&alerts))
+	msg := readDatagram(t, conn)
+	require.Contains(t, msg, "[labels@99999.1 ")
+	require.Contains(t, msg, "[annotations@99999.1 ")
+}
+
+// Test alerts are sent as RFC 3164 messages, with labels in the text.
+func TestWriterRFC3164(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	w := newTestWriter(t, of.SyslogConfig{Address: conn.LocalAddr().String(), Format: of.SyslogRFC3164, Facility: "daemon", AppName: "of-aci"})
+	defer w.Close()
+	alerts := []of.Alert{syslogAlert("fanDown")}
+	require.NoError(t, w.Notify(&alerts))
+	require.Equal(t, fmt.Sprintf(`<26>May  1 22:22:53 of-host of-aci[%d]: fanDown firing: Fan "1" [tray] down `+
+		`alert_severity="critical" alertname="fanDown" source_address="10.0.0.1"`, os.Getpid()), readDatagram(t, conn))
+}
+
+// Test messages are octet-counted on TCP, and the connection is reopened once closed.
+func TestWriterTCP(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+
+	w := newTestWriter(t, of.SyslogConfig{Address: l.Addr().String(), Transport: of.SyslogTCP, Timeout: time.Second})
+	defer w.Close()
+	alerts := []of.Alert{syslogAlert("a"), syslogAlert("b")}
+	require.NoError(t, w.Notify(&alerts))
+
+	conn, err := l.Accept()
+	require.NoError(t, err)
+	r := bufio.NewReader(conn)
+	require.Contains(t, readFrame(t, r), " a firing")
+	require.Contains(t, readFrame(t, r), " b firing")
+	conn.Close()
+
+	alerts = []of.Alert{syslogAlert("c")}
+	require.NoError(t, w.Notify(&alerts))
+	conn, err = l.Accept()
+	require.NoError(t, err)
+	defer conn.Close()
+	require.Contains(t, readFrame(t, bufio.NewReader(conn)), " c firing")
+}
+
+// Self-signed certificate and key for 127.0.0.1, in PEM.
+func selfSigned(t *testing.T) ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "syslog"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+}
+
+// Test messages are sent over TLS to collectors verified with the CA file.
+func TestWriterTLS(t *testing.T) {
+	dir, err := ioutil.TempDir("", "TestWriterTLS")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	certPEM, keyPEM := selfSigned(t)
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	require.NoError(t, err)
+	l, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{cert}})
+	require.NoError(t, err)
+	defer l.Close()
+	received := make(chan string, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		l, _ := bufio.NewReader(conn).ReadString(']')
+		received <- l
+	}()
+
+	caFile := filepath.Join(dir, "ca.pem")
+	require.NoError(t, ioutil.WriteFile(caFile, certPEM, 0600))
+	w := newTestWriter(t, of.SyslogConfig{Address: l.Addr().String(), Transport: of.SyslogTLS, CAFile: caFile, Timeout: time.Second})
+	defer w.Close()
+	alerts := []of.Alert{syslogAlert("a")}
+	require.NoError(t, w.Notify(&alerts))
+	select {
+	case msg := <-received:
+		require.Contains(t, msg, "[labels@32473 alert_severity=\"critical\" alertname=\"a\"")
+	case <-time.After(time.Second):
+		require.FailNow(t, "No message received")
+	}
+
+	// Collector is not trusted without CA file.
+	w = newTestWriter(t, of.SyslogConfig{Address: l.Addr().String(), Transport: of.SyslogTLS, Timeout: 100 * time.Millisecond})
+	require.Error(t, w.Notify(&alerts))
+}
+
+// Test configuration errors.
+func TestNewWriterErrors(t *testing.T) {
+	c := &clock.Clock{}
+	_, err := syslog.NewWriter(&of.SyslogConfig{}, c, logger.New())
+	require.Equal(t, of.ErrNoSyslogAddress, err)
+
+	_, err = syslog.NewWriter(&of.SyslogConfig{Address: "localhost", Transport: "relp"}, c, logger.New())
+	require.Contains(t, err.Error(), of.ErrUnknownSyslogTransport.Error())
+
+	_, err = syslog.NewWriter(&of.SyslogConfig{Address: "localhost", Format: "json"}, c, logger.New())
+	require.Contains(t, err.Error(), of.ErrUnknownSyslogFormat.Error())
+
+	_, err = syslog.NewWriter(&of.SyslogConfig{Address: "localhost", Facility: "local9"}, c, logger.New())
+	require.Contains(t, err.Error(), of.ErrUnknownSyslogFacility.Error())
+
+	_, err = syslog.NewWriter(&of.SyslogConfig{Address: "localhost", Enterprise: "cisco"}, c, logger.New())
+	require.Contains(t, err.Error(), of.ErrInvalidSyslogEnterprise.Error())
+
+	_, err = syslog.NewWriter(&of.SyslogConfig{Address: "localhost", Transport: of.SyslogTLS, CertFile: "cert.pem"}, c, logger.New())
+	require.Equal(t, of.ErrKeyWithoutCert, err)
+
+	w, err := syslog.NewWriter(&of.SyslogConfig{Address: "localhost", Transport: of.SyslogTLS}, c, logger.New())
+	require.NoError(t, err)
+	require.Equal(t, "localhost:6514", w.Address)
+	require.Equal(t, of.SyslogRFC5424, w.Format)
+}
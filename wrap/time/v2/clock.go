@@ -0,0 +1,42 @@
+package v2
+
+import (
+	"sync"
+	"time"
+)
+
+// Represents of.Clock
+type Clock struct {
+}
+
+// Current time in UTC.
+func (c *Clock) Now() time.Time {
+	return time.Now().UTC()
+}
+
+// Represents of.Clock, to be used in test cases. Time only moves when asked to.
+type FixedClock struct {
+	mu sync.Mutex
+	T  time.Time
+}
+
+// Return the fixed time.
+func (c *FixedClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.T
+}
+
+// Move the fixed time forward by d.
+func (c *FixedClock) Add(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.T = c.T.Add(d)
+}
+
+// Set the fixed time to t.
+func (c *FixedClock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.T = t
+}
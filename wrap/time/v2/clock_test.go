@@ -0,0 +1,38 @@
+package v2_test
+
+import (
+	"testing"
+	"time"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+	clock "github.com/cisco-cx/of/wrap/time/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// Enforce interface implementation.
+func TestClockInterface(t *testing.T) {
+	var _ of.Clock = &clock.Clock{}
+	var _ of.Clock = &clock.FixedClock{}
+}
+
+// Test real clock.
+func TestClock(t *testing.T) {
+	c := clock.Clock{}
+	before := time.Now()
+	now := c.Now()
+	require.False(t, now.Before(before.Add(-time.Second)))
+	require.Equal(t, time.UTC, now.Location())
+}
+
+// Test fixed clock.
+func TestFixedClock(t *testing.T) {
+	start := time.Date(2020, 5, 1, 22, 22, 53, 0, time.UTC)
+	c := clock.FixedClock{T: start}
+	require.Equal(t, start, c.Now())
+
+	c.Add(time.Minute)
+	require.Equal(t, start.Add(time.Minute), c.Now())
+
+	c.Set(start)
+	require.Equal(t, start, c.Now())
+}
@@ -0,0 +1,269 @@
+package v2
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+)
+
+// Represents the BER type of a value.
+type BERType byte
+
+const (
+	// BERType constants
+	Integer          BERType = 0x02
+	OctetString      BERType = 0x04
+	Null             BERType = 0x05
+	ObjectIdentifier BERType = 0x06
+	Sequence         BERType = 0x30
+	Counter32        BERType = 0x41
+	Gauge32          BERType = 0x42
+	TimeTicks        BERType = 0x43
+)
+
+// Represents the type of a PDU.
+type PDUType byte
+
+const (
+	// PDUType constants
+	GetRequest    PDUType = 0xa0
+	Response      PDUType = 0xa2
+	InformRequest PDUType = 0xa6
+	SNMPv2Trap    PDUType = 0xa7
+	Report        PDUType = 0xa8
+)
+
+// Encode content with tag and its length.
+func tlv(tag byte, content []byte) []byte {
+	n := len(content)
+	var b []byte
+	switch {
+	case n < 0x80:
+		b = make([]byte, 0, 2+n)
+		b = append(b, tag, byte(n))
+	default:
+		var l []byte
+		for ; n > 0; n >>= 8 {
+			l = append([]byte{byte(n)}, l...)
+		}
+		b = make([]byte, 0, 2+len(l)+len(content))
+		b = append(b, tag, 0x80|byte(len(l)))
+		b = append(b, l...)
+	}
+	return append(b, content...)
+}
+
+// Encode items as a sequence, or constructed type tag.
+func seq(tag byte, items ...[]byte) []byte {
+	var content []byte
+	for _, item := range items {
+		content = append(content, item...)
+	}
+	return tlv(tag, content)
+}
+
+// Encode v in two's complement, in as few bytes as possible.
+func berInt(tag BERType, v int64) []byte {
+	var b []byte
+	for {
+		b = append([]byte{byte(v)}, b...)
+		if (v < 0x80 && v >= -0x80) || len(b) == 8 {
+			break
+		}
+		v >>= 8
+	}
+	return tlv(byte(tag), b)
+}
+
+// Encode unsigned v, with a leading zero byte if its high bit is set.
+func berUint(tag BERType, v uint64) []byte {
+	var b []byte
+	for {
+		b = append([]byte{byte(v)}, b...)
+		v >>= 8
+		if v == 0 {
+			break
+		}
+	}
+	if b[0]&0x80 != 0 {
+		b = append([]byte{0}, b...)
+	}
+	return tlv(byte(tag), b)
+}
+
+// Encode dotted oid.
+func berOID(oid string) ([]byte, error) {
+	arcs, err := parseOID(oid)
+	if err != nil {
+		return nil, err
+	}
+	if len(arcs) < 2 || arcs[0] > 2 || (arcs[0] < 2 && arcs[1] > 39) {
+		return nil, fmt.Errorf("%s Invalid OID %q", of.ErrMalformedSNMP, oid)
+	}
+	var b []byte
+	for _, arc := range append([]uint64{arcs[0]*40 + arcs[1]}, arcs[2:]...) {
+		enc := []byte{byte(arc & 0x7f)}
+		for arc >>= 7; arc > 0; arc >>= 7 {
+			enc = append([]byte{byte(arc&0x7f) | 0x80}, enc...)
+		}
+		b = append(b, enc...)
+	}
+	return tlv(byte(ObjectIdentifier), b), nil
+}
+
+// Arcs of dotted oid, with or without a leading dot.
+func parseOID(oid string) ([]uint64, error) {
+	parts := strings.Split(strings.TrimPrefix(oid, "."), ".")
+	arcs := make([]uint64, len(parts))
+	for i, p := range parts {
+		arc, err := strconv.ParseUint(p, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("%s Invalid OID %q", of.ErrMalformedSNMP, oid)
+		}
+		arcs[i] = arc
+	}
+	return arcs, nil
+}
+
+// Represents a decoded BER element, at b[start:end] with content from b[cstart:end].
+type element struct {
+	tag    byte
+	start  int
+	cstart int
+	end    int
+}
+
+// Reads BER elements from b.
+type decoder struct {
+	b   []byte
+	pos int
+	end int
+}
+
+// Decoder of content of e.
+func (d *decoder) sub(e element) *decoder {
+	return &decoder{b: d.b, pos: e.cstart, end: e.end}
+}
+
+// Check if all elements were read.
+func (d *decoder) done() bool {
+	return d.pos >= d.end
+}
+
+// Read next element, failing if its tag is not tag.
+func (d *decoder) expect(tag byte) (element, error) {
+	e, err := d.next()
+	if err != nil {
+		return e, err
+	}
+	if e.tag != tag {
+		return e, fmt.Errorf("%s Expected tag 0x%02x at %d, got 0x%02x", of.ErrMalformedSNMP, tag, e.start, e.tag)
+	}
+	return e, nil
+}
+
+// Read next element.
+func (d *decoder) next() (element, error) {
+	e := element{start: d.pos}
+	if d.end-d.pos < 2 {
+		return e, fmt.Errorf("%s Truncated at %d", of.ErrMalformedSNMP, d.pos)
+	}
+	e.tag = d.b[d.pos]
+	n := int(d.b[d.pos+1])
+	pos := d.pos + 2
+	if n&0x80 != 0 {
+		lenBytes := n & 0x7f
+		if lenBytes == 0 || lenBytes > 3 || pos+lenBytes > d.end {
+			return e, fmt.Errorf("%s Invalid length at %d", of.ErrMalformedSNMP, d.pos)
+		}
+		n = 0
+		for _, c := range d.b[pos : pos+lenBytes] {
+			n = n<<8 | int(c)
+		}
+		pos += lenBytes
+	}
+	if pos+n > d.end {
+		return e, fmt.Errorf("%s Truncated at %d", of.ErrMalformedSNMP, d.pos)
+	}
+	e.cstart, e.end = pos, pos+n
+	d.pos = e.end
+	return e, nil
+}
+
+// Content of e.
+func (d *decoder) bytes(e element) []byte {
+	return d.b[e.cstart:e.end]
+}
+
+// Read next element as a signed integer.
+func (d *decoder) int() (int64, error) {
+	e, err := d.expect(byte(Integer))
+	if err != nil {
+		return 0, err
+	}
+	return decodeInt(d.bytes(e))
+}
+
+// Read next element as an octet string.
+func (d *decoder) octets() ([]byte, error) {
+	e, err := d.expect(byte(OctetString))
+	if err != nil {
+		return nil, err
+	}
+	return d.bytes(e), nil
+}
+
+// Signed integer in two's complement b.
+func decodeInt(b []byte) (int64, error) {
+	if len(b) == 0 || len(b) > 8 {
+		return 0, fmt.Errorf("%s Invalid integer length %d", of.ErrMalformedSNMP, len(b))
+	}
+	v := int64(int8(b[0]))
+	for _, c := range b[1:] {
+		v = v<<8 | int64(c)
+	}
+	return v, nil
+}
+
+// Unsigned integer b.
+func decodeUint(b []byte) (uint64, error) {
+	if len(b) == 0 || len(b) > 9 {
+		return 0, fmt.Errorf("%s Invalid integer length %d", of.ErrMalformedSNMP, len(b))
+	}
+	var v uint64
+	for _, c := range b {
+		v = v<<8 | uint64(c)
+	}
+	return v, nil
+}
+
+// Dotted OID encoded in b.
+func decodeOID(b []byte) (string, error) {
+	var arcs []string
+	var arc uint64
+	for i, c := range b {
+		arc = arc<<7 | uint64(c&0x7f)
+		if c&0x80 != 0 {
+			if i == len(b)-1 {
+				return "", fmt.Errorf("%s Truncated OID", of.ErrMalformedSNMP)
+			}
+			continue
+		}
+		if len(arcs) == 0 {
+			first := arc / 40
+			if first > 2 {
+				first = 2
+			}
+			arcs = append(arcs, strconv.FormatUint(first, 10), strconv.FormatUint(arc-first*40, 10))
+		} else {
+			arcs = append(arcs, strconv.FormatUint(arc, 10))
+		}
+		arc = 0
+	}
+	if len(arcs) == 0 {
+		return "", fmt.Errorf("%s Empty OID", of.ErrMalformedSNMP)
+	}
+	return strings.Join(arcs, "."), nil
+}
@@ -0,0 +1,74 @@
+package v2
+
+import (
+	"time"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+)
+
+// OID OF-ALERT-MIB is registered at, see OF-ALERT-MIB.txt.
+type AlertMIB string
+
+// Registration of OF-ALERT-MIB.txt, under the enterprise reserved for documentation (RFC 5612).
+// Managers of a deployment should register the MIB under an OID of their own enterprise.
+const DefaultAlertMIB AlertMIB = "1.3.6.1.4.1.32473.1"
+
+// OIDs of OF-ALERT-MIB, relative to AlertMIB.
+const (
+	AlertNotification   = "0.1"
+	AlertNameOID        = "1.1"
+	AlertSeverityOID    = "1.2"
+	AlertSourceOID      = "1.3"
+	AlertFingerprintOID = "1.4"
+	AlertStateOID       = "1.5"
+	AlertDescriptionOID = "1.6"
+)
+
+// Other OIDs of notifications.
+const (
+	SysUpTimeOID         = "1.3.6.1.2.1.1.3.0"
+	SNMPTrapOID          = "1.3.6.1.6.3.1.1.4.1.0"
+	usmStatsNotInTimeOID = "1.3.6.1.6.3.15.1.1.2.0"
+	usmStatsUnknownIDOID = "1.3.6.1.6.3.15.1.1.4.0"
+)
+
+// Values of ofAlertState.
+const (
+	AlertFiring   int64 = 1
+	AlertResolved int64 = 2
+)
+
+// OID of object of the MIB.
+func (m AlertMIB) OID(object string) string {
+	return string(m) + "." + object
+}
+
+// Varbinds of the ofAlertNotification of alert, after sysUpTime and snmpTrapOID.
+// Alerts ended at or before now are resolved.
+func (m AlertMIB) VarBinds(alert of.Alert, now time.Time) []VarBind {
+	state := AlertFiring
+	if alert.EndsAt.IsZero() == false && alert.EndsAt.After(now) == false {
+		state = AlertResolved
+	}
+	description := alert.Annotations["description"]
+	if description == "" {
+		description = alert.Annotations["summary"]
+	}
+	return []VarBind{
+		{OID: m.OID(AlertNameOID) + ".0", Type: OctetString, Value: alert.Labels["alertname"]},
+		{OID: m.OID(AlertSeverityOID) + ".0", Type: OctetString, Value: alert.Labels["alert_severity"]},
+		{OID: m.OID(AlertSourceOID) + ".0", Type: OctetString, Value: alert.Labels["source_address"]},
+		{OID: m.OID(AlertFingerprintOID) + ".0", Type: OctetString, Value: alert.Labels["alert_fingerprint"]},
+		{OID: m.OID(AlertStateOID) + ".0", Type: Integer, Value: state},
+		{OID: m.OID(AlertDescriptionOID) + ".0", Type: OctetString, Value: description},
+	}
+}
+
+// Notification PDU of alert, with uptime of the sender.
+func (m AlertMIB) alertPDU(alert of.Alert, pduType PDUType, requestID int32, uptime time.Duration, now time.Time) PDU {
+	vbs := []VarBind{
+		{OID: SysUpTimeOID, Type: TimeTicks, Value: uint64(uptime / (10 * time.Millisecond))},
+		{OID: SNMPTrapOID, Type: ObjectIdentifier, Value: m.OID(AlertNotification)},
+	}
+	return PDU{Type: pduType, RequestID: requestID, VarBinds: append(vbs, m.VarBinds(alert, now)...)}
+}
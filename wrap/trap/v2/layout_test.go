@@ -0,0 +1,62 @@
+package v2_test
+
+import (
+	"io/ioutil"
+	"testing"
+	"time"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+	trap "github.com/cisco-cx/of/wrap/trap/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// Test alerts map to the objects of OF-ALERT-MIB.
+func TestAlertVarBinds(t *testing.T) {
+	now := time.Date(2020, 5, 1, 22, 22, 53, 0, time.UTC)
+	alert := of.Alert{
+		Labels: map[string]string{
+			"alertname":         "apicFault",
+			"alert_severity":    "critical",
+			"source_address":    "10.0.0.1",
+			"alert_fingerprint": "F0475",
+		},
+		Annotations: map[string]string{"summary": "Interface down"},
+	}
+	require.Equal(t, []trap.VarBind{
+		{OID: "1.3.6.1.4.1.32473.1.1.1.0", Type: trap.OctetString, Value: "apicFault"},
+		{OID: "1.3.6.1.4.1.32473.1.1.2.0", Type: trap.OctetString, Value: "critical"},
+		{OID: "1.3.6.1.4.1.32473.1.1.3.0", Type: trap.OctetString, Value: "10.0.0.1"},
+		{OID: "1.3.6.1.4.1.32473.1.1.4.0", Type: trap.OctetString, Value: "F0475"},
+		{OID: "1.3.6.1.4.1.32473.1.1.5.0", Type: trap.Integer, Value: trap.AlertFiring},
+		{OID: "1.3.6.1.4.1.32473.1.1.6.0", Type: trap.OctetString, Value: "Interface down"},
+	}, trap.DefaultAlertMIB.VarBinds(alert, now))
+
+	alert.EndsAt = now
+	alert.Annotations["description"] = "Interface eth1/1 down"
+	vbs := trap.DefaultAlertMIB.VarBinds(alert, now)
+	require.Equal(t, trap.AlertResolved, vbs[4].Value)
+	require.Equal(t, "Interface eth1/1 down", vbs[5].Value)
+
+	alert.EndsAt = now.Add(time.Minute)
+	require.Equal(t, trap.AlertFiring, trap.DefaultAlertMIB.VarBinds(alert, now)[4].Value)
+}
+
+// Test objects are registered under the MIB.
+func TestAlertMIBOID(t *testing.T) {
+	mib := trap.AlertMIB("1.3.6.1.4.1.99999.7")
+	require.Equal(t, "1.3.6.1.4.1.99999.7.0.1", mib.OID(trap.AlertNotification))
+	require.Equal(t, "1.3.6.1.4.1.99999.7.1.1.0", mib.VarBinds(of.Alert{}, time.Now())[0].OID)
+}
+
+// Test the MIB defines the objects.
+func TestAlertMIB(t *testing.T) {
+	b, err := ioutil.ReadFile("OF-ALERT-MIB.txt")
+	require.NoError(t, err)
+	mib := string(b)
+	require.Contains(t, mib, "ofAlertMIB MODULE-IDENTITY")
+	require.Contains(t, mib, "::= { enterprises 32473 1 }")
+	for _, object := range []string{"ofAlertName", "ofAlertSeverity", "ofAlertSource", "ofAlertFingerprint", "ofAlertState", "ofAlertDescription"} {
+		require.Contains(t, mib, object+" OBJECT-TYPE")
+	}
+	require.Contains(t, mib, "ofAlertNotification NOTIFICATION-TYPE")
+}
@@ -0,0 +1,360 @@
+package v2
+
+import (
+	"fmt"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+)
+
+const (
+	// SNMP message versions.
+	versionV2c = 1
+	versionV3  = 3
+
+	// USM security model.
+	securityModelUSM = 3
+
+	// Max. message size sent in SNMPv3 headers.
+	maxMessageSize = 65507
+)
+
+// Represents a variable binding.
+type VarBind struct {
+	OID   string
+	Type  BERType
+	Value interface{} // int64 for Integer, uint64 for counters and TimeTicks, string for OctetString and ObjectIdentifier.
+}
+
+// Represents a PDU.
+type PDU struct {
+	Type        PDUType
+	RequestID   int32
+	ErrorStatus int
+	ErrorIndex  int
+	VarBinds    []VarBind
+}
+
+// Represents an SNMPv2c or SNMPv3 message.
+type message struct {
+	version   int
+	community string // v2c.
+
+	// v3.
+	msgID       int32
+	flags       byte
+	engineID    []byte
+	boots       int32
+	engineTime  int32
+	user        string
+	privParams  []byte
+	contextName string
+
+	pdu PDU
+}
+
+// Encode vb.
+func (vb *VarBind) marshal() ([]byte, error) {
+	name, err := berOID(vb.OID)
+	if err != nil {
+		return nil, err
+	}
+	var value []byte
+	switch v := vb.Value.(type) {
+	case int64:
+		value = berInt(vb.Type, v)
+	case uint64:
+		value = berUint(vb.Type, v)
+	case string:
+		if vb.Type == ObjectIdentifier {
+			if value, err = berOID(v); err != nil {
+				return nil, err
+			}
+		} else {
+			value = tlv(byte(vb.Type), []byte(v))
+		}
+	case nil:
+		value = tlv(byte(vb.Type), nil)
+	default:
+		return nil, fmt.Errorf("%s Unsupported value %T of %s", of.ErrMalformedSNMP, vb.Value, vb.OID)
+	}
+	return seq(byte(Sequence), name, value), nil
+}
+
+// Encode p.
+func (p *PDU) marshal() ([]byte, error) {
+	var vbs []byte
+	for i := range p.VarBinds {
+		vb, err := p.VarBinds[i].marshal()
+		if err != nil {
+			return nil, err
+		}
+		vbs = append(vbs, vb...)
+	}
+	return seq(byte(p.Type),
+		berInt(Integer, int64(p.RequestID)),
+		berInt(Integer, int64(p.ErrorStatus)),
+		berInt(Integer, int64(p.ErrorIndex)),
+		tlv(byte(Sequence), vbs),
+	), nil
+}
+
+// Encode m, signing and encrypting it as user u if its flags say so.
+func (m *message) marshal(u *User, salt uint64) ([]byte, error) {
+	pdu, err := m.pdu.marshal()
+	if err != nil {
+		return nil, err
+	}
+	if m.version == versionV2c {
+		return seq(byte(Sequence), berInt(Integer, versionV2c), tlv(byte(OctetString), []byte(m.community)), pdu), nil
+	}
+
+	var k *usmKeys
+	if m.flags&flagAuth != 0 {
+		k = u.localKeys(m.engineID)
+	}
+	data := seq(byte(Sequence), tlv(byte(OctetString), m.engineID), tlv(byte(OctetString), []byte(m.contextName)), pdu)
+	if m.flags&flagPriv != 0 {
+		encrypted, privParams, err := u.encrypt(k, data, m.boots, m.engineTime, salt)
+		if err != nil {
+			return nil, err
+		}
+		data, m.privParams = tlv(byte(OctetString), encrypted), privParams
+	}
+
+	var authParams []byte
+	if m.flags&flagAuth != 0 {
+		authParams = make([]byte, authParamsLen)
+	}
+	usmPrefix := seqContent(
+		tlv(byte(OctetString), m.engineID),
+		berInt(Integer, int64(m.boots)),
+		berInt(Integer, int64(m.engineTime)),
+		tlv(byte(OctetString), []byte(m.user)),
+	)
+	authTLV, privTLV := tlv(byte(OctetString), authParams), tlv(byte(OctetString), m.privParams)
+	usm := seq(byte(Sequence), usmPrefix, authTLV, privTLV)
+	header := seq(byte(Sequence),
+		berInt(Integer, int64(m.msgID)),
+		berInt(Integer, maxMessageSize),
+		tlv(byte(OctetString), []byte{m.flags}),
+		berInt(Integer, securityModelUSM),
+	)
+	msg := seq(byte(Sequence), berInt(Integer, versionV3), header, tlv(byte(OctetString), usm), data)
+
+	if m.flags&flagAuth != 0 {
+		// USM params end where data starts, auth params follow the USM prefix.
+		usmHeader := len(usm) - len(usmPrefix) - len(authTLV) - len(privTLV)
+		offset := len(msg) - len(data) - len(usm) + usmHeader + len(usmPrefix) + 2
+		u.sign(msg, k, offset)
+	}
+	return msg, nil
+}
+
+// Concatenated items.
+func seqContent(items ...[]byte) []byte {
+	var b []byte
+	for _, item := range items {
+		b = append(b, item...)
+	}
+	return b
+}
+
+// Decode b, a v2c message or a v3 message of a user returned by users.
+// users is called with the user name and engine ID of v3 messages, and returns nil for unknown users.
+func unmarshal(b []byte, users func(name string) *User) (*message, error) {
+	d := &decoder{b: b, end: len(b)}
+	root, err := d.expect(byte(Sequence))
+	if err != nil {
+		return nil, err
+	}
+	d = d.sub(root)
+	version, err := d.int()
+	if err != nil {
+		return nil, err
+	}
+
+	m := &message{version: int(version)}
+	switch version {
+	case versionV2c:
+		community, err := d.octets()
+		if err != nil {
+			return nil, err
+		}
+		m.community = string(community)
+		if m.pdu, err = unmarshalPDU(d); err != nil {
+			return nil, err
+		}
+		return m, nil
+	case versionV3:
+	default:
+		return nil, fmt.Errorf("%s %d", of.ErrUnknownSNMPVersion, version)
+	}
+
+	header, err := d.expect(byte(Sequence))
+	if err != nil {
+		return nil, err
+	}
+	hd := d.sub(header)
+	msgID, err := hd.int()
+	if err != nil {
+		return nil, err
+	}
+	m.msgID = int32(msgID)
+	if _, err = hd.int(); err != nil {
+		return nil, err
+	}
+	flags, err := hd.octets()
+	if err != nil || len(flags) != 1 {
+		return nil, fmt.Errorf("%s Invalid message flags", of.ErrMalformedSNMP)
+	}
+	m.flags = flags[0]
+
+	securityParams, err := d.expect(byte(OctetString))
+	if err != nil {
+		return nil, err
+	}
+	sd := d.sub(securityParams)
+	usm, err := sd.expect(byte(Sequence))
+	if err != nil {
+		return nil, err
+	}
+	ud := sd.sub(usm)
+	if m.engineID, err = ud.octets(); err != nil {
+		return nil, err
+	}
+	boots, err := ud.int()
+	if err != nil {
+		return nil, err
+	}
+	engineTime, err := ud.int()
+	if err != nil {
+		return nil, err
+	}
+	m.boots, m.engineTime = int32(boots), int32(engineTime)
+	user, err := ud.octets()
+	if err != nil {
+		return nil, err
+	}
+	m.user = string(user)
+	authParams, err := ud.expect(byte(OctetString))
+	if err != nil {
+		return nil, err
+	}
+	if m.privParams, err = ud.octets(); err != nil {
+		return nil, err
+	}
+
+	var u *User
+	var k *usmKeys
+	if m.flags&flagAuth != 0 {
+		if u = users(m.user); u == nil || u.flags()&flagAuth == 0 {
+			return nil, fmt.Errorf("%s Unknown user %q", of.ErrSNMPAuthFailed, m.user)
+		}
+		k = u.localKeys(m.engineID)
+		if authParams.end-authParams.cstart != authParamsLen || u.verify(b, k, authParams.cstart) == false {
+			return nil, of.ErrSNMPAuthFailed
+		}
+	}
+
+	if m.flags&flagPriv != 0 {
+		if u == nil || u.flags()&flagPriv == 0 {
+			return nil, fmt.Errorf("%s User %q has no privacy key", of.ErrSNMPAuthFailed, m.user)
+		}
+		encrypted, err := d.octets()
+		if err != nil {
+			return nil, err
+		}
+		plain, err := u.decrypt(k, encrypted, m.privParams, m.boots, m.engineTime)
+		if err != nil {
+			return nil, err
+		}
+		d = &decoder{b: plain, end: len(plain)}
+	}
+
+	scoped, err := d.expect(byte(Sequence))
+	if err != nil {
+		return nil, err
+	}
+	sc := d.sub(scoped)
+	if _, err = sc.octets(); err != nil {
+		return nil, err
+	}
+	contextName, err := sc.octets()
+	if err != nil {
+		return nil, err
+	}
+	m.contextName = string(contextName)
+	if m.pdu, err = unmarshalPDU(sc); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Decode next element of d as a PDU.
+func unmarshalPDU(d *decoder) (PDU, error) {
+	p := PDU{}
+	e, err := d.next()
+	if err != nil {
+		return p, err
+	}
+	p.Type = PDUType(e.tag)
+	pd := d.sub(e)
+	requestID, err := pd.int()
+	if err != nil {
+		return p, err
+	}
+	errorStatus, err := pd.int()
+	if err != nil {
+		return p, err
+	}
+	errorIndex, err := pd.int()
+	if err != nil {
+		return p, err
+	}
+	p.RequestID, p.ErrorStatus, p.ErrorIndex = int32(requestID), int(errorStatus), int(errorIndex)
+
+	vbs, err := pd.expect(byte(Sequence))
+	if err != nil {
+		return p, err
+	}
+	vd := pd.sub(vbs)
+	for vd.done() == false {
+		vbe, err := vd.expect(byte(Sequence))
+		if err != nil {
+			return p, err
+		}
+		vbd := vd.sub(vbe)
+		name, err := vbd.expect(byte(ObjectIdentifier))
+		if err != nil {
+			return p, err
+		}
+		vb := VarBind{}
+		if vb.OID, err = decodeOID(vbd.bytes(name)); err != nil {
+			return p, err
+		}
+		value, err := vbd.next()
+		if err != nil {
+			return p, err
+		}
+		vb.Type = BERType(value.tag)
+		content := vbd.bytes(value)
+		switch vb.Type {
+		case Integer:
+			vb.Value, err = decodeInt(content)
+		case Counter32, Gauge32, TimeTicks:
+			vb.Value, err = decodeUint(content)
+		case ObjectIdentifier:
+			vb.Value, err = decodeOID(content)
+		case OctetString:
+			vb.Value = string(content)
+		default:
+			// Other types, like IpAddress or exceptions, are kept as raw content.
+			vb.Value = string(content)
+		}
+		if err != nil {
+			return p, err
+		}
+		p.VarBinds = append(p.VarBinds, vb)
+	}
+	return p, nil
+}
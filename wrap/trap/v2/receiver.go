@@ -0,0 +1,138 @@
+package v2
+
+import (
+	"net"
+	"time"
+
+	logger "github.com/cisco-cx/of/wrap/logrus/v2"
+)
+
+// Represents a notification received by Receiver.
+type Notification struct {
+	Addr      net.Addr
+	Community string // v2c.
+	User      string // v3.
+	Boots     int32  // v3, snmpEngineBoots of the engine the notification was sent from.
+	PDU       PDU
+}
+
+// Receives SNMP notifications on UDP, acknowledging informs. It answers SNMPv3 engine
+// discovery as an authoritative engine, but does not check time windows or keep
+// usmStats counters. Meant for tests and for checking the configuration of senders.
+type Receiver struct {
+	Community     string           // Community of v2c notifications, others are dropped.
+	Users         map[string]*User // SNMPv3 users by name.
+	EngineID      []byte           // Authoritative engine of informs.
+	Notifications chan Notification
+	Log           *logger.Logger
+
+	conn  net.PacketConn
+	start time.Time
+}
+
+// Init Receiver listening on addr, a host:port.
+func NewReceiver(addr string, l *logger.Logger) (*Receiver, error) {
+	id, err := engineID("")
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.ListenPacket("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return &Receiver{
+		Community:     defaultCommunity,
+		Users:         make(map[string]*User),
+		EngineID:      id,
+		Notifications: make(chan Notification, 100),
+		Log:           l,
+		conn:          conn,
+		start:         time.Now(),
+	}, nil
+}
+
+// Address r listens on.
+func (r *Receiver) Addr() net.Addr {
+	return r.conn.LocalAddr()
+}
+
+// Receive notifications until r is closed.
+func (r *Receiver) Serve() error {
+	buf := make([]byte, maxMessageSize)
+	for {
+		n, addr, err := r.conn.ReadFrom(buf)
+		if err != nil {
+			return err
+		}
+		r.handle(append([]byte(nil), buf[:n]...), addr)
+	}
+}
+
+// Stop receiving notifications.
+func (r *Receiver) Close() error {
+	return r.conn.Close()
+}
+
+// Handle message b from addr.
+func (r *Receiver) handle(b []byte, addr net.Addr) {
+	m, err := unmarshal(b, func(name string) *User { return r.Users[name] })
+	if err != nil {
+		r.Log.WithError(err).Debugf("Dropping invalid SNMP message from %s.", addr)
+		return
+	}
+
+	if m.version == versionV2c {
+		if m.community != r.Community {
+			r.Log.Debugf("Dropping SNMP message from %s with unknown community.", addr)
+			return
+		}
+		if r.notify(m, addr) == true {
+			r.reply(&message{version: versionV2c, community: m.community, pdu: response(m.pdu)}, nil, addr)
+		}
+		return
+	}
+
+	boots, engineTime := int32(1), int32(time.Since(r.start)/time.Second)
+	if m.pdu.Type != SNMPv2Trap && string(m.engineID) != string(r.EngineID) {
+		// Discovery, or a request for another engine.
+		r.reply(&message{
+			version: versionV3, msgID: m.msgID, engineID: r.EngineID, boots: boots, engineTime: engineTime,
+			user: m.user, pdu: PDU{Type: Report, RequestID: m.pdu.RequestID, VarBinds: []VarBind{
+				{OID: usmStatsUnknownIDOID, Type: Counter32, Value: uint64(1)},
+			}},
+		}, nil, addr)
+		return
+	}
+	if r.notify(m, addr) == true {
+		r.reply(&message{
+			version: versionV3, msgID: m.msgID, flags: m.flags &^ flagReportable, engineID: r.EngineID,
+			boots: boots, engineTime: engineTime, user: m.user, pdu: response(m.pdu),
+		}, r.Users[m.user], addr)
+	}
+}
+
+// Emit notification of m. Returns true if m is an inform to acknowledge.
+func (r *Receiver) notify(m *message, addr net.Addr) bool {
+	if m.pdu.Type != SNMPv2Trap && m.pdu.Type != InformRequest {
+		r.Log.Debugf("Dropping SNMP PDU of type %#x from %s.", byte(m.pdu.Type), addr)
+		return false
+	}
+	r.Notifications <- Notification{Addr: addr, Community: m.community, User: m.user, Boots: m.boots, PDU: m.pdu}
+	return m.pdu.Type == InformRequest
+}
+
+// Send m to addr.
+func (r *Receiver) reply(m *message, u *User, addr net.Addr) {
+	b, err := m.marshal(u, uint64(time.Now().UnixNano()))
+	if err == nil {
+		_, err = r.conn.WriteTo(b, addr)
+	}
+	if err != nil {
+		r.Log.WithError(err).Errorf("Failed to reply to SNMP message from %s.", addr)
+	}
+}
+
+// Response to inform p.
+func response(p PDU) PDU {
+	return PDU{Type: Response, RequestID: p.RequestID, VarBinds: p.VarBinds}
+}
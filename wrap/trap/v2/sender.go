@@ -0,0 +1,394 @@
+package v2
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+	logger "github.com/cisco-cx/of/wrap/logrus/v2"
+	ratelimit "github.com/cisco-cx/of/wrap/ratelimit/v2"
+)
+
+const (
+	defaultTrapPort  = "162"
+	defaultCommunity = "public"
+	defaultTimeout   = 5 * time.Second
+
+	// Enterprise number of engine IDs generated, with the high bit set (RFC 3411).
+	engineIDEnterprise = 0x80000009
+	// Max. snmpEngineBoots (RFC 3414).
+	maxEngineBoots = 2147483647
+)
+
+// Implements of.Notifier, sending an ofAlertNotification of OF-ALERT-MIB, registered at MIB, for every alert
+// to every target. Notifications are spaced by Rate, and resent Retries times if they fail.
+type Sender struct {
+	of.TrapConfig
+	User  *User // SNMPv3 user, nil for v2c.
+	Clock of.Clock
+	Sleep func(time.Duration) // Defaults to time.Sleep.
+	Log   *logger.Logger
+
+	mib       AlertMIB
+	engineID  []byte // Local engine, traps are sent from.
+	boots     int32  // snmpEngineBoots of engineID.
+	start     time.Time
+	targets   []*target
+	requestID int32
+	salt      uint64
+}
+
+// Represents a manager notifications are sent to.
+type target struct {
+	addr   string
+	bucket *ratelimit.TokenBucket // nil without rate limit.
+
+	mu     sync.Mutex
+	engine *remoteEngine // Discovered for SNMPv3 informs.
+}
+
+// Represents the authoritative engine of a manager receiving informs.
+type remoteEngine struct {
+	id    []byte
+	boots int32
+	time  int32
+	at    time.Time // When time was received.
+}
+
+// Init Sender with cfg, reading passphrase files.
+func NewSender(cfg *of.TrapConfig, c of.Clock, l *logger.Logger) (*Sender, error) {
+	s := &Sender{TrapConfig: *cfg, Clock: c, Sleep: time.Sleep, Log: l, start: c.Now()}
+	if len(s.Targets) == 0 {
+		return nil, of.ErrNoTrapTargets
+	}
+	if s.Version == "" {
+		s.Version = of.SNMPv2c
+	}
+	if s.Community == "" {
+		s.Community = defaultCommunity
+	}
+	if s.Timeout <= 0 {
+		s.Timeout = defaultTimeout
+	}
+	s.mib = DefaultAlertMIB
+	if s.MIB != "" {
+		s.mib = AlertMIB(strings.TrimPrefix(s.MIB, "."))
+	}
+	if _, err := berOID(string(s.mib)); err != nil {
+		return nil, fmt.Errorf("Invalid OF-ALERT-MIB OID %q", s.MIB)
+	}
+
+	switch s.Version {
+	case of.SNMPv2c:
+	case of.SNMPv3:
+		u, err := newUser(cfg)
+		if err != nil {
+			return nil, err
+		}
+		s.User = u
+		if s.engineID, err = engineID(cfg.EngineID); err != nil {
+			return nil, err
+		}
+		if s.boots, err = engineBoots(cfg); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("%s %q, expected v2c or v3", of.ErrUnknownSNMPVersion, s.Version)
+	}
+
+	for _, addr := range s.Targets {
+		if _, _, err := net.SplitHostPort(addr); err != nil {
+			addr = net.JoinHostPort(addr, defaultTrapPort)
+		}
+		t := &target{addr: addr}
+		if s.Rate > 0 {
+			t.bucket = ratelimit.NewTokenBucket(s.Rate, 1)
+		}
+		s.targets = append(s.targets, t)
+	}
+
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	s.salt = binary.BigEndian.Uint64(b)
+	s.requestID = int32(binary.BigEndian.Uint32(b) & 0x7fffffff)
+	return s, nil
+}
+
+// User of cfg, with passphrases read from files.
+func newUser(cfg *of.TrapConfig) (*User, error) {
+	u := &User{Name: cfg.User, Auth: cfg.AuthProtocol, Priv: cfg.PrivProtocol}
+	var err error
+	if u.AuthPassphrase, err = readPassphrase(cfg.AuthPassphraseFile); err != nil {
+		return nil, err
+	}
+	if u.PrivPassphrase, err = readPassphrase(cfg.PrivPassphraseFile); err != nil {
+		return nil, err
+	}
+	if err = u.Validate(); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// Contents of file without surrounding whitespace, empty if file is not set.
+func readPassphrase(file string) (string, error) {
+	if file == "" {
+		return "", nil
+	}
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(b)), nil
+}
+
+// Engine ID of hex id, or a random one if id is empty.
+func engineID(id string) ([]byte, error) {
+	if id != "" {
+		b, err := hex.DecodeString(strings.TrimPrefix(strings.ToLower(id), "0x"))
+		if err != nil || len(b) < 5 || len(b) > 32 {
+			return nil, fmt.Errorf("Invalid SNMPv3 engine ID %q, expected 5 to 32 bytes in hex", id)
+		}
+		return b, nil
+	}
+	b := make([]byte, 13)
+	binary.BigEndian.PutUint32(b, engineIDEnterprise)
+	b[4] = 5 // Octets format.
+	if _, err := rand.Read(b[5:]); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// snmpEngineBoots of the engine of cfg, incremented in cfg.EngineBootsFile.
+// A generated engine ID is new on every start, so it is booted once.
+// Receivers drop notifications of a known engine, unless boots grow on restart (RFC 3414).
+func engineBoots(cfg *of.TrapConfig) (int32, error) {
+	if cfg.EngineID == "" {
+		return 1, nil
+	}
+	if cfg.EngineBootsFile == "" {
+		return 0, of.ErrNoEngineBootsFile
+	}
+	var boots int64
+	b, err := ioutil.ReadFile(cfg.EngineBootsFile)
+	if err != nil && os.IsNotExist(err) == false {
+		return 0, err
+	}
+	if s := strings.TrimSpace(string(b)); s != "" {
+		if boots, err = strconv.ParseInt(s, 10, 32); err != nil {
+			return 0, fmt.Errorf("Invalid SNMPv3 engine boots in %s: %s", cfg.EngineBootsFile, err)
+		}
+	}
+	// Stays at its max. value, when the engine ID must be changed.
+	if boots < maxEngineBoots {
+		boots++
+	}
+	tmp := cfg.EngineBootsFile + ".tmp"
+	if err = ioutil.WriteFile(tmp, []byte(strconv.FormatInt(boots, 10)+"\n"), 0644); err != nil {
+		return 0, err
+	}
+	if err = os.Rename(tmp, cfg.EngineBootsFile); err != nil {
+		return 0, err
+	}
+	return int32(boots), nil
+}
+
+// Local engine ID, traps are sent from. Managers need it to localize keys of the user.
+func (s *Sender) EngineID() []byte {
+	return s.engineID
+}
+
+// Send a notification of every alert to every target, each target in its own goroutine.
+// Returns an error naming the targets that failed.
+func (s *Sender) Notify(alerts *[]of.Alert) error {
+	now := s.Clock.Now()
+	errs := make([]error, len(s.targets))
+	var wg sync.WaitGroup
+	for i, t := range s.targets {
+		wg.Add(1)
+		go func(i int, t *target) {
+			defer wg.Done()
+			for _, alert := range *alerts {
+				if err := s.send(t, alert, now); err != nil {
+					s.Log.WithError(err).Errorf("Failed to send SNMP notification of %s to %s.", alert.Labels["alertname"], t.addr)
+					errs[i] = err
+				}
+			}
+		}(i, t)
+	}
+	wg.Wait()
+
+	var failed []string
+	for i, err := range errs {
+		if err != nil {
+			failed = append(failed, s.targets[i].addr+": "+err.Error())
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	sort.Strings(failed)
+	return fmt.Errorf("Sending SNMP notifications to %d of %d targets failed: %s", len(failed), len(s.targets), strings.Join(failed, "; "))
+}
+
+// Send notification of alert to t, once it is its turn. Resent up to Retries times.
+func (s *Sender) send(t *target, alert of.Alert, now time.Time) error {
+	if t.bucket != nil {
+		if wait := t.bucket.Reserve(s.Clock.Now(), 1); wait > 0 {
+			s.Sleep(wait)
+		}
+	}
+
+	var err error
+	for attempt := 0; attempt <= s.Retries; attempt++ {
+		if err = s.exchange(t, alert, now); err == nil {
+			return nil
+		}
+		s.Log.WithError(err).Debugf("SNMP notification to %s failed, attempt %d.", t.addr, attempt+1)
+	}
+	return err
+}
+
+// Send notification of alert to t once, waiting for the acknowledgement of informs.
+func (s *Sender) exchange(t *target, alert of.Alert, now time.Time) error {
+	conn, err := net.Dial("udp", t.addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	pduType := SNMPv2Trap
+	if s.Inform == true {
+		pduType = InformRequest
+	}
+	uptime := s.Clock.Now().Sub(s.start)
+	m := &message{version: versionV2c, community: s.Community, pdu: s.mib.alertPDU(alert, pduType, s.nextID(), uptime, now)}
+
+	if s.Version == of.SNMPv3 {
+		m.version, m.community = versionV3, ""
+		m.msgID, m.user, m.flags = s.nextID(), s.User.Name, s.User.flags()
+		m.engineID, m.boots, m.engineTime = s.engineID, s.boots, int32(uptime/time.Second)
+		if s.Inform == true {
+			e, err := s.discover(conn, t)
+			if err != nil {
+				return err
+			}
+			m.flags |= flagReportable
+			m.engineID, m.boots = e.id, e.boots
+			m.engineTime = e.time + int32(time.Since(e.at)/time.Second)
+		}
+	}
+
+	b, err := m.marshal(s.User, atomic.AddUint64(&s.salt, 1))
+	if err != nil {
+		return err
+	}
+	if _, err = conn.Write(b); err != nil {
+		return err
+	}
+	if s.Inform == false {
+		return nil
+	}
+
+	res, err := s.receive(conn, m)
+	if err != nil {
+		return err
+	}
+	if res.pdu.Type == Report {
+		return s.report(t, res)
+	}
+	return nil
+}
+
+// Wait for the response to req, ignoring other messages.
+func (s *Sender) receive(conn net.Conn, req *message) (*message, error) {
+	if err := conn.SetReadDeadline(time.Now().Add(s.Timeout)); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, maxMessageSize)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return nil, err
+		}
+		res, err := unmarshal(buf[:n], func(name string) *User { return s.User })
+		if err != nil {
+			s.Log.WithError(err).Debugf("Ignoring invalid SNMP message.")
+			continue
+		}
+		if res.version != req.version {
+			continue
+		}
+		if (res.version == versionV3 && res.msgID == req.msgID) || (res.pdu.Type == Response && res.pdu.RequestID == req.pdu.RequestID) {
+			return res, nil
+		}
+	}
+}
+
+// Handle a report in response to an inform to t. Resending the inform after
+// an updated engine time or a new discovery may succeed.
+func (s *Sender) report(t *target, res *message) error {
+	var oid string
+	if len(res.pdu.VarBinds) > 0 {
+		oid = res.pdu.VarBinds[0].OID
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	switch oid {
+	case usmStatsNotInTimeOID:
+		if t.engine != nil {
+			t.engine.boots, t.engine.time, t.engine.at = res.boots, res.engineTime, time.Now()
+		}
+		return fmt.Errorf("Inform not in time window of %s", t.addr)
+	case usmStatsUnknownIDOID:
+		t.engine = nil
+		return fmt.Errorf("Engine of %s is unknown", t.addr)
+	}
+	return fmt.Errorf("Inform rejected by %s, with report %s", t.addr, oid)
+}
+
+// Engine of t, discovered with a request without auth if unknown (RFC 3414 4).
+func (s *Sender) discover(conn net.Conn, t *target) (remoteEngine, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.engine != nil {
+		return *t.engine, nil
+	}
+
+	req := &message{version: versionV3, msgID: s.nextID(), flags: flagReportable, pdu: PDU{Type: GetRequest, RequestID: s.nextID()}}
+	b, err := req.marshal(nil, 0)
+	if err != nil {
+		return remoteEngine{}, err
+	}
+	if _, err = conn.Write(b); err != nil {
+		return remoteEngine{}, err
+	}
+	res, err := s.receive(conn, req)
+	if err != nil {
+		return remoteEngine{}, fmt.Errorf("Failed to discover SNMP engine of %s: %s", t.addr, err.Error())
+	}
+	if len(res.engineID) == 0 {
+		return remoteEngine{}, fmt.Errorf("Failed to discover SNMP engine of %s: no engine ID reported", t.addr)
+	}
+	t.engine = &remoteEngine{id: res.engineID, boots: res.boots, time: res.engineTime, at: time.Now()}
+	return *t.engine, nil
+}
+
+// Next request or message ID.
+func (s *Sender) nextID() int32 {
+	return atomic.AddInt32(&s.requestID, 1) & 0x7fffffff
+}
@@ -0,0 +1,248 @@
+package v2_test
+
+import (
+	"encoding/hex"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+	logger "github.com/cisco-cx/of/wrap/logrus/v2"
+	clock "github.com/cisco-cx/of/wrap/time/v2"
+	trap "github.com/cisco-cx/of/wrap/trap/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// Enforce Notifier Interface
+func TestSenderInterface(t *testing.T) {
+	var _ of.Notifier = &trap.Sender{}
+}
+
+// Receiver on localhost, to be served once configured.
+func newTestReceiver(t *testing.T) *trap.Receiver {
+	r, err := trap.NewReceiver("127.0.0.1:0", logger.New())
+	require.NoError(t, err)
+	return r
+}
+
+// Alert named name.
+func trapAlert(name string) of.Alert {
+	return of.Alert{Labels: map[string]string{"alertname": name, "alert_severity": "major", "source_address": "10.0.0.1"}}
+}
+
+// Write passphrase to a file in dir.
+func passphraseFile(t *testing.T, dir string, name string, passphrase string) string {
+	file := filepath.Join(dir, name)
+	require.NoError(t, ioutil.WriteFile(file, []byte(passphrase+"\n"), 0600))
+	return file
+}
+
+// Next notification of r, failing after a second.
+func nextNotification(t *testing.T, r *trap.Receiver) trap.Notification {
+	select {
+	case n := <-r.Notifications:
+		return n
+	case <-time.After(time.Second):
+		require.FailNow(t, "No notification received")
+	}
+	return trap.Notification{}
+}
+
+// Check n is the ofAlertNotification of an alert named name.
+func requireAlertNotification(t *testing.T, n trap.Notification, name string) {
+	vbs := n.PDU.VarBinds
+	require.Len(t, vbs, 8)
+	require.Equal(t, trap.SysUpTimeOID, vbs[0].OID)
+	require.Equal(t, trap.TimeTicks, vbs[0].Type)
+	require.Equal(t, trap.VarBind{OID: trap.SNMPTrapOID, Type: trap.ObjectIdentifier, Value: trap.DefaultAlertMIB.OID(trap.AlertNotification)}, vbs[1])
+	require.Equal(t, trap.VarBind{OID: trap.DefaultAlertMIB.OID(trap.AlertNameOID) + ".0", Type: trap.OctetString, Value: name}, vbs[2])
+	require.Equal(t, trap.VarBind{OID: trap.DefaultAlertMIB.OID(trap.AlertStateOID) + ".0", Type: trap.Integer, Value: trap.AlertFiring}, vbs[6])
+}
+
+// Test SNMPv2c traps and informs are received.
+func TestSenderV2c(t *testing.T) {
+	r := newTestReceiver(t)
+	defer r.Close()
+	r.Community = "of"
+	go r.Serve()
+
+	for _, inform := range []bool{false, true} {
+		s, err := trap.NewSender(&of.TrapConfig{Targets: []string{r.Addr().String()}, Community: "of", Inform: inform}, &clock.Clock{}, logger.New())
+		require.NoError(t, err)
+		alerts := []of.Alert{trapAlert("a"), trapAlert("b")}
+		require.NoError(t, s.Notify(&alerts))
+
+		for _, name := range []string{"a", "b"} {
+			n := nextNotification(t, r)
+			require.Equal(t, "of", n.Community)
+			if inform == true {
+				require.Equal(t, trap.InformRequest, n.PDU.Type)
+			} else {
+				require.Equal(t, trap.SNMPv2Trap, n.PDU.Type)
+			}
+			requireAlertNotification(t, n, name)
+		}
+	}
+}
+
+// Test SNMPv3 traps and informs are received, for each security level.
+func TestSenderV3(t *testing.T) {
+	dir, err := ioutil.TempDir("", "TestSenderV3")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	authFile := passphraseFile(t, dir, "auth", "maplesyrup")
+	privFile := passphraseFile(t, dir, "priv", "pancakes!")
+
+	for _, cfg := range []of.TrapConfig{
+		{User: "noauth"},
+		{User: "md5", AuthProtocol: of.SNMPMD5, AuthPassphraseFile: authFile},
+		{User: "sha-aes", AuthProtocol: of.SNMPSHA, AuthPassphraseFile: authFile, PrivProtocol: of.SNMPAES, PrivPassphraseFile: privFile},
+		{User: "md5-des", AuthProtocol: of.SNMPMD5, AuthPassphraseFile: authFile, PrivProtocol: of.SNMPDES, PrivPassphraseFile: privFile},
+		{User: "sha-aes", AuthProtocol: of.SNMPSHA, AuthPassphraseFile: authFile, PrivProtocol: of.SNMPAES, PrivPassphraseFile: privFile, Inform: true},
+		{User: "md5-des", AuthProtocol: of.SNMPMD5, AuthPassphraseFile: authFile, PrivProtocol: of.SNMPDES, PrivPassphraseFile: privFile, Inform: true},
+	} {
+		r := newTestReceiver(t)
+		cfg.Targets, cfg.Version, cfg.Timeout = []string{r.Addr().String()}, of.SNMPv3, time.Second
+		if cfg.Inform == false {
+			cfg.EngineID, cfg.EngineBootsFile = "800000090548656c6c6f", filepath.Join(dir, "boots")
+		}
+		s, err := trap.NewSender(&cfg, &clock.Clock{}, logger.New())
+		require.NoError(t, err)
+		r.Users[cfg.User] = &trap.User{Name: cfg.User, Auth: cfg.AuthProtocol, AuthPassphrase: "maplesyrup", Priv: cfg.PrivProtocol, PrivPassphrase: "pancakes!"}
+		go r.Serve()
+
+		alerts := []of.Alert{trapAlert("a"), trapAlert("b")}
+		require.NoError(t, s.Notify(&alerts), cfg.User)
+		for _, name := range []string{"a", "b"} {
+			n := nextNotification(t, r)
+			require.Equal(t, cfg.User, n.User)
+			requireAlertNotification(t, n, name)
+		}
+		if cfg.Inform == false {
+			require.Equal(t, "800000090548656c6c6f", hex.EncodeToString(s.EngineID()))
+		}
+		r.Close()
+	}
+}
+
+// Test engine boots of a configured engine ID grow on every start.
+func TestSenderEngineBoots(t *testing.T) {
+	dir, err := ioutil.TempDir("", "TestSenderEngineBoots")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	r := newTestReceiver(t)
+	defer r.Close()
+	r.Users["of"] = &trap.User{Name: "of"}
+	go r.Serve()
+
+	cfg := of.TrapConfig{Targets: []string{r.Addr().String()}, Version: of.SNMPv3, User: "of", EngineID: "800000090548656c6c6f", EngineBootsFile: filepath.Join(dir, "boots")}
+	for _, boots := range []int32{1, 2} {
+		s, err := trap.NewSender(&cfg, &clock.Clock{}, logger.New())
+		require.NoError(t, err)
+		alerts := []of.Alert{trapAlert("a")}
+		require.NoError(t, s.Notify(&alerts))
+		require.Equal(t, boots, nextNotification(t, r).Boots)
+	}
+	b, err := ioutil.ReadFile(cfg.EngineBootsFile)
+	require.NoError(t, err)
+	require.Equal(t, "2\n", string(b))
+
+	require.NoError(t, ioutil.WriteFile(cfg.EngineBootsFile, []byte("many"), 0644))
+	_, err = trap.NewSender(&cfg, &clock.Clock{}, logger.New())
+	require.Contains(t, err.Error(), "Invalid SNMPv3 engine boots")
+}
+
+// Test notifications of unknown users and communities are dropped.
+func TestSenderRejected(t *testing.T) {
+	r := newTestReceiver(t)
+	defer r.Close()
+	go r.Serve()
+
+	s, err := trap.NewSender(&of.TrapConfig{Targets: []string{r.Addr().String()}, Community: "private", Inform: true, Timeout: 50 * time.Millisecond}, &clock.Clock{}, logger.New())
+	require.NoError(t, err)
+	alerts := []of.Alert{trapAlert("a")}
+	err = s.Notify(&alerts)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "Sending SNMP notifications to 1 of 1 targets failed")
+	require.Len(t, r.Notifications, 0)
+}
+
+// Test informs are resent until acknowledged.
+func TestSenderRetries(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	s, err := trap.NewSender(&of.TrapConfig{Targets: []string{conn.LocalAddr().String()}, Inform: true, Retries: 2, Timeout: 50 * time.Millisecond}, &clock.Clock{}, logger.New())
+	require.NoError(t, err)
+	alerts := []of.Alert{trapAlert("a")}
+	require.Error(t, s.Notify(&alerts))
+
+	// The inform was sent once, and resent twice.
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+	buf := make([]byte, 1500)
+	for i := 0; i < 3; i++ {
+		_, _, err = conn.ReadFrom(buf)
+		require.NoError(t, err)
+	}
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(50*time.Millisecond)))
+	_, _, err = conn.ReadFrom(buf)
+	require.Error(t, err)
+}
+
+// Test notifications are spaced by rate.
+func TestSenderRate(t *testing.T) {
+	r := newTestReceiver(t)
+	defer r.Close()
+	go r.Serve()
+
+	c := &clock.FixedClock{T: time.Date(2020, 5, 1, 22, 22, 53, 0, time.UTC)}
+	s, err := trap.NewSender(&of.TrapConfig{Targets: []string{r.Addr().String()}, Rate: 4}, c, logger.New())
+	require.NoError(t, err)
+	var waits []time.Duration
+	s.Sleep = func(d time.Duration) {
+		waits = append(waits, d)
+		c.Add(d)
+	}
+
+	alerts := []of.Alert{trapAlert("a"), trapAlert("b"), trapAlert("c")}
+	require.NoError(t, s.Notify(&alerts))
+	require.Equal(t, []time.Duration{250 * time.Millisecond, 250 * time.Millisecond}, waits)
+	for _, name := range []string{"a", "b", "c"} {
+		requireAlertNotification(t, nextNotification(t, r), name)
+	}
+}
+
+// Test configuration errors.
+func TestNewSenderErrors(t *testing.T) {
+	c := &clock.Clock{}
+	_, err := trap.NewSender(&of.TrapConfig{}, c, logger.New())
+	require.Equal(t, of.ErrNoTrapTargets, err)
+
+	_, err = trap.NewSender(&of.TrapConfig{Targets: []string{"localhost"}, Version: "v1"}, c, logger.New())
+	require.Contains(t, err.Error(), of.ErrUnknownSNMPVersion.Error())
+
+	_, err = trap.NewSender(&of.TrapConfig{Targets: []string{"localhost"}, Version: of.SNMPv3}, c, logger.New())
+	require.Equal(t, of.ErrNoSNMPUser, err)
+
+	_, err = trap.NewSender(&of.TrapConfig{Targets: []string{"localhost"}, MIB: "1.3.6.x"}, c, logger.New())
+	require.Contains(t, err.Error(), "Invalid OF-ALERT-MIB OID")
+
+	_, err = trap.NewSender(&of.TrapConfig{Targets: []string{"localhost"}, Version: of.SNMPv3, User: "of", EngineID: "80"}, c, logger.New())
+	require.Contains(t, err.Error(), "Invalid SNMPv3 engine ID")
+
+	_, err = trap.NewSender(&of.TrapConfig{Targets: []string{"localhost"}, Version: of.SNMPv3, User: "of", EngineID: "800000090548656c6c6f"}, c, logger.New())
+	require.Equal(t, of.ErrNoEngineBootsFile, err)
+
+	_, err = trap.NewSender(&of.TrapConfig{Targets: []string{"localhost"}, Version: of.SNMPv3, User: "of", AuthProtocol: of.SNMPSHA, AuthPassphraseFile: "/nonexistent"}, c, logger.New())
+	require.Error(t, err)
+
+	s, err := trap.NewSender(&of.TrapConfig{Targets: []string{"localhost"}}, c, logger.New())
+	require.NoError(t, err)
+	require.Equal(t, of.SNMPv2c, s.Version)
+	require.Equal(t, "public", s.Community)
+}
@@ -0,0 +1,240 @@
+package v2
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha1"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"sync"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+)
+
+const (
+	// USM message flags.
+	flagAuth       byte = 0x01
+	flagPriv       byte = 0x02
+	flagReportable byte = 0x04
+
+	// Length of HMAC-MD5-96 and HMAC-SHA-96 auth params.
+	authParamsLen = 12
+)
+
+// Represents an SNMPv3 user of the user based security model (RFC 3414).
+// Keys are derived from passphrases once, and localized to each engine as needed.
+type User struct {
+	Name           string
+	Auth           of.SNMPAuthProtocol
+	AuthPassphrase string
+	Priv           of.SNMPPrivProtocol
+	PrivPassphrase string
+
+	mu     sync.Mutex
+	authKu []byte
+	privKu []byte
+	keys   map[string]*usmKeys // By engine ID.
+}
+
+// Represents keys of a user, localized to an engine.
+type usmKeys struct {
+	auth []byte
+	priv []byte
+}
+
+// Check protocols and passphrases of u.
+func (u *User) Validate() error {
+	if u.Name == "" {
+		return of.ErrNoSNMPUser
+	}
+	switch u.Auth {
+	case of.SNMPNoAuth, of.SNMPMD5, of.SNMPSHA:
+	default:
+		return fmt.Errorf("%s %q, expected MD5 or SHA", of.ErrUnknownAuthProtocol, u.Auth)
+	}
+	switch u.Priv {
+	case of.SNMPNoPriv, of.SNMPDES, of.SNMPAES:
+	default:
+		return fmt.Errorf("%s %q, expected DES or AES", of.ErrUnknownPrivProtocol, u.Priv)
+	}
+	if u.Priv != of.SNMPNoPriv && u.Auth == of.SNMPNoAuth {
+		return of.ErrPrivWithoutAuth
+	}
+	if (u.Auth != of.SNMPNoAuth && len(u.AuthPassphrase) < 8) || (u.Priv != of.SNMPNoPriv && len(u.PrivPassphrase) < 8) {
+		return of.ErrShortPassphrase
+	}
+	return nil
+}
+
+// Security level of u, as message flags.
+func (u *User) flags() byte {
+	var flags byte
+	if u.Auth != of.SNMPNoAuth {
+		flags |= flagAuth
+	}
+	if u.Priv != of.SNMPNoPriv {
+		flags |= flagPriv
+	}
+	return flags
+}
+
+// Hash of the auth protocol of u.
+func (u *User) hash() func() hash.Hash {
+	if u.Auth == of.SNMPSHA {
+		return sha1.New
+	}
+	return md5.New
+}
+
+// Keys of u localized to engineID.
+func (u *User) localKeys(engineID []byte) *usmKeys {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if k, ok := u.keys[string(engineID)]; ok == true {
+		return k
+	}
+	if u.keys == nil {
+		u.keys = make(map[string]*usmKeys)
+	}
+	h := u.hash()
+	k := &usmKeys{}
+	if u.Auth != of.SNMPNoAuth {
+		if u.authKu == nil {
+			u.authKu = PassphraseToKey(h, u.AuthPassphrase)
+		}
+		k.auth = LocalizeKey(h, u.authKu, engineID)
+	}
+	if u.Priv != of.SNMPNoPriv {
+		if u.privKu == nil {
+			u.privKu = PassphraseToKey(h, u.PrivPassphrase)
+		}
+		k.priv = LocalizeKey(h, u.privKu, engineID)
+	}
+	u.keys[string(engineID)] = k
+	return k
+}
+
+// Set auth params of msg at offset to its HMAC, computed with them zeroed.
+func (u *User) sign(msg []byte, k *usmKeys, offset int) {
+	copy(msg[offset:offset+authParamsLen], make([]byte, authParamsLen))
+	mac := hmac.New(u.hash(), k.auth)
+	mac.Write(msg)
+	copy(msg[offset:offset+authParamsLen], mac.Sum(nil)[:authParamsLen])
+}
+
+// Check auth params of msg at offset. msg is not modified.
+func (u *User) verify(msg []byte, k *usmKeys, offset int) bool {
+	if offset+authParamsLen > len(msg) {
+		return false
+	}
+	signed := make([]byte, len(msg))
+	copy(signed, msg)
+	u.sign(signed, k, offset)
+	return hmac.Equal(signed[offset:offset+authParamsLen], msg[offset:offset+authParamsLen])
+}
+
+// Encrypt scoped PDU plain, returning it with the priv params to decrypt it.
+func (u *User) encrypt(k *usmKeys, plain []byte, boots int32, engineTime int32, salt uint64) ([]byte, []byte, error) {
+	switch u.Priv {
+	case of.SNMPDES:
+		privParams := make([]byte, 8)
+		binary.BigEndian.PutUint32(privParams, uint32(boots))
+		binary.BigEndian.PutUint32(privParams[4:], uint32(salt))
+		block, err := des.NewCipher(k.priv[:8])
+		if err != nil {
+			return nil, nil, err
+		}
+		iv := make([]byte, 8)
+		for i := range iv {
+			iv[i] = k.priv[8+i] ^ privParams[i]
+		}
+		// Padding is ignored by receivers, as the scoped PDU has its own length.
+		padded := append(append([]byte{}, plain...), make([]byte, (8-len(plain)%8)%8)...)
+		out := make([]byte, len(padded))
+		cipher.NewCBCEncrypter(block, iv).CryptBlocks(out, padded)
+		return out, privParams, nil
+	case of.SNMPAES:
+		privParams := make([]byte, 8)
+		binary.BigEndian.PutUint64(privParams, salt)
+		block, err := aes.NewCipher(k.priv[:16])
+		if err != nil {
+			return nil, nil, err
+		}
+		out := make([]byte, len(plain))
+		cipher.NewCFBEncrypter(block, aesIV(boots, engineTime, privParams)).XORKeyStream(out, plain)
+		return out, privParams, nil
+	}
+	return plain, nil, nil
+}
+
+// Decrypt encrypted scoped PDU with privParams.
+func (u *User) decrypt(k *usmKeys, encrypted []byte, privParams []byte, boots int32, engineTime int32) ([]byte, error) {
+	if len(privParams) != 8 {
+		return nil, fmt.Errorf("%s Invalid privacy params", of.ErrMalformedSNMP)
+	}
+	switch u.Priv {
+	case of.SNMPDES:
+		if len(encrypted)%8 != 0 {
+			return nil, fmt.Errorf("%s Invalid DES encrypted length", of.ErrMalformedSNMP)
+		}
+		block, err := des.NewCipher(k.priv[:8])
+		if err != nil {
+			return nil, err
+		}
+		iv := make([]byte, 8)
+		for i := range iv {
+			iv[i] = k.priv[8+i] ^ privParams[i]
+		}
+		out := make([]byte, len(encrypted))
+		cipher.NewCBCDecrypter(block, iv).CryptBlocks(out, encrypted)
+		return out, nil
+	case of.SNMPAES:
+		block, err := aes.NewCipher(k.priv[:16])
+		if err != nil {
+			return nil, err
+		}
+		out := make([]byte, len(encrypted))
+		cipher.NewCFBDecrypter(block, aesIV(boots, engineTime, privParams)).XORKeyStream(out, encrypted)
+		return out, nil
+	}
+	return encrypted, nil
+}
+
+// IV of AES, from engine boots and time, and salt (RFC 3826).
+func aesIV(boots int32, engineTime int32, salt []byte) []byte {
+	iv := make([]byte, 16)
+	binary.BigEndian.PutUint32(iv, uint32(boots))
+	binary.BigEndian.PutUint32(iv[4:], uint32(engineTime))
+	copy(iv[8:], salt)
+	return iv
+}
+
+// Key of passphrase, hashed over 1MB of it repeated (RFC 3414 A.2).
+func PassphraseToKey(h func() hash.Hash, passphrase string) []byte {
+	d := h()
+	buf := make([]byte, 64)
+	p := []byte(passphrase)
+	i := 0
+	for count := 0; count < 1048576; count += len(buf) {
+		for j := range buf {
+			buf[j] = p[i%len(p)]
+			i++
+		}
+		d.Write(buf)
+	}
+	return d.Sum(nil)
+}
+
+// Key ku localized to engineID.
+func LocalizeKey(h func() hash.Hash, ku []byte, engineID []byte) []byte {
+	d := h()
+	d.Write(ku)
+	d.Write(engineID)
+	d.Write(ku)
+	return d.Sum(nil)
+}
@@ -0,0 +1,50 @@
+package v2_test
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"encoding/hex"
+	"testing"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+	trap "github.com/cisco-cx/of/wrap/trap/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// Test key derivation and localization against RFC 3414 A.3.
+func TestLocalizeKey(t *testing.T) {
+	engineID, err := hex.DecodeString("000000000000000000000002")
+	require.NoError(t, err)
+
+	ku := trap.PassphraseToKey(md5.New, "maplesyrup")
+	require.Equal(t, "9faf3283884e92834ebc9847d8edd963", hex.EncodeToString(ku))
+	require.Equal(t, "526f5eed9fcce26f8964c2930787d82b", hex.EncodeToString(trap.LocalizeKey(md5.New, ku, engineID)))
+
+	ku = trap.PassphraseToKey(sha1.New, "maplesyrup")
+	require.Equal(t, "9fb5cc0381497b3793528939ff788d5d79145211", hex.EncodeToString(ku))
+	require.Equal(t, "6695febc9288e36282235fc7151f128497b38f3f", hex.EncodeToString(trap.LocalizeKey(sha1.New, ku, engineID)))
+}
+
+// Test user validation.
+func TestUserValidate(t *testing.T) {
+	u := trap.User{Name: "of", Auth: of.SNMPSHA, AuthPassphrase: "maplesyrup", Priv: of.SNMPAES, PrivPassphrase: "maplesyrup"}
+	require.NoError(t, u.Validate())
+
+	u = trap.User{Name: "of"}
+	require.NoError(t, u.Validate())
+
+	u = trap.User{}
+	require.Equal(t, of.ErrNoSNMPUser, u.Validate())
+
+	u = trap.User{Name: "of", Auth: "SHA256", AuthPassphrase: "maplesyrup"}
+	require.Contains(t, u.Validate().Error(), of.ErrUnknownAuthProtocol.Error())
+
+	u = trap.User{Name: "of", Auth: of.SNMPMD5, AuthPassphrase: "maplesyrup", Priv: "3DES", PrivPassphrase: "maplesyrup"}
+	require.Contains(t, u.Validate().Error(), of.ErrUnknownPrivProtocol.Error())
+
+	u = trap.User{Name: "of", Priv: of.SNMPDES, PrivPassphrase: "maplesyrup"}
+	require.Equal(t, of.ErrPrivWithoutAuth, u.Validate())
+
+	u = trap.User{Name: "of", Auth: of.SNMPMD5, AuthPassphrase: "maple"}
+	require.Equal(t, of.ErrShortPassphrase, u.Validate())
+}
@@ -24,7 +24,8 @@ func NewPath(path string, l *logger.Logger) (*Notifier, error) {
 
 // Watch for change in given path. Path can be a file or directory.
 func (fs *Notifier) Watch() error {
-	fs.c = make(chan notify.EventInfo, 1)
+	// notify drops events when c is full, like the rename after writing a file in a watched dir.
+	fs.c = make(chan notify.EventInfo, 64)
 	if err := notify.Watch(fs.path, fs.c, notify.All); err != nil {
 		return err
 	}
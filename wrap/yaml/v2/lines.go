@@ -0,0 +1,143 @@
+package v2
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Maps paths of values in a YAML document to their line number.
+// Paths are JSON pointers, like /config/alerts/0/name.
+type Lines map[string]int
+
+// Represents a mapping or sequence being indexed.
+type lineFrame struct {
+	indent int
+	path   string
+	seq    bool
+	index  int
+}
+
+// Index values of a block style YAML document.
+// Values in flow style ([a, b] or {a: b}) are indexed at the line of their key.
+func NewLines(data []byte) Lines {
+	lines := Lines{"": 1}
+	var stack []lineFrame
+	pending := ""     // Path of key without inline value, expecting nested values.
+	blockIndent := -1 // Indent of key with a literal or folded value, whose lines are skipped.
+
+	for n, text := range strings.Split(string(data), "\n") {
+		lineNum := n + 1
+		content := strings.TrimLeft(text, " ")
+		indent := len(text) - len(content)
+		if blockIndent >= 0 {
+			if strings.TrimSpace(content) == "" || indent > blockIndent {
+				continue
+			}
+			blockIndent = -1
+		}
+		if content == "" || content[0] == '#' || strings.HasPrefix(content, "---") {
+			continue
+		}
+
+		// Sequence items, possibly starting a mapping.
+		for content == "-" || strings.HasPrefix(content, "- ") {
+			for len(stack) != 0 && stack[len(stack)-1].indent > indent {
+				stack = stack[:len(stack)-1]
+			}
+			if len(stack) != 0 && stack[len(stack)-1].indent == indent && stack[len(stack)-1].seq {
+				stack[len(stack)-1].index++
+			} else {
+				stack = append(stack, lineFrame{indent: indent, path: pending, seq: true})
+			}
+			top := stack[len(stack)-1]
+			pending = top.path + "/" + strconv.Itoa(top.index)
+			lines[pending] = lineNum
+
+			rest := strings.TrimLeft(strings.TrimPrefix(content, "-"), " ")
+			indent += len(content) - len(rest)
+			content = rest
+		}
+		if content == "" || content[0] == '#' {
+			continue
+		}
+
+		key, value, ok := splitKey(content)
+		if ok == false {
+			// Scalar item, already indexed.
+			continue
+		}
+
+		for len(stack) != 0 {
+			top := stack[len(stack)-1]
+			if top.indent > indent || (top.indent == indent && top.seq) {
+				stack = stack[:len(stack)-1]
+				continue
+			}
+			break
+		}
+		if len(stack) == 0 || stack[len(stack)-1].indent != indent {
+			stack = append(stack, lineFrame{indent: indent, path: pending})
+		}
+
+		path := stack[len(stack)-1].path + "/" + key
+		lines[path] = lineNum
+		pending = path
+		if value != "" && (value[0] == '|' || value[0] == '>') {
+			blockIndent = indent
+		}
+	}
+	return lines
+}
+
+// Line of value at path, or of its closest parent.
+func (l Lines) Line(path string) int {
+	for {
+		if n, ok := l[path]; ok == true {
+			return n
+		}
+		idx := strings.LastIndex(path, "/")
+		if idx < 0 {
+			return 0
+		}
+		path = path[:idx]
+	}
+}
+
+// Split "key: value" into key and value, without comments.
+// Returns false if content is not a key.
+func splitKey(content string) (string, string, bool) {
+	var key, rest string
+	if content[0] == '"' || content[0] == '\'' {
+		end := strings.IndexByte(content[1:], content[0])
+		if end < 0 {
+			return "", "", false
+		}
+		key, rest = content[1:end+1], content[end+2:]
+		if strings.HasPrefix(rest, ":") == false {
+			return "", "", false
+		}
+		rest = rest[1:]
+	} else {
+		idx := strings.Index(content, ": ")
+		if idx < 0 {
+			if strings.HasSuffix(content, ":") == false {
+				idx = strings.Index(content, ":\t")
+				if idx < 0 {
+					return "", "", false
+				}
+			} else {
+				idx = len(content) - 1
+			}
+		}
+		key, rest = content[:idx], content[idx+1:]
+		if strings.ContainsAny(key, "{[") {
+			return "", "", false
+		}
+	}
+
+	value := strings.TrimSpace(rest)
+	if strings.HasPrefix(value, "#") {
+		value = ""
+	}
+	return strings.TrimSpace(key), value, true
+}
@@ -0,0 +1,57 @@
+package v2_test
+
+import (
+	"testing"
+
+	yaml "github.com/cisco-cx/of/wrap/yaml/v2"
+	"github.com/stretchr/testify/require"
+)
+
+const linesDoc = `# Comment
+epc:
+  defaults:
+    enabled: true
+    description: |
+      key: not a key
+    label_mods:
+    - type: set
+      key: vendor
+  alerts:
+  - name: starCardDown
+    firing:
+      select:
+      - type: equals
+        "oid": .1.3.6.1.6.3.1.1.4.1.0 # snmpTrapOID
+        values:
+        - .1.3.6.1.4.1.8164.2.5
+        - .1.3.6.1.4.1.8164.2.6
+  -
+    name: starCardUp
+    label_mods: [{type: set, key: a, value: b}]
+nso:
+  alerts: []
+`
+
+// Test line numbers of values.
+func TestLines(t *testing.T) {
+	lines := yaml.NewLines([]byte(linesDoc))
+	tests := map[string]int{
+		"/epc":                                   2,
+		"/epc/defaults/enabled":                  4,
+		"/epc/defaults/label_mods/0":             8,
+		"/epc/defaults/label_mods/0/key":         9,
+		"/epc/alerts/0/name":                     11,
+		"/epc/alerts/0/firing/select/0/oid":      15,
+		"/epc/alerts/0/firing/select/0/as":       14,
+		"/epc/alerts/0/firing/select/0/values/1": 18,
+		"/epc/alerts/1/name":                     20,
+		"/epc/alerts/1/label_mods/0/key":         21,
+		"/nso/alerts":                            23,
+		"/missing":                               1,
+	}
+	for path, line := range tests {
+		require.Equal(t, line, lines.Line(path), path)
+	}
+	_, ok := lines["/epc/defaults/key"]
+	require.False(t, ok)
+}
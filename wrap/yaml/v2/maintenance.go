@@ -0,0 +1,21 @@
+package v2
+
+import (
+	"io"
+	"io/ioutil"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+	"gopkg.in/yaml.v2"
+)
+
+type Maintenance of.MaintenanceSchedule
+
+// Implements maintenance schedule Decoder.
+func (m *Maintenance) Decode(r io.Reader) error {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	return yaml.UnmarshalStrict(data, m)
+}
@@ -0,0 +1,27 @@
+package v2
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	of "github.com/cisco-cx/of/pkg/v2"
+	"gopkg.in/yaml.v2"
+)
+
+type Routes of.RoutesConfig
+
+// Implements routes file Decoder.
+func (rc *Routes) Decode(r io.Reader) error {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	// An empty file is more likely being written than meant to drop every route.
+	if len(bytes.TrimSpace(data)) == 0 {
+		return fmt.Errorf("%s Routes file is empty", of.ErrInvalidRoutes)
+	}
+	return yaml.UnmarshalStrict(data, rc)
+}
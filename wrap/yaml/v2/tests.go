@@ -0,0 +1,21 @@
+package v2
+
+import (
+	"io"
+	"io/ioutil"
+
+	snmp_config "github.com/cisco-cx/of/pkg/v2/snmp"
+	"gopkg.in/yaml.v2"
+)
+
+type TestFile snmp_config.TestFile
+
+// Implements snmp v2 test file Decoder.
+func (t *TestFile) Decode(r io.Reader) error {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	return yaml.UnmarshalStrict(data, t)
+}